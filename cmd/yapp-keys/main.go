@@ -0,0 +1,174 @@
+// Command yapp-keys manages the keys access tokens are signed with.
+//
+//	yapp-keys list [-all]
+//	yapp-keys generate [-alg EdDSA|RS256] [-activate-in 0s]
+//	yapp-keys rotate [-alg EdDSA|RS256] [-publish-for 15m]
+//	yapp-keys retire [-revoke] <kid>
+//	yapp-keys prune
+//
+// Start with generate on a fresh deployment, then rotate on a schedule.
+// rotate publishes the new key in /.well-known/jwks.json first and only
+// switches signing over once -publish-for has passed, so verifiers that cache
+// the JWKS see it before the first token signed with it. The old key keeps
+// verifying until its last token expired. retire -revoke is for leaked keys
+// and signs out everyone holding a token from it.
+//
+// It reads the same environment as the server (database and
+// TOKEN_ENCRYPTION_KEY / JWT_SECRET_KEY for sealing the private keys).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/services"
+)
+
+const usage = `usage: yapp-keys <command> [flags]
+
+commands:
+  list       show signing keys (-all includes expired ones)
+  generate   add a key without retiring the others
+  rotate     add a key that takes over signing after -publish-for
+  retire     stop a key from signing, -revoke also rejects its tokens
+  prune      delete expired keys
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	_ = godotenv.Load()
+
+	pool, err := database.PostgresDBConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer pool.Close()
+
+	keys := services.NewSigningKeyService(repositories.NewSigningKeyRepository(), pool)
+	ctx := context.Background()
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "list":
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		all := fs.Bool("all", false, "include expired keys")
+		_ = fs.Parse(args)
+
+		list, err := keys.ListSigningKeys(ctx, *all)
+		if err != nil {
+			fatal(err)
+		}
+		printKeys(list)
+
+	case "generate":
+		fs := flag.NewFlagSet("generate", flag.ExitOnError)
+		alg := fs.String("alg", auth.AlgEdDSA, "EdDSA or RS256")
+		activateIn := fs.Duration("activate-in", 0, "delay before the key starts signing")
+		_ = fs.Parse(args)
+
+		key, err := keys.GenerateSigningKey(ctx, *alg, time.Now().Add(*activateIn))
+		if err != nil {
+			fatal(err)
+		}
+		printKeys([]*models.JWTSigningKey{key})
+
+	case "rotate":
+		fs := flag.NewFlagSet("rotate", flag.ExitOnError)
+		alg := fs.String("alg", auth.AlgEdDSA, "EdDSA or RS256")
+		publishFor := fs.Duration("publish-for", 15*time.Minute, "how long the key is only published before it signs")
+		_ = fs.Parse(args)
+
+		key, retired, err := keys.RotateSigningKeys(ctx, *alg, *publishFor)
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Println("new key:")
+		printKeys([]*models.JWTSigningKey{key})
+		if len(retired) > 0 {
+			fmt.Println("\nretiring:")
+			printKeys(retired)
+		}
+
+	case "retire":
+		fs := flag.NewFlagSet("retire", flag.ExitOnError)
+		revoke := fs.Bool("revoke", false, "also reject tokens the key already signed")
+		_ = fs.Parse(args)
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: yapp-keys retire [-revoke] <kid>")
+			os.Exit(2)
+		}
+
+		key, err := keys.RetireSigningKey(ctx, fs.Arg(0), *revoke)
+		if err != nil {
+			fatal(err)
+		}
+		printKeys([]*models.JWTSigningKey{key})
+
+	case "prune":
+		deleted, err := keys.PruneSigningKeys(ctx)
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Printf("deleted %d expired keys\n", deleted)
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func printKeys(keys []*models.JWTSigningKey) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALG\tSTATE\tACTIVATES\tRETIRES\tEXPIRES")
+
+	now := time.Now()
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			k.ID,
+			k.Algorithm,
+			keyState(k, now),
+			k.ActivatesAt.Format(time.RFC3339),
+			formatTime(k.RetiresAt),
+			formatTime(k.ExpiresAt),
+		)
+	}
+	_ = w.Flush()
+}
+
+func keyState(k *models.JWTSigningKey, now time.Time) string {
+	switch {
+	case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+		return "expired"
+	case k.RetiresAt != nil && !now.Before(*k.RetiresAt):
+		return "verify-only"
+	case now.Before(k.ActivatesAt):
+		return "published"
+	default:
+		return "signing"
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "yapp-keys: %v\n", err)
+	os.Exit(1)
+}
@@ -67,3 +67,8 @@ func SetupEnvironment() (config AppConfig, err error) {
 		RedisClient:  rdb,
 	}, nil
 }
+
+// IsDevelopment : true when running with APP_ENV=development
+func IsDevelopment() bool {
+	return os.Getenv("APP_ENV") == "development"
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// AppLinkProviderConfig : OAuth client for one of the accounts users can show
+// on their profile. A provider is offered once APP_LINK_<NAME>_CLIENT_ID is set,
+// steam has no OAuth and is switched on with APP_LINK_STEAM_ENABLED=true.
+type AppLinkProviderConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the frontend page the provider returns to, it forwards
+	// the query parameters to POST /me/app-links/:provider/callback
+	RedirectURL string
+}
+
+func GetAppLinkProviders() map[string]AppLinkProviderConfig {
+	_ = godotenv.Load()
+
+	baseURL := strings.TrimRight(GetMailConfig().AppBaseURL, "/")
+
+	out := make(map[string]AppLinkProviderConfig)
+	for _, name := range []string{"spotify", "reddit", "twitter", "steam"} {
+		prefix := "APP_LINK_" + strings.ToUpper(name) + "_"
+
+		cfg := AppLinkProviderConfig{
+			Name:         name,
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+		if cfg.RedirectURL == "" {
+			cfg.RedirectURL = baseURL + "/settings/connections/callback/" + name
+		}
+
+		if cfg.ClientID == "" && os.Getenv(prefix+"ENABLED") != "true" {
+			continue
+		}
+		out[name] = cfg
+	}
+
+	return out
+}
+
+// GetTokenEncryptionKey : base64 encoded 32 byte key for third party tokens
+// stored in the database. Empty means one is derived from JWT_SECRET_KEY.
+func GetTokenEncryptionKey() string {
+	_ = godotenv.Load()
+	return os.Getenv("TOKEN_ENCRYPTION_KEY")
+}
@@ -9,8 +9,9 @@ import (
 	"github.com/joho/godotenv"
 )
 
-// corsMiddleware : Inject CORS settings into app
-func buildCORS() gin.HandlerFunc {
+// GetAllowedOrigins : browser origins allowed to call the API with
+// credentials. CORS and the WebSocket origin check both use it.
+func GetAllowedOrigins() []string {
 	_ = godotenv.Load()
 
 	frontendOrigin := os.Getenv("FRONTEND_ORIGIN")
@@ -18,13 +19,18 @@ func buildCORS() gin.HandlerFunc {
 		frontendOrigin = "http://localhost:3000"
 	}
 
+	return []string{
+		"http://localhost:3000",
+		"http://127.0.0.1:3000",
+		frontendOrigin,
+		"https://yapp-frontend-gamma.vercel.app",
+	}
+}
+
+// corsMiddleware : Inject CORS settings into app
+func buildCORS() gin.HandlerFunc {
 	cfg := cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:3000",
-			"http://127.0.0.1:3000",
-			frontendOrigin,
-			"https://yapp-frontend-gamma.vercel.app",
-		},
+		AllowOrigins: GetAllowedOrigins(),
 		AllowMethods: []string{
 			"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS",
 		},
@@ -37,6 +43,12 @@ func buildCORS() gin.HandlerFunc {
 			"X-CSRF-Token",
 			"X-Timezone",
 		},
+		// the frontend runs on another site and can't read the csrf_token
+		// cookie, it picks the token up from this header instead
+		ExposeHeaders: []string{
+			"X-CSRF-Token",
+			"Retry-After",
+		},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -18,3 +18,11 @@ func GetSecretKey() string {
 	return secretKey
 
 }
+
+// AcceptLegacyHS256Tokens : access tokens signed with JWT_SECRET_KEY keep
+// working until JWT_ACCEPT_HS256=false. Turn it off once every instance signs
+// with a rotated key and the last HS256 token has expired.
+func AcceptLegacyHS256Tokens() bool {
+	_ = godotenv.Load()
+	return os.Getenv("JWT_ACCEPT_HS256") != "false"
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverLog  = "log"
+)
+
+// MailConfig : outgoing e-mail. Locally SMTP_HOST=mailpit catches everything
+// without delivering it (see docker-compose.yml).
+type MailConfig struct {
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+
+	// AppBaseURL is the frontend origin links in e-mails point at
+	AppBaseURL string
+}
+
+func GetMailConfig() MailConfig {
+	_ = godotenv.Load()
+
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil || port <= 0 {
+		port = 587
+	}
+
+	// without an explicit driver only development falls back to the log,
+	// anywhere else mail.NewMailer refuses to start
+	driver := os.Getenv("MAIL_DRIVER")
+	if driver == "" {
+		switch {
+		case os.Getenv("SMTP_HOST") != "":
+			driver = MailDriverSMTP
+		case IsDevelopment():
+			driver = MailDriverLog
+		}
+	}
+
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "Yapp <no-reply@yapp.local>"
+	}
+
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:5173"
+	}
+
+	return MailConfig{
+		Driver:     driver,
+		Host:       os.Getenv("SMTP_HOST"),
+		Port:       port,
+		Username:   os.Getenv("SMTP_USERNAME"),
+		Password:   os.Getenv("SMTP_PASSWORD"),
+		From:       from,
+		AppBaseURL: baseURL,
+	}
+}
+
+// RequireEmailVerification : when true, unverified accounts are kept away from
+// actions guarded by auth.RequireVerifiedEmail
+func RequireEmailVerification() bool {
+	_ = godotenv.Load()
+	return os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+}
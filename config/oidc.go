@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// OIDCProviderConfig : one OpenID Connect identity provider. Providers are
+// listed in OIDC_PROVIDERS (comma separated) and configured through
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET and
+// optionally OIDC_<NAME>_DISPLAY_NAME, OIDC_<NAME>_SCOPES, OIDC_<NAME>_REDIRECT_URL.
+type OIDCProviderConfig struct {
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// RedirectURL is the frontend page the provider sends the browser back
+	// to, it forwards code and state to POST /auth/oidc/:provider/callback
+	RedirectURL string
+}
+
+func GetOIDCProviders() []OIDCProviderConfig {
+	_ = godotenv.Load()
+
+	baseURL := strings.TrimRight(GetMailConfig().AppBaseURL, "/")
+
+	providers := make([]OIDCProviderConfig, 0)
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		cfg := OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  os.Getenv(prefix + "DISPLAY_NAME"),
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       []string{"openid", "email", "profile"},
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+
+		if cfg.Issuer == "" || cfg.ClientID == "" {
+			continue
+		}
+		if cfg.DisplayName == "" {
+			cfg.DisplayName = name
+		}
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			cfg.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+		}
+		if cfg.RedirectURL == "" {
+			cfg.RedirectURL = baseURL + "/oauth/callback/" + name
+		}
+
+		providers = append(providers, cfg)
+	}
+
+	return providers
+}
+
+// GetMockOIDCIssuer : where the development-only mock provider believes it lives,
+// has to match OIDC_MOCK_ISSUER for the "mock" provider to verify its tokens
+func GetMockOIDCIssuer() string {
+	_ = godotenv.Load()
+
+	if issuer := os.Getenv("OIDC_MOCK_ISSUER"); issuer != "" {
+		return strings.TrimRight(issuer, "/")
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return "http://localhost:" + port + "/dev/oidc"
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+// PasswordConfig : Argon2id cost for new password hashes, unset values keep the
+// built-in defaults. Raising them is safe at any time, existing hashes are
+// upgraded as their owners sign in.
+type PasswordConfig struct {
+	Argon2MemoryKiB   uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+
+	// BreachedListDir holds the Pwned Passwords ranges, one <PREFIX>.txt per
+	// SHA-1 prefix. Empty turns the breached password check off.
+	BreachedListDir string
+}
+
+func GetPasswordConfig() PasswordConfig {
+	_ = godotenv.Load()
+
+	return PasswordConfig{
+		Argon2MemoryKiB:   uint32(envUint("PASSWORD_ARGON2_MEMORY_KIB", 32)),
+		Argon2Iterations:  uint32(envUint("PASSWORD_ARGON2_ITERATIONS", 32)),
+		Argon2Parallelism: uint8(envUint("PASSWORD_ARGON2_PARALLELISM", 8)),
+		BreachedListDir:   os.Getenv("BREACHED_PASSWORDS_DIR"),
+	}
+}
+
+// envUint reads an unsigned number, 0 when unset or unparseable
+func envUint(key string, bits int) uint64 {
+	n, err := strconv.ParseUint(os.Getenv(key), 10, bits)
+	if err != nil {
+		return 0
+	}
+	return n
+}
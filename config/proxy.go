@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// GetTrustedProxies : CIDRs whose X-Forwarded-For is believed when working out
+// the client address, comma separated in TRUSTED_PROXIES. Defaults to private
+// networks, which covers nginx in compose and the Render load balancer.
+// Anything else could spoof its address past the sign-in throttle.
+func GetTrustedProxies() []string {
+	_ = godotenv.Load()
+
+	raw := os.Getenv("TRUSTED_PROXIES")
+	if raw == "" {
+		return []string{
+			"127.0.0.0/8",
+			"::1/128",
+			"10.0.0.0/8",
+			"172.16.0.0/12",
+			"192.168.0.0/16",
+			"fc00::/7",
+		}
+	}
+
+	out := make([]string, 0)
+	for _, cidr := range strings.Split(raw, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			out = append(out, cidr)
+		}
+	}
+	return out
+}
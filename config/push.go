@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// VAPIDConfig : Web Push application server keys (base64url, as produced by any VAPID key generator)
+type VAPIDConfig struct {
+	PublicKey  string
+	PrivateKey string
+	// Subject is a mailto: or https: contact push services can reach us at
+	Subject string
+}
+
+func GetVAPIDConfig() VAPIDConfig {
+	_ = godotenv.Load()
+
+	subject := os.Getenv("VAPID_SUBJECT")
+	if subject == "" {
+		subject = "mailto:admin@yapp.local"
+	}
+
+	return VAPIDConfig{
+		PublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+		PrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+		Subject:    subject,
+	}
+}
+
+// Enabled reports whether real pushes can be signed and encrypted.
+func (c VAPIDConfig) Enabled() bool {
+	return c.PublicKey != "" && c.PrivateKey != ""
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// RateLimitConfig : RATE_LIMIT_ENABLED=false turns every limit off. RATE_LIMITS
+// overrides single policies, comma separated, e.g.
+//
+//	RATE_LIMITS=api.write=30/1m:10,ws.typing=off
+type RateLimitConfig struct {
+	Enabled   bool
+	Overrides map[string]string
+}
+
+func GetRateLimitConfig() RateLimitConfig {
+	_ = godotenv.Load()
+
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("RATE_LIMITS"), ",") {
+		name, spec, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		overrides[strings.TrimSpace(name)] = strings.TrimSpace(spec)
+	}
+
+	return RateLimitConfig{
+		Enabled:   os.Getenv("RATE_LIMIT_ENABLED") != "false",
+		Overrides: overrides,
+	}
+}
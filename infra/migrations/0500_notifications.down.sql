@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS notifications_set_updated_at ON notifications;
+DROP TABLE IF EXISTS notifications;
+DROP TYPE IF EXISTS notification_type;
@@ -0,0 +1,44 @@
+DO $$ BEGIN
+  CREATE TYPE notification_type AS ENUM (
+    'mention',
+    'mention_everyone',
+    'friend_request',
+    'friend_request_accepted',
+    'join_request_accepted',
+    'invite_accepted'
+  );
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type notification_type NOT NULL,
+
+    -- who caused it, kept even if the actor later deletes their account
+    actor_id uuid REFERENCES users(id) ON DELETE SET NULL,
+
+    hall_id uuid REFERENCES halls(id) ON DELETE CASCADE,
+    room_id uuid REFERENCES rooms(id) ON DELETE CASCADE,
+    message_id uuid REFERENCES messages(id) ON DELETE CASCADE,
+
+    -- friend request / join request / invite id, no FK since those rows are deleted once handled
+    reference_id uuid,
+    preview text,
+
+    read_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_created_idx
+    ON notifications(user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS notifications_user_unread_idx
+    ON notifications(user_id)
+    WHERE read_at IS NULL;
+
+DROP TRIGGER IF EXISTS notifications_set_updated_at ON notifications;
+
+CREATE TRIGGER notifications_set_updated_at
+BEFORE UPDATE ON notifications
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
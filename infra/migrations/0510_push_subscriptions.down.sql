@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS push_subscriptions_set_updated_at ON push_subscriptions;
+DROP TABLE IF EXISTS push_subscriptions;
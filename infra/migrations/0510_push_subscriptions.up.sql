@@ -0,0 +1,30 @@
+-- one row per browser/device that granted push permission
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    endpoint text NOT NULL UNIQUE,
+    p256dh text NOT NULL,
+    auth text NOT NULL,
+
+    user_agent text,
+    device_name text,
+
+    -- PushSubscription.expirationTime, most browsers leave it null
+    expires_at timestamptz,
+
+    failure_count int NOT NULL DEFAULT 0,
+    last_success_at timestamptz,
+
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS push_subscriptions_user_id_idx
+    ON push_subscriptions(user_id);
+
+DROP TRIGGER IF EXISTS push_subscriptions_set_updated_at ON push_subscriptions;
+
+CREATE TRIGGER push_subscriptions_set_updated_at
+BEFORE UPDATE ON push_subscriptions
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS notification_settings_set_updated_at ON notification_settings;
+DROP TABLE IF EXISTS notification_settings;
+DROP TYPE IF EXISTS notification_scope;
+DROP TYPE IF EXISTS notification_level;
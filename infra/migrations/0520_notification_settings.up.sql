@@ -0,0 +1,37 @@
+DO $$ BEGIN
+  CREATE TYPE notification_level AS ENUM ('all','mentions','nothing');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+DO $$ BEGIN
+  CREATE TYPE notification_scope AS ENUM ('hall','floor','room');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- A row overrides the scope above it, NULL columns mean "inherit".
+-- room > floor > hall > defaults
+CREATE TABLE IF NOT EXISTS notification_settings (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    scope notification_scope NOT NULL,
+    scope_id uuid NOT NULL,
+    -- owning hall of the scope, lets the row go away with the hall
+    hall_id uuid NOT NULL REFERENCES halls(id) ON DELETE CASCADE,
+
+    level notification_level,
+    suppress_everyone boolean,
+    muted_until timestamptz,
+
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+
+    UNIQUE (user_id, scope, scope_id)
+);
+
+CREATE INDEX IF NOT EXISTS notification_settings_scope_idx
+    ON notification_settings(scope, scope_id);
+
+DROP TRIGGER IF EXISTS notification_settings_set_updated_at ON notification_settings;
+
+CREATE TRIGGER notification_settings_set_updated_at
+BEFORE UPDATE ON notification_settings
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
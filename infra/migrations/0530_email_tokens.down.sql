@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS email_tokens;
+DROP TYPE IF EXISTS email_token_purpose;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
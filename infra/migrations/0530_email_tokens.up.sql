@@ -0,0 +1,21 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+
+DO $$ BEGIN
+  CREATE TYPE email_token_purpose AS ENUM ('verify_email','reset_password');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- Only the HMAC of a token is stored, the raw value lives in the e-mailed link.
+CREATE TABLE IF NOT EXISTS email_tokens (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose email_token_purpose NOT NULL,
+    token_hash bytea NOT NULL UNIQUE,
+    -- address the token was sent to, verifying only counts if it is still current
+    email text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS email_tokens_user_purpose_idx
+    ON email_tokens(user_id, purpose, created_at DESC);
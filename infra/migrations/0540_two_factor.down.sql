@@ -0,0 +1,4 @@
+ALTER TABLE halls DROP COLUMN IF EXISTS require_mod_2fa;
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TRIGGER IF EXISTS user_totp_set_updated_at ON user_totp;
+DROP TABLE IF EXISTS user_totp;
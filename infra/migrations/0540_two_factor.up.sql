@@ -0,0 +1,30 @@
+-- enabled_at stays NULL until the user proved the authenticator works
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret text NOT NULL,
+    enabled_at timestamptz,
+    -- last accepted 30s step, a code is never accepted twice
+    last_used_step bigint,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS user_totp_set_updated_at ON user_totp;
+
+CREATE TRIGGER user_totp_set_updated_at
+BEFORE UPDATE ON user_totp
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash bytea NOT NULL UNIQUE,
+    used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_recovery_codes_user_id_idx
+    ON user_recovery_codes(user_id);
+
+-- members holding ban_members / manage_roles need 2FA for those to apply
+ALTER TABLE halls ADD COLUMN IF NOT EXISTS require_mod_2fa boolean NOT NULL DEFAULT false;
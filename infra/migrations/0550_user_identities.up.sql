@@ -0,0 +1,28 @@
+-- external OpenID Connect logins, (provider, subject) is what the IdP
+-- guarantees to be stable, the e-mail is only kept for display
+CREATE TABLE IF NOT EXISTS user_identities (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider text NOT NULL,
+    subject text NOT NULL,
+    email text,
+    last_login_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+-- authorization requests in flight, keyed by the HMAC of the state parameter
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash bytea PRIMARY KEY,
+    provider text NOT NULL,
+    nonce text NOT NULL,
+    code_verifier text NOT NULL,
+    -- set when a signed in user is linking another login
+    link_user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS oidc_login_states_expires_at_idx
+    ON oidc_login_states(expires_at);
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS app_link_states;
+
+DROP INDEX IF EXISTS user_app_links_provider_external_id_key;
+
+ALTER TABLE user_app_links
+    DROP COLUMN IF EXISTS verified_at,
+    DROP COLUMN IF EXISTS token_expires_at,
+    DROP COLUMN IF EXISTS refresh_token_enc,
+    DROP COLUMN IF EXISTS access_token_enc,
+    DROP COLUMN IF EXISTS external_handle,
+    DROP COLUMN IF EXISTS external_id;
+
+DELETE FROM user_app_links
+WHERE provider NOT IN ('spotify','reddit','steam','twitter');
+
+ALTER TABLE user_app_links ALTER COLUMN provider TYPE app_provider USING provider::app_provider;
@@ -0,0 +1,30 @@
+-- providers come from the application registry now, not a database enum
+ALTER TABLE user_app_links ALTER COLUMN provider TYPE text USING provider::text;
+
+-- filled once the user went through the provider's login, rows from the old
+-- free-form URL endpoint keep them NULL and show up unverified
+ALTER TABLE user_app_links
+    ADD COLUMN IF NOT EXISTS external_id text,
+    ADD COLUMN IF NOT EXISTS external_handle text,
+    ADD COLUMN IF NOT EXISTS access_token_enc bytea,
+    ADD COLUMN IF NOT EXISTS refresh_token_enc bytea,
+    ADD COLUMN IF NOT EXISTS token_expires_at timestamptz,
+    ADD COLUMN IF NOT EXISTS verified_at timestamptz;
+
+-- one external account can only back a single profile
+CREATE UNIQUE INDEX IF NOT EXISTS user_app_links_provider_external_id_key
+    ON user_app_links(provider, external_id)
+    WHERE external_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS app_link_states (
+    state_hash bytea PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider text NOT NULL,
+    code_verifier text NOT NULL,
+    show_on_profile boolean NOT NULL DEFAULT true,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS app_link_states_expires_at_idx
+    ON app_link_states(expires_at);
@@ -0,0 +1,14 @@
+-- asymmetric keys for access tokens. A key signs from activates_at until
+-- retires_at and verifies until expires_at, the gap lets tokens it issued
+-- run out after a rotation. Private keys are sealed with TOKEN_ENCRYPTION_KEY.
+CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+    kid text PRIMARY KEY,
+    algorithm text NOT NULL CHECK (algorithm IN ('EdDSA', 'RS256')),
+    private_key_enc bytea NOT NULL,
+    activates_at timestamptz NOT NULL DEFAULT now(),
+    retires_at timestamptz,
+    expires_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CHECK (retires_at IS NULL OR retires_at >= activates_at),
+    CHECK (expires_at IS NULL OR (retires_at IS NOT NULL AND expires_at >= retires_at))
+);
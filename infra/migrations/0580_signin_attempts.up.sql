@@ -0,0 +1,22 @@
+-- postgres can't drop enum values, the down migration leaves it in place
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'new_signin';
+
+-- every sign-in attempt, user_id is null when the e-mail matched no account.
+-- device_id points at the user_metadatas row of the browser that tried.
+CREATE TABLE IF NOT EXISTS signin_attempts (
+    id uuid PRIMARY KEY,
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    device_id uuid REFERENCES user_metadatas(id) ON DELETE SET NULL,
+    method text NOT NULL,
+    succeeded boolean NOT NULL,
+    failure_reason text,
+    ip inet,
+    user_agent text,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS signin_attempts_user_created_idx
+    ON signin_attempts(user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS signin_attempts_ip_created_idx
+    ON signin_attempts(ip, created_at DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS messages_room_author_time_idx;
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS slowmode_seconds;
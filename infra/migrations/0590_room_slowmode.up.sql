@@ -0,0 +1,6 @@
+ALTER TABLE rooms
+    ADD COLUMN slowmode_seconds integer NOT NULL DEFAULT 0
+        CHECK (slowmode_seconds >= 0 AND slowmode_seconds <= 21600);
+
+-- slow mode looks up the author's latest message in the room
+CREATE INDEX messages_room_author_time_idx ON messages (room_id, author_id, created_at DESC);
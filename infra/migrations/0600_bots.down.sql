@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS bot_tokens;
+
+DELETE FROM users WHERE is_bot;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_bot_owner_chk,
+    DROP COLUMN IF EXISTS bot_owner_id,
+    DROP COLUMN IF EXISTS is_bot;
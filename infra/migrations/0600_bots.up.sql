@@ -0,0 +1,20 @@
+-- bots are users owned by a human, they never sign in with a password and
+-- authenticate with a bot token instead
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS is_bot boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS bot_owner_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT users_bot_owner_chk CHECK (is_bot = (bot_owner_id IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS users_bot_owner_idx ON users(bot_owner_id) WHERE is_bot;
+
+-- long lived, only the keyed hash is stored. Resetting a bot's token deletes
+-- the old row.
+CREATE TABLE IF NOT EXISTS bot_tokens (
+    id uuid PRIMARY KEY,
+    bot_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash bytea NOT NULL UNIQUE,
+    last_used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS bot_tokens_bot_idx ON bot_tokens(bot_id);
@@ -0,0 +1,14 @@
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS author_avatar_url,
+    DROP COLUMN IF EXISTS author_name,
+    DROP COLUMN IF EXISTS webhook_id;
+
+DROP TABLE IF EXISTS webhooks;
+
+-- ownerless bots only ever authored webhook messages
+DELETE FROM messages WHERE author_id IN (SELECT id FROM users WHERE is_bot AND bot_owner_id IS NULL);
+DELETE FROM users WHERE is_bot AND bot_owner_id IS NULL;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_bot_owner_chk,
+    ADD CONSTRAINT users_bot_owner_chk CHECK (is_bot = (bot_owner_id IS NOT NULL));
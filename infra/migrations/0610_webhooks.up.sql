@@ -0,0 +1,27 @@
+-- a webhook posts as its own user, a bot without an owner, so its messages
+-- keep an author after the webhook is deleted
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_bot_owner_chk,
+    ADD CONSTRAINT users_bot_owner_chk CHECK (bot_owner_id IS NULL OR is_bot);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id uuid PRIMARY KEY,
+    room_id uuid NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    avatar_url text,
+    -- keyed hash of the secret in the webhook's URL
+    token_hash bytea NOT NULL UNIQUE,
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_room_idx ON webhooks(room_id);
+
+-- payloads may override the webhook's name and avatar per message
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS webhook_id uuid REFERENCES webhooks(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS author_name text,
+    ADD COLUMN IF NOT EXISTS author_avatar_url text;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS outgoing_webhooks (
+    id uuid PRIMARY KEY,
+    hall_id uuid NOT NULL REFERENCES halls(id) ON DELETE CASCADE,
+    url text NOT NULL,
+    -- signing secret, sealed with the server key since it has to be read back
+    secret_enc bytea NOT NULL,
+    events text[] NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    -- consecutive failed deliveries, reset by any success
+    failure_count integer NOT NULL DEFAULT 0,
+    disabled_at timestamptz,
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS outgoing_webhooks_hall_idx ON outgoing_webhooks(hall_id);
+
+CREATE TABLE IF NOT EXISTS outgoing_webhook_deliveries (
+    id uuid PRIMARY KEY,
+    webhook_id uuid NOT NULL REFERENCES outgoing_webhooks(id) ON DELETE CASCADE,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    last_status_code integer,
+    last_error text,
+    delivered_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- the worker only ever looks for pending rows that are due
+CREATE INDEX IF NOT EXISTS outgoing_webhook_deliveries_due_idx
+    ON outgoing_webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS outgoing_webhook_deliveries_webhook_idx
+    ON outgoing_webhook_deliveries(webhook_id, created_at DESC);
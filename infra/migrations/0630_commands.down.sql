@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS bot_commands;
+
+-- enum values can't be dropped, only the rows using it
+DELETE FROM notifications WHERE type = 'reminder';
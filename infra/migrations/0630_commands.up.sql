@@ -0,0 +1,18 @@
+-- slash commands bots register for the halls they are in, the built-in ones
+-- live in code
+CREATE TABLE IF NOT EXISTS bot_commands (
+    id uuid PRIMARY KEY,
+    hall_id uuid NOT NULL REFERENCES halls(id) ON DELETE CASCADE,
+    bot_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    description text NOT NULL,
+    -- typed arguments, see models.CommandOption
+    options jsonb NOT NULL DEFAULT '[]',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (hall_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS bot_commands_bot_idx ON bot_commands(bot_id, hall_id);
+
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'reminder';
@@ -0,0 +1,14 @@
+-- personal access tokens let scripts call the API as their user, limited to
+-- the scopes picked when the token was created. Revoking deletes the row.
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    token_hash bytea NOT NULL UNIQUE,
+    scopes text[] NOT NULL,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS personal_access_tokens_user_idx ON personal_access_tokens(user_id);
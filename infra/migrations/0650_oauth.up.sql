@@ -0,0 +1,31 @@
+-- third-party apps users can authorize to act on their behalf. client_id is
+-- the app's id, public apps (no secret) have to use PKCE.
+CREATE TABLE IF NOT EXISTS oauth_apps (
+    id uuid PRIMARY KEY,
+    owner_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    description text,
+    redirect_uris text[] NOT NULL,
+    client_secret_hash bytea,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS oauth_apps_owner_idx ON oauth_apps(owner_id);
+
+-- one row per grant, refreshing swaps both hashes in place. Revoking either
+-- token deletes the row. Authorization codes live in Redis.
+CREATE TABLE IF NOT EXISTS oauth_tokens (
+    id uuid PRIMARY KEY,
+    app_id uuid NOT NULL REFERENCES oauth_apps(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    access_token_hash bytea NOT NULL UNIQUE,
+    refresh_token_hash bytea NOT NULL UNIQUE,
+    scopes text[] NOT NULL,
+    access_expires_at timestamptz NOT NULL,
+    refresh_expires_at timestamptz NOT NULL,
+    last_used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS oauth_tokens_user_app_idx ON oauth_tokens(user_id, app_id);
@@ -0,0 +1,12 @@
+-- every edit keeps the text it replaced, moderators need to see what a
+-- message said before it was changed. written_at is when that text went
+-- live, the message's sent_at or the edit before it.
+CREATE TABLE IF NOT EXISTS message_revisions (
+    id uuid PRIMARY KEY,
+    message_id uuid NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    content text,
+    written_at timestamptz NOT NULL,
+    replaced_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS message_revisions_message_idx ON message_revisions(message_id, replaced_at);
@@ -0,0 +1,10 @@
+-- members with text_manage_messages pin messages to the top of a room. A
+-- message is pinned at most once, pinned_by survives the user as NULL.
+CREATE TABLE IF NOT EXISTS pinned_messages (
+    message_id uuid PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    room_id uuid NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    pinned_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    pinned_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS pinned_messages_room_idx ON pinned_messages(room_id, pinned_at DESC);
@@ -0,0 +1,17 @@
+-- personal bookmarks, a user saves a message once with an optional note and
+-- reminder. reminded_at is set when the reminder has been delivered.
+CREATE TABLE IF NOT EXISTS saved_messages (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_id uuid NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    note text,
+    remind_at timestamptz,
+    reminded_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (user_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS saved_messages_user_idx ON saved_messages(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS saved_messages_due_idx ON saved_messages(remind_at)
+    WHERE remind_at IS NOT NULL AND reminded_at IS NULL;
@@ -0,0 +1,23 @@
+-- messages composed now and posted later by the dispatcher. A row is claimed
+-- as 'sending' while it is posted and removed once the message is in the
+-- room, 'failed' keeps the reason. Cancelling deletes the row.
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    id uuid PRIMARY KEY,
+    author_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    room_id uuid NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    content text,
+    -- see models.ScheduledAttachment
+    attachments jsonb NOT NULL DEFAULT '[]',
+    mention_everyone boolean NOT NULL DEFAULT false,
+    mentions uuid[] NOT NULL DEFAULT '{}',
+    send_at timestamptz NOT NULL,
+    status text NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sending', 'failed')),
+    failure text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_messages_author_idx ON scheduled_messages(author_id, send_at, id);
+CREATE INDEX IF NOT EXISTS scheduled_messages_due_idx ON scheduled_messages(send_at)
+    WHERE status = 'pending';
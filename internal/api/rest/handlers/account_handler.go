@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type AccountHandler struct {
+	services.IAccountService
+}
+
+func NewAccountHandler(accountService services.IAccountService) *AccountHandler {
+	return &AccountHandler{accountService}
+}
+
+// VerifyEmail godoc
+// @Summary      Verify e-mail address
+// @Description  Consumes the token from the verification link sent after signup or an e-mail change.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.VerifyEmailReq          true  "Token from the link"
+// @Success      200   {object}  map[string]interface{}  "E-mail verified"
+// @Failure      400   {object}  map[string]interface{}  "Invalid or expired link"
+// @Router       /auth/email/verify [post]
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	req := &dto.VerifyEmailReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	if err := h.IAccountService.VerifyEmail(c.Request.Context(), req); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Email verified successfully",
+		"data":    nil,
+	})
+}
+
+// ResendVerificationEmail godoc
+// @Summary      Resend verification e-mail
+// @Description  Sends a fresh verification link to the current address, previous links stop working.
+// @Tags         auth
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}  "E-mail sent"
+// @Failure      400  {object}  map[string]interface{}  "Already verified"
+// @Failure      429  {object}  map[string]interface{}  "Requested too recently"
+// @Router       /auth/email/resend [post]
+func (h *AccountHandler) ResendVerificationEmail(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	if err := h.IAccountService.ResendVerificationEmail(c.Request.Context(), userInfo); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Verification email sent",
+		"data":    nil,
+	})
+}
+
+// RequestPasswordReset godoc
+// @Summary      Forgot password
+// @Description  E-mails a single-use reset link. Always succeeds so it can't be used to probe for accounts.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.ForgotPasswordReq       true  "Account e-mail"
+// @Success      200   {object}  map[string]interface{}  "Reset e-mail sent if the account exists"
+// @Router       /auth/password/forgot [post]
+func (h *AccountHandler) RequestPasswordReset(c *gin.Context) {
+	req := &dto.ForgotPasswordReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	if err := h.IAccountService.RequestPasswordReset(c.Request.Context(), req); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "If an account exists for this email, a reset link has been sent",
+		"data":    nil,
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Sets a new password using the token from the reset link.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.ResetPasswordReq        true  "Token and new password"
+// @Success      200   {object}  map[string]interface{}  "Password changed"
+// @Failure      400   {object}  map[string]interface{}  "Invalid or expired link / weak password"
+// @Router       /auth/password/reset [post]
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	req := &dto.ResetPasswordReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	if err := h.IAccountService.ResetPassword(c.Request.Context(), req); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password reset successfully",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type AppLinkHandler struct {
+	services.IAppLinkService
+}
+
+func NewAppLinkHandler(appLinkService services.IAppLinkService) *AppLinkHandler {
+	return &AppLinkHandler{appLinkService}
+}
+
+// ListAppLinkProviders godoc
+// @Summary      List connectable apps
+// @Tags         users
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/app-links/providers [get]
+func (h *AppLinkHandler) ListAppLinkProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "App providers retrieved successfully",
+		"data":    h.IAppLinkService.ListAppLinkProviders(c.Request.Context()),
+	})
+}
+
+// BeginAppLink godoc
+// @Summary      Connect an app account
+// @Description  Returns the provider URL to send the browser to, the provider redirects back to the frontend.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        provider  path      string               true   "Provider name"
+// @Param        body      body      dto.BeginAppLinkReq  false  "Profile visibility once connected"
+// @Success      200       {object}  map[string]interface{}
+// @Failure      404       {object}  map[string]interface{}  "Unknown provider"
+// @Router       /me/app-links/{provider}/connect [post]
+func (h *AppLinkHandler) BeginAppLink(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	// the body is optional
+	req := &dto.BeginAppLinkReq{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(req); err != nil {
+			utils.WriteError(c, utils.ErrorInvalidInput)
+			return
+		}
+	}
+
+	res, err := h.IAppLinkService.BeginAppLink(c.Request.Context(), userInfo, models.AppProvider(c.Param("provider")), req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Redirect to the app provider",
+		"data":    res,
+	})
+}
+
+// CompleteAppLink godoc
+// @Summary      Finish connecting an app account
+// @Description  Takes every query parameter of the redirect back from the provider and stores the verified account.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        provider  path      string                  true  "Provider name"
+// @Param        body      body      dto.CompleteAppLinkReq  true  "Redirect query parameters"
+// @Success      200       {object}  map[string]interface{}
+// @Failure      400       {object}  map[string]interface{}  "Expired or reused attempt"
+// @Failure      409       {object}  map[string]interface{}  "Account connected to another profile"
+// @Router       /me/app-links/{provider}/callback [post]
+func (h *AppLinkHandler) CompleteAppLink(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.CompleteAppLinkReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IAppLinkService.CompleteAppLink(c.Request.Context(), userInfo, models.AppProvider(c.Param("provider")), req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "App account connected successfully",
+		"data":    res,
+	})
+}
+
+// UpdateAppLink godoc
+// @Summary      Show or hide a connected app on the profile
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        provider  path      string                true  "Provider name"
+// @Param        body      body      dto.UpdateAppLinkReq  true  "Visibility"
+// @Success      200       {object}  map[string]interface{}
+// @Failure      404       {object}  map[string]interface{}
+// @Router       /me/app-links/{provider} [patch]
+func (h *AppLinkHandler) UpdateAppLink(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.UpdateAppLinkReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IAppLinkService.UpdateAppLink(c.Request.Context(), userInfo, models.AppProvider(c.Param("provider")), req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "App link updated successfully",
+		"data":    res,
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
 	dto "github.com/suck-seed/yapp/internal/dto/user"
 	"github.com/suck-seed/yapp/internal/services"
 	"github.com/suck-seed/yapp/internal/utils"
@@ -53,9 +55,10 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        body  body      dto.SigninUserReq          true  "Signin payload"
-// @Success      200   {object}  map[string]interface{}  "Signed in — jwt cookie is set"
+// @Success      200   {object}  map[string]interface{}  "Signed in — jwt cookie is set, or mfa_required with an mfa_token"
 // @Failure      400   {object}  map[string]interface{}  "Invalid input"
 // @Failure      401   {object}  map[string]interface{}  "Wrong credentials"
+// @Failure      429   {object}  map[string]interface{}  "Too many failed attempts"
 // @Router       /auth/signin [post]
 func (h *AuthHandler) Signin(c *gin.Context) {
 	userSignIn := &dto.SigninUserReq{}
@@ -64,25 +67,28 @@ func (h *AuthHandler) Signin(c *gin.Context) {
 		return
 	}
 
-	signInRes, err := h.IUserService.Signin(c.Request.Context(), userSignIn)
+	signInRes, err := h.IUserService.Signin(c.Request.Context(), userSignIn, clientInfo(c))
 	if err != nil {
 		utils.WriteError(c, err)
 		return
 	}
 
-	// set cookie
-	const cookieSeconds = 24 * 60 * 60
-
-	isHTTPS := c.GetHeader("X-Forwarded-Proto") == "https"
-	if isHTTPS {
-		c.SetSameSite(http.SameSiteNoneMode)
-		c.SetCookie("jwt", signInRes.AccessToken, cookieSeconds, "/", "", true, true)
-	} else {
-		// local dev over plain http
-		c.SetSameSite(http.SameSiteLaxMode)
-		c.SetCookie("jwt", signInRes.AccessToken, cookieSeconds, "/", "", false, true)
+	// second factor pending, no session until POST /auth/signin/2fa
+	if signInRes.MFARequired {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Two-factor authentication required",
+			"data": &dto.SigninUserRes{
+				Success:     signInRes.Success,
+				MFARequired: true,
+				MFAToken:    signInRes.MFAToken,
+			},
+		})
+		return
 	}
 
+	setAuthCookie(c, signInRes.AccessToken)
+
 	// filtered response (not sending accesstoken over https, so removed it)
 	res := &dto.SigninUserRes{
 		UserMe:      signInRes.UserMe,
@@ -97,6 +103,54 @@ func (h *AuthHandler) Signin(c *gin.Context) {
 	})
 }
 
+const (
+	deviceCookieName   = "yapp_device"
+	deviceCookieMaxAge = 400 * 24 * 60 * 60
+)
+
+// clientInfo collects what sign-in alerts and throttling need. The device id
+// lives in its own long lived cookie so it survives sign-outs, API clients
+// without a cookie jar can send X-Device-ID instead.
+func clientInfo(c *gin.Context) *dto.ClientInfo {
+	deviceID, err := c.Cookie(deviceCookieName)
+	if err != nil || deviceID == "" {
+		deviceID = c.GetHeader("X-Device-ID")
+	}
+	if deviceID == "" {
+		deviceID = uuid.NewString()
+
+		isHTTPS := c.GetHeader("X-Forwarded-Proto") == "https"
+		if isHTTPS {
+			c.SetSameSite(http.SameSiteNoneMode)
+		} else {
+			c.SetSameSite(http.SameSiteLaxMode)
+		}
+		c.SetCookie(deviceCookieName, deviceID, deviceCookieMaxAge, "/", "", isHTTPS, true)
+	}
+
+	return &dto.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		DeviceID:  deviceID,
+	}
+}
+
+func setAuthCookie(c *gin.Context, accessToken string) {
+	const cookieSeconds = 24 * 60 * 60
+
+	isHTTPS := c.GetHeader("X-Forwarded-Proto") == "https"
+	if isHTTPS {
+		c.SetSameSite(http.SameSiteNoneMode)
+		c.SetCookie("jwt", accessToken, cookieSeconds, "/", "", true, true)
+	} else {
+		// local dev over plain http
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie("jwt", accessToken, cookieSeconds, "/", "", false, true)
+	}
+
+	auth.RotateCSRFToken(c)
+}
+
 // Signout godoc
 // @Summary      Sign out
 // @Description  Clears the `jwt` cookie, effectively ending the session.
@@ -116,9 +170,28 @@ func (h *AuthHandler) Signout(c *gin.Context) {
 		c.SetSameSite(http.SameSiteLaxMode)
 		c.SetCookie("jwt", "", -1, "/", "", false, true)
 	}
+	auth.RotateCSRFToken(c)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Signed out successfully",
 		"data":    nil,
 	})
 }
+
+// GetCSRFToken godoc
+// @Summary      Get the CSRF token
+// @Description  For frontends on another site that can't read the csrf_token cookie. Send it back as X-CSRF-Token on every POST/PUT/PATCH/DELETE made with the jwt cookie. It changes on sign-in and sign-out.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Router       /auth/csrf [get]
+func (h *AuthHandler) GetCSRFToken(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "CSRF token retrieved successfully",
+		"data": gin.H{
+			"csrf_token": auth.CurrentCSRFToken(c),
+		},
+	})
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type BotHandler struct {
+	services.IBotService
+}
+
+func NewBotHandler(botService services.IBotService) *BotHandler {
+	return &BotHandler{botService}
+}
+
+// CreateBot godoc
+// @Summary      Create a bot
+// @Description  The bot's token is only returned here and by the token reset, send it as "Authorization: Bot <token>".
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        body  body      dto.CreateBotReq  true  "Bot profile"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}  "Invalid input or too many bots"
+// @Failure      409   {object}  map[string]interface{}  "Username taken"
+// @Router       /me/bots [post]
+func (h *BotHandler) CreateBot(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.CreateBotReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IBotService.CreateBot(c.Request.Context(), userInfo, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bot created successfully",
+		"data":    res,
+	})
+}
+
+// ListMyBots godoc
+// @Summary      List the bots you own
+// @Tags         bots
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/bots [get]
+func (h *BotHandler) ListMyBots(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IBotService.ListMyBots(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bots retrieved successfully",
+		"data":    res,
+	})
+}
+
+// ResetBotToken godoc
+// @Summary      Reset a bot's token
+// @Description  The previous token stops working immediately.
+// @Tags         bots
+// @Produce      json
+// @Security     CookieAuth
+// @Param        botID  path      string  true  "Bot ID"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      404    {object}  map[string]interface{}  "Bot not found"
+// @Router       /me/bots/{botID}/token [post]
+func (h *BotHandler) ResetBotToken(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	botID, err := uuid.Parse(c.Param("botID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.IBotService.ResetBotToken(c.Request.Context(), userInfo, botID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bot token reset successfully",
+		"data":    res,
+	})
+}
+
+// DeleteBot godoc
+// @Summary      Delete a bot
+// @Tags         bots
+// @Produce      json
+// @Security     CookieAuth
+// @Param        botID  path      string  true  "Bot ID"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      404    {object}  map[string]interface{}  "Bot not found"
+// @Router       /me/bots/{botID} [delete]
+func (h *BotHandler) DeleteBot(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	botID, err := uuid.Parse(c.Param("botID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IBotService.DeleteBot(c.Request.Context(), userInfo, botID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bot deleted successfully",
+		"data":    nil,
+	})
+}
+
+// AddBotToHall godoc
+// @Summary      Add one of your bots to a hall
+// @Description  Needs the manage hall permission, and manage roles to give the bot anything but the default role.
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string               true  "Hall ID"
+// @Param        body    body      dto.AddBotToHallReq  true  "Bot and role"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}  "Missing permission"
+// @Failure      404     {object}  map[string]interface{}  "Bot or role not found"
+// @Router       /halls/{hallID}/bots [post]
+func (h *BotHandler) AddBotToHall(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, err := uuid.Parse(c.Param("hallID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	req := &dto.AddBotToHallReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IBotService.AddBotToHall(c.Request.Context(), userInfo, hallID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bot added to hall successfully",
+		"data":    res,
+	})
+}
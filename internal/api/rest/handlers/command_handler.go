@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type CommandHandler struct {
+	services.ICommandService
+}
+
+func NewCommandHandler(commandService services.ICommandService) *CommandHandler {
+	return &CommandHandler{commandService}
+}
+
+// ListCommands godoc
+// @Summary      Slash commands available in a hall
+// @Description  Autocompletion metadata: built-in commands the caller is allowed to use and the commands registered by the hall's bots, with typed options and a usage line. Commands run when a text frame on /ws starts with "/".
+// @Tags         commands
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string  true   "Hall ID"
+// @Param        prefix  query     string  false  "Only names starting with this"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}  "Not a hall member"
+// @Router       /halls/{hallID}/commands [get]
+func (h *CommandHandler) ListCommands(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, err := uuid.Parse(c.Param("hallID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.ICommandService.ListCommands(c.Request.Context(), userInfo, hallID, c.Query("prefix"))
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Commands retrieved successfully",
+		"data":    res,
+	})
+}
+
+// RegisterBotCommands godoc
+// @Summary      Replace the calling bot's commands in a hall
+// @Description  Bot tokens only. Invocations reach the bot as "command" frames on /ws and are answered through the reply endpoint within 15 minutes.
+// @Tags         commands
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        hallID  path      string                      true  "Hall ID"
+// @Param        body    body      dto.RegisterBotCommandsReq  true  "Commands"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}  "Invalid command definition"
+// @Failure      409     {object}  map[string]interface{}  "Name used by another command"
+// @Router       /halls/{hallID}/commands [put]
+func (h *CommandHandler) RegisterBotCommands(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, err := uuid.Parse(c.Param("hallID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	req := &dto.RegisterBotCommandsReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.ICommandService.RegisterBotCommands(c.Request.Context(), userInfo, hallID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Commands registered successfully",
+		"data":    res,
+	})
+}
+
+// ReplyToInteraction godoc
+// @Summary      Answer a command invocation
+// @Description  Bot tokens only. Ephemeral replies are shown to the invoking connection alone, others are posted to the room as the bot.
+// @Tags         commands
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        interactionID  path      string               true  "Interaction ID"
+// @Param        body           body      dto.CommandReplyReq  true  "Reply"
+// @Success      200            {object}  map[string]interface{}
+// @Failure      404            {object}  map[string]interface{}  "Unknown or expired interaction"
+// @Router       /commands/interactions/{interactionID}/reply [post]
+func (h *CommandHandler) ReplyToInteraction(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	interactionID, err := uuid.Parse(c.Param("interactionID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	req := &dto.CommandReplyReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.ICommandService.ReplyToInteraction(c.Request.Context(), userInfo, interactionID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Reply sent successfully",
+		"data":    res,
+	})
+}
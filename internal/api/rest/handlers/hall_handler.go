@@ -315,6 +315,98 @@ func (h *HallHandler) UpdateHallProfile(c *gin.Context) {
 
 }
 
+// ─────────────────────────────────────────────────────────────────────────────
+// SETTINGS — SECURITY
+// ─────────────────────────────────────────────────────────────────────────────
+
+// GetHallSecuritySettings godoc
+// @Summary      Get hall security settings
+// @Description  Returns whether moderators must have two-factor authentication enabled. Owner only.
+// @Tags         hall-settings
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string  true  "Hall ID (UUID)"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Router       /halls/{hallID}/settings/security [get]
+func (h *HallHandler) GetHallSecuritySettings(c *gin.Context) {
+
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, err := uuid.Parse(c.Param("hallID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.IHallService.GetHallSecuritySettings(c.Request.Context(), userInfo, hallID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Hall security settings retrieved successfully",
+		"data":    res,
+	})
+
+}
+
+// UpdateHallSecuritySettings godoc
+// @Summary      Update hall security settings
+// @Description  Toggles the moderator 2FA requirement. The owner must have 2FA enabled to turn it on.
+// @Tags         hall-settings
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string                             true  "Hall ID (UUID)"
+// @Param        body    body      dto.UpdateHallSecuritySettingsReq  true  "Security settings"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Router       /halls/{hallID}/settings/security [patch]
+func (h *HallHandler) UpdateHallSecuritySettings(c *gin.Context) {
+
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, err := uuid.Parse(c.Param("hallID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	var req dto.UpdateHallSecuritySettingsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IHallService.UpdateHallSecuritySettings(c.Request.Context(), userInfo, hallID, &req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Hall security settings updated successfully",
+		"data":    res,
+	})
+
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // SETTINGS — MEMBERS
 // ─────────────────────────────────────────────────────────────────────────────
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/auth"
+)
+
+type JWKSHandler struct {
+	*auth.KeyRing
+}
+
+func NewJWKSHandler(keyRing *auth.KeyRing) *JWKSHandler {
+	return &JWKSHandler{keyRing}
+}
+
+// GetJWKS godoc
+// @Summary      Access token verification keys
+// @Description  Public keys for verifying access tokens, looked up by the kid header. Served at the root, not under /api/v1. Keys show up here before they start signing.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  auth.JSONWebKeySet
+// @Router       /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// verifiers cache it, rotation publishes new keys well before this runs out
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.KeyRing.JWKS())
+}
@@ -145,6 +145,52 @@ func (h *MessageHandler) GetMessage(c *gin.Context) {
 	})
 }
 
+// GetMessageHistory godoc
+// @Summary      List a message's earlier versions
+// @Description  Returns the text each edit replaced, oldest first. Only the author and members with text_manage_messages may see it.
+// @Tags         messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        roomID     path      string  true  "Room ID (UUID)"
+// @Param        messageID  path      string  true  "Message ID (UUID)"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      403        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Router       /rooms/{roomID}/messages/{messageID}/history [get]
+func (h *MessageHandler) GetMessageHistory(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("roomID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	messageID, err := uuid.Parse(c.Param("messageID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.IMessageService.GetMessageHistory(c.Request.Context(), userInfo, roomID, messageID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Message history retrieved successfully",
+		"data":    res,
+	})
+}
+
 // UpdateMessage godoc
 // @Summary      Edit a message
 // @Description  Updates the content of a message. Only the original author may edit.
@@ -343,3 +389,132 @@ func (h *MessageHandler) RemoveReaction(c *gin.Context) {
 		"data":    nil,
 	})
 }
+
+// PinMessage godoc
+// @Summary      Pin a message
+// @Description  Pins a message to its room. Needs text_manage_messages, a room holds at most 50 pins. Pinning a pinned message returns the existing pin.
+// @Tags         messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        roomID     path      string  true  "Room ID (UUID)"
+// @Param        messageID  path      string  true  "Message ID (UUID)"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      403        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Router       /rooms/{roomID}/messages/{messageID}/pin [put]
+func (h *MessageHandler) PinMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("roomID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	messageID, err := uuid.Parse(c.Param("messageID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.IMessageService.PinMessage(c.Request.Context(), userInfo, roomID, messageID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Message pinned",
+		"data":    res,
+	})
+}
+
+// UnpinMessage godoc
+// @Summary      Unpin a message
+// @Description  Removes a message from its room's pins. Needs text_manage_messages.
+// @Tags         messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        roomID     path      string  true  "Room ID (UUID)"
+// @Param        messageID  path      string  true  "Message ID (UUID)"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}
+// @Failure      403        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Router       /rooms/{roomID}/messages/{messageID}/pin [delete]
+func (h *MessageHandler) UnpinMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("roomID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	messageID, err := uuid.Parse(c.Param("messageID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IMessageService.UnpinMessage(c.Request.Context(), userInfo, roomID, messageID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Message unpinned",
+		"data":    nil,
+	})
+}
+
+// GetPinnedMessages godoc
+// @Summary      List a room's pinned messages
+// @Description  Returns the room's pins, latest pin first, each with who pinned it and when.
+// @Tags         messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        roomID  path      string  true  "Room ID (UUID)"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Router       /rooms/{roomID}/pins [get]
+func (h *MessageHandler) GetPinnedMessages(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("roomID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.IMessageService.GetPinnedMessages(c.Request.Context(), userInfo, roomID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pinned messages retrieved successfully",
+		"data":    res,
+	})
+}
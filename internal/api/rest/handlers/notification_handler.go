@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/notification"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type NotificationHandler struct {
+	services.INotificationService
+}
+
+func NewNotificationHandler(notificationService services.INotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService}
+}
+
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	query := &dto.ListNotificationsQuery{}
+
+	query.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if err != nil || query.Limit <= 0 {
+		query.Limit = 30
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		id, err := uuid.Parse(beforeStr)
+		if err != nil {
+			utils.WriteError(c, utils.ErrorInvalidInput)
+			return
+		}
+		query.Before = &id
+	}
+
+	query.UnreadOnly = c.Query("unread_only") == "true"
+
+	res, err := h.INotificationService.ListNotifications(c.Request.Context(), userInfo, query)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notifications fetched successfully",
+		"data":    res,
+	})
+}
+
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.INotificationService.GetUnreadCount(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Unread count fetched successfully",
+		"data":    res,
+	})
+}
+
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("notificationID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.INotificationService.MarkNotificationRead(c.Request.Context(), userInfo, notificationID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification marked as read",
+		"data":    nil,
+	})
+}
+
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.INotificationService.MarkAllNotificationsRead(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notifications marked as read",
+		"data":    res,
+	})
+}
+
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("notificationID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.INotificationService.DeleteNotification(c.Request.Context(), userInfo, notificationID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification deleted successfully",
+		"data":    nil,
+	})
+}
+
+func (h *NotificationHandler) ClearNotifications(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	if err := h.INotificationService.ClearNotifications(c.Request.Context(), userInfo); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notifications cleared successfully",
+		"data":    nil,
+	})
+}
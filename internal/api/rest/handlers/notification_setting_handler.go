@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/notification"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type NotificationSettingHandler struct {
+	services.INotificationSettingService
+}
+
+func NewNotificationSettingHandler(notificationSettingService services.INotificationSettingService) *NotificationSettingHandler {
+	return &NotificationSettingHandler{notificationSettingService}
+}
+
+func (h *NotificationSettingHandler) ListNotificationSettings(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.INotificationSettingService.ListNotificationSettings(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification settings fetched successfully",
+		"data":    res,
+	})
+}
+
+func (h *NotificationSettingHandler) UpsertNotificationSetting(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	scopeID, err := uuid.Parse(c.Param("scopeID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	req := &dto.UpsertNotificationSettingReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.INotificationSettingService.UpsertNotificationSetting(c.Request.Context(), userInfo, c.Param("scope"), scopeID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification setting saved successfully",
+		"data":    res,
+	})
+}
+
+func (h *NotificationSettingHandler) DeleteNotificationSetting(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	scopeID, err := uuid.Parse(c.Param("scopeID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.INotificationSettingService.DeleteNotificationSetting(c.Request.Context(), userInfo, c.Param("scope"), scopeID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification setting reset successfully",
+	})
+}
+
+func (h *NotificationSettingHandler) GetEffectiveNotificationSetting(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	roomID, err := uuid.Parse(c.Param("roomID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.INotificationSettingService.GetEffectiveNotificationSetting(c.Request.Context(), userInfo, roomID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Effective notification setting fetched successfully",
+		"data":    res,
+	})
+}
+
+func (h *NotificationSettingHandler) GetUnreadBadges(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.INotificationSettingService.GetUnreadBadges(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Unread badges fetched successfully",
+		"data":    res,
+	})
+}
@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type OAuthHandler struct {
+	services.IOAuthService
+}
+
+func NewOAuthHandler(oauthService services.IOAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService}
+}
+
+// clientCredentials prefers HTTP Basic (client_secret_basic) and falls back
+// to the form (client_secret_post, or just client_id for public apps)
+func clientCredentials(c *gin.Context) *dto.OAuthClientCredentials {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		return &dto.OAuthClientCredentials{ClientID: id, ClientSecret: secret}
+	}
+	return &dto.OAuthClientCredentials{
+		ClientID:     c.PostForm("client_id"),
+		ClientSecret: c.PostForm("client_secret"),
+	}
+}
+
+// writeOAuthJSON answers protocol endpoints without the usual envelope,
+// clients expect the bare RFC 6749 bodies
+func writeOAuthJSON(c *gin.Context, body any) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, body)
+}
+
+// ── APPS ──────────────────────────────────────────────────────────────────────
+
+// CreateOAuthApp godoc
+// @Summary      Register an OAuth app
+// @Description  Confidential apps get a client secret, returned only here. Public apps (SPAs, native apps) have none and must use PKCE. Redirect URIs are https, http on localhost, or a reverse-domain scheme.
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        body  body      dto.CreateOAuthAppReq  true  "Name, description, redirect URIs and client type"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}  "Invalid redirect URI or too many apps"
+// @Router       /me/oauth-apps [post]
+func (h *OAuthHandler) CreateOAuthApp(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.CreateOAuthAppReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOAuthService.CreateOAuthApp(c.Request.Context(), userInfo, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "OAuth app created successfully",
+		"data":    res,
+	})
+}
+
+// ListMyOAuthApps godoc
+// @Summary      List the OAuth apps you registered
+// @Tags         oauth
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/oauth-apps [get]
+func (h *OAuthHandler) ListMyOAuthApps(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IOAuthService.ListMyOAuthApps(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "OAuth apps retrieved successfully",
+		"data":    res,
+	})
+}
+
+// UpdateOAuthApp godoc
+// @Summary      Update an OAuth app
+// @Description  Replacing redirect URIs doesn't revoke tokens already issued.
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        appID  path      string                 true  "App (client) ID"
+// @Param        body   body      dto.UpdateOAuthAppReq  true  "Fields to change"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      404    {object}  map[string]interface{}  "App not found"
+// @Router       /me/oauth-apps/{appID} [patch]
+func (h *OAuthHandler) UpdateOAuthApp(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	appID, err := uuid.Parse(c.Param("appID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	req := &dto.UpdateOAuthAppReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOAuthService.UpdateOAuthApp(c.Request.Context(), userInfo, appID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "OAuth app updated successfully",
+		"data":    res,
+	})
+}
+
+// RotateOAuthAppSecret godoc
+// @Summary      Rotate an OAuth app's client secret
+// @Description  The old secret stops working immediately.
+// @Tags         oauth
+// @Produce      json
+// @Security     CookieAuth
+// @Param        appID  path      string  true  "App (client) ID"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]interface{}  "Public apps have no secret"
+// @Failure      404    {object}  map[string]interface{}  "App not found"
+// @Router       /me/oauth-apps/{appID}/secret [post]
+func (h *OAuthHandler) RotateOAuthAppSecret(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	appID, err := uuid.Parse(c.Param("appID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.IOAuthService.RotateOAuthAppSecret(c.Request.Context(), userInfo, appID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Client secret rotated successfully",
+		"data":    res,
+	})
+}
+
+// DeleteOAuthApp godoc
+// @Summary      Delete an OAuth app
+// @Description  Every token issued to the app is revoked with it.
+// @Tags         oauth
+// @Produce      json
+// @Security     CookieAuth
+// @Param        appID  path      string  true  "App (client) ID"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      404    {object}  map[string]interface{}  "App not found"
+// @Router       /me/oauth-apps/{appID} [delete]
+func (h *OAuthHandler) DeleteOAuthApp(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	appID, err := uuid.Parse(c.Param("appID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IOAuthService.DeleteOAuthApp(c.Request.Context(), userInfo, appID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "OAuth app deleted successfully",
+		"data":    nil,
+	})
+}
+
+// ── CONSENT ───────────────────────────────────────────────────────────────────
+
+// GetConsent godoc
+// @Summary      Describe an authorization request
+// @Description  The consent page forwards the app's /oauth/authorize query here and shows the result. Nothing is redirected until the user decides.
+// @Tags         oauth
+// @Produce      json
+// @Security     CookieAuth
+// @Param        response_type          query     string  true   "Must be code"
+// @Param        client_id              query     string  true   "App (client) ID"
+// @Param        redirect_uri           query     string  true   "One of the app's redirect URIs"
+// @Param        scope                  query     string  true   "Space separated scopes"
+// @Param        state                  query     string  false  "Echoed back on redirect"
+// @Param        code_challenge         query     string  false  "PKCE challenge, required for public apps"
+// @Param        code_challenge_method  query     string  false  "Must be S256"
+// @Success      200                    {object}  map[string]interface{}
+// @Failure      400                    {object}  map[string]interface{}  "Invalid request"
+// @Router       /oauth/authorize [get]
+func (h *OAuthHandler) GetConsent(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.OAuthAuthorizeReq{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOAuthService.GetConsent(c.Request.Context(), userInfo, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Authorization request retrieved successfully",
+		"data":    res,
+	})
+}
+
+// Authorize godoc
+// @Summary      Approve or deny an authorization request
+// @Description  Returns the URL to send the browser to, carrying either a single use code or error=access_denied, plus state.
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        body  body      dto.OAuthDecisionReq  true  "The authorization request and the user's decision"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}  "Invalid request"
+// @Router       /oauth/authorize [post]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.OAuthDecisionReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOAuthService.Authorize(c.Request.Context(), userInfo, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Authorization decision recorded",
+		"data":    res,
+	})
+}
+
+// ── AUTHORIZATIONS ────────────────────────────────────────────────────────────
+
+// ListMyAuthorizations godoc
+// @Summary      List apps you've authorized
+// @Tags         oauth
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/oauth-authorizations [get]
+func (h *OAuthHandler) ListMyAuthorizations(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IOAuthService.ListMyAuthorizations(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Authorizations retrieved successfully",
+		"data":    res,
+	})
+}
+
+// RevokeAuthorization godoc
+// @Summary      Revoke an app's access to your account
+// @Description  Every access and refresh token the app holds for you stops working.
+// @Tags         oauth
+// @Produce      json
+// @Security     CookieAuth
+// @Param        appID  path      string  true  "App (client) ID"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      404    {object}  map[string]interface{}  "Not authorized"
+// @Router       /me/oauth-authorizations/{appID} [delete]
+func (h *OAuthHandler) RevokeAuthorization(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	appID, err := uuid.Parse(c.Param("appID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IOAuthService.RevokeAuthorization(c.Request.Context(), userInfo, appID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Authorization revoked successfully",
+		"data":    nil,
+	})
+}
+
+// ── PROTOCOL ──────────────────────────────────────────────────────────────────
+
+// ExchangeToken godoc
+// @Summary      OAuth2 token endpoint
+// @Description  grant_type=authorization_code (code, redirect_uri, code_verifier) or refresh_token (refresh_token, optional narrower scope). Refresh tokens rotate on every use. Authenticate with HTTP Basic or client_id/client_secret in the form.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Success      200  {object}  dto.OAuthTokenRes
+// @Failure      400  {object}  map[string]interface{}  "RFC 6749 error"
+// @Failure      401  {object}  map[string]interface{}  "invalid_client"
+// @Router       /oauth/token [post]
+func (h *OAuthHandler) ExchangeToken(c *gin.Context) {
+	req := &dto.OAuthTokenReq{}
+	if err := c.ShouldBind(req); err != nil {
+		utils.WriteOAuthError(c, utils.ErrorOAuthInvalidRequest)
+		return
+	}
+
+	res, err := h.IOAuthService.ExchangeToken(c.Request.Context(), clientCredentials(c), req)
+	if err != nil {
+		utils.WriteOAuthError(c, err)
+		return
+	}
+
+	writeOAuthJSON(c, res)
+}
+
+// RevokeToken godoc
+// @Summary      OAuth2 token revocation (RFC 7009)
+// @Description  Revoking either token of a grant revokes both. Unknown tokens are not an error.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token  formData  string  true  "Access or refresh token"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      401    {object}  map[string]interface{}  "invalid_client"
+// @Router       /oauth/revoke [post]
+func (h *OAuthHandler) RevokeToken(c *gin.Context) {
+	if err := h.IOAuthService.RevokeToken(c.Request.Context(), clientCredentials(c), c.PostForm("token")); err != nil {
+		utils.WriteOAuthError(c, err)
+		return
+	}
+
+	writeOAuthJSON(c, gin.H{})
+}
+
+// IntrospectToken godoc
+// @Summary      OAuth2 token introspection (RFC 7662)
+// @Description  Clients can only introspect tokens issued to them.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token  formData  string  true  "Access or refresh token"
+// @Success      200    {object}  dto.OAuthIntrospectionRes
+// @Failure      401    {object}  map[string]interface{}  "invalid_client"
+// @Router       /oauth/introspect [post]
+func (h *OAuthHandler) IntrospectToken(c *gin.Context) {
+	res, err := h.IOAuthService.IntrospectToken(c.Request.Context(), clientCredentials(c), c.PostForm("token"))
+	if err != nil {
+		utils.WriteOAuthError(c, err)
+		return
+	}
+
+	writeOAuthJSON(c, res)
+}
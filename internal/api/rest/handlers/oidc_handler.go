@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type OIDCHandler struct {
+	services.IOIDCService
+}
+
+func NewOIDCHandler(oidcService services.IOIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService}
+}
+
+// writeOIDCLogin sets the session cookie when the login finished and strips
+// the token from the body like Signin does.
+func writeOIDCLogin(c *gin.Context, res *dto.OIDCLoginRes) {
+	message := "Signed in successfully"
+
+	switch res.Status {
+	case dto.OIDCLoginSignedIn:
+		setAuthCookie(c, res.AccessToken)
+	case dto.OIDCLoginMFARequired:
+		message = "Two-factor authentication required"
+	case dto.OIDCLoginSignupRequired:
+		message = "Choose a username to finish creating your account"
+	case dto.OIDCLoginLinked:
+		message = "Login linked successfully"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    res,
+	})
+}
+
+// ListProviders godoc
+// @Summary      List external login providers
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Router       /auth/oidc/providers [get]
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Login providers retrieved successfully",
+		"data":    h.IOIDCService.ListProviders(c.Request.Context()),
+	})
+}
+
+// BeginLogin godoc
+// @Summary      Start an external login
+// @Description  Returns the provider URL to send the browser to. The provider redirects back to the frontend with code and state.
+// @Tags         auth
+// @Produce      json
+// @Param        provider  path      string  true  "Provider name"
+// @Success      200       {object}  map[string]interface{}
+// @Failure      404       {object}  map[string]interface{}  "Unknown provider"
+// @Router       /auth/oidc/{provider}/start [post]
+func (h *OIDCHandler) BeginLogin(c *gin.Context) {
+	res, err := h.IOIDCService.BeginLogin(c.Request.Context(), c.Param("provider"), nil)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Redirect to the login provider",
+		"data":    res,
+	})
+}
+
+// CompleteLogin godoc
+// @Summary      Finish an external login
+// @Description  Status is signed_in (jwt cookie set), mfa_required, signup_required or linked.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        provider  path      string               true  "Provider name"
+// @Param        body      body      dto.OIDCCallbackReq  true  "Code and state from the redirect"
+// @Success      200       {object}  map[string]interface{}
+// @Failure      400       {object}  map[string]interface{}  "Expired or reused state"
+// @Failure      409       {object}  map[string]interface{}  "E-mail belongs to another account"
+// @Router       /auth/oidc/{provider}/callback [post]
+func (h *OIDCHandler) CompleteLogin(c *gin.Context) {
+	req := &dto.OIDCCallbackReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOIDCService.CompleteLogin(c.Request.Context(), c.Param("provider"), req, clientInfo(c))
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	writeOIDCLogin(c, res)
+}
+
+// CompleteSignup godoc
+// @Summary      Create an account from an external login
+// @Description  Takes the signup_token from a signup_required callback and the chosen username.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.OIDCSignupReq  true  "Signup token and username"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}  "Expired signup token"
+// @Failure      409   {object}  map[string]interface{}  "Username taken"
+// @Router       /auth/oidc/signup [post]
+func (h *OIDCHandler) CompleteSignup(c *gin.Context) {
+	req := &dto.OIDCSignupReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOIDCService.CompleteSignup(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	writeOIDCLogin(c, res)
+}
+
+// ListMyIdentities godoc
+// @Summary      List linked logins
+// @Tags         users
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/identities [get]
+func (h *OIDCHandler) ListMyIdentities(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IOIDCService.ListMyIdentities(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Linked logins retrieved successfully",
+		"data":    res,
+	})
+}
+
+// LinkIdentity godoc
+// @Summary      Link an external login
+// @Description  Like /auth/oidc/{provider}/start, but the identity coming back is attached to the signed in account.
+// @Tags         users
+// @Produce      json
+// @Security     CookieAuth
+// @Param        provider  path      string  true  "Provider name"
+// @Success      200       {object}  map[string]interface{}
+// @Router       /me/identities/{provider} [post]
+func (h *OIDCHandler) LinkIdentity(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IOIDCService.BeginLogin(c.Request.Context(), c.Param("provider"), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Redirect to the login provider",
+		"data":    res,
+	})
+}
+
+// UnlinkIdentity godoc
+// @Summary      Unlink an external login
+// @Description  Refused when it is the only way left to sign in.
+// @Tags         users
+// @Produce      json
+// @Security     CookieAuth
+// @Param        provider  path      string  true  "Provider name"
+// @Success      200       {object}  map[string]interface{}
+// @Failure      409       {object}  map[string]interface{}  "Last login method"
+// @Router       /me/identities/{provider} [delete]
+func (h *OIDCHandler) UnlinkIdentity(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	if err := h.IOIDCService.UnlinkIdentity(c.Request.Context(), userInfo, c.Param("provider")); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Login unlinked successfully",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/hall"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type OutgoingWebhookHandler struct {
+	services.IOutgoingWebhookService
+}
+
+func NewOutgoingWebhookHandler(outgoingWebhookService services.IOutgoingWebhookService) *OutgoingWebhookHandler {
+	return &OutgoingWebhookHandler{outgoingWebhookService}
+}
+
+// hallWebhookParams parses :hallID and, for routes on one webhook, :webhookID
+func hallWebhookParams(c *gin.Context, withWebhook bool) (uuid.UUID, uuid.UUID, bool) {
+	hallID, err := uuid.Parse(c.Param("hallID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !withWebhook {
+		return hallID, uuid.Nil, true
+	}
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return hallID, webhookID, true
+}
+
+// CreateOutgoingWebhook godoc
+// @Summary      Send a hall's events to an endpoint
+// @Description  Needs the manage hall permission. Deliveries are POSTed as JSON and signed with X-Yapp-Signature, sha256 HMAC of "<X-Yapp-Timestamp>.<body>" with the secret, which is only returned here and on rotation.
+// @Tags         outgoing-webhooks
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string                        true  "Hall ID"
+// @Param        body    body      dto.CreateOutgoingWebhookReq  true  "Endpoint and event types"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}  "Invalid URL or event type"
+// @Failure      401     {object}  map[string]interface{}  "Missing permission"
+// @Router       /halls/{hallID}/outgoing-webhooks [post]
+func (h *OutgoingWebhookHandler) CreateOutgoingWebhook(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, _, ok := hallWebhookParams(c, false)
+	if !ok {
+		return
+	}
+
+	req := &dto.CreateOutgoingWebhookReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOutgoingWebhookService.CreateOutgoingWebhook(c.Request.Context(), userInfo, hallID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Outgoing webhook created successfully",
+		"data":    res,
+	})
+}
+
+// ListOutgoingWebhooks godoc
+// @Summary      List a hall's outgoing webhooks
+// @Tags         outgoing-webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string  true  "Hall ID"
+// @Success      200     {object}  map[string]interface{}
+// @Router       /halls/{hallID}/outgoing-webhooks [get]
+func (h *OutgoingWebhookHandler) ListOutgoingWebhooks(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, _, ok := hallWebhookParams(c, false)
+	if !ok {
+		return
+	}
+
+	res, err := h.IOutgoingWebhookService.ListOutgoingWebhooks(c.Request.Context(), userInfo, hallID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Outgoing webhooks retrieved successfully",
+		"data":    res,
+	})
+}
+
+// UpdateOutgoingWebhook godoc
+// @Summary      Change an outgoing webhook's URL, events or state
+// @Description  Re-enabling a webhook that was disabled after failing resets its failure count.
+// @Tags         outgoing-webhooks
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID     path      string                        true  "Hall ID"
+// @Param        webhookID  path      string                        true  "Webhook ID"
+// @Param        body       body      dto.UpdateOutgoingWebhookReq  true  "Fields to change"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}  "Webhook not found"
+// @Router       /halls/{hallID}/outgoing-webhooks/{webhookID} [patch]
+func (h *OutgoingWebhookHandler) UpdateOutgoingWebhook(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, webhookID, ok := hallWebhookParams(c, true)
+	if !ok {
+		return
+	}
+
+	req := &dto.UpdateOutgoingWebhookReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IOutgoingWebhookService.UpdateOutgoingWebhook(c.Request.Context(), userInfo, hallID, webhookID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Outgoing webhook updated successfully",
+		"data":    res,
+	})
+}
+
+// RotateOutgoingWebhookSecret godoc
+// @Summary      Rotate an outgoing webhook's signing secret
+// @Description  Every delivery from now on, retries included, is signed with the new secret.
+// @Tags         outgoing-webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID     path      string  true  "Hall ID"
+// @Param        webhookID  path      string  true  "Webhook ID"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}  "Webhook not found"
+// @Router       /halls/{hallID}/outgoing-webhooks/{webhookID}/secret [post]
+func (h *OutgoingWebhookHandler) RotateOutgoingWebhookSecret(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, webhookID, ok := hallWebhookParams(c, true)
+	if !ok {
+		return
+	}
+
+	res, err := h.IOutgoingWebhookService.RotateOutgoingWebhookSecret(c.Request.Context(), userInfo, hallID, webhookID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Outgoing webhook secret rotated successfully",
+		"data":    res,
+	})
+}
+
+// DeleteOutgoingWebhook godoc
+// @Summary      Delete an outgoing webhook
+// @Description  Its delivery log goes with it.
+// @Tags         outgoing-webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID     path      string  true  "Hall ID"
+// @Param        webhookID  path      string  true  "Webhook ID"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}  "Webhook not found"
+// @Router       /halls/{hallID}/outgoing-webhooks/{webhookID} [delete]
+func (h *OutgoingWebhookHandler) DeleteOutgoingWebhook(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, webhookID, ok := hallWebhookParams(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.IOutgoingWebhookService.DeleteOutgoingWebhook(c.Request.Context(), userInfo, hallID, webhookID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Outgoing webhook deleted successfully",
+		"data":    nil,
+	})
+}
+
+// ListOutgoingDeliveries godoc
+// @Summary      Recent deliveries of an outgoing webhook
+// @Description  Newest first, kept for 30 days.
+// @Tags         outgoing-webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID     path      string  true  "Hall ID"
+// @Param        webhookID  path      string  true  "Webhook ID"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}  "Webhook not found"
+// @Router       /halls/{hallID}/outgoing-webhooks/{webhookID}/deliveries [get]
+func (h *OutgoingWebhookHandler) ListOutgoingDeliveries(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, webhookID, ok := hallWebhookParams(c, true)
+	if !ok {
+		return
+	}
+
+	res, err := h.IOutgoingWebhookService.ListOutgoingDeliveries(c.Request.Context(), userInfo, hallID, webhookID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Deliveries retrieved successfully",
+		"data":    res,
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type PersonalTokenHandler struct {
+	services.IPersonalTokenService
+}
+
+func NewPersonalTokenHandler(personalTokenService services.IPersonalTokenService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{personalTokenService}
+}
+
+// CreatePersonalToken godoc
+// @Summary      Create a personal access token
+// @Description  The token is only returned here, send it as "Authorization: Bearer <token>". Scopes are "<resource>:read" or "<resource>:write" for users, halls, messages, presence, notifications and webhooks; write also allows reading. Account, credential and token routes never accept personal access tokens.
+// @Tags         tokens
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        body  body      dto.CreatePersonalTokenReq  true  "Name, scopes and optional expiry"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}  "Unknown scope or too many tokens"
+// @Router       /me/tokens [post]
+func (h *PersonalTokenHandler) CreatePersonalToken(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.CreatePersonalTokenReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IPersonalTokenService.CreatePersonalToken(c.Request.Context(), userInfo, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Personal access token created successfully",
+		"data":    res,
+	})
+}
+
+// ListPersonalTokens godoc
+// @Summary      List your personal access tokens
+// @Description  Expired tokens are listed until revoked.
+// @Tags         tokens
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/tokens [get]
+func (h *PersonalTokenHandler) ListPersonalTokens(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IPersonalTokenService.ListPersonalTokens(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Personal access tokens retrieved successfully",
+		"data":    res,
+	})
+}
+
+// RevokePersonalToken godoc
+// @Summary      Revoke a personal access token
+// @Tags         tokens
+// @Produce      json
+// @Security     CookieAuth
+// @Param        tokenID  path      string  true  "Token ID"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}  "Token not found"
+// @Router       /me/tokens/{tokenID} [delete]
+func (h *PersonalTokenHandler) RevokePersonalToken(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("tokenID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IPersonalTokenService.RevokePersonalToken(c.Request.Context(), userInfo, tokenID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Personal access token revoked successfully",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/push"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type PushHandler struct {
+	services.IPushService
+}
+
+func NewPushHandler(pushService services.IPushService) *PushHandler {
+	return &PushHandler{pushService}
+}
+
+func (h *PushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	res, err := h.IPushService.GetVAPIDPublicKey(c.Request.Context())
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "VAPID public key fetched successfully",
+		"data":    res,
+	})
+}
+
+func (h *PushHandler) RegisterSubscription(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	var req dto.RegisterPushSubscriptionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IPushService.RegisterSubscription(c.Request.Context(), userInfo, &req, c.Request.UserAgent())
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Push subscription registered successfully",
+		"data":    res,
+	})
+}
+
+func (h *PushHandler) ListSubscriptions(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IPushService.ListSubscriptions(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Push subscriptions fetched successfully",
+		"data":    res,
+	})
+}
+
+func (h *PushHandler) DeleteSubscription(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(c.Param("subscriptionID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IPushService.DeleteSubscription(c.Request.Context(), userInfo, subscriptionID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Push subscription removed successfully",
+		"data":    nil,
+	})
+}
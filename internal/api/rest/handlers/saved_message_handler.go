@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type SavedMessageHandler struct {
+	services.ISavedMessageService
+}
+
+func NewSavedMessageHandler(savedMessageService services.ISavedMessageService) *SavedMessageHandler {
+	return &SavedMessageHandler{savedMessageService}
+}
+
+// ListSavedMessages godoc
+// @Summary      List saved messages
+// @Description  Newest first. Items from rooms the caller can no longer reach, or whose message was deleted, are left out.
+// @Tags         saved-messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        limit   query     int     false  "Page size, default 30, max 100"
+// @Param        before  query     string  false  "Saved item ID (UUID) to page from"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Router       /me/saved-messages [get]
+func (h *SavedMessageHandler) ListSavedMessages(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	query := &dto.ListSavedMessagesQuery{}
+
+	query.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if err != nil || query.Limit <= 0 {
+		query.Limit = 30
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		id, err := uuid.Parse(beforeStr)
+		if err != nil {
+			utils.WriteError(c, utils.ErrorInvalidInput)
+			return
+		}
+		query.Before = &id
+	}
+
+	res, err := h.ISavedMessageService.ListSavedMessages(c.Request.Context(), userInfo, query)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Saved messages fetched successfully",
+		"data":    res,
+	})
+}
+
+// SaveMessage godoc
+// @Summary      Save a message
+// @Description  Bookmarks a message from any room the caller can see, with an optional note and reminder. The reminder
+// @Description  arrives as a notification and must be between a minute and a year out.
+// @Tags         saved-messages
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        payload  body      dto.SaveMessageReq  true  "message_id, note, remind_at"
+// @Success      201      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}  "Already saved"
+// @Router       /me/saved-messages [post]
+func (h *SavedMessageHandler) SaveMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	var req dto.SaveMessageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.ISavedMessageService.SaveMessage(c.Request.Context(), userInfo, &req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Message saved successfully",
+		"data":    res,
+	})
+}
+
+// UpdateSavedMessage godoc
+// @Summary      Update a saved message
+// @Description  Changes the note or the reminder. A new remind_at re-arms a reminder that already went out.
+// @Tags         saved-messages
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        savedID  path      string                     true  "Saved item ID (UUID)"
+// @Param        payload  body      dto.UpdateSavedMessageReq  true  "note, remind_at, clear_reminder"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Router       /me/saved-messages/{savedID} [patch]
+func (h *SavedMessageHandler) UpdateSavedMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	savedID, err := uuid.Parse(c.Param("savedID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	var req dto.UpdateSavedMessageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.ISavedMessageService.UpdateSavedMessage(c.Request.Context(), userInfo, savedID, &req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Saved message updated successfully",
+		"data":    res,
+	})
+}
+
+// DeleteSavedMessage godoc
+// @Summary      Remove a saved message
+// @Tags         saved-messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        savedID  path      string  true  "Saved item ID (UUID)"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Router       /me/saved-messages/{savedID} [delete]
+func (h *SavedMessageHandler) DeleteSavedMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	savedID, err := uuid.Parse(c.Param("savedID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.ISavedMessageService.DeleteSavedMessage(c.Request.Context(), userInfo, savedID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Saved message removed successfully",
+		"data":    nil,
+	})
+}
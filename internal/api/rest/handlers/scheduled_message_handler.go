@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type ScheduledMessageHandler struct {
+	services.IScheduledMessageService
+}
+
+func NewScheduledMessageHandler(scheduledMessageService services.IScheduledMessageService) *ScheduledMessageHandler {
+	return &ScheduledMessageHandler{scheduledMessageService}
+}
+
+// ListScheduledMessages godoc
+// @Summary      List scheduled messages
+// @Description  The caller's messages waiting to be posted, soonest first. Failed ones stay listed with the reason until
+// @Description  they are rescheduled or cancelled.
+// @Tags         scheduled-messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        room_id  query     string  false  "Only this room (UUID)"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Router       /me/scheduled-messages [get]
+func (h *ScheduledMessageHandler) ListScheduledMessages(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	query := &dto.ListScheduledMessagesQuery{}
+
+	if roomStr := c.Query("room_id"); roomStr != "" {
+		id, err := uuid.Parse(roomStr)
+		if err != nil {
+			utils.WriteError(c, utils.ErrorInvalidRoomIDFormat)
+			return
+		}
+		query.RoomID = &id
+	}
+
+	res, err := h.IScheduledMessageService.ListScheduledMessages(c.Request.Context(), userInfo, query)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Scheduled messages fetched successfully",
+		"data":    res,
+	})
+}
+
+// ScheduleMessage godoc
+// @Summary      Schedule a message
+// @Description  Posts the message to the room at send_at, between a minute and a year from now. Room access and the
+// @Description  send permission are checked now and again when it goes out.
+// @Tags         scheduled-messages
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        payload  body      dto.ScheduleMessageReq  true  "room_id, content, attachments, mentions, send_at"
+// @Success      201      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      401      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Router       /me/scheduled-messages [post]
+func (h *ScheduledMessageHandler) ScheduleMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	var req dto.ScheduleMessageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IScheduledMessageService.ScheduleMessage(c.Request.Context(), userInfo, &req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Message scheduled successfully",
+		"data":    res,
+	})
+}
+
+// UpdateScheduledMessage godoc
+// @Summary      Edit a scheduled message
+// @Description  Changes the content, mentions or time. A failed message is queued again when it gets a new send_at.
+// @Tags         scheduled-messages
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        scheduledID  path      string                         true  "Scheduled message ID (UUID)"
+// @Param        payload      body      dto.UpdateScheduledMessageReq  true  "content, attachments, mentions, send_at"
+// @Success      200          {object}  map[string]interface{}
+// @Failure      400          {object}  map[string]interface{}
+// @Failure      401          {object}  map[string]interface{}
+// @Failure      404          {object}  map[string]interface{}
+// @Failure      409          {object}  map[string]interface{}  "Being posted right now"
+// @Router       /me/scheduled-messages/{scheduledID} [patch]
+func (h *ScheduledMessageHandler) UpdateScheduledMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	scheduledID, err := uuid.Parse(c.Param("scheduledID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	var req dto.UpdateScheduledMessageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IScheduledMessageService.UpdateScheduledMessage(c.Request.Context(), userInfo, scheduledID, &req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Scheduled message updated successfully",
+		"data":    res,
+	})
+}
+
+// CancelScheduledMessage godoc
+// @Summary      Cancel a scheduled message
+// @Tags         scheduled-messages
+// @Produce      json
+// @Security     CookieAuth
+// @Param        scheduledID  path      string  true  "Scheduled message ID (UUID)"
+// @Success      200          {object}  map[string]interface{}
+// @Failure      400          {object}  map[string]interface{}
+// @Failure      401          {object}  map[string]interface{}
+// @Failure      404          {object}  map[string]interface{}
+// @Failure      409          {object}  map[string]interface{}  "Being posted right now"
+// @Router       /me/scheduled-messages/{scheduledID} [delete]
+func (h *ScheduledMessageHandler) CancelScheduledMessage(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	scheduledID, err := uuid.Parse(c.Param("scheduledID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IScheduledMessageService.CancelScheduledMessage(c.Request.Context(), userInfo, scheduledID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Scheduled message cancelled",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type SigninSecurityHandler struct {
+	services.ISigninSecurityService
+}
+
+func NewSigninSecurityHandler(signinSecurityService services.ISigninSecurityService) *SigninSecurityHandler {
+	return &SigninSecurityHandler{signinSecurityService}
+}
+
+// ListMySignins godoc
+// @Summary      Recent sign-in attempts
+// @Description  The last 50 attempts on the account, failed ones included.
+// @Tags         users
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/signins [get]
+func (h *SigninSecurityHandler) ListMySignins(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.ISigninSecurityService.ListMySignins(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Sign-in history retrieved successfully",
+		"data":    res,
+	})
+}
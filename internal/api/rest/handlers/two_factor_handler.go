@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type TwoFactorHandler struct {
+	services.ITwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService services.ITwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService}
+}
+
+// CompleteSignin godoc
+// @Summary      Finish two-factor sign in
+// @Description  Exchanges the mfa_token from /auth/signin plus a TOTP or recovery code for the `jwt` cookie.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.SigninSecondFactorReq   true  "Challenge and code"
+// @Success      200   {object}  map[string]interface{}  "Signed in — jwt cookie is set"
+// @Failure      400   {object}  map[string]interface{}  "Invalid input"
+// @Failure      401   {object}  map[string]interface{}  "Wrong code or expired challenge"
+// @Router       /auth/signin/2fa [post]
+func (h *TwoFactorHandler) CompleteSignin(c *gin.Context) {
+	req := &dto.SigninSecondFactorReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	signInRes, err := h.ITwoFactorService.CompleteSignin(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	setAuthCookie(c, signInRes.AccessToken)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Signed in successfully",
+		"data": &dto.SigninUserRes{
+			UserMe:  signInRes.UserMe,
+			Success: signInRes.Success,
+		},
+	})
+}
+
+// GetTwoFactorStatus godoc
+// @Summary      Two-factor status
+// @Tags         two-factor
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Router       /me/2fa [get]
+func (h *TwoFactorHandler) GetTwoFactorStatus(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.ITwoFactorService.GetTwoFactorStatus(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor status retrieved successfully",
+		"data":    res,
+	})
+}
+
+// BeginTOTPEnrollment godoc
+// @Summary      Start TOTP enrollment
+// @Description  Generates a new secret and an otpauth:// URI for the authenticator app QR code. Nothing is enforced until confirmed.
+// @Tags         two-factor
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}  "Already enabled"
+// @Router       /me/2fa/totp [post]
+func (h *TwoFactorHandler) BeginTOTPEnrollment(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.ITwoFactorService.BeginTOTPEnrollment(c.Request.Context(), userInfo)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Scan the code and confirm it to enable two-factor authentication",
+		"data":    res,
+	})
+}
+
+// ConfirmTOTPEnrollment godoc
+// @Summary      Confirm TOTP enrollment
+// @Description  Enables 2FA once the first code checks out. The recovery codes are only ever shown in this response.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        body  body      dto.ConfirmTOTPReq          true  "Code from the authenticator app"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}  "Wrong code"
+// @Router       /me/2fa/totp/confirm [post]
+func (h *TwoFactorHandler) ConfirmTOTPEnrollment(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.ConfirmTOTPReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.ITwoFactorService.ConfirmTOTPEnrollment(c.Request.Context(), userInfo, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication enabled",
+		"data":    res,
+	})
+}
+
+// DisableTwoFactor godoc
+// @Summary      Disable two-factor authentication
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        body  body      dto.TwoFactorCodeReq        true  "TOTP or recovery code"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}  "Wrong code"
+// @Router       /me/2fa [delete]
+func (h *TwoFactorHandler) DisableTwoFactor(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.TwoFactorCodeReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	if err := h.ITwoFactorService.DisableTwoFactor(c.Request.Context(), userInfo, req); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication disabled",
+		"data":    nil,
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate recovery codes
+// @Description  Replaces every recovery code, the old ones stop working.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        body  body      dto.TwoFactorCodeReq        true  "TOTP or recovery code"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}  "Wrong code"
+// @Router       /me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	req := &dto.TwoFactorCodeReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.ITwoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userInfo, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Recovery codes regenerated",
+		"data":    res,
+	})
+}
@@ -307,32 +307,6 @@ func (h *UserHandler) GetMyFriends(c *gin.Context) {
 	})
 }
 
-func (h *UserHandler) UpsertMyAppLink(c *gin.Context) {
-	userInfo, err := auth.CurrentUserFromGinContext(c)
-	if err != nil {
-		utils.WriteError(c, err)
-		return
-	}
-
-	var req dto.UpsertAppLinkReq
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.WriteError(c, utils.ErrorInvalidInput)
-		return
-	}
-
-	res, err := h.IUserService.UpsertMyAppLink(c.Request.Context(), userInfo, &req)
-	if err != nil {
-		utils.WriteError(c, err)
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "App link saved successfully",
-		"data":    res,
-	})
-}
-
 func (h *UserHandler) DeleteMyAppLink(c *gin.Context) {
 	userInfo, err := auth.CurrentUserFromGinContext(c)
 	if err != nil {
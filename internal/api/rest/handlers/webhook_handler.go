@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type WebhookHandler struct {
+	services.IWebhookService
+}
+
+func NewWebhookHandler(webhookService services.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService}
+}
+
+// hallRoomParams parses the :hallID and :roomID every webhook management
+// route is nested under
+func hallRoomParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	hallID, err := uuid.Parse(c.Param("hallID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return uuid.Nil, uuid.Nil, false
+	}
+	roomID, err := uuid.Parse(c.Param("roomID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return hallID, roomID, true
+}
+
+// CreateWebhook godoc
+// @Summary      Create an incoming webhook for a room
+// @Description  Needs the manage channels permission. The URL holds the webhook's secret and is only returned here and on rotation.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string                true  "Hall ID"
+// @Param        roomID  path      string                true  "Room ID"
+// @Param        body    body      dto.CreateWebhookReq  true  "Webhook name and avatar"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}  "Missing permission"
+// @Router       /halls/{hallID}/rooms/{roomID}/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, roomID, ok := hallRoomParams(c)
+	if !ok {
+		return
+	}
+
+	req := &dto.CreateWebhookReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IWebhookService.CreateWebhook(c.Request.Context(), userInfo, hallID, roomID, req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook created successfully",
+		"data":    res,
+	})
+}
+
+// ListRoomWebhooks godoc
+// @Summary      List a room's incoming webhooks
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID  path      string  true  "Hall ID"
+// @Param        roomID  path      string  true  "Room ID"
+// @Success      200     {object}  map[string]interface{}
+// @Router       /halls/{hallID}/rooms/{roomID}/webhooks [get]
+func (h *WebhookHandler) ListRoomWebhooks(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, roomID, ok := hallRoomParams(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.IWebhookService.ListRoomWebhooks(c.Request.Context(), userInfo, hallID, roomID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhooks retrieved successfully",
+		"data":    res,
+	})
+}
+
+// RotateWebhookToken godoc
+// @Summary      Rotate a webhook's secret URL
+// @Description  The previous URL stops working immediately.
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID     path      string  true  "Hall ID"
+// @Param        roomID     path      string  true  "Room ID"
+// @Param        webhookID  path      string  true  "Webhook ID"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}  "Webhook not found"
+// @Router       /halls/{hallID}/rooms/{roomID}/webhooks/{webhookID}/token [post]
+func (h *WebhookHandler) RotateWebhookToken(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, roomID, ok := hallRoomParams(c)
+	if !ok {
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	res, err := h.IWebhookService.RotateWebhookToken(c.Request.Context(), userInfo, hallID, roomID, webhookID)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook URL rotated successfully",
+		"data":    res,
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete an incoming webhook
+// @Description  Messages it already posted stay.
+// @Tags         webhooks
+// @Produce      json
+// @Security     CookieAuth
+// @Param        hallID     path      string  true  "Hall ID"
+// @Param        roomID     path      string  true  "Room ID"
+// @Param        webhookID  path      string  true  "Webhook ID"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}  "Webhook not found"
+// @Router       /halls/{hallID}/rooms/{roomID}/webhooks/{webhookID} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	hallID, roomID, ok := hallRoomParams(c)
+	if !ok {
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	if err := h.IWebhookService.DeleteWebhook(c.Request.Context(), userInfo, hallID, roomID, webhookID); err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook deleted successfully",
+		"data":    nil,
+	})
+}
+
+// ExecuteWebhook godoc
+// @Summary      Post a message through an incoming webhook
+// @Description  No session needed, the token in the path authorizes the post. Needs content or attachments.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhookID  path      string                 true  "Webhook ID"
+// @Param        token      path      string                 true  "Webhook secret"
+// @Param        body       body      dto.ExecuteWebhookReq  true  "Message"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      401        {object}  map[string]interface{}  "Invalid token"
+// @Failure      404        {object}  map[string]interface{}  "Webhook not found"
+// @Router       /webhooks/{webhookID}/{token} [post]
+func (h *WebhookHandler) ExecuteWebhook(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		utils.WriteError(c, utils.ErrorInvalidIDFormart)
+		return
+	}
+
+	req := &dto.ExecuteWebhookReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	res, err := h.IWebhookService.ExecuteWebhook(c.Request.Context(), webhookID, c.Param("token"), req)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Message posted successfully",
+		"data":    res,
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type WSTicketHandler struct {
+	services.IWSTicketService
+}
+
+func NewWSTicketHandler(wsTicketService services.IWSTicketService) *WSTicketHandler {
+	return &WSTicketHandler{wsTicketService}
+}
+
+// IssueWSTicket godoc
+// @Summary      Get a WebSocket ticket
+// @Description  Returns a single-use ticket valid for 30 seconds. Open the socket with /ws?ticket=<ticket> from the same origin that asked for it.
+// @Tags         websocket
+// @Produce      json
+// @Security     CookieAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}  "Not authenticated"
+// @Router       /ws/tickets [post]
+func (h *WSTicketHandler) IssueWSTicket(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	res, err := h.IWSTicketService.IssueWSTicket(c.Request.Context(), userInfo, c.GetHeader("Origin"))
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "WebSocket ticket issued",
+		"data":    res,
+	})
+}
@@ -17,16 +17,95 @@ func RegisterAuthRoutes(r *gin.RouterGroup, userService services.IUserService) {
 		authGroup.POST("/signup", authHandler.Signup)
 		authGroup.POST("/signin", authHandler.Signin)
 		authGroup.GET("/signout", auth.AuthMiddleware(), authHandler.Signout)
+		authGroup.GET("/csrf", authHandler.GetCSRFToken)
+	}
+}
+
+func RegisterTwoFactorRoutes(r *gin.RouterGroup, twoFactorService services.ITwoFactorService) {
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+
+	r.POST("/auth/signin/2fa", twoFactorHandler.CompleteSignin)
+
+	twoFactorGroup := r.Group("/me/2fa", auth.AuthMiddleware(), auth.SessionsOnly())
+	{
+		twoFactorGroup.GET("", twoFactorHandler.GetTwoFactorStatus)
+		twoFactorGroup.DELETE("", twoFactorHandler.DisableTwoFactor)
+		twoFactorGroup.POST("/totp", twoFactorHandler.BeginTOTPEnrollment)
+		twoFactorGroup.POST("/totp/confirm", twoFactorHandler.ConfirmTOTPEnrollment)
+		twoFactorGroup.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+	}
+}
+
+func RegisterSigninSecurityRoutes(r *gin.RouterGroup, signinSecurityService services.ISigninSecurityService) {
+	signinSecurityHandler := handlers.NewSigninSecurityHandler(signinSecurityService)
+
+	r.GET("/me/signins", auth.SessionsOnly(), signinSecurityHandler.ListMySignins)
+}
+
+func RegisterAppLinkRoutes(r *gin.RouterGroup, appLinkService services.IAppLinkService) {
+	appLinkHandler := handlers.NewAppLinkHandler(appLinkService)
+
+	appLinkGroup := r.Group("/me/app-links", auth.AuthMiddleware(), auth.SessionsOnly())
+	{
+		appLinkGroup.GET("/providers", appLinkHandler.ListAppLinkProviders)
+		appLinkGroup.PATCH("/:provider", appLinkHandler.UpdateAppLink)
+		appLinkGroup.POST("/:provider/connect", appLinkHandler.BeginAppLink)
+		appLinkGroup.POST("/:provider/callback", appLinkHandler.CompleteAppLink)
+	}
+}
+
+func RegisterWSTicketRoutes(r *gin.RouterGroup, wsTicketService services.IWSTicketService) {
+	wsTicketHandler := handlers.NewWSTicketHandler(wsTicketService)
+
+	r.POST("/ws/tickets", auth.SessionsOnly(), wsTicketHandler.IssueWSTicket)
+}
+
+// RegisterWellKnownRoutes goes on the root router, verifiers expect the
+// standard path
+func RegisterWellKnownRoutes(r *gin.RouterGroup, keyRing *auth.KeyRing) {
+	jwksHandler := handlers.NewJWKSHandler(keyRing)
+
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+}
+
+func RegisterOIDCRoutes(r *gin.RouterGroup, oidcService services.IOIDCService) {
+	oidcHandler := handlers.NewOIDCHandler(oidcService)
+
+	oidcGroup := r.Group("/auth/oidc")
+	{
+		oidcGroup.GET("/providers", oidcHandler.ListProviders)
+		oidcGroup.POST("/signup", oidcHandler.CompleteSignup)
+		oidcGroup.POST("/:provider/start", oidcHandler.BeginLogin)
+		oidcGroup.POST("/:provider/callback", oidcHandler.CompleteLogin)
+	}
+
+	identityGroup := r.Group("/me/identities", auth.AuthMiddleware(), auth.SessionsOnly())
+	{
+		identityGroup.GET("", oidcHandler.ListMyIdentities)
+		identityGroup.POST("/:provider", oidcHandler.LinkIdentity)
+		identityGroup.DELETE("/:provider", oidcHandler.UnlinkIdentity)
+	}
+}
+
+func RegisterAccountRoutes(r *gin.RouterGroup, accountService services.IAccountService) {
+	accountHandler := handlers.NewAccountHandler(accountService)
+
+	authGroup := r.Group("/auth")
+	{
+		authGroup.POST("/email/verify", accountHandler.VerifyEmail)
+		authGroup.POST("/email/resend", auth.AuthMiddleware(), auth.SessionsOnly(), accountHandler.ResendVerificationEmail)
+		authGroup.POST("/password/forgot", accountHandler.RequestPasswordReset)
+		authGroup.POST("/password/reset", accountHandler.ResetPassword)
 	}
 }
 
 // RegisterUserRoutes : Group routes proceeding from /user/ and more
-func RegisterUserRoutes(r *gin.RouterGroup, userService services.IUserService) {
+func RegisterUserRoutes(r *gin.RouterGroup, userService services.IUserService, requireVerifiedEmail gin.HandlerFunc) {
 
 	// make instance of userHandler
 	userHandler := handlers.NewUserHandler(userService)
 
-	userGroup := r.Group("/users")
+	userGroup := r.Group("/users", auth.RequireScope("users"))
 	{
 		userGroup.GET("/", userHandler.Ping)
 		userGroup.GET("/:user_id", userHandler.GetUserByID)
@@ -34,35 +113,36 @@ func RegisterUserRoutes(r *gin.RouterGroup, userService services.IUserService) {
 	}
 
 	meGroup := r.Group("/me")
-	meGroup.Use(auth.AuthMiddleware())
+	meGroup.Use(auth.AuthMiddleware(), auth.RequireScope("users"))
 	{
 		meGroup.GET("/", userHandler.GetUserMe)
 		meGroup.PATCH("/", userHandler.UpdateUserMe)
-		meGroup.DELETE("/", userHandler.DeleteMe)
+		meGroup.DELETE("/", auth.SessionsOnly(), userHandler.DeleteMe)
 		meGroup.PATCH("/username", userHandler.UpdateUsername)
-		meGroup.PATCH("/email", userHandler.UpdateEmail)
+		meGroup.PATCH("/email", auth.SessionsOnly(), userHandler.UpdateEmail)
 
 		meGroup.GET("/friends", userHandler.GetMyFriends)
-		meGroup.POST("/friends/requests", userHandler.SendFriendRequest)
+		meGroup.POST("/friends/requests", requireVerifiedEmail, userHandler.SendFriendRequest)
 		meGroup.PATCH("/friends/requests/:request_id", userHandler.RespondFriendRequest)
 		meGroup.DELETE("/friends/:user_id", userHandler.Unfriend)
 
-		meGroup.PUT("/app-links", userHandler.UpsertMyAppLink)
-		meGroup.DELETE("/app-links/:provider", userHandler.DeleteMyAppLink)
+		meGroup.DELETE("/app-links/:provider", auth.SessionsOnly(), userHandler.DeleteMyAppLink)
 	}
 
 }
 
-func RegisterHallRoutes(r *gin.RouterGroup, hallService services.IHallService, roleServices services.IRoleService, banServices services.IBanService, inviteService services.IInviteService, floorService services.IFloorService, roomService services.IRoomService, messageService services.IMessageService) {
+func RegisterHallRoutes(r *gin.RouterGroup, hallService services.IHallService, roleServices services.IRoleService, banServices services.IBanService, inviteService services.IInviteService, floorService services.IFloorService, roomService services.IRoomService, messageService services.IMessageService, requireVerifiedEmail gin.HandlerFunc) {
 	hallHandler := handlers.NewHallHandler(hallService, roleServices, banServices)
 	inviteHandler := handlers.NewInviteHandler(inviteService)
 
-	halls := r.Group("/halls")
+	// messages nested under a hall are scoped on their own, not as halls
+	hallsRoot := r.Group("/halls")
+	halls := hallsRoot.Group("", auth.RequireScope("halls"))
 	{
 
 		// TOP LEVEL HALL OPERATIONS
 		halls.GET("", hallHandler.GetUserHalls)
-		halls.POST("", hallHandler.CreateHall)
+		halls.POST("", requireVerifiedEmail, hallHandler.CreateHall)
 
 		// HALL SIDEBAR PINNING
 		halls.PUT("/:hallID/pin", hallHandler.PinHall)
@@ -70,7 +150,7 @@ func RegisterHallRoutes(r *gin.RouterGroup, hallService services.IHallService, r
 		halls.PUT("/:hallID/pin/move", hallHandler.MovePinnedHall)
 
 		// JOIN HALL
-		halls.POST("/:hallID/join", hallHandler.JoinHall)
+		halls.POST("/:hallID/join", requireVerifiedEmail, hallHandler.JoinHall)
 
 		// SINGLE HALL RUD
 		halls.GET("/:hallID", hallHandler.GetCurrentHall)
@@ -86,6 +166,10 @@ func RegisterHallRoutes(r *gin.RouterGroup, hallService services.IHallService, r
 			settings.GET("/profile", hallHandler.GetHallProfile)
 			settings.PATCH("/profile", hallHandler.UpdateHallProfile)
 
+			// SECURITY, OWNER ONLY
+			settings.GET("/security", hallHandler.GetHallSecuritySettings)
+			settings.PATCH("/security", hallHandler.UpdateHallSecuritySettings)
+
 			// MEMBERS MANAGEMENT
 			members := settings.Group("/members")
 			{
@@ -138,7 +222,7 @@ func RegisterHallRoutes(r *gin.RouterGroup, hallService services.IHallService, r
 		}
 
 		// Halls scoped routes
-		hallScoped := halls.Group("/:hallID")
+		hallScoped := hallsRoot.Group("/:hallID")
 		{
 			RegisterFloorRoutes(hallScoped, floorService)
 			RegisterRoomRoutes(hallScoped, roomService, messageService)
@@ -160,7 +244,7 @@ func RegisterInvitePublicRoutes(r *gin.RouterGroup, inviteService services.IInvi
 func RegisterInvitePrivateRoutes(r *gin.RouterGroup, inviteService services.IInviteService) {
 	inviteHandler := handlers.NewInviteHandler(inviteService)
 
-	invites := r.Group("/invites")
+	invites := r.Group("/invites", auth.RequireScope("halls"))
 	{
 		invites.POST("/:code/accept", inviteHandler.AcceptInviteLink) // authenticated
 	}
@@ -170,7 +254,7 @@ func RegisterFloorRoutes(r *gin.RouterGroup, floorService services.IFloorService
 
 	floorHandler := handlers.NewFloorHandler(floorService)
 
-	floorGroup := r.Group("/floors")
+	floorGroup := r.Group("/floors", auth.RequireScope("halls"))
 	{
 		floorGroup.POST("", floorHandler.CreateFloor)
 		floorGroup.GET("", floorHandler.GetFloors) // ?hall_id=
@@ -189,7 +273,8 @@ func RegisterFloorRoutes(r *gin.RouterGroup, floorService services.IFloorService
 func RegisterRoomRoutes(r *gin.RouterGroup, roomService services.IRoomService, messageService services.IMessageService) {
 	roomHandler := handlers.NewRoomHandler(roomService)
 
-	roomGroup := r.Group("/rooms")
+	roomsRoot := r.Group("/rooms")
+	roomGroup := roomsRoot.Group("", auth.RequireScope("halls"))
 	{
 		roomGroup.POST("", roomHandler.CreateRoom)
 		roomGroup.GET("", roomHandler.GetHallRooms)
@@ -208,7 +293,7 @@ func RegisterRoomRoutes(r *gin.RouterGroup, roomService services.IRoomService, m
 		roomGroup.PUT("/:roomID/sync-floor-members", roomHandler.SyncRoomMembersToFloor)
 
 		// Room scoped routes
-		roomScoped := roomGroup.Group("/:roomID")
+		roomScoped := roomsRoot.Group("/:roomID")
 		{
 			RegisterMessageRoutes(roomScoped, messageService)
 		}
@@ -219,14 +304,22 @@ func RegisterMessageRoutes(r *gin.RouterGroup, messageService services.IMessageS
 
 	messageHandler := handlers.NewMessageHandler(messageService)
 
-	messageGroup := r.Group("/messages")
+	messageGroup := r.Group("/messages", auth.RequireScope("messages"))
 	{
 		messageGroup.GET("", messageHandler.FetchMessages)
 		messageGroup.GET("/:messageID", messageHandler.GetMessage)
+		messageGroup.GET("/:messageID/history", messageHandler.GetMessageHistory)
 		messageGroup.PATCH("/:messageID", messageHandler.UpdateMessage)
 		messageGroup.DELETE("/:messageID", messageHandler.DeleteMessage)
 		messageGroup.PUT("/:messageID/reactions/:emoji", messageHandler.AddReaction)
 		messageGroup.DELETE("/:messageID/reactions/:emoji", messageHandler.RemoveReaction)
+		messageGroup.PUT("/:messageID/pin", messageHandler.PinMessage)
+		messageGroup.DELETE("/:messageID/pin", messageHandler.UnpinMessage)
+	}
+
+	pinGroup := r.Group("/pins", auth.RequireScope("messages"))
+	{
+		pinGroup.GET("", messageHandler.GetPinnedMessages)
 	}
 
 }
@@ -234,10 +327,185 @@ func RegisterMessageRoutes(r *gin.RouterGroup, messageService services.IMessageS
 func RegisterPresenceRoutes(r *gin.RouterGroup, presenceService services.IPresenceService) {
 	presenceHandler := handlers.NewPresenceHandler(presenceService)
 
-	presenceGroup := r.Group("/presence")
+	presenceGroup := r.Group("/presence", auth.RequireScope("presence"))
 	{
 		presenceGroup.GET("/me", presenceHandler.GetMyPresence)
 		presenceGroup.PATCH("/me", presenceHandler.UpdateMyPresence)
 		presenceGroup.GET("/users", presenceHandler.GetManyPresence) // ?ids=id1,id2,id3
 	}
 }
+
+func RegisterSavedMessageRoutes(r *gin.RouterGroup, savedMessageService services.ISavedMessageService) {
+	savedMessageHandler := handlers.NewSavedMessageHandler(savedMessageService)
+
+	savedGroup := r.Group("/me/saved-messages", auth.RequireScope("messages"))
+	{
+		savedGroup.GET("", savedMessageHandler.ListSavedMessages) // ?limit=&before=
+		savedGroup.POST("", savedMessageHandler.SaveMessage)
+		savedGroup.PATCH("/:savedID", savedMessageHandler.UpdateSavedMessage)
+		savedGroup.DELETE("/:savedID", savedMessageHandler.DeleteSavedMessage)
+	}
+}
+
+func RegisterScheduledMessageRoutes(r *gin.RouterGroup, scheduledMessageService services.IScheduledMessageService) {
+	scheduledMessageHandler := handlers.NewScheduledMessageHandler(scheduledMessageService)
+
+	scheduledGroup := r.Group("/me/scheduled-messages", auth.RequireScope("messages"))
+	{
+		scheduledGroup.GET("", scheduledMessageHandler.ListScheduledMessages) // ?room_id=
+		scheduledGroup.POST("", scheduledMessageHandler.ScheduleMessage)
+		scheduledGroup.PATCH("/:scheduledID", scheduledMessageHandler.UpdateScheduledMessage)
+		scheduledGroup.DELETE("/:scheduledID", scheduledMessageHandler.CancelScheduledMessage)
+	}
+}
+
+func RegisterNotificationRoutes(r *gin.RouterGroup, notificationService services.INotificationService) {
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+
+	notificationGroup := r.Group("/me/notifications", auth.RequireScope("notifications"))
+	{
+		notificationGroup.GET("", notificationHandler.ListNotifications) // ?limit=&before=&unread_only=
+		notificationGroup.GET("/unread-count", notificationHandler.GetUnreadCount)
+		notificationGroup.PATCH("/read", notificationHandler.MarkAllNotificationsRead)
+		notificationGroup.PATCH("/:notificationID/read", notificationHandler.MarkNotificationRead)
+		notificationGroup.DELETE("", notificationHandler.ClearNotifications)
+		notificationGroup.DELETE("/:notificationID", notificationHandler.DeleteNotification)
+	}
+}
+
+func RegisterNotificationSettingRoutes(r *gin.RouterGroup, notificationSettingService services.INotificationSettingService) {
+	notificationSettingHandler := handlers.NewNotificationSettingHandler(notificationSettingService)
+
+	settingGroup := r.Group("/me/notification-settings", auth.RequireScope("notifications"))
+	{
+		settingGroup.GET("", notificationSettingHandler.ListNotificationSettings)
+		settingGroup.GET("/badges", notificationSettingHandler.GetUnreadBadges)
+		settingGroup.GET("/effective/:roomID", notificationSettingHandler.GetEffectiveNotificationSetting)
+		settingGroup.PUT("/:scope/:scopeID", notificationSettingHandler.UpsertNotificationSetting) // scope: hall | floor | room
+		settingGroup.DELETE("/:scope/:scopeID", notificationSettingHandler.DeleteNotificationSetting)
+	}
+}
+
+func RegisterPushRoutes(r *gin.RouterGroup, pushService services.IPushService) {
+	pushHandler := handlers.NewPushHandler(pushService)
+
+	pushGroup := r.Group("/me/push", auth.RequireScope("notifications"))
+	{
+		pushGroup.GET("/vapid-public-key", pushHandler.GetVAPIDPublicKey)
+		pushGroup.GET("/subscriptions", pushHandler.ListSubscriptions)
+		pushGroup.POST("/subscriptions", pushHandler.RegisterSubscription)
+		pushGroup.DELETE("/subscriptions/:subscriptionID", pushHandler.DeleteSubscription)
+	}
+}
+
+// RegisterBotRoutes is for the humans owning bots, bots can't manage bots
+func RegisterBotRoutes(r *gin.RouterGroup, botService services.IBotService) {
+	botHandler := handlers.NewBotHandler(botService)
+
+	botGroup := r.Group("/me/bots", auth.HumansOnly(), auth.SessionsOnly())
+	{
+		botGroup.GET("", botHandler.ListMyBots)
+		botGroup.POST("", botHandler.CreateBot)
+		botGroup.POST("/:botID/token", botHandler.ResetBotToken)
+		botGroup.DELETE("/:botID", botHandler.DeleteBot)
+	}
+
+	r.POST("/halls/:hallID/bots", auth.HumansOnly(), auth.SessionsOnly(), botHandler.AddBotToHall)
+}
+
+// RegisterWebhookRoutes manages a room's incoming webhooks, under room settings
+func RegisterWebhookRoutes(r *gin.RouterGroup, webhookService services.IWebhookService) {
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	webhookGroup := r.Group("/halls/:hallID/rooms/:roomID/webhooks", auth.RequireScope("webhooks"))
+	{
+		webhookGroup.GET("", webhookHandler.ListRoomWebhooks)
+		webhookGroup.POST("", webhookHandler.CreateWebhook)
+		webhookGroup.POST("/:webhookID/token", webhookHandler.RotateWebhookToken)
+		webhookGroup.DELETE("/:webhookID", webhookHandler.DeleteWebhook)
+	}
+}
+
+// RegisterWebhookExecuteRoutes is the public side, callers only have the URL
+func RegisterWebhookExecuteRoutes(r *gin.RouterGroup, webhookService services.IWebhookService) {
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	r.POST("/webhooks/:webhookID/:token", webhookHandler.ExecuteWebhook)
+}
+
+// RegisterOutgoingWebhookRoutes manages the endpoints a hall's events are sent to
+func RegisterOutgoingWebhookRoutes(r *gin.RouterGroup, outgoingWebhookService services.IOutgoingWebhookService) {
+	outgoingWebhookHandler := handlers.NewOutgoingWebhookHandler(outgoingWebhookService)
+
+	outgoingGroup := r.Group("/halls/:hallID/outgoing-webhooks", auth.RequireScope("webhooks"))
+	{
+		outgoingGroup.GET("", outgoingWebhookHandler.ListOutgoingWebhooks)
+		outgoingGroup.POST("", outgoingWebhookHandler.CreateOutgoingWebhook)
+		outgoingGroup.PATCH("/:webhookID", outgoingWebhookHandler.UpdateOutgoingWebhook)
+		outgoingGroup.DELETE("/:webhookID", outgoingWebhookHandler.DeleteOutgoingWebhook)
+		outgoingGroup.POST("/:webhookID/secret", outgoingWebhookHandler.RotateOutgoingWebhookSecret)
+		outgoingGroup.GET("/:webhookID/deliveries", outgoingWebhookHandler.ListOutgoingDeliveries)
+	}
+}
+
+// RegisterCommandRoutes exposes slash command metadata and the bot side of commands
+func RegisterCommandRoutes(r *gin.RouterGroup, commandService services.ICommandService) {
+	commandHandler := handlers.NewCommandHandler(commandService)
+
+	r.GET("/halls/:hallID/commands", auth.RequireScope("messages"), commandHandler.ListCommands)
+	r.PUT("/halls/:hallID/commands", auth.BotsOnly(), commandHandler.RegisterBotCommands)
+	r.POST("/commands/interactions/:interactionID/reply", auth.BotsOnly(), commandHandler.ReplyToInteraction)
+}
+
+// RegisterPersonalTokenRoutes manages the caller's personal access tokens,
+// tokens can't be used to mint more tokens
+func RegisterPersonalTokenRoutes(r *gin.RouterGroup, personalTokenService services.IPersonalTokenService) {
+	personalTokenHandler := handlers.NewPersonalTokenHandler(personalTokenService)
+
+	tokenGroup := r.Group("/me/tokens", auth.HumansOnly(), auth.SessionsOnly())
+	{
+		tokenGroup.GET("", personalTokenHandler.ListPersonalTokens)
+		tokenGroup.POST("", personalTokenHandler.CreatePersonalToken)
+		tokenGroup.DELETE("/:tokenID", personalTokenHandler.RevokePersonalToken)
+	}
+}
+
+// RegisterOAuthRoutes are the client-facing protocol endpoints, clients
+// authenticate with their own credentials rather than a user's
+func RegisterOAuthRoutes(r *gin.RouterGroup, oauthService services.IOAuthService) {
+	oauthHandler := handlers.NewOAuthHandler(oauthService)
+
+	oauthGroup := r.Group("/oauth")
+	{
+		oauthGroup.POST("/token", oauthHandler.ExchangeToken)
+		oauthGroup.POST("/revoke", oauthHandler.RevokeToken)
+		oauthGroup.POST("/introspect", oauthHandler.IntrospectToken)
+	}
+}
+
+// RegisterOAuthAppRoutes covers registering apps, the consent step and the
+// user's authorized apps, all of which need a real session
+func RegisterOAuthAppRoutes(r *gin.RouterGroup, oauthService services.IOAuthService) {
+	oauthHandler := handlers.NewOAuthHandler(oauthService)
+
+	appGroup := r.Group("/me/oauth-apps", auth.HumansOnly(), auth.SessionsOnly())
+	{
+		appGroup.GET("", oauthHandler.ListMyOAuthApps)
+		appGroup.POST("", oauthHandler.CreateOAuthApp)
+		appGroup.PATCH("/:appID", oauthHandler.UpdateOAuthApp)
+		appGroup.DELETE("/:appID", oauthHandler.DeleteOAuthApp)
+		appGroup.POST("/:appID/secret", oauthHandler.RotateOAuthAppSecret)
+	}
+
+	authorizeGroup := r.Group("/oauth/authorize", auth.HumansOnly(), auth.SessionsOnly())
+	{
+		authorizeGroup.GET("", oauthHandler.GetConsent)
+		authorizeGroup.POST("", oauthHandler.Authorize)
+	}
+
+	authorizationGroup := r.Group("/me/oauth-authorizations", auth.HumansOnly(), auth.SessionsOnly())
+	{
+		authorizationGroup.GET("", oauthHandler.ListMyAuthorizations)
+		authorizationGroup.DELETE("/:appID", oauthHandler.RevokeAuthorization)
+	}
+}
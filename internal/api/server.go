@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,10 +13,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/suck-seed/yapp/config"
 	"github.com/suck-seed/yapp/internal/api/rest"
+	"github.com/suck-seed/yapp/internal/applinks"
 	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/graph"
+	"github.com/suck-seed/yapp/internal/mail"
+	"github.com/suck-seed/yapp/internal/oidc"
+	"github.com/suck-seed/yapp/internal/outgoing"
+	"github.com/suck-seed/yapp/internal/push"
 	"github.com/suck-seed/yapp/internal/realtime"
 	"github.com/suck-seed/yapp/internal/repositories"
 	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
 	"github.com/suck-seed/yapp/internal/ws"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -27,8 +35,13 @@ func StartServer(cfg config.AppConfig) {
 	// Engine instance with the Logger and Recovery middleware already attached.
 	router := gin.Default()
 
+	if err := router.SetTrustedProxies(config.GetTrustedProxies()); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	router.Use(auth.CSRFCookieMiddleware())
 	router.Use(cfg.CORS)
+	router.Use(auth.CSRFMiddleware())
 
 	// --- Swagger UI -------
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -49,6 +62,16 @@ func StartServer(cfg config.AppConfig) {
 		})
 	})
 
+	// local stand-in for browser push services, never exposed in production
+	if config.IsDevelopment() {
+		push.NewFakeEndpoint().Register(router)
+
+		// point OIDC_MOCK_ISSUER at <server>/dev/oidc to sign in without a real IdP
+		if mockOIDC, err := oidc.NewMockProvider(config.GetMockOIDCIssuer()); err == nil {
+			mockOIDC.Register(router)
+		}
+	}
+
 	// Dependency Injection
 	// Repository Initialization
 	userRepository := repositories.NewUserRepository()
@@ -60,6 +83,45 @@ func StartServer(cfg config.AppConfig) {
 	messageRepository := repositories.NewMessageRepository()
 	inviteRepository := repositories.NewInviteRepository()
 	presenceRepository := repositories.NewPresenceRepository(cfg.RedisClient)
+	wsTicketRepository := repositories.NewWSTicketRepository(cfg.RedisClient)
+	signinThrottleRepository := repositories.NewSigninThrottleRepository(cfg.RedisClient)
+	rateLimitRepository := repositories.NewRateLimitRepository(cfg.RedisClient)
+	notificationRepository := repositories.NewNotificationRepository()
+	pushSubscriptionRepository := repositories.NewPushSubscriptionRepository()
+	notificationSettingRepository := repositories.NewNotificationSettingRepository()
+	emailTokenRepository := repositories.NewEmailTokenRepository()
+	twoFactorRepository := repositories.NewTwoFactorRepository()
+	userIdentityRepository := repositories.NewUserIdentityRepository()
+	signingKeyRepository := repositories.NewSigningKeyRepository()
+	signinActivityRepository := repositories.NewSigninActivityRepository()
+	botRepository := repositories.NewBotRepository()
+	webhookRepository := repositories.NewWebhookRepository()
+	outgoingWebhookRepository := repositories.NewOutgoingWebhookRepository()
+	botCommandRepository := repositories.NewBotCommandRepository()
+	commandStateRepository := repositories.NewCommandStateRepository(cfg.RedisClient)
+	personalTokenRepository := repositories.NewPersonalTokenRepository()
+	oauthRepository := repositories.NewOAuthRepository()
+	oauthCodeRepository := repositories.NewOAuthCodeRepository(cfg.RedisClient)
+	savedMessageRepository := repositories.NewSavedMessageRepository()
+	scheduledMessageRepository := repositories.NewScheduledMessageRepository()
+
+	// Access token keys, generated and rotated with cmd/yapp-keys
+	signingKeyService := services.NewSigningKeyService(signingKeyRepository, cfg.PostgresPool)
+
+	keyRing := auth.NewKeyRing(signingKeyService.LoadSigningKeys)
+	if err := keyRing.Refresh(context.Background()); err != nil {
+		log.Printf("signing keys not loaded, signing with JWT_SECRET_KEY: %v", err)
+	}
+	auth.UseKeyRing(keyRing)
+	go keyRing.Run(context.Background(), time.Minute)
+
+	passwordConfig := config.GetPasswordConfig()
+	utils.UsePasswordParams(utils.Argon2Params{
+		Memory:      passwordConfig.Argon2MemoryKiB,
+		Iterations:  passwordConfig.Argon2Iterations,
+		Parallelism: passwordConfig.Argon2Parallelism,
+	})
+	utils.UseBreachedPasswordList(passwordConfig.BreachedListDir)
 
 	// Checker services
 	permissionCheckerService := services.NewPermissionCheckerService(
@@ -67,15 +129,99 @@ func StartServer(cfg config.AppConfig) {
 		userRepository,
 		hallRepository,
 		banRepository,
+		twoFactorRepository,
 		cfg.PostgresPool,
 	)
 
 	presenceService := services.NewPresenceService(presenceRepository)
+	wsTicketService := services.NewWSTicketService(wsTicketRepository)
+
+	rateLimitConfig := config.GetRateLimitConfig()
+	rateLimitService := services.NewRateLimitService(rateLimitRepository, rateLimitConfig.Enabled, rateLimitConfig.Overrides)
 
 	eventBus := realtime.NewEventBus(1024)
 
+	// hall events also go out to the halls' own endpoints
+	outgoingWebhookService := services.NewOutgoingWebhookService(
+		outgoingWebhookRepository,
+		permissionCheckerService,
+		outgoing.NewHTTPSender(config.IsDevelopment()),
+		config.IsDevelopment(),
+		cfg.PostgresPool,
+	)
+
+	publisher := realtime.Fanout{eventBus, outgoingWebhookService}
+
+	vapidConfig := config.GetVAPIDConfig()
+
+	pushService := services.NewPushService(
+		pushSubscriptionRepository,
+		presenceService,
+		push.NewSender(vapidConfig),
+		vapidConfig.PublicKey,
+		config.IsDevelopment(),
+		cfg.PostgresPool,
+	)
+
+	notificationService := services.NewNotificationService(
+		notificationRepository,
+		notificationSettingRepository,
+		userRepository,
+		pushService,
+		publisher,
+		cfg.PostgresPool,
+	)
+
+	mailConfig := config.GetMailConfig()
+	mailer, err := mail.NewMailer(mailConfig)
+	if err != nil {
+		log.Fatalf("mail: %v", err)
+	}
+
+	accountService := services.NewAccountService(
+		userRepository,
+		emailTokenRepository,
+		mailer,
+		mailConfig.AppBaseURL,
+		cfg.PostgresPool,
+	)
+
+	signinSecurityService := services.NewSigninSecurityService(
+		signinActivityRepository,
+		signinThrottleRepository,
+		notificationService,
+		mailer,
+		mailConfig.AppBaseURL,
+		cfg.PostgresPool,
+	)
+
 	// Usual Services
-	userService := services.NewUserService(userRepository, cfg.PostgresPool)
+	userService := services.NewUserService(userRepository, twoFactorRepository, notificationService, accountService, signinSecurityService, cfg.PostgresPool)
+
+	twoFactorService := services.NewTwoFactorService(
+		twoFactorRepository,
+		userRepository,
+		userService,
+		signinSecurityService,
+		cfg.PostgresPool,
+	)
+
+	oidcService := services.NewOIDCService(
+		userIdentityRepository,
+		userRepository,
+		twoFactorRepository,
+		userService,
+		accountService,
+		signinSecurityService,
+		oidc.NewRegistry(config.GetOIDCProviders()),
+		cfg.PostgresPool,
+	)
+
+	appLinkService := services.NewAppLinkService(
+		userRepository,
+		applinks.NewDefaultRegistry(config.GetAppLinkProviders()),
+		cfg.PostgresPool,
+	)
 
 	hallService := services.NewHallService(
 		hallRepository,
@@ -83,9 +229,11 @@ func StartServer(cfg config.AppConfig) {
 		roleRepository,
 		roomRepository,
 		banRepository,
+		twoFactorRepository,
 		permissionCheckerService,
 		presenceService,
-		eventBus,
+		notificationService,
+		publisher,
 		cfg.PostgresPool,
 	)
 
@@ -95,7 +243,7 @@ func StartServer(cfg config.AppConfig) {
 		roomRepository,
 		banRepository,
 		permissionCheckerService,
-		eventBus,
+		publisher,
 		cfg.PostgresPool,
 	)
 
@@ -105,7 +253,7 @@ func StartServer(cfg config.AppConfig) {
 		roomRepository,
 		banRepository,
 		permissionCheckerService,
-		eventBus,
+		publisher,
 		cfg.PostgresPool,
 	)
 
@@ -115,7 +263,7 @@ func StartServer(cfg config.AppConfig) {
 		hallRepository,
 		banRepository,
 		permissionCheckerService,
-		eventBus,
+		publisher,
 		cfg.PostgresPool,
 	)
 
@@ -124,7 +272,16 @@ func StartServer(cfg config.AppConfig) {
 		userRepository,
 		hallRepository,
 		permissionCheckerService,
-		eventBus,
+		publisher,
+		cfg.PostgresPool,
+	)
+
+	notificationSettingService := services.NewNotificationSettingService(
+		notificationSettingRepository,
+		hallRepository,
+		floorRepository,
+		roomRepository,
+		roomService,
 		cfg.PostgresPool,
 	)
 
@@ -134,6 +291,9 @@ func StartServer(cfg config.AppConfig) {
 		messageRepository,
 		userRepository,
 		permissionCheckerService,
+		notificationService,
+		eventBus,
+		outgoingWebhookService,
 		cfg.PostgresPool,
 	)
 
@@ -142,7 +302,72 @@ func StartServer(cfg config.AppConfig) {
 		hallRepository,
 		roleRepository,
 		permissionCheckerService,
-		eventBus,
+		notificationService,
+		publisher,
+		cfg.PostgresPool,
+	)
+
+	botService := services.NewBotService(
+		botRepository,
+		userRepository,
+		hallRepository,
+		roleRepository,
+		banRepository,
+		permissionCheckerService,
+		publisher,
+		cfg.PostgresPool,
+	)
+	auth.UseBotTokenVerifier(botService.VerifyBotToken)
+
+	personalTokenService := services.NewPersonalTokenService(personalTokenRepository, userRepository, cfg.PostgresPool)
+	auth.UsePersonalTokenVerifier(personalTokenService.VerifyPersonalToken)
+
+	oauthService := services.NewOAuthService(
+		oauthRepository,
+		oauthCodeRepository,
+		userRepository,
+		config.IsDevelopment(),
+		cfg.PostgresPool,
+	)
+	auth.UseOAuthTokenVerifier(oauthService.VerifyOAuthToken)
+
+	webhookService := services.NewWebhookService(
+		webhookRepository,
+		botRepository,
+		userRepository,
+		roomRepository,
+		permissionCheckerService,
+		messageService,
+		cfg.PostgresPool,
+	)
+
+	commandService := services.NewCommandService(
+		roomRepository,
+		hallRepository,
+		userRepository,
+		botCommandRepository,
+		commandStateRepository,
+		permissionCheckerService,
+		banService,
+		messageService,
+		notificationService,
+		publisher,
+		cfg.PostgresPool,
+	)
+
+	savedMessageService := services.NewSavedMessageService(
+		savedMessageRepository,
+		messageRepository,
+		roomService,
+		notificationService,
+		cfg.PostgresPool,
+	)
+
+	scheduledMessageService := services.NewScheduledMessageService(
+		scheduledMessageRepository,
+		roomService,
+		permissionCheckerService,
+		messageService,
 		cfg.PostgresPool,
 	)
 
@@ -153,35 +378,82 @@ func StartServer(cfg config.AppConfig) {
 
 	readRecieptFunction := ws.MakeReadReceiptFunction(messageService)
 
+	commandFunction := ws.MakeCommandFunction(commandService)
+
 	accessRevolver := ws.MakeAccessResolver(roomService)
 
 	hub := ws.NewHub(
 		presistFunction,
 		readRecieptFunction,
+		commandFunction,
 		presenceService,
+		rateLimitService,
 		eventBus,
 		accessRevolver,
 	)
 
 	go hub.Run()
+	go pushService.Run()
+	go outgoingWebhookService.Run()
+	go commandService.Run()
+	go savedMessageService.Run()
+	go scheduledMessageService.Run()
 
 	// Routes
 
+	rest.RegisterWellKnownRoutes(&router.RouterGroup, keyRing)
+
 	apiv1 := router.Group("/api/v1")
 
+	// unverified accounts can sign in and look around, opt in to keep them
+	// from creating or joining anything until the address is confirmed
+	var requireVerifiedEmail gin.HandlerFunc = func(c *gin.Context) { c.Next() }
+	var isEmailVerified auth.EmailVerifiedChecker
+	if config.RequireEmailVerification() {
+		isEmailVerified = accountService.IsEmailVerified
+		requireVerifiedEmail = auth.RequireVerifiedEmail(isEmailVerified)
+	}
+
+	graphSchema, err := graph.NewSchema(
+		userService,
+		hallService,
+		roomService,
+		messageService,
+		presenceService,
+		hub.Attach,
+		isEmailVerified,
+	)
+	if err != nil {
+		log.Fatalf("graphql schema: %v", err)
+	}
+
 	// ---- PUBLIC ROUTES ,  NO AUTHENTICATION
+	// limited per address, nobody is signed in yet
+	publicv1 := apiv1.Group("", auth.RateLimitMiddleware(rateLimitService.Allow, "auth"))
+	{
+		rest.RegisterAuthRoutes(publicv1, userService)
+		rest.RegisterAccountRoutes(publicv1, accountService)
+		rest.RegisterTwoFactorRoutes(publicv1, twoFactorService)
+		rest.RegisterOIDCRoutes(publicv1, oidcService)
+		rest.RegisterInvitePublicRoutes(publicv1, inviteService)
+		rest.RegisterOAuthRoutes(publicv1, oauthService)
+	}
+
+	// incoming webhooks authenticate with the secret in their URL
+	webhookv1 := apiv1.Group("", auth.RateLimitMiddleware(rateLimitService.Allow, "webhooks"))
 	{
-		rest.RegisterAuthRoutes(apiv1, userService)
-		rest.RegisterInvitePublicRoutes(apiv1, inviteService)
+		rest.RegisterWebhookExecuteRoutes(webhookv1, webhookService)
 	}
 
 	// For endpoint with authentication required
-	protectedv1 := apiv1.Group("", auth.AuthMiddleware())
+	protectedv1 := apiv1.Group("", auth.AuthMiddleware(), auth.RateLimitMiddleware(rateLimitService.Allow, "api"))
 	{
-		rest.RegisterUserRoutes(protectedv1, userService)
+		rest.RegisterUserRoutes(protectedv1, userService, requireVerifiedEmail)
+		rest.RegisterAppLinkRoutes(protectedv1, appLinkService)
+		rest.RegisterSigninSecurityRoutes(protectedv1, signinSecurityService)
 
 		rest.RegisterHallRoutes(
-			protectedv1,
+			protectedv1.Group("", auth.RateLimitMiddleware(rateLimitService.Allow, "halls")),
 			hallService,
 			roleService,
 			banService,
@@ -189,18 +461,34 @@ func StartServer(cfg config.AppConfig) {
 			floorService,
 			roomService,
 			messageService,
+			requireVerifiedEmail,
 		)
 
 		rest.RegisterMessageRoutes(protectedv1, messageService)
+		rest.RegisterSavedMessageRoutes(protectedv1, savedMessageService)
+		rest.RegisterScheduledMessageRoutes(protectedv1, scheduledMessageService)
 		rest.RegisterInvitePrivateRoutes(protectedv1, inviteService)
 		rest.RegisterPresenceRoutes(protectedv1, presenceService)
+		rest.RegisterWSTicketRoutes(protectedv1, wsTicketService)
+		rest.RegisterNotificationRoutes(protectedv1, notificationService)
+		rest.RegisterNotificationSettingRoutes(protectedv1, notificationSettingService)
+		rest.RegisterPushRoutes(protectedv1, pushService)
+		rest.RegisterBotRoutes(protectedv1, botService)
+		rest.RegisterWebhookRoutes(protectedv1, webhookService)
+		rest.RegisterOutgoingWebhookRoutes(protectedv1, outgoingWebhookService)
+		rest.RegisterCommandRoutes(protectedv1, commandService)
+		rest.RegisterPersonalTokenRoutes(protectedv1, personalTokenService)
+		rest.RegisterOAuthAppRoutes(protectedv1, oauthService)
+
+		graph.RegisterGraphQLRoutes(protectedv1, graphSchema, config.GetAllowedOrigins(), userService, presenceService)
 	}
 
-	wsHandler := router.Group("/ws", auth.WebSocketAuthMiddleware())
+	wsHandler := router.Group("/ws", auth.WebSocketAuthMiddleware(wsTicketService.RedeemWSTicket))
 	{
 		ws.RegisterWebSocketRoutes(
 			wsHandler,
 			&hub,
+			config.GetAllowedOrigins(),
 			messageService,
 			hallService,
 			roomService,
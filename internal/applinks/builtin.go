@@ -0,0 +1,95 @@
+package applinks
+
+import (
+	"encoding/json"
+
+	"github.com/suck-seed/yapp/config"
+)
+
+var SpotifySpec = OAuth2Spec{
+	Name:        "spotify",
+	DisplayName: "Spotify",
+	AuthURL:     "https://accounts.spotify.com/authorize",
+	TokenURL:    "https://accounts.spotify.com/api/token",
+	UserInfoURL: "https://api.spotify.com/v1/me",
+	Scopes:      []string{"user-read-private"},
+	PKCE:        true,
+	ParseAccount: func(body []byte) (*Account, error) {
+		me := struct {
+			ID           string `json:"id"`
+			DisplayName  string `json:"display_name"`
+			ExternalURLs struct {
+				Spotify string `json:"spotify"`
+			} `json:"external_urls"`
+		}{}
+		if err := json.Unmarshal(body, &me); err != nil {
+			return nil, err
+		}
+
+		handle := me.DisplayName
+		if handle == "" {
+			handle = me.ID
+		}
+		return &Account{ExternalID: me.ID, Handle: handle, ProfileURL: me.ExternalURLs.Spotify}, nil
+	},
+}
+
+var RedditSpec = OAuth2Spec{
+	Name:            "reddit",
+	DisplayName:     "Reddit",
+	AuthURL:         "https://www.reddit.com/api/v1/authorize",
+	TokenURL:        "https://www.reddit.com/api/v1/access_token",
+	UserInfoURL:     "https://oauth.reddit.com/api/v1/me",
+	Scopes:          []string{"identity"},
+	BasicAuth:       true,
+	ExtraAuthParams: map[string]string{"duration": "temporary"},
+	ParseAccount: func(body []byte) (*Account, error) {
+		me := struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}{}
+		if err := json.Unmarshal(body, &me); err != nil {
+			return nil, err
+		}
+		return &Account{ExternalID: me.ID, Handle: "u/" + me.Name, ProfileURL: "https://www.reddit.com/user/" + me.Name}, nil
+	},
+}
+
+var TwitterSpec = OAuth2Spec{
+	Name:        "twitter",
+	DisplayName: "X (Twitter)",
+	AuthURL:     "https://twitter.com/i/oauth2/authorize",
+	TokenURL:    "https://api.twitter.com/2/oauth2/token",
+	UserInfoURL: "https://api.twitter.com/2/users/me",
+	Scopes:      []string{"users.read", "tweet.read"},
+	PKCE:        true,
+	BasicAuth:   true,
+	ParseAccount: func(body []byte) (*Account, error) {
+		me := struct {
+			Data struct {
+				ID       string `json:"id"`
+				Username string `json:"username"`
+			} `json:"data"`
+		}{}
+		if err := json.Unmarshal(body, &me); err != nil {
+			return nil, err
+		}
+		return &Account{ExternalID: me.Data.ID, Handle: "@" + me.Data.Username, ProfileURL: "https://x.com/" + me.Data.Username}, nil
+	},
+}
+
+// NewDefaultRegistry registers the built in providers that have credentials.
+func NewDefaultRegistry(cfgs map[string]config.AppLinkProviderConfig) *Registry {
+	providers := make([]Provider, 0, len(cfgs))
+
+	for _, spec := range []OAuth2Spec{SpotifySpec, RedditSpec, TwitterSpec} {
+		if cfg, ok := cfgs[spec.Name]; ok {
+			providers = append(providers, NewOAuth2Provider(spec, cfg))
+		}
+	}
+	if cfg, ok := cfgs["steam"]; ok {
+		providers = append(providers, NewSteamProvider(cfg))
+	}
+
+	return NewRegistry(providers...)
+}
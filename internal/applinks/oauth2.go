@@ -0,0 +1,185 @@
+package applinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/suck-seed/yapp/config"
+)
+
+// OAuth2Spec describes a provider that speaks plain OAuth 2.0 authorization
+// code and exposes a "who am I" endpoint.
+type OAuth2Spec struct {
+	Name            string
+	DisplayName     string
+	AuthURL         string
+	TokenURL        string
+	UserInfoURL     string
+	Scopes          []string
+	PKCE            bool
+	ExtraAuthParams map[string]string
+
+	// BasicAuth sends the client credentials in the Authorization header
+	// instead of the form body
+	BasicAuth bool
+
+	// ParseAccount pulls the account out of the UserInfoURL response
+	ParseAccount func(body []byte) (*Account, error)
+}
+
+type oauth2Provider struct {
+	spec   OAuth2Spec
+	cfg    config.AppLinkProviderConfig
+	client *http.Client
+}
+
+func NewOAuth2Provider(spec OAuth2Spec, cfg config.AppLinkProviderConfig) Provider {
+	return &oauth2Provider{
+		spec:   spec,
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *oauth2Provider) Name() string {
+	return p.spec.Name
+}
+
+func (p *oauth2Provider) DisplayName() string {
+	return p.spec.DisplayName
+}
+
+func (p *oauth2Provider) AuthorizationURL(state string, codeVerifier string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.spec.Scopes, " "))
+	q.Set("state", state)
+	if p.spec.PKCE {
+		q.Set("code_challenge", codeChallengeS256(codeVerifier))
+		q.Set("code_challenge_method", "S256")
+	}
+	for k, v := range p.spec.ExtraAuthParams {
+		q.Set(k, v)
+	}
+
+	return p.spec.AuthURL + "?" + q.Encode()
+}
+
+func (p *oauth2Provider) Complete(ctx context.Context, params map[string]string, codeVerifier string) (*Connection, error) {
+	if params["error"] != "" || params["code"] == "" {
+		return nil, ErrAuthorizationFailed
+	}
+
+	token, err := p.exchange(ctx, params["code"], codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.spec.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+	// reddit refuses requests without a descriptive agent
+	req.Header.Set("User-Agent", "yapp-app-links/1.0")
+
+	body, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := p.spec.ParseAccount(body)
+	if err != nil {
+		return nil, err
+	}
+	if account.ExternalID == "" {
+		return nil, fmt.Errorf("applinks: %s returned no account id", p.spec.Name)
+	}
+
+	return &Connection{Account: *account, Token: token}, nil
+}
+
+func (p *oauth2Provider) exchange(ctx context.Context, code string, codeVerifier string) (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	if p.spec.PKCE {
+		form.Set("code_verifier", codeVerifier)
+	}
+	if !p.spec.BasicAuth {
+		form.Set("client_id", p.cfg.ClientID)
+		if p.cfg.ClientSecret != "" {
+			form.Set("client_secret", p.cfg.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.spec.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "yapp-app-links/1.0")
+	if p.spec.BasicAuth {
+		req.SetBasicAuth(p.cfg.ClientID, p.cfg.ClientSecret)
+	}
+
+	body, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	if res.AccessToken == "" {
+		return nil, ErrAuthorizationFailed
+	}
+
+	token := &Token{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+	}
+	if res.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+		token.ExpiresAt = &expiresAt
+	}
+	return token, nil
+}
+
+func (p *oauth2Provider) do(req *http.Request) ([]byte, error) {
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("%w: %s %s: %s", ErrAuthorizationFailed, req.Method, req.URL.Host, res.Status)
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("applinks: %s %s: %s", req.Method, req.URL.Host, res.Status)
+	}
+
+	return body, nil
+}
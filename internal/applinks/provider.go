@@ -0,0 +1,88 @@
+// Package applinks connects third party accounts (Spotify, Reddit, ...) to a
+// profile. Every provider proves ownership by sending the user through its
+// own login, free-form URLs are never trusted.
+package applinks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"time"
+)
+
+var ErrAuthorizationFailed = errors.New("applinks: provider rejected the authorization")
+
+// Account is the verified identity at the provider.
+type Account struct {
+	ExternalID string
+	Handle     string
+	ProfileURL string
+}
+
+// Token is kept (encrypted) for providers that hand one out.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    *time.Time
+}
+
+type Connection struct {
+	Account Account
+	Token   *Token
+}
+
+// Provider is one kind of account a user can connect. New providers only
+// need to implement this and be passed to NewRegistry.
+type Provider interface {
+	Name() string
+	DisplayName() string
+
+	// AuthorizationURL is where the browser goes, state comes back untouched.
+	AuthorizationURL(state string, codeVerifier string) string
+
+	// Complete verifies what the provider appended to the redirect URL.
+	Complete(ctx context.Context, params map[string]string, codeVerifier string) (*Connection, error)
+}
+
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+func (r *Registry) List() []Provider {
+	out := make([]Provider, 0, len(r.providers))
+	for _, p := range r.providers {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// NewCodeVerifier returns a PKCE verifier, providers without PKCE ignore it.
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
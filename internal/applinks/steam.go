@@ -0,0 +1,120 @@
+package applinks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/suck-seed/yapp/config"
+)
+
+const steamOpenIDEndpoint = "https://steamcommunity.com/openid/login"
+
+var steamClaimedID = regexp.MustCompile(`^https://steamcommunity\.com/openid/id/(\d{17})$`)
+
+// steamProvider uses Steam's OpenID 2.0 login, Steam offers no OAuth to
+// third parties. The state travels inside return_to.
+type steamProvider struct {
+	cfg    config.AppLinkProviderConfig
+	client *http.Client
+}
+
+func NewSteamProvider(cfg config.AppLinkProviderConfig) Provider {
+	return &steamProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *steamProvider) Name() string {
+	return "steam"
+}
+
+func (p *steamProvider) DisplayName() string {
+	return "Steam"
+}
+
+func (p *steamProvider) returnTo(state string) string {
+	return p.cfg.RedirectURL + "?state=" + url.QueryEscape(state)
+}
+
+func (p *steamProvider) realm() string {
+	u, err := url.Parse(p.cfg.RedirectURL)
+	if err != nil {
+		return p.cfg.RedirectURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func (p *steamProvider) AuthorizationURL(state string, _ string) string {
+	q := url.Values{}
+	q.Set("openid.ns", "http://specs.openid.net/auth/2.0")
+	q.Set("openid.mode", "checkid_setup")
+	q.Set("openid.return_to", p.returnTo(state))
+	q.Set("openid.realm", p.realm())
+	q.Set("openid.identity", "http://specs.openid.net/auth/2.0/identifier_select")
+	q.Set("openid.claimed_id", "http://specs.openid.net/auth/2.0/identifier_select")
+
+	return steamOpenIDEndpoint + "?" + q.Encode()
+}
+
+// Complete asks Steam to confirm the signed assertion it redirected with.
+func (p *steamProvider) Complete(ctx context.Context, params map[string]string, _ string) (*Connection, error) {
+	if params["openid.mode"] != "id_res" {
+		return nil, ErrAuthorizationFailed
+	}
+	if params["openid.return_to"] != p.returnTo(params["state"]) {
+		return nil, ErrAuthorizationFailed
+	}
+
+	match := steamClaimedID.FindStringSubmatch(params["openid.claimed_id"])
+	if match == nil {
+		return nil, ErrAuthorizationFailed
+	}
+
+	form := url.Values{}
+	for k, v := range params {
+		if strings.HasPrefix(k, "openid.") {
+			form.Set(k, v)
+		}
+	}
+	form.Set("openid.mode", "check_authentication")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, steamOpenIDEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("applinks: steam check_authentication: %s", res.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 4096))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(string(body), "is_valid:true") {
+		return nil, ErrAuthorizationFailed
+	}
+
+	steamID := match[1]
+	return &Connection{
+		Account: Account{
+			ExternalID: steamID,
+			Handle:     steamID,
+			ProfileURL: "https://steamcommunity.com/profiles/" + steamID,
+		},
+	}, nil
+}
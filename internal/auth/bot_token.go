@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// BotTokenVerifier resolves a bot token to the bot it was issued to
+type BotTokenVerifier func(ctx context.Context, token string) (*UserInfo, error)
+
+var activeBotTokenVerifier atomic.Pointer[BotTokenVerifier]
+
+// UseBotTokenVerifier lets AuthMiddleware and WebSocketAuthMiddleware accept
+// "Authorization: Bot <token>". Without one bot tokens are refused.
+func UseBotTokenVerifier(verify BotTokenVerifier) {
+	activeBotTokenVerifier.Store(&verify)
+}
+
+func botTokenFromRequest(c *gin.Context) (string, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bot ")
+	if !ok {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// authenticateBot handles requests carrying a bot token, it reports false
+// when there is none and the caller should look for a user token instead
+func authenticateBot(c *gin.Context) bool {
+	token, ok := botTokenFromRequest(c)
+	if !ok {
+		return false
+	}
+
+	verify := activeBotTokenVerifier.Load()
+	if verify == nil {
+		utils.WriteError(c, utils.ErrorInvalidBotToken)
+		c.Abort()
+		return true
+	}
+
+	userInfo, err := (*verify)(c.Request.Context(), token)
+	if err != nil {
+		utils.WriteError(c, err)
+		c.Abort()
+		return true
+	}
+
+	setCurrentUser(c, userInfo)
+	c.Next()
+	return true
+}
+
+// HumansOnly must run after AuthMiddleware, it keeps bots away from the
+// routes it guards
+func HumansOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userInfo, err := CurrentUserFromGinContext(c)
+		if err != nil {
+			utils.WriteError(c, err)
+			c.Abort()
+			return
+		}
+		if userInfo.IsBot {
+			utils.WriteError(c, utils.ErrorBotsNotAllowed)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// BotsOnly is the other way around, for routes only bot accounts may call
+func BotsOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userInfo, err := CurrentUserFromGinContext(c)
+		if err != nil {
+			utils.WriteError(c, err)
+			c.Abort()
+			return
+		}
+		if !userInfo.IsBot {
+			utils.WriteError(c, utils.ErrorBotsOnly)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -2,10 +2,23 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	csrfCookieName   = "csrf_token"
+	csrfCookieMaxAge = 24 * 60 * 60
+
+	// CSRFHeaderName carries the token on unsafe requests, and on responses
+	// that hand out a new one
+	CSRFHeaderName = "X-CSRF-Token"
+
+	ctxCSRFTokenKey = "csrf_token"
 )
 
 func generateCSRFToken() string {
@@ -14,29 +27,53 @@ func generateCSRFToken() string {
 	return base64.RawURLEncoding.EncodeToString(b)
 }
 
+func setCSRFToken(c *gin.Context, token string) {
+	isHTTPS := c.GetHeader("X-Forwarded-Proto") == "https"
+
+	if isHTTPS {
+		c.SetSameSite(http.SameSiteNoneMode)
+		c.SetCookie(csrfCookieName, token, csrfCookieMaxAge, "/", "", true, false)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(csrfCookieName, token, csrfCookieMaxAge, "/", "", false, false)
+	}
+
+	// a frontend on another site can't read our cookies
+	c.Header(CSRFHeaderName, token)
+	c.Set(ctxCSRFTokenKey, token)
+}
+
 // Sets csrf_token cookie if missing
 func CSRFCookieMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		_, err := c.Cookie("csrf_token")
-		if err != nil {
-			isHTTPS := c.GetHeader("X-Forwarded-Proto") == "https"
-
-			token := generateCSRFToken()
-
-			if isHTTPS {
-				c.SetSameSite(http.SameSiteNoneMode)
-				c.SetCookie("csrf_token", token, 24*60*60, "/", "", true, false)
-			} else {
-				c.SetSameSite(http.SameSiteLaxMode)
-				c.SetCookie("csrf_token", token, 24*60*60, "/", "", false, false)
-			}
+		token, err := c.Cookie(csrfCookieName)
+		if err != nil || token == "" {
+			setCSRFToken(c, generateCSRFToken())
+		} else {
+			c.Set(ctxCSRFTokenKey, token)
 		}
 
 		c.Next()
 	}
 }
 
-// Enforce CSRF on unsafe methods
+// RotateCSRFToken issues a fresh token, call it whenever the session
+// changes hands so a token planted before sign-in is worthless after.
+func RotateCSRFToken(c *gin.Context) string {
+	token := generateCSRFToken()
+	setCSRFToken(c, token)
+	return token
+}
+
+// CurrentCSRFToken is the token the client should send back, requires
+// CSRFCookieMiddleware.
+func CurrentCSRFToken(c *gin.Context) string {
+	return c.GetString(ctxCSRFTokenKey)
+}
+
+// Enforce CSRF on unsafe methods that are authenticated by the jwt cookie.
+// Callers that only send Authorization: Bearer are left alone, a cross-site
+// form can't set that header.
 func CSRFMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		switch c.Request.Method {
@@ -45,19 +82,22 @@ func CSRFMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		cookieToken, err := c.Cookie("csrf_token")
+		if session, err := c.Cookie("jwt"); err != nil || session == "" {
+			c.Next()
+			return
+		}
+
+		cookieToken, err := c.Cookie(csrfCookieName)
 		if err != nil || cookieToken == "" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "missing csrf cookie",
-			})
+			utils.WriteError(c, utils.ErrorMissingCSRFToken)
+			c.Abort()
 			return
 		}
 
-		headerToken := c.GetHeader("X-CSRF-Token")
-		if headerToken == "" || headerToken != cookieToken {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "invalid csrf token",
-			})
+		headerToken := c.GetHeader(CSRFHeaderName)
+		if headerToken == "" || subtle.ConstantTimeCompare([]byte(headerToken), []byte(cookieToken)) != 1 {
+			utils.WriteError(c, utils.ErrorInvalidCSRFToken)
+			c.Abort()
 			return
 		}
 
@@ -12,6 +12,12 @@ import (
 const (
 	AccessTokenTTL  = 60 * time.Minute
 	RefreshTokenTTL = 30 * 24 * time.Hour
+
+	// time a user has to enter their second factor after the password
+	MFAChallengeTTL = 5 * time.Minute
+
+	// time a new external login has to pick a username
+	ExternalSignupTTL = 15 * time.Minute
 )
 
 type JWTPayload struct {
@@ -22,7 +28,7 @@ type JWTPayload struct {
 
 func GetSignedToken(user *models.User) (string, error) {
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTPayload{
+	claims := JWTPayload{
 		ID:       user.ID.String(),
 		Username: user.Username,
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -31,7 +37,18 @@ func GetSignedToken(user *models.User) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-	})
+	}
+
+	if ring := activeKeyRing.Load(); ring != nil {
+		if key := ring.signingKey(); key != nil {
+			token := jwt.NewWithClaims(key.method(), claims)
+			token.Header["kid"] = key.ID
+			return token.SignedString(key.PrivateKey)
+		}
+	}
+
+	// no rotated key yet, fall back to the shared secret
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	secretKey := config.GetSecretKey()
 	signedString, err := token.SignedString([]byte(secretKey))
@@ -40,18 +57,34 @@ func GetSignedToken(user *models.User) (string, error) {
 }
 
 func ParseAndVerify(tokenString string) (*JWTPayload, error) {
-	secretKey := config.GetSecretKey()
 	claims := &JWTPayload{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
 
-		if token.Method != jwt.SigningMethodHS256 {
+		if token.Method == jwt.SigningMethodHS256 {
+			if !config.AcceptLegacyHS256Tokens() {
+				return nil, fmt.Errorf("HS256 tokens are no longer accepted")
+			}
+			return []byte(config.GetSecretKey()), nil
+		}
+
+		ring := activeKeyRing.Load()
+		kid, _ := token.Header["kid"].(string)
+		if ring == nil || kid == "" {
+			return nil, fmt.Errorf("unknown signing key")
+		}
+
+		key, ok := ring.verificationKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method")
 		}
 
-		return []byte(secretKey), nil
+		return key.PublicKey, nil
 
-	})
+	}, jwt.WithValidMethods([]string{AlgEdDSA, AlgRS256, jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		return nil, err
 	}
@@ -62,3 +95,90 @@ func ParseAndVerify(tokenString string) (*JWTPayload, error) {
 
 	return claims, nil
 }
+
+// MFA challenge tokens prove the password step succeeded. They are signed with
+// a key derived from the secret so they can never pass as an access token.
+func mfaSigningKey() []byte {
+	return []byte(config.GetSecretKey() + ":mfa")
+}
+
+func GetMFAChallengeToken(user *models.User) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    "yapp",
+		Subject:   user.ID.String(),
+		Audience:  jwt.ClaimStrings{"mfa"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFAChallengeTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	})
+
+	return token.SignedString(mfaSigningKey())
+}
+
+// ParseMFAChallengeToken returns the user id the challenge was issued for.
+func ParseMFAChallengeToken(tokenString string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return mfaSigningKey(), nil
+	}, jwt.WithAudience("mfa"))
+	if err != nil {
+		return "", err
+	}
+
+	if token == nil || !token.Valid {
+		return "", fmt.Errorf("invalid token")
+	}
+
+	return claims.Subject, nil
+}
+
+// ExternalSignupPayload carries a verified external identity that has no
+// account yet, until the user picked a username.
+type ExternalSignupPayload struct {
+	Provider      string `json:"provider"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+func externalSignupSigningKey() []byte {
+	return []byte(config.GetSecretKey() + ":external-signup")
+}
+
+// GetExternalSignupToken : subject is the provider's subject for the identity
+func GetExternalSignupToken(subject string, payload ExternalSignupPayload) (string, error) {
+	payload.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    "yapp",
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{"external-signup"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ExternalSignupTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	return token.SignedString(externalSignupSigningKey())
+}
+
+func ParseExternalSignupToken(tokenString string) (*ExternalSignupPayload, error) {
+	claims := &ExternalSignupPayload{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return externalSignupSigningKey(), nil
+	}, jwt.WithAudience("external-signup"))
+	if err != nil {
+		return nil, err
+	}
+
+	if token == nil || !token.Valid || claims.Subject == "" {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
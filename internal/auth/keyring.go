@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// an unknown kid triggers a reload at most this often, so garbage kids can't
+// turn every request into a database round trip
+const keyRingMissRefreshInterval = 10 * time.Second
+
+// KeyLoader returns every signing key that has not expired yet.
+type KeyLoader func(ctx context.Context) ([]*SigningKey, error)
+
+// KeyRing keeps the signing keys in memory. Keys are loaded ahead of their
+// NotBefore, so every instance switches to a new key at the same moment
+// without having to reload at that exact time.
+type KeyRing struct {
+	load KeyLoader
+
+	mu   sync.RWMutex
+	keys []*SigningKey
+	byID map[string]*SigningKey
+
+	// missMu guards the reloads an unknown kid triggers. missReloadAt is the
+	// last attempt whether or not it worked, missReload is closed when the
+	// one in flight is done.
+	missMu       sync.Mutex
+	missReloadAt time.Time
+	missReload   chan struct{}
+}
+
+func NewKeyRing(load KeyLoader) *KeyRing {
+	return &KeyRing{
+		load: load,
+		byID: map[string]*SigningKey{},
+	}
+}
+
+func (r *KeyRing) Refresh(ctx context.Context) error {
+	keys, err := r.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*SigningKey, len(keys))
+	for _, k := range keys {
+		byID[k.ID] = k
+	}
+	// newest first, signingKey picks the first one that is live
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].NotBefore.After(keys[j].NotBefore)
+	})
+
+	r.mu.Lock()
+	r.keys = keys
+	r.byID = byID
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Run reloads the ring until ctx is cancelled.
+func (r *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			if err := r.Refresh(refreshCtx); err != nil {
+				log.Printf("auth: reload signing keys: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (r *KeyRing) signingKey() *SigningKey {
+	now := time.Now()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.signsAt(now) {
+			return k
+		}
+	}
+	return nil
+}
+
+func (r *KeyRing) verificationKey(id string) (*SigningKey, bool) {
+	r.mu.RLock()
+	key, ok := r.byID[id]
+	r.mu.RUnlock()
+
+	// another instance may have generated it since our last reload
+	if !ok {
+		r.reloadOnMiss()
+
+		r.mu.RLock()
+		key, ok = r.byID[id]
+		r.mu.RUnlock()
+	}
+
+	if !ok || !key.verifiesAt(time.Now()) {
+		return nil, false
+	}
+	return key, true
+}
+
+// reloadOnMiss reloads at most once per keyRingMissRefreshInterval, even
+// while the database is failing. Misses that arrive during a reload wait for
+// it instead of starting their own.
+func (r *KeyRing) reloadOnMiss() {
+	r.missMu.Lock()
+	if inFlight := r.missReload; inFlight != nil {
+		r.missMu.Unlock()
+		<-inFlight
+		return
+	}
+	if time.Since(r.missReloadAt) < keyRingMissRefreshInterval {
+		r.missMu.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	r.missReload = done
+	r.missReloadAt = time.Now()
+	r.missMu.Unlock()
+
+	defer func() {
+		r.missMu.Lock()
+		r.missReload = nil
+		r.missMu.Unlock()
+		close(done)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := r.Refresh(ctx); err != nil {
+		log.Printf("auth: reload signing keys: %v", err)
+	}
+}
+
+// JWKS lists the public keys of everything that verifies, including keys
+// that are published but not signing yet.
+func (r *KeyRing) JWKS() JSONWebKeySet {
+	now := time.Now()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.verifiesAt(now) {
+			set.Keys = append(set.Keys, k.JSONWebKey())
+		}
+	}
+	return set
+}
+
+var activeKeyRing atomic.Pointer[KeyRing]
+
+// UseKeyRing makes GetSignedToken and ParseAndVerify use the ring. Without
+// one, or while it holds no live key, tokens are signed with JWT_SECRET_KEY.
+func UseKeyRing(r *KeyRing) {
+	activeKeyRing.Store(r)
+}
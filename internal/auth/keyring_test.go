@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUnknownKidReloadsOncePerIntervalWhileLoadFails(t *testing.T) {
+	var loads atomic.Int32
+	ring := NewKeyRing(func(ctx context.Context) ([]*SigningKey, error) {
+		loads.Add(1)
+		return nil, errors.New("database down")
+	})
+
+	for i := 0; i < 20; i++ {
+		if _, ok := ring.verificationKey("made-up"); ok {
+			t.Fatal("verified an unknown kid")
+		}
+	}
+
+	if got := loads.Load(); got != 1 {
+		t.Fatalf("loaded %d times for repeated misses, want 1", got)
+	}
+}
+
+func TestConcurrentUnknownKidsShareOneReload(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	ring := NewKeyRing(func(ctx context.Context) ([]*SigningKey, error) {
+		loads.Add(1)
+		<-release
+		return nil, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ring.verificationKey("made-up")
+		}()
+	}
+
+	// let the misses pile up behind the first reload
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := loads.Load(); got != 1 {
+		t.Fatalf("loaded %d times for concurrent misses, want 1", got)
+	}
+}
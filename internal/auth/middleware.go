@@ -12,19 +12,37 @@ import (
 const (
 	CtxUserIDKey   = "user_id"
 	CtxUsernameKey = "username"
+	CtxIsBotKey    = "is_bot"
+	CtxScopesKey   = "token_scopes"
 )
 
 // UserInfo hold authenticated user information
 type UserInfo struct {
 	ID       uuid.UUID
 	Username string
+
+	// IsBot is set when the caller authenticated with a bot token
+	IsBot bool
+
+	// Scopes is only set for personal access tokens, nil means unrestricted
+	Scopes []string
 }
 
-// Verifies JWT from cookie "jwt" or "Authorization : Bearer <token>"
+// Verifies JWT from cookie "jwt" or "Authorization : Bearer <token>", a
+// personal access token or OAuth access token from the same header, or a
+// bot token from "Authorization : Bot <token>",
 // and injects userId/username into gin.Context
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
+		if authenticateBot(c) {
+			return
+		}
+
+		if authenticateScopedToken(c) {
+			return
+		}
+
 		token, ok := GetTokenFromRequest(c)
 		if !ok {
 			utils.WriteError(c, utils.ErrorMissingToken)
@@ -48,23 +66,29 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		userInfo := &UserInfo{
+		setCurrentUser(c, &UserInfo{
 			ID:       userID,
 			Username: claims.Username,
-		}
+		})
 
-		// store in gin.Context
-		c.Set(CtxUserIDKey, userInfo.ID)
-		c.Set(CtxUsernameKey, userInfo.Username)
+		c.Next()
+	}
 
-		// context.context
-		ctx := context.WithValue(c.Request.Context(), CtxUserIDKey, userInfo.ID)
-		ctx = context.WithValue(ctx, CtxUsernameKey, userInfo.Username)
-		c.Request = c.Request.WithContext(ctx)
+}
 
-		c.Next()
+// setCurrentUser stores the caller in both the gin and the request context
+func setCurrentUser(c *gin.Context, userInfo *UserInfo) {
+	c.Set(CtxUserIDKey, userInfo.ID)
+	c.Set(CtxUsernameKey, userInfo.Username)
+	c.Set(CtxIsBotKey, userInfo.IsBot)
+	if userInfo.Scopes != nil {
+		c.Set(CtxScopesKey, userInfo.Scopes)
 	}
 
+	ctx := context.WithValue(c.Request.Context(), CtxUserIDKey, userInfo.ID)
+	ctx = context.WithValue(ctx, CtxUsernameKey, userInfo.Username)
+	ctx = context.WithValue(ctx, CtxIsBotKey, userInfo.IsBot)
+	c.Request = c.Request.WithContext(ctx)
 }
 
 func GetTokenFromRequest(c *gin.Context) (string, bool) {
@@ -98,9 +122,47 @@ func CurrentUserFromGinContext(c *gin.Context) (*UserInfo, error) {
 	rawUsername, _ := c.Get(CtxUsernameKey)
 	username, _ := rawUsername.(string)
 
+	rawIsBot, _ := c.Get(CtxIsBotKey)
+	isBot, _ := rawIsBot.(bool)
+
+	rawScopes, _ := c.Get(CtxScopesKey)
+	scopes, _ := rawScopes.([]string)
+
 	return &UserInfo{
 		ID:       userID,
 		Username: username,
+		IsBot:    isBot,
+		Scopes:   scopes,
 	}, nil
 
 }
+
+// EmailVerifiedChecker reports whether the user has confirmed their e-mail address
+type EmailVerifiedChecker func(ctx context.Context, userID uuid.UUID) (bool, error)
+
+// RequireVerifiedEmail must run after AuthMiddleware. It blocks accounts that
+// haven't verified their e-mail yet from the routes it guards.
+func RequireVerifiedEmail(isVerified EmailVerifiedChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userInfo, err := CurrentUserFromGinContext(c)
+		if err != nil {
+			utils.WriteError(c, err)
+			c.Abort()
+			return
+		}
+
+		verified, err := isVerified(c.Request.Context(), userInfo.ID)
+		if err != nil {
+			utils.WriteError(c, err)
+			c.Abort()
+			return
+		}
+		if !verified {
+			utils.WriteError(c, utils.ErrorEmailNotVerified)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
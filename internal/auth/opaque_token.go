@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/suck-seed/yapp/config"
+)
+
+// NewOpaqueToken returns a random url-safe token together with the keyed
+// hash that should be persisted in its place.
+func NewOpaqueToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+
+	raw := base64.RawURLEncoding.EncodeToString(b)
+	return raw, HashOpaqueToken(raw), nil
+}
+
+// HashOpaqueToken signs the raw token with the server secret, so a leaked
+// table alone is not enough to forge or replay links.
+func HashOpaqueToken(raw string) []byte {
+	mac := hmac.New(sha256.New, []byte(config.GetSecretKey()))
+	mac.Write([]byte(raw))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// RateLimiter takes one token for subject from the named policy's bucket
+type RateLimiter func(ctx context.Context, policy string, subject string) *models.RateLimitResult
+
+// RateLimitMiddleware limits a route group. "<group>" counts per user behind
+// AuthMiddleware and per address in front of it, "<group>.ip" always per
+// address and "<group>.write" only non-GET requests. Bots are their own
+// class and count against "bot.<group>" and "bot.<group>.write" instead.
+// Refused requests get a 429 with Retry-After in seconds.
+func RateLimitMiddleware(limit RateLimiter, group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := "ip:" + c.ClientIP()
+
+		policy := group
+		subject := ip
+		if v, ok := c.Get(CtxUserIDKey); ok {
+			if userID, ok := v.(uuid.UUID); ok {
+				subject = "user:" + userID.String()
+				if c.GetBool(CtxIsBotKey) {
+					policy = "bot." + group
+					subject = "bot:" + userID.String()
+				}
+			}
+		}
+
+		checks := [][2]string{
+			{policy, subject},
+			{group + ".ip", ip},
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			checks = append(checks, [2]string{policy + ".write", subject})
+		}
+
+		// stop at the first refusal, a request that is turned away must not
+		// spend tokens from the buckets after it
+		for _, check := range checks {
+			result := limit(c.Request.Context(), check[0], check[1])
+			if !result.Allowed {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				utils.WriteError(c, utils.ErrorRateLimited)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
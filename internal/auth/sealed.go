@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/suck-seed/yapp/config"
+)
+
+// first byte of every sealed value, bump it when the key or cipher changes
+const sealVersion byte = 1
+
+var ErrSealedValueInvalid = errors.New("auth: sealed value is corrupt or was sealed with another key")
+
+func sealKey() []byte {
+	if encoded := config.GetTokenEncryptionKey(); encoded != "" {
+		if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == 32 {
+			return key
+		}
+	}
+
+	sum := sha256.Sum256([]byte(config.GetSecretKey() + ":token-encryption"))
+	return sum[:]
+}
+
+func sealCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(sealKey())
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts secrets that have to be kept at rest, like third party OAuth
+// tokens. Layout: version | nonce | AES-256-GCM ciphertext.
+func Seal(plaintext []byte) ([]byte, error) {
+	aead, err := sealCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{sealVersion}, nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte{sealVersion}), nil
+}
+
+func Open(sealed []byte) ([]byte, error) {
+	aead, err := sealCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < 1+aead.NonceSize() || sealed[0] != sealVersion {
+		return nil, ErrSealedValueInvalid
+	}
+
+	nonce := sealed[1 : 1+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, sealed[1+aead.NonceSize():], []byte{sealVersion})
+	if err != nil {
+		return nil, ErrSealedValueInvalid
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+
+	rsaKeyBits = 3072
+)
+
+// SigningKey is one entry of the access token key ring. A key signs between
+// NotBefore and RetiresAt and is still accepted for verification until
+// ExpiresAt, so tokens it issued can run out naturally after a rotation.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+
+	NotBefore time.Time
+	RetiresAt *time.Time
+	ExpiresAt *time.Time
+}
+
+func (k *SigningKey) signsAt(t time.Time) bool {
+	return !t.Before(k.NotBefore) && (k.RetiresAt == nil || t.Before(*k.RetiresAt))
+}
+
+func (k *SigningKey) verifiesAt(t time.Time) bool {
+	return k.ExpiresAt == nil || t.Before(*k.ExpiresAt)
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+func IsSupportedSigningAlgorithm(alg string) bool {
+	return alg == AlgEdDSA || alg == AlgRS256
+}
+
+// NewSigningKeyID : date prefix keeps kids sortable when reading logs
+func NewSigningKeyID(now time.Time) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return now.UTC().Format("20060102") + "-" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateSigningKey returns a fresh private key in PKCS #8 form.
+func GenerateSigningKey(alg string) ([]byte, error) {
+	var private any
+	switch alg {
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("auth: unsupported signing algorithm %q", alg)
+	}
+
+	return x509.MarshalPKCS8PrivateKey(private)
+}
+
+// ParseSigningKey loads a PKCS #8 private key and checks it matches alg.
+func ParseSigningKey(id string, alg string, pkcs8 []byte) (*SigningKey, error) {
+	parsed, err := x509.ParsePKCS8PrivateKey(pkcs8)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: id, Algorithm: alg}
+	switch private := parsed.(type) {
+	case ed25519.PrivateKey:
+		if alg != AlgEdDSA {
+			return nil, fmt.Errorf("auth: key %s is ed25519 but marked %s", id, alg)
+		}
+		key.PrivateKey = private
+		key.PublicKey = private.Public()
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return nil, fmt.Errorf("auth: key %s is rsa but marked %s", id, alg)
+		}
+		key.PrivateKey = private
+		key.PublicKey = &private.PublicKey
+	default:
+		return nil, fmt.Errorf("auth: key %s has unsupported type %T", id, parsed)
+	}
+
+	return key, nil
+}
+
+// JSONWebKey is the public half of a signing key as published in the JWKS.
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func (k *SigningKey) JSONWebKey() JSONWebKey {
+	jwk := JSONWebKey{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+
+	switch public := k.PublicKey.(type) {
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	}
+
+	return jwk
+}
@@ -2,31 +2,67 @@
 package auth
 
 import (
+	"context"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/utils"
 )
 
-// WebSocketAuthMiddleware allows browser WebSocket auth using ?token=
-// while still reusing your normal AuthMiddleware logic.
-func WebSocketAuthMiddleware() gin.HandlerFunc {
-	baseAuth := AuthMiddleware()
+// WSTicketRedeemer burns a one-time ticket minted by POST /ws/tickets and
+// returns who it was issued to.
+type WSTicketRedeemer func(ctx context.Context, ticket string, origin string) (*UserInfo, error)
 
+// WebSocketAuthMiddleware accepts a bot token, a ?ticket= or the jwt cookie.
+// Access tokens in the query string are refused, they end up in proxy logs
+// and browser history.
+func WebSocketAuthMiddleware(redeem WSTicketRedeemer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token := strings.TrimSpace(c.Query("token"))
 
-		// Optional alias if you ever use ?access_token=
-		if token == "" {
-			token = strings.TrimSpace(c.Query("access_token"))
+		// bots aren't browsers, they can send the header on the upgrade
+		if authenticateBot(c) {
+			return
 		}
 
-		// Browser WebSocket cannot send Authorization header,
-		// so we convert ?token= into Authorization header
-		// before your existing AuthMiddleware runs.
-		if token != "" && c.GetHeader("Authorization") == "" {
-			c.Request.Header.Set("Authorization", "Bearer "+token)
+		if ticket := strings.TrimSpace(c.Query("ticket")); ticket != "" {
+			userInfo, err := redeem(c.Request.Context(), ticket, c.GetHeader("Origin"))
+			if err != nil {
+				utils.WriteError(c, err)
+				c.Abort()
+				return
+			}
+
+			setCurrentUser(c, userInfo)
+			c.Next()
+			return
+		}
+
+		token, err := c.Cookie("jwt")
+		if err != nil || token == "" {
+			utils.WriteError(c, utils.ErrorMissingToken)
+			c.Abort()
+			return
+		}
+
+		claims, err := ParseAndVerify(token)
+		if err != nil {
+			utils.WriteError(c, utils.ErrorInvalidToken)
+			c.Abort()
+			return
+		}
+
+		userID, err := uuid.Parse(claims.ID)
+		if err != nil {
+			utils.WriteError(c, utils.ErrorInvalidUserUUID)
+			c.Abort()
+			return
 		}
 
-		baseAuth(c)
+		setCurrentUser(c, &UserInfo{
+			ID:       userID,
+			Username: claims.Username,
+		})
+		c.Next()
 	}
 }
@@ -242,3 +242,10 @@ var ValidPermissionColumns = map[string]struct{}{
 	PermVoiceVideo:         {},
 	PermVoiceMuteMembers:   {},
 }
+
+// Mod2FAPermissions only take effect for members with 2FA enabled when the
+// hall turned on require_mod_2fa
+var Mod2FAPermissions = map[string]struct{}{
+	PermBanMembers:  {},
+	PermManageRoles: {},
+}
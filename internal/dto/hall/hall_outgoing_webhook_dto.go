@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// CreateOutgoingWebhookReq - Events must be known event types, see models.OutgoingEventTypes
+type CreateOutgoingWebhookReq struct {
+	URL    string   `json:"url"    binding:"required,url,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,required"`
+}
+
+// UpdateOutgoingWebhookReq - re-enabling a disabled webhook clears its failures
+type UpdateOutgoingWebhookReq struct {
+	URL     *string   `json:"url"     binding:"omitempty,url,max=2048"`
+	Events  *[]string `json:"events"  binding:"omitempty,min=1,dive,required"`
+	Enabled *bool     `json:"enabled"`
+}
+
+type OutgoingWebhookRes struct {
+	ID           uuid.UUID  `json:"id"`
+	HallID       uuid.UUID  `json:"hall_id"`
+	URL          string     `json:"url"`
+	Events       []string   `json:"events"`
+	Enabled      bool       `json:"enabled"`
+	FailureCount int        `json:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at"`
+	CreatedBy    *uuid.UUID `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type OutgoingWebhooksRes struct {
+	Webhooks []OutgoingWebhookRes `json:"webhooks"`
+}
+
+// OutgoingWebhookSecretRes is only returned on creation and rotation
+type OutgoingWebhookSecretRes struct {
+	Webhook OutgoingWebhookRes `json:"webhook"`
+	Secret  string             `json:"secret"`
+}
+
+type OutgoingDeliveryRes struct {
+	ID             uuid.UUID                     `json:"id"`
+	EventType      string                        `json:"event_type"`
+	Status         models.OutgoingDeliveryStatus `json:"status"`
+	Attempts       int                           `json:"attempts"`
+	NextAttemptAt  *time.Time                    `json:"next_attempt_at"`
+	LastStatusCode *int                          `json:"last_status_code"`
+	LastError      *string                       `json:"last_error"`
+	DeliveredAt    *time.Time                    `json:"delivered_at"`
+	Payload        json.RawMessage               `json:"payload"`
+	CreatedAt      time.Time                     `json:"created_at"`
+}
+
+type OutgoingDeliveriesRes struct {
+	Deliveries []OutgoingDeliveryRes `json:"deliveries"`
+}
+
+// OutgoingEvent is the body POSTed to receivers, Data depends on Type
+type OutgoingEvent struct {
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	HallID     uuid.UUID `json:"hall_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// OutgoingMemberData is Data for member.* events
+type OutgoingMemberData struct {
+	UserID   uuid.UUID  `json:"user_id"`
+	MemberID *uuid.UUID `json:"member_id,omitempty"`
+	Kicked   bool       `json:"kicked,omitempty"`
+}
+
+// OutgoingRoleData is Data for role.changed, UserID is set when a member's
+// role changed and empty when the role's permissions did
+type OutgoingRoleData struct {
+	RoleID uuid.UUID  `json:"role_id"`
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+}
+
+// OutgoingRoomData is Data for room.created
+type OutgoingRoomData struct {
+	RoomID    uuid.UUID  `json:"room_id"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	IsPrivate bool       `json:"is_private"`
+}
+
+func ToOutgoingWebhookRes(w *models.OutgoingWebhook) OutgoingWebhookRes {
+	return OutgoingWebhookRes{
+		ID:           w.ID,
+		HallID:       w.HallID,
+		URL:          w.URL,
+		Events:       w.Events,
+		Enabled:      w.Enabled,
+		FailureCount: w.FailureCount,
+		DisabledAt:   w.DisabledAt,
+		CreatedBy:    w.CreatedBy,
+		CreatedAt:    w.CreatedAt,
+		UpdatedAt:    w.UpdatedAt,
+	}
+}
+
+func ToOutgoingDeliveryRes(d *models.OutgoingWebhookDelivery) OutgoingDeliveryRes {
+	res := OutgoingDeliveryRes{
+		ID:             d.ID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		Payload:        d.Payload,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == models.OutgoingDeliveryPending {
+		res.NextAttemptAt = &d.NextAttemptAt
+	}
+	return res
+}
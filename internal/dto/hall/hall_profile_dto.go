@@ -57,3 +57,12 @@ type GetCurrentHallRes struct {
 	UpdatedAt        time.Time `json:"updated_at"`
 	OwnerID          uuid.UUID `json:"owner_id"`
 }
+
+type HallSecuritySettingsRes struct {
+	HallID        uuid.UUID `json:"hall_id"`
+	RequireMod2FA bool      `json:"require_mod_2fa"`
+}
+
+type UpdateHallSecuritySettingsReq struct {
+	RequireMod2FA *bool `json:"require_mod_2fa" binding:"required"`
+}
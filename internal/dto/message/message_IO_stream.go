@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	notificationDto "github.com/suck-seed/yapp/internal/dto/notification"
 	"github.com/suck-seed/yapp/internal/models"
 )
 
@@ -19,6 +20,8 @@ const (
 	MessageTypeEdit       MessageType = "edit"
 	MessageTypeDelete     MessageType = "delete"
 	MessageTypeReact      MessageType = "react"
+	MessageTypePin        MessageType = "pin"
+	MessageTypeUnpin      MessageType = "unpin"
 
 	MessageTypePresence MessageType = "presence"
 
@@ -28,6 +31,14 @@ const (
 	// Server confirms subscriptions were refreshed.
 	MessageTypeSubscriptionsSynced MessageType = "subscriptions_synced"
 
+	// Server pushes a new inbox item to the user it belongs to.
+	MessageTypeNotification MessageType = "notification"
+
+	// Slash commands: command frames go to the bot owning the command,
+	// command_response frames only to the tab that invoked it
+	MessageTypeCommand         MessageType = "command"
+	MessageTypeCommandResponse MessageType = "command_response"
+
 	// System messages (sent by server only)
 	MessageTypeJoin          MessageType = "join"
 	MessageTypeLeave         MessageType = "leave"
@@ -53,6 +64,7 @@ type InboundMessage struct {
 	// Server-owned fields. Never accept these from frontend.
 	UserID   uuid.UUID `json:"-"`
 	ClientID uuid.UUID `json:"-"`
+	IsBot    bool      `json:"-"`
 }
 
 type OutboundMessage struct {
@@ -73,11 +85,19 @@ type OutboundMessage struct {
 	Mentions         []UserBasic         `json:"mentions"`
 	Attachments      []models.Attachment `json:"attachments"`
 
+	// Webhook messages, see models.Message
+	WebhookID       *uuid.UUID `json:"webhook_id,omitempty"`
+	AuthorName      *string    `json:"author_name,omitempty"`
+	AuthorAvatarURL *string    `json:"author_avatar_url,omitempty"`
+
 	EditedAt  *time.Time `json:"edited_at,omitempty"`  // opt
 	DeletedAt *time.Time `json:"deleted_at,omitempty"` // opt
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 
+	// Edit frames, how many revisions the message has now
+	RevisionCount int `json:"revision_count,omitempty"`
+
 	// Typing
 	TypingUser *uuid.UUID `json:"typing_user,omitempty"` // opt
 
@@ -86,6 +106,10 @@ type OutboundMessage struct {
 	ReadBy    *uuid.UUID `json:"read_by,omitempty"`    // opt
 	ReadAt    *time.Time `json:"read_at,omitempty"`    // opt
 
+	// Pin and unpin, who did it and when the pin was made
+	PinnedBy *uuid.UUID `json:"pinned_by,omitempty"`
+	PinnedAt *time.Time `json:"pinned_at,omitempty"`
+
 	// Pressence
 	PresenceUserID *uuid.UUID `json:"presence_user_id,omitempty"`
 	PresenceStatus *string    `json:"presence_status,omitempty"`
@@ -93,6 +117,10 @@ type OutboundMessage struct {
 
 	Error *string `json:"error,omitempty"` // opt
 
+	// Set on errors a client can react to, e.g. "rate_limited"
+	ErrorCode    *string `json:"error_code,omitempty"`
+	RetryAfterMs *int64  `json:"retry_after_ms,omitempty"`
+
 	// New subscription sync response
 	SubscribedRoomCount *int                 `json:"subscribed_room_count,omitempty"`
 	SubscribedRooms     []SubscribedRoomInfo `json:"subscribed_rooms,omitempty"`
@@ -106,6 +134,13 @@ type OutboundMessage struct {
 	MutedUntil        *time.Time `json:"muted_until,omitempty"`
 	PermanentlyMuted  *bool      `json:"permanently_muted,omitempty"`
 	ModerationAction  *string    `json:"moderation_action,omitempty"`
+
+	// Notification inbox
+	Notification *notificationDto.NotificationRes `json:"notification,omitempty"`
+
+	// Slash commands
+	Command     *string            `json:"command,omitempty"`
+	Interaction *CommandInvocation `json:"interaction,omitempty"`
 }
 
 type SubscribedRoomInfo struct {
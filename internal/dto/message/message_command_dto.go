@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// CommandSpec describes one slash command for autocompletion, BotID is empty
+// for the built-in ones
+type CommandSpec struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Usage       string                 `json:"usage"`
+	Options     []models.CommandOption `json:"options"`
+	BotID       *uuid.UUID             `json:"bot_id,omitempty"`
+}
+
+type CommandsRes struct {
+	Commands []CommandSpec `json:"commands"`
+}
+
+type CommandOptionReq struct {
+	Name        string                   `json:"name"        binding:"required,min=1,max=32"`
+	Description string                   `json:"description" binding:"omitempty,max=100"`
+	Type        models.CommandOptionType `json:"type"        binding:"required"`
+	Required    bool                     `json:"required"`
+	Choices     []string                 `json:"choices"     binding:"omitempty,max=25,dive,min=1,max=100"`
+}
+
+type BotCommandReq struct {
+	Name        string             `json:"name"        binding:"required,min=1,max=32"`
+	Description string             `json:"description" binding:"required,min=1,max=100"`
+	Options     []CommandOptionReq `json:"options"     binding:"omitempty,max=10,dive"`
+}
+
+// RegisterBotCommandsReq replaces every command the bot has in the hall, an
+// empty list removes them all
+type RegisterBotCommandsReq struct {
+	Commands []BotCommandReq `json:"commands" binding:"max=50,dive"`
+}
+
+// CommandReplyReq answers a bot command. Ephemeral replies only reach the tab
+// that invoked it, the others are posted to the room as the bot.
+type CommandReplyReq struct {
+	Content   string `json:"content"   binding:"required,min=1,max=8000"`
+	Ephemeral bool   `json:"ephemeral"`
+}
+
+// CommandResult is what a command answers its invoker with, nil when it
+// already did something everyone sees
+type CommandResult struct {
+	Command string `json:"command"`
+	Content string `json:"content"`
+}
+
+// CommandInvocation is sent to a bot on a command frame. Users arrive as IDs
+// and durations in seconds.
+type CommandInvocation struct {
+	ID        uuid.UUID      `json:"id"`
+	Name      string         `json:"name"`
+	HallID    uuid.UUID      `json:"hall_id"`
+	RoomID    uuid.UUID      `json:"room_id"`
+	InvokerID uuid.UUID      `json:"invoker_id"`
+	Args      map[string]any `json:"args"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func ToCommandSpec(command *models.BotCommand, usage string) CommandSpec {
+	return CommandSpec{
+		Name:        command.Name,
+		Description: command.Description,
+		Usage:       usage,
+		Options:     command.Options,
+		BotID:       &command.BotID,
+	}
+}
+
+func ToCommandInvocation(interaction *models.CommandInteraction) *CommandInvocation {
+	return &CommandInvocation{
+		ID:        interaction.ID,
+		Name:      interaction.Name,
+		HallID:    interaction.HallID,
+		RoomID:    interaction.RoomID,
+		InvokerID: interaction.InvokerID,
+		Args:      interaction.Args,
+		CreatedAt: interaction.CreatedAt,
+	}
+}
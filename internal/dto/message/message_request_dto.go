@@ -15,6 +15,21 @@ type CreateMessageReq struct {
 
 	MentionEveryone *bool        `json:"mention_everyone" binding:"omitempty"`
 	Mentions        *[]uuid.UUID `json:"mentions" binding:"omitempty"`
+
+	// Webhook is set by the incoming webhook service, never bound from a body
+	Webhook *WebhookAuthor `json:"-"`
+
+	// FromSocket is set by the hub, which delivers the saved message to the
+	// room itself
+	FromSocket bool `json:"-"`
+}
+
+// WebhookAuthor posts as AuthorID without being a member of the room, the
+// webhook's secret already authorized it
+type WebhookAuthor struct {
+	WebhookID uuid.UUID
+	Name      *string
+	AvatarURL *string
 }
 
 type AttachmentReq struct {
@@ -17,12 +17,48 @@ type CreateMessageRes struct {
 	Mentions         []UserBasic         `json:"mentions"`
 	Attachments      []models.Attachment `json:"attachments"`
 
+	WebhookID       *uuid.UUID `json:"webhook_id,omitempty"`
+	AuthorName      *string    `json:"author_name,omitempty"`
+	AuthorAvatarURL *string    `json:"author_avatar_url,omitempty"`
+
 	EditedAt  *time.Time `json:"edited_at"`
 	DeletedAt *time.Time `json:"deleted_at"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 }
 
+// clients have always received an empty error on text frames
+var noError = ""
+
+// ToOutbound is the frame every client subscribed to the room gets for it
+func (m *CreateMessageRes) ToOutbound(hallID uuid.UUID) *OutboundMessage {
+	return &OutboundMessage{
+		Type: MessageTypeText,
+
+		ID:       m.ID,
+		RoomID:   m.RoomID,
+		HallID:   hallID,
+		AuthorID: m.AuthorID,
+		Content:  m.Content,
+		SentAt:   m.SentAt,
+
+		CreatedAt: m.CreatedAt,
+		EditedAt:  m.EditedAt,
+		DeletedAt: m.DeletedAt,
+		UpdatedAt: m.UpdatedAt,
+
+		MentionsEveryone: m.MentionsEveryone,
+		Mentions:         m.Mentions,
+		Attachments:      m.Attachments,
+
+		WebhookID:       m.WebhookID,
+		AuthorName:      m.AuthorName,
+		AuthorAvatarURL: m.AuthorAvatarURL,
+
+		Error: &noError,
+	}
+}
+
 type AttachmentResponseMinimal struct {
 	ID        uuid.UUID `json:"id"`
 	MessageID uuid.UUID `json:"message_id"`
@@ -63,6 +99,43 @@ type MessageDetailed struct {
 	Attachments []AttachmentResponseMinimal `json:"attachments"`
 	Reactions   []ReactionGroup             `json:"reactions"`
 	Mentions    []UserBasic                 `json:"mentions"`
+
+	// RevisionCount is how many times it was edited, see GetMessageHistory
+	RevisionCount int `json:"revision_count"`
+}
+
+// ToEditOutbound is the frame broadcast after the author edited the message,
+// attachments can't change so they're left out
+func (m *MessageDetailed) ToEditOutbound(hallID uuid.UUID) *OutboundMessage {
+	return &OutboundMessage{
+		Type: MessageTypeEdit,
+
+		ID:       m.ID,
+		RoomID:   m.RoomID,
+		HallID:   hallID,
+		AuthorID: m.AuthorID,
+		Content:  m.Content,
+		SentAt:   m.SentAt,
+
+		CreatedAt:     m.CreatedAt,
+		EditedAt:      m.EditedAt,
+		UpdatedAt:     m.UpdatedAt,
+		RevisionCount: m.RevisionCount,
+
+		MentionsEveryone: m.MentionEveryone,
+		Mentions:         m.Mentions,
+
+		WebhookID:       m.WebhookID,
+		AuthorName:      m.AuthorName,
+		AuthorAvatarURL: m.AuthorAvatarURL,
+	}
+}
+
+// PinnedMessageRes is one entry of a room's pinned list
+type PinnedMessageRes struct {
+	Message  *MessageDetailed `json:"message"`
+	PinnedBy *uuid.UUID       `json:"pinned_by,omitempty"`
+	PinnedAt time.Time        `json:"pinned_at"`
 }
 
 type MessageListResponse struct {
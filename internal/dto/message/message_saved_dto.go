@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// REQUESTS
+
+type SaveMessageReq struct {
+	MessageID uuid.UUID  `json:"message_id" binding:"required"`
+	Note      *string    `json:"note" binding:"omitempty,max=500"`
+	RemindAt  *time.Time `json:"remind_at"`
+}
+
+// UpdateSavedMessageReq leaves out what isn't sent. An empty note removes
+// it, ClearReminder drops the reminder.
+type UpdateSavedMessageReq struct {
+	Note          *string    `json:"note" binding:"omitempty,max=500"`
+	RemindAt      *time.Time `json:"remind_at"`
+	ClearReminder bool       `json:"clear_reminder"`
+}
+
+type ListSavedMessagesQuery struct {
+	Limit  int
+	Before *uuid.UUID
+}
+
+// RESPONSES
+
+type SavedMessageRes struct {
+	ID         uuid.UUID  `json:"id"`
+	HallID     uuid.UUID  `json:"hall_id"`
+	RoomID     uuid.UUID  `json:"room_id"`
+	Note       *string    `json:"note,omitempty"`
+	RemindAt   *time.Time `json:"remind_at,omitempty"`
+	RemindedAt *time.Time `json:"reminded_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Message *MessageDetailed `json:"message"`
+}
+
+type SavedMessageListRes struct {
+	SavedMessages []*SavedMessageRes `json:"saved_messages"`
+	HasMore       bool               `json:"has_more"`
+}
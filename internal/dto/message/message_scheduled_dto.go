@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// REQUESTS
+
+type ScheduleMessageReq struct {
+	RoomID      uuid.UUID        `json:"room_id" binding:"required"`
+	Content     *string          `json:"content" binding:"omitempty,min=1,max=8000"`
+	Attachments *[]AttachmentReq `json:"attachments" binding:"omitempty,max=10"`
+	SendAt      time.Time        `json:"send_at" binding:"required"`
+
+	MentionEveryone *bool        `json:"mention_everyone" binding:"omitempty"`
+	Mentions        *[]uuid.UUID `json:"mentions" binding:"omitempty,max=50"`
+}
+
+// UpdateScheduledMessageReq leaves out what isn't sent. A failed message
+// goes back in the queue when it gets a new send_at.
+type UpdateScheduledMessageReq struct {
+	Content     *string          `json:"content" binding:"omitempty,max=8000"`
+	Attachments *[]AttachmentReq `json:"attachments" binding:"omitempty,max=10"`
+	SendAt      *time.Time       `json:"send_at"`
+
+	MentionEveryone *bool        `json:"mention_everyone" binding:"omitempty"`
+	Mentions        *[]uuid.UUID `json:"mentions" binding:"omitempty,max=50"`
+}
+
+type ListScheduledMessagesQuery struct {
+	RoomID *uuid.UUID
+}
+
+// RESPONSES
+
+type ScheduledMessageRes struct {
+	ID              uuid.UUID                     `json:"id"`
+	RoomID          uuid.UUID                     `json:"room_id"`
+	Content         *string                       `json:"content,omitempty"`
+	Attachments     []AttachmentReq               `json:"attachments"`
+	MentionEveryone bool                          `json:"mention_everyone"`
+	Mentions        []uuid.UUID                   `json:"mentions"`
+	SendAt          time.Time                     `json:"send_at"`
+	Status          models.ScheduledMessageStatus `json:"status"`
+	Failure         *string                       `json:"failure,omitempty"`
+	CreatedAt       time.Time                     `json:"created_at"`
+	UpdatedAt       time.Time                     `json:"updated_at"`
+}
+
+func ToScheduledMessageRes(m *models.ScheduledMessage) *ScheduledMessageRes {
+	attachments := make([]AttachmentReq, 0, len(m.Attachments))
+	for _, a := range m.Attachments {
+		attachments = append(attachments, AttachmentReq{
+			FileName: a.FileName,
+			URL:      a.URL,
+			FileType: a.FileType,
+			FileSize: a.FileSize,
+		})
+	}
+
+	mentions := m.Mentions
+	if mentions == nil {
+		mentions = []uuid.UUID{}
+	}
+
+	return &ScheduledMessageRes{
+		ID:              m.ID,
+		RoomID:          m.RoomID,
+		Content:         m.Content,
+		Attachments:     attachments,
+		MentionEveryone: m.MentionEveryone,
+		Mentions:        mentions,
+		SendAt:          m.SendAt,
+		Status:          m.Status,
+		Failure:         m.Failure,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}
+}
+
+type ScheduledMessageListRes struct {
+	ScheduledMessages []*ScheduledMessageRes `json:"scheduled_messages"`
+}
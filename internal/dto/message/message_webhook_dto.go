@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type CreateWebhookReq struct {
+	Name      string  `json:"name"       binding:"required"`
+	AvatarURL *string `json:"avatar_url" binding:"omitempty,url,max=2048"`
+}
+
+// ExecuteWebhookReq is what outside systems post to a webhook's URL. Username
+// and AvatarURL replace the webhook's own for this one message.
+type ExecuteWebhookReq struct {
+	Content     *string          `json:"content"     binding:"omitempty,min=1,max=8000"`
+	Username    *string          `json:"username"    binding:"omitempty"`
+	AvatarURL   *string          `json:"avatar_url"  binding:"omitempty,url,max=2048"`
+	Attachments *[]AttachmentReq `json:"attachments" binding:"omitempty"`
+}
+
+type WebhookRes struct {
+	ID         uuid.UUID  `json:"id"`
+	RoomID     uuid.UUID  `json:"room_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	AvatarURL  *string    `json:"avatar_url"`
+	CreatedBy  *uuid.UUID `json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type WebhooksRes struct {
+	Webhooks []WebhookRes `json:"webhooks"`
+}
+
+// WebhookURLRes is only returned on creation and rotation, URL is relative to
+// the API's origin and carries the secret
+type WebhookURLRes struct {
+	Webhook WebhookRes `json:"webhook"`
+	Token   string     `json:"token"`
+	URL     string     `json:"url"`
+}
+
+func ToWebhookRes(w *models.Webhook) WebhookRes {
+	return WebhookRes{
+		ID:         w.ID,
+		RoomID:     w.RoomID,
+		UserID:     w.UserID,
+		Name:       w.Name,
+		AvatarURL:  w.AvatarURL,
+		CreatedBy:  w.CreatedBy,
+		LastUsedAt: w.LastUsedAt,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+}
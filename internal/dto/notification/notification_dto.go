@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// REQUESTS
+
+type ListNotificationsQuery struct {
+	Limit      int        `form:"limit"`
+	Before     *uuid.UUID `form:"before" binding:"omitempty"`
+	UnreadOnly bool       `form:"unread_only"`
+}
+
+// RESPONSES
+
+type NotificationActor struct {
+	ID          uuid.UUID `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   *string   `json:"avatar_url"`
+}
+
+type NotificationRes struct {
+	ID     uuid.UUID               `json:"id"`
+	UserID uuid.UUID               `json:"user_id"`
+	Type   models.NotificationType `json:"type"`
+	Actor  *NotificationActor      `json:"actor,omitempty"`
+
+	HallID      *uuid.UUID `json:"hall_id,omitempty"`
+	RoomID      *uuid.UUID `json:"room_id,omitempty"`
+	MessageID   *uuid.UUID `json:"message_id,omitempty"`
+	ReferenceID *uuid.UUID `json:"reference_id,omitempty"`
+	Preview     *string    `json:"preview,omitempty"`
+
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationListRes struct {
+	Notifications []*NotificationRes `json:"notifications"`
+	UnreadCount   int                `json:"unread_count"`
+	HasMore       bool               `json:"has_more"`
+}
+
+type UnreadCountRes struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+type MarkAllReadRes struct {
+	Updated int64 `json:"updated"`
+}
+
+// ── NOTIFICATION SETTINGS ─────────────────────────────────────────────────────
+
+// UpsertNotificationSettingReq replaces the whole override for one scope,
+// omitted fields go back to inheriting from the enclosing scope.
+type UpsertNotificationSettingReq struct {
+	Level            *models.NotificationLevel `json:"level" binding:"omitempty,oneof=all mentions nothing"`
+	SuppressEveryone *bool                     `json:"suppress_everyone"`
+	MutedUntil       *time.Time                `json:"muted_until"`
+}
+
+type EffectiveNotificationSettingRes struct {
+	RoomID  uuid.UUID  `json:"room_id"`
+	HallID  uuid.UUID  `json:"hall_id"`
+	FloorID *uuid.UUID `json:"floor_id,omitempty"`
+
+	*models.EffectiveNotificationSetting
+}
+
+// RoomUnreadCount is the raw per-room tally before any settings apply.
+type RoomUnreadCount struct {
+	RoomID        uuid.UUID
+	HallID        uuid.UUID
+	FloorID       *uuid.UUID
+	UnreadCount   int
+	MentionCount  int
+	EveryoneCount int
+}
+
+type RoomBadgeRes struct {
+	RoomID       uuid.UUID `json:"room_id"`
+	HallID       uuid.UUID `json:"hall_id"`
+	UnreadCount  int       `json:"unread_count"`
+	MentionCount int       `json:"mention_count"`
+	Muted        bool      `json:"muted"`
+}
+
+type HallBadgeRes struct {
+	HallID       uuid.UUID `json:"hall_id"`
+	HasUnread    bool      `json:"has_unread"`
+	MentionCount int       `json:"mention_count"`
+}
+
+type UnreadBadgesRes struct {
+	Rooms []*RoomBadgeRes `json:"rooms"`
+	Halls []*HallBadgeRes `json:"halls"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// REQUESTS
+
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh" binding:"required"`
+	Auth   string `json:"auth" binding:"required"`
+}
+
+// RegisterPushSubscriptionReq mirrors PushSubscription.toJSON() from the browser
+type RegisterPushSubscriptionReq struct {
+	Endpoint string               `json:"endpoint" binding:"required,url"`
+	Keys     PushSubscriptionKeys `json:"keys" binding:"required"`
+
+	// epoch milliseconds, as reported by the browser (usually null)
+	ExpirationTime *int64  `json:"expiration_time" binding:"omitempty"`
+	DeviceName     *string `json:"device_name" binding:"omitempty,max=64"`
+}
+
+// RESPONSES
+
+type PushSubscriptionRes struct {
+	ID            uuid.UUID  `json:"id"`
+	Endpoint      string     `json:"endpoint"`
+	DeviceName    *string    `json:"device_name"`
+	UserAgent     *string    `json:"user_agent"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type VAPIDPublicKeyRes struct {
+	PublicKey string `json:"public_key"`
+}
+
+// Payload is what the service worker receives in its `push` event
+type PushPayload struct {
+	Type           string     `json:"type"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body,omitempty"`
+	NotificationID uuid.UUID  `json:"notification_id"`
+	HallID         *uuid.UUID `json:"hall_id,omitempty"`
+	RoomID         *uuid.UUID `json:"room_id,omitempty"`
+	MessageID      *uuid.UUID `json:"message_id,omitempty"`
+}
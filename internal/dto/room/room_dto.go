@@ -16,6 +16,7 @@ type RoomRes struct {
 	Position             float64    `json:"position"`
 	IsPrivate            bool       `json:"is_private"`
 	SyncWithFloorMembers bool       `json:"sync_with_floor_members"`
+	SlowmodeSeconds      int        `json:"slowmode_seconds"`
 	CreatedAt            time.Time  `json:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at"`
 }
@@ -53,6 +54,9 @@ type GetHallRoomsRes struct {
 type UpdateRoomReq struct {
 	Name      *string `json:"name"       binding:"omitempty,min=1,max=64"`
 	IsPrivate *bool   `json:"is_private" binding:"omitempty"`
+
+	// 0 turns slow mode off, at most 6 hours
+	SlowmodeSeconds *int `json:"slowmode_seconds" binding:"omitempty,min=0,max=21600"`
 }
 
 // ── The only drag-drop endpoint you need ──────────────────────────────────────
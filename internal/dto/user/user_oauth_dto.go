@@ -0,0 +1,163 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// ── APPS ──────────────────────────────────────────────────────────────────────
+
+// CreateOAuthAppReq : confidential apps get a client secret, public ones
+// (native and single page apps) have to use PKCE instead
+type CreateOAuthAppReq struct {
+	Name         string   `json:"name" binding:"required,min=1,max=64"`
+	Description  *string  `json:"description" binding:"omitempty,max=512"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,max=10,dive,required,max=2048"`
+	Confidential bool     `json:"confidential"`
+}
+
+type UpdateOAuthAppReq struct {
+	Name         *string   `json:"name" binding:"omitempty,min=1,max=64"`
+	Description  *string   `json:"description" binding:"omitempty,max=512"`
+	RedirectURIs *[]string `json:"redirect_uris" binding:"omitempty,min=1,max=10,dive,required,max=2048"`
+}
+
+type OAuthAppRes struct {
+	ClientID     uuid.UUID `json:"client_id"`
+	Name         string    `json:"name"`
+	Description  *string   `json:"description"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type OAuthAppsRes struct {
+	Apps []OAuthAppRes `json:"apps"`
+}
+
+// OAuthAppSecretRes is the only time a client secret is shown
+type OAuthAppSecretRes struct {
+	OAuthAppRes
+	ClientSecret *string `json:"client_secret,omitempty"`
+}
+
+func ToOAuthAppRes(app models.OAuthApp) OAuthAppRes {
+	return OAuthAppRes{
+		ClientID:     app.ID,
+		Name:         app.Name,
+		Description:  app.Description,
+		RedirectURIs: app.RedirectURIs,
+		Confidential: app.IsConfidential(),
+		CreatedAt:    app.CreatedAt,
+		UpdatedAt:    app.UpdatedAt,
+	}
+}
+
+// ── CONSENT ───────────────────────────────────────────────────────────────────
+
+// OAuthAuthorizeReq carries the app's authorization request as it arrived on
+// the consent page
+type OAuthAuthorizeReq struct {
+	ResponseType        string    `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            uuid.UUID `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string    `form:"redirect_uri" json:"redirect_uri" binding:"required,max=2048"`
+	Scope               string    `form:"scope" json:"scope" binding:"required,max=1024"`
+	State               string    `form:"state" json:"state" binding:"omitempty,max=1024"`
+	CodeChallenge       string    `form:"code_challenge" json:"code_challenge" binding:"omitempty,max=128"`
+	CodeChallengeMethod string    `form:"code_challenge_method" json:"code_challenge_method" binding:"omitempty,max=16"`
+}
+
+// OAuthDecisionReq is the user's answer on the consent page
+type OAuthDecisionReq struct {
+	OAuthAuthorizeReq
+	Approve bool `json:"approve"`
+}
+
+type OAuthConsentAppRes struct {
+	ClientID      uuid.UUID `json:"client_id"`
+	Name          string    `json:"name"`
+	Description   *string   `json:"description"`
+	OwnerUsername string    `json:"owner_username"`
+}
+
+// OAuthConsentRes is what the consent page shows, AlreadyAuthorized when the
+// user granted every requested scope before
+type OAuthConsentRes struct {
+	App               OAuthConsentAppRes `json:"app"`
+	Scopes            []string           `json:"scopes"`
+	RedirectURI       string             `json:"redirect_uri"`
+	AlreadyAuthorized bool               `json:"already_authorized"`
+}
+
+// OAuthRedirectRes is where the browser goes next, back to the app with a
+// code or with error=access_denied
+type OAuthRedirectRes struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// ── AUTHORIZATIONS ────────────────────────────────────────────────────────────
+
+type OAuthAuthorizationRes struct {
+	ClientID     uuid.UUID  `json:"client_id"`
+	AppName      string     `json:"app_name"`
+	Scopes       []string   `json:"scopes"`
+	AuthorizedAt time.Time  `json:"authorized_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+}
+
+type OAuthAuthorizationsRes struct {
+	Authorizations []OAuthAuthorizationRes `json:"authorizations"`
+}
+
+func ToOAuthAuthorizationRes(a models.OAuthAuthorization) OAuthAuthorizationRes {
+	return OAuthAuthorizationRes{
+		ClientID:     a.AppID,
+		AppName:      a.AppName,
+		Scopes:       a.Scopes,
+		AuthorizedAt: a.AuthorizedAt,
+		LastUsedAt:   a.LastUsedAt,
+	}
+}
+
+// ── PROTOCOL ──────────────────────────────────────────────────────────────────
+
+// OAuthClientCredentials come from HTTP Basic or the form, handlers fill
+// them in, they are never bound from a body
+type OAuthClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthTokenReq is the application/x-www-form-urlencoded token request
+type OAuthTokenReq struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+}
+
+type OAuthTokenRes struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// OAuthIntrospectionRes follows RFC 7662, only Active is set for tokens that
+// are unknown, expired or belong to another client
+type OAuthIntrospectionRes struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+}
@@ -35,6 +35,34 @@ type UpdateEmailReq struct {
 	NewEmail string `json:"new_email" binding:"required,email"`
 }
 
+type VerifyEmailReq struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordReq struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type ConfirmTOTPReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorCodeReq takes either an authenticator code or one of the recovery codes
+type TwoFactorCodeReq struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type SigninSecondFactorReq struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	TwoFactorCodeReq
+}
+
 type SendFriendRequestReq struct {
 	ReceiverID uuid.UUID `json:"receiver_id" binding:"required"`
 }
@@ -43,8 +71,57 @@ type RespondFriendRequestReq struct {
 	Action string `json:"action" binding:"required,oneof=accept decline"`
 }
 
-type UpsertAppLinkReq struct {
-	Provider models.AppProvider `json:"provider" binding:"required,oneof=spotify reddit twitter steam"`
-	URL      string             `json:"url" binding:"required,url"`
-	Show     bool               `json:"show_on_profile"`
+type BeginAppLinkReq struct {
+	ShowOnProfile *bool `json:"show_on_profile"`
+}
+
+// CompleteAppLinkReq carries every query parameter the provider appended to
+// the redirect URL, which ones matter depends on the provider
+type CompleteAppLinkReq struct {
+	Params map[string]string `json:"params" binding:"required"`
+}
+
+type UpdateAppLinkReq struct {
+	ShowOnProfile *bool `json:"show_on_profile" binding:"required"`
+}
+
+// OIDCCallbackReq is what the provider appended to the redirect URL
+type OIDCCallbackReq struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type OIDCSignupReq struct {
+	SignupToken string  `json:"signup_token" binding:"required"`
+	Username    string  `json:"username" binding:"required"`
+	DisplayName *string `json:"display_name"`
+}
+
+type CreateBotReq struct {
+	Username    string  `json:"username" binding:"required"`
+	DisplayName string  `json:"display_name" binding:"required"`
+	Description *string `json:"description" binding:"omitempty,max=512"`
+}
+
+// AddBotToHallReq : RoleID falls back to the hall's default role
+type AddBotToHallReq struct {
+	BotID  uuid.UUID  `json:"bot_id" binding:"required"`
+	RoleID *uuid.UUID `json:"role_id" binding:"omitempty"`
+}
+
+// CreatePersonalTokenReq : the token never expires without ExpiresInDays
+type CreatePersonalTokenReq struct {
+	Name          string   `json:"name" binding:"required,min=1,max=64"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,max=12,dive,required"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// ClientInfo describes where a sign-in comes from. Handlers fill it in from
+// the request, it is never bound from a body.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+
+	// long lived random id from the device cookie
+	DeviceID string
 }
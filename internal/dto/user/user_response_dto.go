@@ -19,6 +19,28 @@ type SigninUserRes struct {
 	AccessToken string `json:"-"`
 	UserMe
 	Success bool `json:"success"`
+
+	// set instead of AccessToken when the account has 2FA, the client
+	// finishes with POST /auth/signin/2fa
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+}
+
+type TwoFactorStatusRes struct {
+	Enabled                bool `json:"enabled"`
+	PendingEnrollment      bool `json:"pending_enrollment"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type TOTPEnrollmentRes struct {
+	Secret string `json:"secret"`
+	// otpauth:// URI, render it as a QR code for authenticator apps
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesRes is the only time the plain codes are ever shown
+type RecoveryCodesRes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type UserPublic struct {
@@ -32,6 +54,7 @@ type UserPublic struct {
 	FriendCount        int       `json:"friend_count"`
 	MutualFriendCount  int       `json:"mutual_friend_count,omitempty"`
 	IsFriend           bool      `json:"is_friend"`
+	IsBot              bool      `json:"is_bot"`
 }
 
 type UserMe struct {
@@ -39,11 +62,13 @@ type UserMe struct {
 	Username           string    `json:"username"`
 	DisplayName        string    `json:"display_name"`
 	Email              string    `json:"email"`
+	EmailVerified      bool      `json:"email_verified"`
 	PhoneNumber        *string   `json:"phone_number"`
 	AvatarURL          *string   `json:"avatar_url"`
 	AvatarThumbnailURL *string   `json:"avatar_thumbnail_url"`
 	Description        *string   `json:"description"`
 	FriendPolicy       string    `json:"friend_policy"`
+	IsBot              bool      `json:"is_bot"`
 	AppLinks           []AppLink `json:"app_links"`
 	CreatedAt          string    `json:"created_at"`
 	UpdatedAt          string    `json:"updated_at"`
@@ -53,6 +78,8 @@ type AppLink struct {
 	Provider models.AppProvider `json:"provider"`
 	URL      string             `json:"url"`
 	Show     bool               `json:"show_on_profile"`
+	Handle   *string            `json:"handle,omitempty"`
+	Verified bool               `json:"verified"`
 }
 
 type FriendRequestRes struct {
@@ -72,16 +99,28 @@ type FriendListRes struct {
 	Total int           `json:"total"`
 }
 
-type UpsertAppLinkRes struct {
+type AppLinkRes struct {
 	ID            uuid.UUID          `json:"id"`
 	UserID        uuid.UUID          `json:"user_id"`
 	Provider      models.AppProvider `json:"provider"`
 	URL           string             `json:"url"`
 	ShowOnProfile bool               `json:"show_on_profile"`
+	Handle        *string            `json:"handle,omitempty"`
+	Verified      bool               `json:"verified"`
+	VerifiedAt    *time.Time         `json:"verified_at,omitempty"`
 	CreatedAt     time.Time          `json:"created_at"`
 	UpdatedAt     time.Time          `json:"updated_at"`
 }
 
+type AppLinkProviderRes struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type AppLinkAuthorizationRes struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
 func ToUserPublic(u models.User) UserPublic {
 	return UserPublic{
 		ID:                 u.ID,
@@ -93,6 +132,7 @@ func ToUserPublic(u models.User) UserPublic {
 		AppLinks:           []AppLink{},
 		FriendCount:        0,
 		IsFriend:           false,
+		IsBot:              u.IsBot,
 	}
 }
 
@@ -102,13 +142,146 @@ func ToUserMe(u models.User) UserMe {
 		Username:           u.Username,
 		DisplayName:        u.DisplayName,
 		Email:              u.Email,
+		EmailVerified:      u.IsEmailVerified(),
 		PhoneNumber:        u.PhoneNumber,
 		AvatarURL:          u.AvatarURL,
 		AvatarThumbnailURL: u.AvatarThumbnailURL,
 		Description:        u.Description,
 		FriendPolicy:       string(u.FriendPolicy),
+		IsBot:              u.IsBot,
 		AppLinks:           []AppLink{},
 		CreatedAt:          u.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:          u.UpdatedAt.Format(time.RFC3339),
 	}
 }
+
+type OIDCProviderRes struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type OIDCAuthorizationRes struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+const (
+	OIDCLoginSignedIn       = "signed_in"
+	OIDCLoginMFARequired    = "mfa_required"
+	OIDCLoginSignupRequired = "signup_required"
+	OIDCLoginLinked         = "linked"
+)
+
+// OIDCLoginRes : Status says which of the optional fields are set
+type OIDCLoginRes struct {
+	Status      string  `json:"status"`
+	AccessToken string  `json:"-"`
+	UserMe      *UserMe `json:"user,omitempty"`
+
+	MFAToken string `json:"mfa_token,omitempty"`
+
+	// first login with this identity, finish with POST /auth/oidc/signup
+	SignupToken       string `json:"signup_token,omitempty"`
+	SuggestedUsername string `json:"suggested_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+type UserIdentityRes struct {
+	Provider    string     `json:"provider"`
+	Email       *string    `json:"email,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type UserIdentitiesRes struct {
+	HasPassword bool              `json:"has_password"`
+	Identities  []UserIdentityRes `json:"identities"`
+}
+
+// WSTicketRes is passed as ?ticket= on the /ws upgrade
+type WSTicketRes struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type SigninAttemptRes struct {
+	ID            uuid.UUID           `json:"id"`
+	Method        models.SigninMethod `json:"method"`
+	Succeeded     bool                `json:"succeeded"`
+	FailureReason *string             `json:"failure_reason,omitempty"`
+	IP            *string             `json:"ip,omitempty"`
+	DeviceName    *string             `json:"device_name,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+type SigninAttemptsRes struct {
+	Attempts []SigninAttemptRes `json:"attempts"`
+}
+
+type BotRes struct {
+	ID                 uuid.UUID `json:"id"`
+	Username           string    `json:"username"`
+	DisplayName        string    `json:"display_name"`
+	Description        *string   `json:"description"`
+	AvatarURL          *string   `json:"avatar_url"`
+	AvatarThumbnailURL *string   `json:"avatar_thumbnail_url"`
+	OwnerID            uuid.UUID `json:"owner_id"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type BotsRes struct {
+	Bots []BotRes `json:"bots"`
+}
+
+// BotTokenRes is the only time a bot token is ever shown, send it as
+// "Authorization: Bot <token>"
+type BotTokenRes struct {
+	Bot   BotRes `json:"bot"`
+	Token string `json:"token"`
+}
+
+type PersonalTokenRes struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type PersonalTokensRes struct {
+	Tokens []PersonalTokenRes `json:"tokens"`
+}
+
+// PersonalTokenCreatedRes is the only time the token is shown, send it as
+// "Authorization: Bearer <token>"
+type PersonalTokenCreatedRes struct {
+	PersonalTokenRes
+	Token string `json:"token"`
+}
+
+func ToPersonalTokenRes(t models.PersonalAccessToken) PersonalTokenRes {
+	return PersonalTokenRes{
+		ID:         t.ID,
+		Name:       t.Name,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+func ToBotRes(u models.User) BotRes {
+	res := BotRes{
+		ID:                 u.ID,
+		Username:           u.Username,
+		DisplayName:        u.DisplayName,
+		Description:        u.Description,
+		AvatarURL:          u.AvatarURL,
+		AvatarThumbnailURL: u.AvatarThumbnailURL,
+		CreatedAt:          u.CreatedAt,
+	}
+	if u.BotOwnerID != nil {
+		res.OwnerID = *u.BotOwnerID
+	}
+	return res
+}
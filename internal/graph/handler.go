@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/services"
+	"github.com/suck-seed/yapp/internal/utils"
+	"github.com/suck-seed/yapp/internal/ws"
+)
+
+type Handler struct {
+	schema   *graphql.Schema
+	users    services.IUserService
+	presence services.IPresenceService
+	upgrader websocket.Upgrader
+}
+
+func NewHandler(schema *graphql.Schema, allowedOrigins []string, userService services.IUserService, presenceService services.IPresenceService) *Handler {
+	upgrader := ws.NewUpgrader(allowedOrigins)
+	upgrader.Subprotocols = []string{subprotocol}
+
+	return &Handler{
+		schema,
+		userService,
+		presenceService,
+		upgrader,
+	}
+}
+
+// Request is the body of POST /graphql and the payload of a subscribe frame
+type Request struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// operationContext carries the caller and a fresh set of loaders. Loaders
+// cache for the length of one request, a subscription lives far longer
+// than that and must not keep serving the user it loaded an hour ago.
+func (h *Handler) operationContext(ctx context.Context, userInfo *auth.UserInfo, cache bool) context.Context {
+	ctx = withUserInfo(ctx, userInfo)
+	return withLoaders(ctx, newLoaders(h.users, h.presence, cache))
+}
+
+// Query godoc
+// @Summary      Run a GraphQL query or mutation
+// @Description  Executes one operation against the schema at /api/v1/graphql. The response is the
+// @Description  standard GraphQL result, not the usual envelope, errors carry the status in extensions.code.
+// @Description  Personal access tokens and OAuth apps see only what their scopes cover.
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Param        payload  body  graph.Request  true  "query, operationName, variables"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /graphql [post]
+func (h *Handler) Query(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	ctx := h.operationContext(c.Request.Context(), userInfo, true)
+	res := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	c.JSON(http.StatusOK, res)
+}
+
+// Subscribe godoc
+// @Summary      GraphQL over WebSocket
+// @Description  Upgrades to a socket speaking the graphql-transport-ws subprotocol. Subscriptions are fed
+// @Description  by the same hub as /ws, queries and mutations may be sent over it as well.
+// @Tags         graphql
+// @Security     CookieAuth
+// @Success      101  "Switching Protocols"
+// @Failure      400  {object}  map[string]interface{}  "Subprotocol missing"
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /graphql [get]
+func (h *Handler) Subscribe(c *gin.Context) {
+	userInfo, err := auth.CurrentUserFromGinContext(c)
+	if err != nil {
+		utils.WriteError(c, err)
+		return
+	}
+
+	if !requestsSubprotocol(c.Request) {
+		utils.WriteError(c, utils.ErrorInvalidInput)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader has answered already
+		return
+	}
+
+	newSession(h, conn, userInfo).serve(c.Request.Context())
+}
+
+func requestsSubprotocol(r *http.Request) bool {
+	for _, p := range websocket.Subprotocols(r) {
+		if p == subprotocol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+	userDto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/services"
+)
+
+// loaderWait is how long a loader collects keys before it runs its batch,
+// sibling fields resolve in parallel well within it
+const loaderWait = 2 * time.Millisecond
+
+// loaders batch the lookups every list field would otherwise repeat per row,
+// a page of messages asks for its authors, mentions and reactors in one query
+type loaders struct {
+	users    *dataloader.Loader[uuid.UUID, *models.User]
+	presence *dataloader.Loader[uuid.UUID, *userDto.UserPresenceRes]
+}
+
+// newLoaders is called per request. Subscriptions keep one set for their
+// whole life, cache is false there so presence and profiles don't go stale.
+func newLoaders(userService services.IUserService, presenceService services.IPresenceService, cache bool) *loaders {
+	userOpts := []dataloader.Option[uuid.UUID, *models.User]{dataloader.WithWait[uuid.UUID, *models.User](loaderWait)}
+	presenceOpts := []dataloader.Option[uuid.UUID, *userDto.UserPresenceRes]{dataloader.WithWait[uuid.UUID, *userDto.UserPresenceRes](loaderWait)}
+	if !cache {
+		userOpts = append(userOpts, dataloader.WithClearCacheOnBatch[uuid.UUID, *models.User]())
+		presenceOpts = append(presenceOpts, dataloader.WithClearCacheOnBatch[uuid.UUID, *userDto.UserPresenceRes]())
+	}
+
+	return &loaders{
+		users:    dataloader.NewBatchedLoader(batchUsers(userService), userOpts...),
+		presence: dataloader.NewBatchedLoader(batchPresence(presenceService), presenceOpts...),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, ctxLoadersKey, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(ctxLoadersKey).(*loaders)
+	return l
+}
+
+// batchUsers answers in key order, users that don't exist (anymore) are nil
+func batchUsers(userService services.IUserService) dataloader.BatchFunc[uuid.UUID, *models.User] {
+	return func(ctx context.Context, userIDs []uuid.UUID) []*dataloader.Result[*models.User] {
+		results := make([]*dataloader.Result[*models.User], len(userIDs))
+
+		users, err := userService.GetUsersByIds(ctx, userIDs)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[*models.User]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[uuid.UUID]*models.User, len(users))
+		for _, user := range users {
+			byID[user.ID] = user
+		}
+		for i, userID := range userIDs {
+			results[i] = &dataloader.Result[*models.User]{Data: byID[userID]}
+		}
+		return results
+	}
+}
+
+// batchPresence : users without a presence record are offline, nil here
+func batchPresence(presenceService services.IPresenceService) dataloader.BatchFunc[uuid.UUID, *userDto.UserPresenceRes] {
+	return func(ctx context.Context, userIDs []uuid.UUID) []*dataloader.Result[*userDto.UserPresenceRes] {
+		results := make([]*dataloader.Result[*userDto.UserPresenceRes], len(userIDs))
+
+		presences, err := presenceService.GetManyPresences(ctx, userIDs)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[*userDto.UserPresenceRes]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[uuid.UUID]*userDto.UserPresenceRes, len(presences))
+		for _, presence := range presences {
+			if presence != nil {
+				byID[presence.UserID] = presence
+			}
+		}
+		for i, userID := range userIDs {
+			results[i] = &dataloader.Result[*userDto.UserPresenceRes]{Data: byID[userID]}
+		}
+		return results
+	}
+}
+
+// loadUser goes through the request's loader, and straight to the service
+// if there is none
+func (r *Resolver) loadUser(ctx context.Context, userID uuid.UUID) (*userResolver, error) {
+	if userID == uuid.Nil {
+		return nil, nil
+	}
+
+	var user *models.User
+	var err error
+	if l := loadersFrom(ctx); l != nil {
+		user, err = l.users.Load(ctx, userID)()
+	} else {
+		user, err = r.users.GetUserById(ctx, userID)
+	}
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	return &userResolver{r, user}, nil
+}
+
+func (r *Resolver) loadUsers(ctx context.Context, userIDs []uuid.UUID) ([]*userResolver, error) {
+	out := make([]*userResolver, 0, len(userIDs))
+
+	l := loadersFrom(ctx)
+	if l == nil {
+		for _, userID := range userIDs {
+			user, err := r.loadUser(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			if user != nil {
+				out = append(out, user)
+			}
+		}
+		return out, nil
+	}
+
+	users, errs := l.users.LoadMany(ctx, userIDs)()
+	for i, user := range users {
+		if errs != nil && errs[i] != nil {
+			return nil, errs[i]
+		}
+		if user != nil {
+			out = append(out, &userResolver{r, user})
+		}
+	}
+	return out, nil
+}
+
+func (r *Resolver) loadPresence(ctx context.Context, userID uuid.UUID) (*presenceResolver, error) {
+	var presence *userDto.UserPresenceRes
+	var err error
+	if l := loadersFrom(ctx); l != nil {
+		presence, err = l.presence.Load(ctx, userID)()
+	} else {
+		presence, err = r.presence.GetUserPresence(ctx, userID)
+	}
+	if err != nil || presence == nil {
+		return nil, err
+	}
+
+	return &presenceResolver{presence}, nil
+}
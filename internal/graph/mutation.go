@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+	msgDto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// maxMessageLength matches the REST and websocket bindings, gin validates
+// those, nothing validates a GraphQL argument for us
+const maxMessageLength = 8000
+
+func validMessageContent(content string) bool {
+	return strings.TrimSpace(content) != "" && utf8.RuneCountInString(content) <= maxMessageLength
+}
+
+func (r *Resolver) SendMessage(ctx context.Context, args struct {
+	RoomID          graphql.ID
+	Content         string
+	Mentions        *[]graphql.ID
+	MentionEveryone *bool
+}) (*messageResolver, error) {
+	userInfo, err := requireScope(ctx, "messages", true)
+	if err != nil {
+		return nil, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if !validMessageContent(args.Content) {
+		return nil, utils.ErrorInvalidInput
+	}
+
+	var mentions *[]uuid.UUID
+	if args.Mentions != nil {
+		ids := make([]uuid.UUID, 0, len(*args.Mentions))
+		for _, id := range *args.Mentions {
+			userID, err := parseID(id)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, userID)
+		}
+		mentions = &ids
+	}
+
+	created, err := r.messages.CreateMessage(ctx, &msgDto.CreateMessageReq{
+		RoomID:          roomID,
+		AuthorID:        userInfo.ID,
+		Content:         &args.Content,
+		SentAt:          time.Now().UTC(),
+		MentionEveryone: args.MentionEveryone,
+		Mentions:        mentions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the author sees it through the same frame everyone else gets
+	return &messageResolver{r, messageFromOutbound(created.ToOutbound(uuid.Nil))}, nil
+}
+
+func (r *Resolver) EditMessage(ctx context.Context, args struct {
+	RoomID  graphql.ID
+	ID      graphql.ID
+	Content string
+}) (*messageResolver, error) {
+	userInfo, err := requireScope(ctx, "messages", true)
+	if err != nil {
+		return nil, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !validMessageContent(args.Content) {
+		return nil, utils.ErrorInvalidInput
+	}
+
+	message, err := r.messages.UpdateMessage(ctx, userInfo, roomID, messageID, &msgDto.UpdateMessageReq{Content: args.Content})
+	if err != nil {
+		return nil, err
+	}
+
+	return &messageResolver{r, message}, nil
+}
+
+func (r *Resolver) DeleteMessage(ctx context.Context, args struct {
+	RoomID graphql.ID
+	ID     graphql.ID
+}) (bool, error) {
+	userInfo, err := requireScope(ctx, "messages", true)
+	if err != nil {
+		return false, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return false, err
+	}
+	messageID, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.messages.DeleteMessage(ctx, userInfo, roomID, messageID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type reactionArgs struct {
+	RoomID    graphql.ID
+	MessageID graphql.ID
+	Emoji     string
+}
+
+func (r *Resolver) AddReaction(ctx context.Context, args reactionArgs) (bool, error) {
+	userInfo, err := requireScope(ctx, "messages", true)
+	if err != nil {
+		return false, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return false, err
+	}
+	messageID, err := parseID(args.MessageID)
+	if err != nil {
+		return false, err
+	}
+	if args.Emoji == "" {
+		return false, utils.ErrorInvalidInput
+	}
+
+	if _, err := r.messages.AddReaction(ctx, userInfo, roomID, messageID, args.Emoji); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Resolver) RemoveReaction(ctx context.Context, args reactionArgs) (bool, error) {
+	userInfo, err := requireScope(ctx, "messages", true)
+	if err != nil {
+		return false, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return false, err
+	}
+	messageID, err := parseID(args.MessageID)
+	if err != nil {
+		return false, err
+	}
+	if args.Emoji == "" {
+		return false, utils.ErrorInvalidInput
+	}
+
+	if err := r.messages.RemoveReaction(ctx, userInfo, roomID, messageID, args.Emoji); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Resolver) MarkRead(ctx context.Context, args struct {
+	RoomID    graphql.ID
+	MessageID graphql.ID
+}) (graphql.Time, error) {
+	userInfo, err := requireScope(ctx, "messages", true)
+	if err != nil {
+		return graphql.Time{}, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return graphql.Time{}, err
+	}
+	messageID, err := parseID(args.MessageID)
+	if err != nil {
+		return graphql.Time{}, err
+	}
+
+	read, err := r.messages.MarkMessageRead(ctx, userInfo, roomID, messageID)
+	if err != nil {
+		return graphql.Time{}, err
+	}
+	return graphql.Time{Time: read.ReadAt}, nil
+}
+
+func (r *Resolver) JoinHall(ctx context.Context, args struct{ ID graphql.ID }) (*joinResultResolver, error) {
+	userInfo, err := requireScope(ctx, "halls", true)
+	if err != nil {
+		return nil, err
+	}
+
+	hallID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.isEmailVerified != nil {
+		verified, err := r.isEmailVerified(ctx, userInfo.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !verified {
+			return nil, utils.ErrorEmailNotVerified
+		}
+	}
+
+	res, err := r.halls.JoinHall(ctx, userInfo, hallID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &joinResultResolver{r, res}, nil
+}
+
+func (r *Resolver) SetPresence(ctx context.Context, args struct{ Status string }) (*presenceResolver, error) {
+	userInfo, err := requireScope(ctx, "presence", true)
+	if err != nil {
+		return nil, err
+	}
+
+	presence, err := r.presence.SetManualStatus(ctx, userInfo.ID, models.PresenceStatus(args.Status))
+	if err != nil {
+		return nil, err
+	}
+
+	return &presenceResolver{presence}, nil
+}
@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	msgDto "github.com/suck-seed/yapp/internal/dto/message"
+)
+
+func (r *Resolver) Me(ctx context.Context) (*meResolver, error) {
+	userInfo, err := requireScope(ctx, "users", false)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.users.GetUserById(ctx, userInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &meResolver{r, user}, nil
+}
+
+func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	if _, err := requireScope(ctx, "users", false); err != nil {
+		return nil, err
+	}
+
+	userID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.loadUser(ctx, userID)
+}
+
+func (r *Resolver) Halls(ctx context.Context) ([]*hallResolver, error) {
+	userInfo, err := requireScope(ctx, "halls", false)
+	if err != nil {
+		return nil, err
+	}
+
+	halls, err := r.halls.GetUserHalls(ctx, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*hallResolver, 0, len(halls))
+	for i := range halls {
+		out = append(out, &hallResolver{r, toHallRes(&halls[i])})
+	}
+	return out, nil
+}
+
+func (r *Resolver) Hall(ctx context.Context, args struct{ ID graphql.ID }) (*hallResolver, error) {
+	userInfo, err := requireScope(ctx, "halls", false)
+	if err != nil {
+		return nil, err
+	}
+
+	hallID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	hall, err := r.halls.GetCurrentHall(ctx, userInfo, hallID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hallResolver{r, hall}, nil
+}
+
+func (r *Resolver) Room(ctx context.Context, args struct {
+	HallID graphql.ID
+	ID     graphql.ID
+}) (*roomResolver, error) {
+	userInfo, err := requireScope(ctx, "halls", false)
+	if err != nil {
+		return nil, err
+	}
+
+	hallID, err := parseID(args.HallID)
+	if err != nil {
+		return nil, err
+	}
+	roomID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := r.rooms.GetRoom(ctx, userInfo, hallID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roomResolver{r, room}, nil
+}
+
+type messagesArgs struct {
+	RoomID graphql.ID
+	Limit  *int32
+	Before *graphql.ID
+	After  *graphql.ID
+	Around *graphql.ID
+}
+
+func (r *Resolver) Messages(ctx context.Context, args messagesArgs) (*messagePageResolver, error) {
+	userInfo, err := requireScope(ctx, "messages", false)
+	if err != nil {
+		return nil, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &msgDto.FetchMessagesQuery{Limit: pageSize(args.Limit)}
+	if query.Before, err = parseOptionalID(args.Before); err != nil {
+		return nil, err
+	}
+	if query.After, err = parseOptionalID(args.After); err != nil {
+		return nil, err
+	}
+	if query.Around, err = parseOptionalID(args.Around); err != nil {
+		return nil, err
+	}
+
+	page, err := r.messages.FetchMessages(ctx, userInfo, roomID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messagePageResolver{r, page}, nil
+}
+
+func (r *Resolver) Message(ctx context.Context, args struct {
+	RoomID graphql.ID
+	ID     graphql.ID
+}) (*messageResolver, error) {
+	userInfo, err := requireScope(ctx, "messages", false)
+	if err != nil {
+		return nil, err
+	}
+
+	roomID, err := parseID(args.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := r.messages.GetMessage(ctx, userInfo, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messageResolver{r, message}, nil
+}
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
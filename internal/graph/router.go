@@ -0,0 +1,16 @@
+package graph
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/suck-seed/yapp/internal/services"
+)
+
+// RegisterGraphQLRoutes serves the schema over POST and graphql-transport-ws
+// on the same path, behind the same auth as the REST API
+func RegisterGraphQLRoutes(r *gin.RouterGroup, schema *graphql.Schema, allowedOrigins []string, userService services.IUserService, presenceService services.IPresenceService) {
+	graphHandler := NewHandler(schema, allowedOrigins, userService, presenceService)
+
+	r.POST("/graphql", graphHandler.Query)
+	r.GET("/graphql", graphHandler.Subscribe)
+}
@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/suck-seed/yapp/internal/auth"
+	msgDto "github.com/suck-seed/yapp/internal/dto/message"
+)
+
+// eventScopes maps the frames a subscription passes on to the scope needed
+// to see them. Errors, sync replies and command frames are for /ws clients.
+var eventScopes = map[msgDto.MessageType]string{
+	msgDto.MessageTypeText:         "messages",
+	msgDto.MessageTypeEdit:         "messages",
+	msgDto.MessageTypeDelete:       "messages",
+	msgDto.MessageTypeTyping:       "messages",
+	msgDto.MessageTypeStopTyping:   "messages",
+	msgDto.MessageTypeRead:         "messages",
+	msgDto.MessageTypePin:          "messages",
+	msgDto.MessageTypeUnpin:        "messages",
+	msgDto.MessageTypePresence:     "presence",
+	msgDto.MessageTypeNotification: "notifications",
+}
+
+func (r *Resolver) Events(ctx context.Context, args struct {
+	HallID *graphql.ID
+	RoomID *graphql.ID
+}) (<-chan *eventResolver, error) {
+	userInfo, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hallID, err := parseOptionalID(args.HallID)
+	if err != nil {
+		return nil, err
+	}
+	roomID, err := parseOptionalID(args.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := r.events(ctx, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *eventResolver)
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case frame, ok := <-frames:
+				if !ok {
+					return
+				}
+				if !wantsEvent(userInfo, frame, hallID, roomID) {
+					continue
+				}
+
+				select {
+				case out <- &eventResolver{r, frame}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func wantsEvent(userInfo *auth.UserInfo, frame *msgDto.OutboundMessage, hallID *uuid.UUID, roomID *uuid.UUID) bool {
+	resource, ok := eventScopes[frame.Type]
+	if !ok || !auth.HasScope(userInfo, resource, false) {
+		return false
+	}
+	if hallID != nil && frame.HallID != *hallID {
+		return false
+	}
+	if roomID != nil && frame.RoomID != *roomID {
+		return false
+	}
+	return true
+}
+
+type eventResolver struct {
+	r     *Resolver
+	frame *msgDto.OutboundMessage
+}
+
+func (e *eventResolver) Type() string         { return string(e.frame.Type) }
+func (e *eventResolver) HallID() *graphql.ID  { return toOptionalID(&e.frame.HallID) }
+func (e *eventResolver) RoomID() *graphql.ID  { return toOptionalID(&e.frame.RoomID) }
+func (e *eventResolver) SentAt() graphql.Time { return graphql.Time{Time: e.frame.SentAt} }
+
+func (e *eventResolver) Message() *messageResolver {
+	switch e.frame.Type {
+	case msgDto.MessageTypeText, msgDto.MessageTypeEdit:
+		return &messageResolver{e.r, messageFromOutbound(e.frame)}
+	}
+	return nil
+}
+
+func (e *eventResolver) MessageID() *graphql.ID {
+	switch e.frame.Type {
+	case msgDto.MessageTypeDelete, msgDto.MessageTypePin, msgDto.MessageTypeUnpin:
+		return toOptionalID(&e.frame.ID)
+	case msgDto.MessageTypeRead:
+		return toOptionalID(e.frame.MessageID)
+	}
+	return nil
+}
+
+func (e *eventResolver) User(ctx context.Context) (*userResolver, error) {
+	var userID *uuid.UUID
+	switch e.frame.Type {
+	case msgDto.MessageTypeTyping, msgDto.MessageTypeStopTyping:
+		userID = e.frame.TypingUser
+	case msgDto.MessageTypeRead:
+		userID = e.frame.ReadBy
+	case msgDto.MessageTypePresence:
+		userID = e.frame.PresenceUserID
+	case msgDto.MessageTypePin, msgDto.MessageTypeUnpin:
+		userID = e.frame.PinnedBy
+	}
+	if userID == nil {
+		return nil, nil
+	}
+	return e.r.loadUser(ctx, *userID)
+}
+
+func (e *eventResolver) Status() *string {
+	if e.frame.Type != msgDto.MessageTypePresence {
+		return nil
+	}
+	return e.frame.PresenceStatus
+}
+
+func (e *eventResolver) Notification() *notificationResolver {
+	if e.frame.Notification == nil {
+		return nil
+	}
+	return &notificationResolver{e.r, e.frame.Notification}
+}
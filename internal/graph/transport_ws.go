@@ -0,0 +1,296 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/suck-seed/yapp/internal/auth"
+)
+
+// subprotocol is graphql-transport-ws, what graphql-ws and most clients speak
+const subprotocol = "graphql-transport-ws"
+
+const (
+	initTimeout = 10 * time.Second
+	writeWait   = 10 * time.Second
+	pongWait    = 60 * time.Second
+	pingPeriod  = (pongWait * 9) / 10
+
+	// every subscription holds a hub client, one socket doesn't get many
+	maxOperations = 16
+)
+
+// close codes from the protocol
+const (
+	closeBadRequest   = 4400
+	closeUnauthorized = 4401
+	closeInitTimeout  = 4408
+	closeDuplicateID  = 4409
+	closeTooManyInits = 4429
+)
+
+type frameType string
+
+const (
+	frameConnectionInit frameType = "connection_init"
+	frameConnectionAck  frameType = "connection_ack"
+	framePing           frameType = "ping"
+	framePong           frameType = "pong"
+	frameSubscribe      frameType = "subscribe"
+	frameNext           frameType = "next"
+	frameError          frameType = "error"
+	frameComplete       frameType = "complete"
+)
+
+type frame struct {
+	ID      string          `json:"id,omitempty"`
+	Type    frameType       `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// session is one socket. The caller was authenticated on the upgrade, so
+// connection_init only has to arrive, its payload is ignored.
+type session struct {
+	h        *Handler
+	conn     *websocket.Conn
+	userInfo *auth.UserInfo
+
+	writeMu sync.Mutex
+
+	mu         sync.Mutex
+	acked      bool
+	operations map[string]*operation
+}
+
+type operation struct {
+	cancel context.CancelFunc
+}
+
+func newSession(h *Handler, conn *websocket.Conn, userInfo *auth.UserInfo) *session {
+	return &session{
+		h:          h,
+		conn:       conn,
+		userInfo:   userInfo,
+		operations: make(map[string]*operation),
+	}
+}
+
+func (s *session) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer s.conn.Close()
+
+	s.conn.SetReadLimit(2 * maxQueryLength)
+	_ = s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	initTimer := time.AfterFunc(initTimeout, func() {
+		s.mu.Lock()
+		acked := s.acked
+		s.mu.Unlock()
+		if !acked {
+			s.close(closeInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+
+	go s.keepAlive(ctx)
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg frame
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.close(closeBadRequest, "Invalid message received")
+			return
+		}
+
+		if !s.handle(ctx, &msg) {
+			return
+		}
+	}
+}
+
+// handle reports whether the socket stays open
+func (s *session) handle(ctx context.Context, msg *frame) bool {
+	switch msg.Type {
+	case frameConnectionInit:
+		s.mu.Lock()
+		already := s.acked
+		s.acked = true
+		s.mu.Unlock()
+
+		if already {
+			s.close(closeTooManyInits, "Too many initialisation requests")
+			return false
+		}
+		s.send(&frame{Type: frameConnectionAck})
+
+	case framePing:
+		s.send(&frame{Type: framePong})
+
+	case framePong:
+
+	case frameSubscribe:
+		return s.subscribe(ctx, msg)
+
+	case frameComplete:
+		s.mu.Lock()
+		if op, ok := s.operations[msg.ID]; ok {
+			op.cancel()
+			delete(s.operations, msg.ID)
+		}
+		s.mu.Unlock()
+
+	default:
+		s.close(closeBadRequest, "Invalid message received")
+		return false
+	}
+
+	return true
+}
+
+func (s *session) subscribe(ctx context.Context, msg *frame) bool {
+	var req Request
+	if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil || req.Query == "" {
+		s.close(closeBadRequest, "Invalid message received")
+		return false
+	}
+
+	s.mu.Lock()
+	if !s.acked {
+		s.mu.Unlock()
+		s.close(closeUnauthorized, "Unauthorized")
+		return false
+	}
+	if _, ok := s.operations[msg.ID]; ok {
+		s.mu.Unlock()
+		s.close(closeDuplicateID, "Subscriber for "+msg.ID+" already exists")
+		return false
+	}
+	if len(s.operations) >= maxOperations {
+		s.mu.Unlock()
+		s.sendErrors(msg.ID, []*qerrors.QueryError{qerrors.Errorf("too many operations on this connection")})
+		return true
+	}
+
+	opCtx, cancel := context.WithCancel(ctx)
+	op := &operation{cancel}
+	s.operations[msg.ID] = op
+	s.mu.Unlock()
+
+	go s.run(opCtx, msg.ID, op, &req)
+	return true
+}
+
+// run streams one operation. Queries and mutations come through Subscribe
+// too, as a single result. Nothing is sent once the client completed the
+// operation itself.
+func (s *session) run(ctx context.Context, id string, op *operation, req *Request) {
+	defer s.finish(id, op)
+
+	ctx = s.h.operationContext(ctx, s.userInfo, false)
+	results, err := s.h.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		s.sendErrors(id, []*qerrors.QueryError{qerrors.Errorf("%s", err)})
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case result, ok := <-results:
+			if !ok {
+				if ctx.Err() == nil {
+					s.send(&frame{ID: id, Type: frameComplete})
+				}
+				return
+			}
+
+			res, _ := result.(*graphql.Response)
+			if res == nil {
+				continue
+			}
+
+			// no data at all means the operation never ran, e.g. it failed
+			// validation, the protocol wants those as an error frame
+			if res.Data == nil && len(res.Errors) > 0 {
+				s.sendErrors(id, res.Errors)
+				return
+			}
+
+			payload, err := json.Marshal(res)
+			if err != nil {
+				continue
+			}
+			s.send(&frame{ID: id, Type: frameNext, Payload: payload})
+		}
+	}
+}
+
+func (s *session) finish(id string, op *operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op.cancel()
+	// a complete and a new subscribe may have reused the id already
+	if s.operations[id] == op {
+		delete(s.operations, id)
+	}
+}
+
+func (s *session) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			s.writeMu.Lock()
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+			s.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *session) sendErrors(id string, errs []*qerrors.QueryError) {
+	payload, err := json.Marshal(errs)
+	if err != nil {
+		return
+	}
+	s.send(&frame{ID: id, Type: frameError, Payload: payload})
+}
+
+func (s *session) send(msg *frame) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	_ = s.conn.WriteJSON(msg)
+}
+
+func (s *session) close(code int, reason string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+	_ = s.conn.Close()
+}
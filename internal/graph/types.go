@@ -0,0 +1,370 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+	hallDto "github.com/suck-seed/yapp/internal/dto/hall"
+	msgDto "github.com/suck-seed/yapp/internal/dto/message"
+	notificationDto "github.com/suck-seed/yapp/internal/dto/notification"
+	roomDto "github.com/suck-seed/yapp/internal/dto/room"
+	userDto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// ── USERS ─────────────────────────────────────────────────────────────────────
+
+type meResolver struct {
+	r    *Resolver
+	user *models.User
+}
+
+func (m *meResolver) ID() graphql.ID       { return toID(m.user.ID) }
+func (m *meResolver) Username() string     { return m.user.Username }
+func (m *meResolver) DisplayName() string  { return m.user.DisplayName }
+func (m *meResolver) Email() string        { return m.user.Email }
+func (m *meResolver) EmailVerified() bool  { return m.user.IsEmailVerified() }
+func (m *meResolver) AvatarURL() *string   { return m.user.AvatarURL }
+func (m *meResolver) Description() *string { return m.user.Description }
+func (m *meResolver) IsBot() bool          { return m.user.IsBot }
+
+func (m *meResolver) Presence(ctx context.Context) (*presenceResolver, error) {
+	if _, err := requireScope(ctx, "presence", false); err != nil {
+		return nil, err
+	}
+	return m.r.loadPresence(ctx, m.user.ID)
+}
+
+// userResolver only has what any signed in user may see, email and phone
+// stay on Me
+type userResolver struct {
+	r    *Resolver
+	user *models.User
+}
+
+func (u *userResolver) ID() graphql.ID              { return toID(u.user.ID) }
+func (u *userResolver) Username() string            { return u.user.Username }
+func (u *userResolver) DisplayName() string         { return u.user.DisplayName }
+func (u *userResolver) AvatarURL() *string          { return u.user.AvatarURL }
+func (u *userResolver) AvatarThumbnailURL() *string { return u.user.AvatarThumbnailURL }
+func (u *userResolver) Description() *string        { return u.user.Description }
+func (u *userResolver) IsBot() bool                 { return u.user.IsBot }
+
+func (u *userResolver) Presence(ctx context.Context) (*presenceResolver, error) {
+	if _, err := requireScope(ctx, "presence", false); err != nil {
+		return nil, err
+	}
+	return u.r.loadPresence(ctx, u.user.ID)
+}
+
+type presenceResolver struct {
+	presence *userDto.UserPresenceRes
+}
+
+func (p *presenceResolver) Status() string { return string(p.presence.Status) }
+
+func (p *presenceResolver) LastSeenAt() *graphql.Time {
+	if p.presence.LastSeenAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *p.presence.LastSeenAt}
+}
+
+func (p *presenceResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: p.presence.UpdatedAt}
+}
+
+// ── HALLS ─────────────────────────────────────────────────────────────────────
+
+// hallResolver covers both the caller's hall list and a single hall, they
+// share every field the schema exposes
+type hallResolver struct {
+	r    *Resolver
+	hall *hallDto.GetCurrentHallRes
+}
+
+func (h *hallResolver) ID() graphql.ID       { return toID(h.hall.ID) }
+func (h *hallResolver) Name() string         { return h.hall.Name }
+func (h *hallResolver) Description() *string { return h.hall.Description }
+func (h *hallResolver) IsPrivate() bool      { return h.hall.IsPrivate }
+func (h *hallResolver) IconURL() *string     { return h.hall.IconURL }
+func (h *hallResolver) BannerColor() *string { return h.hall.BannerColor }
+func (h *hallResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: h.hall.CreatedAt}
+}
+
+func (h *hallResolver) Owner(ctx context.Context) (*userResolver, error) {
+	return h.r.loadUser(ctx, h.hall.OwnerID)
+}
+
+func (h *hallResolver) Rooms(ctx context.Context) ([]*roomResolver, error) {
+	userInfo, err := requireScope(ctx, "halls", false)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.r.rooms.GetHallRooms(ctx, userInfo, h.hall.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make([]*roomResolver, 0, len(res.TopLevel))
+	for i := range res.TopLevel {
+		rooms = append(rooms, &roomResolver{h.r, &res.TopLevel[i]})
+	}
+	for _, floor := range res.Floors {
+		for i := range floor.Rooms {
+			rooms = append(rooms, &roomResolver{h.r, &floor.Rooms[i]})
+		}
+	}
+	return rooms, nil
+}
+
+func (h *hallResolver) Members(ctx context.Context) ([]*memberResolver, error) {
+	userInfo, err := requireScope(ctx, "halls", false)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.r.halls.GetHallMembers(ctx, userInfo, h.hall.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*memberResolver, 0, len(res.Members))
+	for _, member := range res.Members {
+		members = append(members, &memberResolver{h.r, member})
+	}
+	return members, nil
+}
+
+func toHallRes(hall *hallDto.UserHallRes) *hallDto.GetCurrentHallRes {
+	return &hallDto.GetCurrentHallRes{
+		ID:               hall.ID,
+		Name:             hall.Name,
+		IsPrivate:        hall.IsPrivate,
+		IconURL:          hall.IconURL,
+		IconThumbnailURL: hall.IconThumbnailURL,
+		BannerColor:      hall.BannerColor,
+		Description:      hall.Description,
+		CreatedAt:        hall.CreatedAt,
+		UpdatedAt:        hall.UpdatedAt,
+		OwnerID:          hall.OwnerID,
+	}
+}
+
+type memberResolver struct {
+	r      *Resolver
+	member *hallDto.HallMemberRes
+}
+
+func (m *memberResolver) ID() graphql.ID    { return toID(m.member.ID) }
+func (m *memberResolver) Nickname() *string { return m.member.Nickname }
+func (m *memberResolver) JoinedAt() graphql.Time {
+	return graphql.Time{Time: m.member.JoinedAt}
+}
+
+func (m *memberResolver) User(ctx context.Context) (*userResolver, error) {
+	return m.r.loadUser(ctx, m.member.UserID)
+}
+
+type joinResultResolver struct {
+	r   *Resolver
+	res *hallDto.JoinHallRes
+}
+
+func (j *joinResultResolver) Status() string { return j.res.Status }
+
+// Hall is only there once the caller is in, a request still waits for approval
+func (j *joinResultResolver) Hall(ctx context.Context) (*hallResolver, error) {
+	if j.res.MemberID == nil {
+		return nil, nil
+	}
+	return j.r.Hall(ctx, struct{ ID graphql.ID }{toID(j.res.HallID)})
+}
+
+// ── ROOMS ─────────────────────────────────────────────────────────────────────
+
+type roomResolver struct {
+	r    *Resolver
+	room *roomDto.RoomRes
+}
+
+func (rr *roomResolver) ID() graphql.ID          { return toID(rr.room.ID) }
+func (rr *roomResolver) HallID() graphql.ID      { return toID(rr.room.HallID) }
+func (rr *roomResolver) FloorID() *graphql.ID    { return toOptionalID(rr.room.FloorID) }
+func (rr *roomResolver) Name() string            { return rr.room.Name }
+func (rr *roomResolver) Type() string            { return rr.room.RoomType }
+func (rr *roomResolver) IsPrivate() bool         { return rr.room.IsPrivate }
+func (rr *roomResolver) SlowmodeSeconds() int32  { return int32(rr.room.SlowmodeSeconds) }
+func (rr *roomResolver) CreatedAt() graphql.Time { return graphql.Time{Time: rr.room.CreatedAt} }
+
+func (rr *roomResolver) Messages(ctx context.Context, args struct {
+	Limit  *int32
+	Before *graphql.ID
+	After  *graphql.ID
+}) (*messagePageResolver, error) {
+	return rr.r.Messages(ctx, messagesArgs{
+		RoomID: toID(rr.room.ID),
+		Limit:  args.Limit,
+		Before: args.Before,
+		After:  args.After,
+	})
+}
+
+// ── MESSAGES ──────────────────────────────────────────────────────────────────
+
+type messagePageResolver struct {
+	r    *Resolver
+	page *msgDto.MessageListResponse
+}
+
+func (p *messagePageResolver) Messages() []*messageResolver {
+	out := make([]*messageResolver, 0, len(p.page.Messages))
+	for _, message := range p.page.Messages {
+		out = append(out, &messageResolver{p.r, message})
+	}
+	return out
+}
+
+func (p *messagePageResolver) HasMore() bool { return p.page.HasMore }
+
+// messageResolver loads every user through the request's loader, a page of
+// fifty messages costs one user query however many authors, mentions and
+// reactors it has
+type messageResolver struct {
+	r       *Resolver
+	message *msgDto.MessageDetailed
+}
+
+func (m *messageResolver) ID() graphql.ID           { return toID(m.message.ID) }
+func (m *messageResolver) RoomID() graphql.ID       { return toID(m.message.RoomID) }
+func (m *messageResolver) Content() *string         { return m.message.Content }
+func (m *messageResolver) MentionEveryone() bool    { return m.message.MentionEveryone }
+func (m *messageResolver) AuthorName() *string      { return m.message.AuthorName }
+func (m *messageResolver) AuthorAvatarURL() *string { return m.message.AuthorAvatarURL }
+func (m *messageResolver) WebhookID() *graphql.ID   { return toOptionalID(m.message.WebhookID) }
+func (m *messageResolver) SentAt() graphql.Time     { return graphql.Time{Time: m.message.SentAt} }
+func (m *messageResolver) EditedAt() *graphql.Time  { return optionalTime(m.message.EditedAt) }
+func (m *messageResolver) DeletedAt() *graphql.Time { return optionalTime(m.message.DeletedAt) }
+func (m *messageResolver) RevisionCount() int32     { return int32(m.message.RevisionCount) }
+
+func (m *messageResolver) Author(ctx context.Context) (*userResolver, error) {
+	return m.r.loadUser(ctx, m.message.AuthorID)
+}
+
+func (m *messageResolver) Mentions(ctx context.Context) ([]*userResolver, error) {
+	return m.r.loadUsers(ctx, userBasicIDs(m.message.Mentions))
+}
+
+func (m *messageResolver) Reactions() []*reactionResolver {
+	out := make([]*reactionResolver, 0, len(m.message.Reactions))
+	for i := range m.message.Reactions {
+		out = append(out, &reactionResolver{m.r, &m.message.Reactions[i]})
+	}
+	return out
+}
+
+func (m *messageResolver) Attachments() []*attachmentResolver {
+	out := make([]*attachmentResolver, 0, len(m.message.Attachments))
+	for i := range m.message.Attachments {
+		out = append(out, &attachmentResolver{&m.message.Attachments[i]})
+	}
+	return out
+}
+
+type reactionResolver struct {
+	r        *Resolver
+	reaction *msgDto.ReactionGroup
+}
+
+func (rr *reactionResolver) Emoji() string { return rr.reaction.Emoji }
+func (rr *reactionResolver) Count() int32  { return int32(rr.reaction.Count) }
+
+func (rr *reactionResolver) Users(ctx context.Context) ([]*userResolver, error) {
+	return rr.r.loadUsers(ctx, userBasicIDs(rr.reaction.Reactors))
+}
+
+type attachmentResolver struct {
+	attachment *msgDto.AttachmentResponseMinimal
+}
+
+func (a *attachmentResolver) ID() graphql.ID    { return toID(a.attachment.ID) }
+func (a *attachmentResolver) FileName() string  { return a.attachment.FileName }
+func (a *attachmentResolver) URL() string       { return a.attachment.URL }
+func (a *attachmentResolver) FileType() *string { return a.attachment.FileType }
+
+// messageFromOutbound turns a hub frame back into the shape queries return,
+// frames carry no reactions, a new or edited message has none to show yet
+func messageFromOutbound(out *msgDto.OutboundMessage) *msgDto.MessageDetailed {
+	attachments := make([]msgDto.AttachmentResponseMinimal, 0, len(out.Attachments))
+	for _, attachment := range out.Attachments {
+		attachments = append(attachments, msgDto.AttachmentResponseMinimal{
+			ID:        attachment.ID,
+			MessageID: out.ID,
+			URL:       attachment.URL,
+			FileName:  attachment.FileName,
+			FileType:  attachment.FileType,
+			CreatedAt: attachment.CreatedAt,
+			UpdatedAt: attachment.UpdatedAt,
+		})
+	}
+
+	return &msgDto.MessageDetailed{
+		Message: models.Message{
+			ID:              out.ID,
+			RoomID:          out.RoomID,
+			AuthorID:        out.AuthorID,
+			Content:         out.Content,
+			MentionEveryone: out.MentionsEveryone,
+			WebhookID:       out.WebhookID,
+			AuthorName:      out.AuthorName,
+			AuthorAvatarURL: out.AuthorAvatarURL,
+			SentAt:          out.SentAt,
+			EditedAt:        out.EditedAt,
+			DeletedAt:       out.DeletedAt,
+			CreatedAt:       out.CreatedAt,
+			UpdatedAt:       out.UpdatedAt,
+		},
+		Attachments: attachments,
+		Reactions:   []msgDto.ReactionGroup{},
+		Mentions:    out.Mentions,
+
+		RevisionCount: out.RevisionCount,
+	}
+}
+
+// ── NOTIFICATIONS ─────────────────────────────────────────────────────────────
+
+type notificationResolver struct {
+	r            *Resolver
+	notification *notificationDto.NotificationRes
+}
+
+func (n *notificationResolver) ID() graphql.ID         { return toID(n.notification.ID) }
+func (n *notificationResolver) Type() string           { return string(n.notification.Type) }
+func (n *notificationResolver) HallID() *graphql.ID    { return toOptionalID(n.notification.HallID) }
+func (n *notificationResolver) RoomID() *graphql.ID    { return toOptionalID(n.notification.RoomID) }
+func (n *notificationResolver) MessageID() *graphql.ID { return toOptionalID(n.notification.MessageID) }
+func (n *notificationResolver) Preview() *string       { return n.notification.Preview }
+func (n *notificationResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: n.notification.CreatedAt}
+}
+
+func (n *notificationResolver) Actor(ctx context.Context) (*userResolver, error) {
+	if n.notification.Actor == nil {
+		return nil, nil
+	}
+	return n.r.loadUser(ctx, n.notification.Actor.ID)
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func userBasicIDs(users []msgDto.UserBasic) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/suck-seed/yapp/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer hands a message off for delivery.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer picks SMTP when a host is configured. Pointing it at the mailpit
+// container gives a local catch-all. Mails are only logged with
+// MAIL_DRIVER=log or in development, their links are live tokens so the
+// body is left out of the log anywhere else.
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		if cfg.Host == "" {
+			return nil, fmt.Errorf("MAIL_DRIVER=%s needs SMTP_HOST", cfg.Driver)
+		}
+		return NewSMTPMailer(cfg), nil
+
+	case config.MailDriverLog:
+		log.Printf("MAIL_DRIVER=log, e-mails will only be logged")
+		return NewLogMailer(config.IsDevelopment()), nil
+
+	case "":
+		return nil, fmt.Errorf("no mail driver configured, set SMTP_HOST or MAIL_DRIVER=log")
+	}
+
+	return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.Driver)
+}
+
+// ── LOG ONLY ──────────────────────────────────────────────────────────────────
+
+type logMailer struct {
+	withBody bool
+}
+
+// NewLogMailer writes mails to the process log, withBody only belongs in
+// development since reset and verification links are in the body.
+func NewLogMailer(withBody bool) Mailer {
+	return &logMailer{withBody: withBody}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	if !m.withBody {
+		log.Printf("[mail] to=%s subject=%q (not sent, body withheld)", msg.To, msg.Subject)
+		return nil
+	}
+
+	log.Printf("[mail] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/suck-seed/yapp/config"
+)
+
+func TestNewMailerRefusesMissingSMTPHost(t *testing.T) {
+	if _, err := NewMailer(config.MailConfig{Driver: config.MailDriverSMTP}); err == nil {
+		t.Fatal("MAIL_DRIVER=smtp without SMTP_HOST started anyway")
+	}
+}
+
+func TestNewMailerRefusesNoDriver(t *testing.T) {
+	if _, err := NewMailer(config.MailConfig{}); err == nil {
+		t.Fatal("started without any mail driver")
+	}
+}
+
+func TestLogMailerWithholdsLinksOutsideDevelopment(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	mailer, err := NewMailer(config.MailConfig{Driver: config.MailDriverLog})
+	if err != nil {
+		t.Fatalf("MAIL_DRIVER=log: %v", err)
+	}
+
+	link := "https://yapp.test/reset-password?token=secret-token"
+	if err := mailer.Send(context.Background(), PasswordResetMessage("ana@yapp.test", "Ana", link)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if strings.Contains(out.String(), "secret-token") {
+		t.Fatalf("token written to the log:\n%s", out.String())
+	}
+}
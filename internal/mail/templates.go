@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+func VerifyEmailMessage(to string, displayName string, link string) *Message {
+	return &Message{
+		To:      to,
+		Subject: "Verify your Yapp e-mail address",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nConfirm this is your e-mail address by opening the link below:\n\n%s\n\nThe link expires in 24 hours. If you didn't sign up for Yapp you can ignore this e-mail.\n",
+			displayName, link,
+		),
+		HTML: fmt.Sprintf(
+			`<p>Hi %s,</p><p>Confirm this is your e-mail address:</p><p><a href="%s">Verify e-mail</a></p><p>The link expires in 24 hours. If you didn't sign up for Yapp you can ignore this e-mail.</p>`,
+			html.EscapeString(displayName), html.EscapeString(link),
+		),
+	}
+}
+
+func PasswordResetMessage(to string, displayName string, link string) *Message {
+	return &Message{
+		To:      to,
+		Subject: "Reset your Yapp password",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password of your Yapp account. Choose a new one here:\n\n%s\n\nThe link expires in 1 hour and works once. If it wasn't you, nothing has changed and you can ignore this e-mail.\n",
+			displayName, link,
+		),
+		HTML: fmt.Sprintf(
+			`<p>Hi %s,</p><p>Someone asked to reset the password of your Yapp account.</p><p><a href="%s">Choose a new password</a></p><p>The link expires in 1 hour and works once. If it wasn't you, nothing has changed and you can ignore this e-mail.</p>`,
+			html.EscapeString(displayName), html.EscapeString(link),
+		),
+	}
+}
+
+func NewSigninMessage(to string, displayName string, device string, ip string, at time.Time, resetLink string) *Message {
+	when := at.UTC().Format("Jan 2, 2006 15:04 MST")
+
+	return &Message{
+		To:      to,
+		Subject: "New sign-in to your Yapp account",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nYour account was just signed in to from a device we haven't seen before:\n\n%s\nIP address: %s\nTime: %s\n\nIf this was you, there's nothing to do. If it wasn't, reset your password right away:\n\n%s\n",
+			displayName, device, ip, when, resetLink,
+		),
+		HTML: fmt.Sprintf(
+			`<p>Hi %s,</p><p>Your account was just signed in to from a device we haven't seen before:</p><p>%s<br>IP address: %s<br>Time: %s</p><p>If this was you, there's nothing to do. If it wasn't, <a href="%s">reset your password</a> right away.</p>`,
+			html.EscapeString(displayName), html.EscapeString(device), html.EscapeString(ip), when, html.EscapeString(resetLink),
+		),
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BotTokenPrefix starts every bot token, so they are easy to tell apart from
+// access tokens and for secret scanners to spot
+const BotTokenPrefix = "yapp_bot_"
+
+// BotToken authenticates a bot until it is reset. Only the keyed hash is kept.
+type BotToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	BotID      uuid.UUID  `json:"bot_id" db:"bot_id"`
+	TokenHash  []byte     `json:"-" db:"token_hash"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CommandOptionType string
+
+const (
+	CommandOptionString   CommandOptionType = "string"
+	CommandOptionInteger  CommandOptionType = "integer"
+	CommandOptionBoolean  CommandOptionType = "boolean"
+	CommandOptionUser     CommandOptionType = "user"
+	CommandOptionDuration CommandOptionType = "duration"
+
+	// CommandOptionText takes the rest of the line, so it can only come last
+	CommandOptionText CommandOptionType = "text"
+)
+
+func (t CommandOptionType) Valid() bool {
+	switch t {
+	case CommandOptionString, CommandOptionInteger, CommandOptionBoolean,
+		CommandOptionUser, CommandOptionDuration, CommandOptionText:
+		return true
+	}
+	return false
+}
+
+// CommandOption is one typed argument of a slash command. Choices, when set,
+// are the only accepted values and double as autocompletion hints.
+type CommandOption struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Type        CommandOptionType `json:"type"`
+	Required    bool              `json:"required"`
+	Choices     []string          `json:"choices,omitempty"`
+}
+
+// BotCommand is a slash command a bot registered in one hall. Invoking it
+// sends the bot a command frame instead of posting a message.
+type BotCommand struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	HallID      uuid.UUID       `json:"hall_id" db:"hall_id"`
+	BotID       uuid.UUID       `json:"bot_id" db:"bot_id"`
+	Name        string          `json:"name" db:"name"`
+	Description string          `json:"description" db:"description"`
+	Options     []CommandOption `json:"options" db:"options"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CommandInteraction is one invocation of a bot command, kept for a while
+// so the bot can answer it, ClientID is the tab that invoked it
+type CommandInteraction struct {
+	ID        uuid.UUID      `json:"id"`
+	Name      string         `json:"name"`
+	BotID     uuid.UUID      `json:"bot_id"`
+	HallID    uuid.UUID      `json:"hall_id"`
+	RoomID    uuid.UUID      `json:"room_id"`
+	InvokerID uuid.UUID      `json:"invoker_id"`
+	ClientID  uuid.UUID      `json:"client_id"`
+	Args      map[string]any `json:"args"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Reminder is set with /remind and turns into a notification when due
+type Reminder struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	HallID    uuid.UUID `json:"hall_id"`
+	RoomID    uuid.UUID `json:"room_id"`
+	Text      string    `json:"text"`
+	DueAt     time.Time `json:"due_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
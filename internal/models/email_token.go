@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailTokenPurpose string
+
+const (
+	EmailTokenVerifyEmail   EmailTokenPurpose = "verify_email"
+	EmailTokenResetPassword EmailTokenPurpose = "reset_password"
+)
+
+// EmailToken is a single-use token delivered by e-mail. Only its hash is
+// persisted, the raw value exists solely inside the link sent to Email.
+type EmailToken struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	UserID    uuid.UUID         `json:"user_id" db:"user_id"`
+	Purpose   EmailTokenPurpose `json:"purpose" db:"purpose"`
+	TokenHash []byte            `json:"-" db:"token_hash"`
+	Email     string            `json:"email" db:"email"`
+	ExpiresAt time.Time         `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time        `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// JWTSigningKey is a stored access token signing key, see auth.SigningKey.
+type JWTSigningKey struct {
+	ID            string     `json:"kid" db:"kid"`
+	Algorithm     string     `json:"algorithm" db:"algorithm"`
+	PrivateKeyEnc []byte     `json:"-" db:"private_key_enc"`
+	ActivatesAt   time.Time  `json:"activates_at" db:"activates_at"`
+	RetiresAt     *time.Time `json:"retires_at,omitempty" db:"retires_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
@@ -13,6 +13,12 @@ type Message struct {
 	Content         *string   `json:"content,omitempty" db:"content"`
 	MentionEveryone bool      `json:"mention_everyone" db:"mention_everyone"`
 
+	// Set on messages posted through an incoming webhook, the name and avatar
+	// only when the payload overrode the webhook's own
+	WebhookID       *uuid.UUID `json:"webhook_id,omitempty" db:"webhook_id"`
+	AuthorName      *string    `json:"author_name,omitempty" db:"author_name"`
+	AuthorAvatarURL *string    `json:"author_avatar_url,omitempty" db:"author_avatar_url"`
+
 	SentAt    time.Time  `json:"sent_at" db:"sent_at"`
 	EditedAt  *time.Time `json:"edited_at,omitempty" db:"edited_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
@@ -31,6 +37,15 @@ type Attachment struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// MessageRevision is the text an edit replaced, WrittenAt is when it went live
+type MessageRevision struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	MessageID  uuid.UUID `json:"message_id" db:"message_id"`
+	Content    *string   `json:"content,omitempty" db:"content"`
+	WrittenAt  time.Time `json:"written_at" db:"written_at"`
+	ReplacedAt time.Time `json:"replaced_at" db:"replaced_at"`
+}
+
 type Reaction struct {
 	ID        uuid.UUID `json:"id"`
 	MessageID uuid.UUID `json:"message_id"`
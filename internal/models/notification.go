@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationMention               NotificationType = "mention"
+	NotificationMentionEveryone       NotificationType = "mention_everyone"
+	NotificationFriendRequest         NotificationType = "friend_request"
+	NotificationFriendRequestAccepted NotificationType = "friend_request_accepted"
+	NotificationJoinRequestAccepted   NotificationType = "join_request_accepted"
+	NotificationInviteAccepted        NotificationType = "invite_accepted"
+	NotificationNewSignin             NotificationType = "new_signin"
+	NotificationReminder              NotificationType = "reminder"
+)
+
+type Notification struct {
+	ID     uuid.UUID        `json:"id" db:"id"`
+	UserID uuid.UUID        `json:"user_id" db:"user_id"`
+	Type   NotificationType `json:"type" db:"type"`
+
+	ActorID   *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	HallID    *uuid.UUID `json:"hall_id,omitempty" db:"hall_id"`
+	RoomID    *uuid.UUID `json:"room_id,omitempty" db:"room_id"`
+	MessageID *uuid.UUID `json:"message_id,omitempty" db:"message_id"`
+
+	// friend request / join request / invite / sign-in attempt id depending on Type
+	ReferenceID *uuid.UUID `json:"reference_id,omitempty" db:"reference_id"`
+	Preview     *string    `json:"preview,omitempty" db:"preview"`
+
+	ReadAt    *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Prefixes of what the OAuth provider hands out, access tokens are accepted
+// by AuthMiddleware like personal access tokens
+const (
+	OAuthAccessTokenPrefix  = "yapp_oat_"
+	OAuthRefreshTokenPrefix = "yapp_ort_"
+	OAuthClientSecretPrefix = "yapp_ocs_"
+	OAuthCodePrefix         = "yapp_oac_"
+)
+
+// OAuthApp is a third-party app registered by OwnerID. Apps without a client
+// secret are public clients, native or single page apps that can't keep one.
+type OAuthApp struct {
+	ID               uuid.UUID `json:"id" db:"id"`
+	OwnerID          uuid.UUID `json:"owner_id" db:"owner_id"`
+	Name             string    `json:"name" db:"name"`
+	Description      *string   `json:"description,omitempty" db:"description"`
+	RedirectURIs     []string  `json:"redirect_uris" db:"redirect_uris"`
+	ClientSecretHash []byte    `json:"-" db:"client_secret_hash"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (a *OAuthApp) IsConfidential() bool {
+	return len(a.ClientSecretHash) > 0
+}
+
+// OAuthToken is one grant of an app by a user. Only keyed hashes are kept.
+type OAuthToken struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	AppID            uuid.UUID  `json:"app_id" db:"app_id"`
+	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
+	AccessTokenHash  []byte     `json:"-" db:"access_token_hash"`
+	RefreshTokenHash []byte     `json:"-" db:"refresh_token_hash"`
+	Scopes           []string   `json:"scopes" db:"scopes"`
+	AccessExpiresAt  time.Time  `json:"access_expires_at" db:"access_expires_at"`
+	RefreshExpiresAt time.Time  `json:"refresh_expires_at" db:"refresh_expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OAuthAuthorization sums up a user's grants of one app
+type OAuthAuthorization struct {
+	AppID        uuid.UUID  `json:"app_id" db:"app_id"`
+	AppName      string     `json:"app_name" db:"app_name"`
+	Scopes       []string   `json:"scopes" db:"scopes"`
+	AuthorizedAt time.Time  `json:"authorized_at" db:"authorized_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// OAuthCode is what an authorization code stands for until the app redeems
+// it, once, within a few minutes
+type OAuthCode struct {
+	AppID         uuid.UUID `json:"app_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	Scopes        []string  `json:"scopes"`
+	CodeChallenge string    `json:"code_challenge,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutgoingEventType string
+
+const (
+	OutgoingEventMessageCreated OutgoingEventType = "message.created"
+	OutgoingEventMessageUpdated OutgoingEventType = "message.updated"
+	OutgoingEventMessageDeleted OutgoingEventType = "message.deleted"
+	OutgoingEventMemberJoined   OutgoingEventType = "member.joined"
+	OutgoingEventMemberLeft     OutgoingEventType = "member.left"
+	OutgoingEventMemberBanned   OutgoingEventType = "member.banned"
+	OutgoingEventRoleChanged    OutgoingEventType = "role.changed"
+	OutgoingEventRoomCreated    OutgoingEventType = "room.created"
+)
+
+// OutgoingEventTypes is everything a hall can subscribe an endpoint to
+var OutgoingEventTypes = []OutgoingEventType{
+	OutgoingEventMessageCreated,
+	OutgoingEventMessageUpdated,
+	OutgoingEventMessageDeleted,
+	OutgoingEventMemberJoined,
+	OutgoingEventMemberLeft,
+	OutgoingEventMemberBanned,
+	OutgoingEventRoleChanged,
+	OutgoingEventRoomCreated,
+}
+
+func IsOutgoingEventType(t string) bool {
+	for _, known := range OutgoingEventTypes {
+		if string(known) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// OutgoingWebhook POSTs a hall's events to an outside URL, signed with a
+// secret only the hall and the receiver know
+type OutgoingWebhook struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	HallID       uuid.UUID  `json:"hall_id" db:"hall_id"`
+	URL          string     `json:"url" db:"url"`
+	SecretEnc    []byte     `json:"-" db:"secret_enc"`
+	Events       []string   `json:"events" db:"events"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	FailureCount int        `json:"failure_count" db:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type OutgoingDeliveryStatus string
+
+const (
+	OutgoingDeliveryPending   OutgoingDeliveryStatus = "pending"
+	OutgoingDeliverySucceeded OutgoingDeliveryStatus = "succeeded"
+	OutgoingDeliveryFailed    OutgoingDeliveryStatus = "failed"
+)
+
+// OutgoingWebhookDelivery is one event for one webhook, kept as a log
+// whether or not the receiver ever accepted it
+type OutgoingWebhookDelivery struct {
+	ID             uuid.UUID              `json:"id" db:"id"`
+	WebhookID      uuid.UUID              `json:"webhook_id" db:"webhook_id"`
+	EventType      string                 `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage        `json:"payload" db:"payload"`
+	Status         OutgoingDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                    `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time              `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode *int                   `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string                `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time             `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at" db:"updated_at"`
+}
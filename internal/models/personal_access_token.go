@@ -0,0 +1,39 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, it is how
+// AuthMiddleware tells them apart from a JWT in "Authorization: Bearer"
+const PersonalAccessTokenPrefix = "yapp_pat_"
+
+// TokenScopes are what a personal access token may be limited to. Each
+// "<resource>:write" also grants "<resource>:read".
+var TokenScopes = []string{
+	"users:read", "users:write",
+	"halls:read", "halls:write",
+	"messages:read", "messages:write",
+	"presence:read", "presence:write",
+	"notifications:read", "notifications:write",
+	"webhooks:read", "webhooks:write",
+}
+
+func IsValidTokenScope(scope string) bool {
+	return slices.Contains(TokenScopes, scope)
+}
+
+// PersonalAccessToken authenticates scripts as UserID. Only the keyed hash is kept.
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  []byte     `json:"-" db:"token_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PinnedMessage keeps a message at the top of its room. PinnedBy is nil once
+// the user who pinned it is gone.
+type PinnedMessage struct {
+	MessageID uuid.UUID  `json:"message_id" db:"message_id"`
+	RoomID    uuid.UUID  `json:"room_id" db:"room_id"`
+	PinnedBy  *uuid.UUID `json:"pinned_by,omitempty" db:"pinned_by"`
+	PinnedAt  time.Time  `json:"pinned_at" db:"pinned_at"`
+}
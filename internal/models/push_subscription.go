@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PushSubscription struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+
+	Endpoint string `json:"endpoint" db:"endpoint"`
+	P256dh   string `json:"-" db:"p256dh"`
+	Auth     string `json:"-" db:"auth"`
+
+	UserAgent  *string `json:"user_agent,omitempty" db:"user_agent"`
+	DeviceName *string `json:"device_name,omitempty" db:"device_name"`
+
+	ExpiresAt     *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	FailureCount  int        `json:"failure_count" db:"failure_count"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty" db:"last_success_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
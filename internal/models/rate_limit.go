@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is a token bucket: Requests tokens refill every Per and at most
+// Burst are saved up. A zero Burst holds exactly Requests.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// Capacity is how many requests can go through back to back
+func (l RateLimit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// ParseRateLimit reads "<requests>/<per>" with an optional ":<burst>",
+// e.g. "60/1m" or "5/1s:10".
+func ParseRateLimit(s string) (RateLimit, error) {
+	var l RateLimit
+
+	spec, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	requests, per, ok := strings.Cut(spec, "/")
+	if !ok {
+		return l, fmt.Errorf("rate limit %q: want <requests>/<per>[:<burst>]", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return l, fmt.Errorf("rate limit %q: bad request count", s)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return l, fmt.Errorf("rate limit %q: bad period", s)
+	}
+	l.Requests, l.Per = n, d
+
+	if hasBurst {
+		b, err := strconv.Atoi(burst)
+		if err != nil || b <= 0 {
+			return l, fmt.Errorf("rate limit %q: bad burst", s)
+		}
+		l.Burst = b
+	}
+
+	return l, nil
+}
+
+// RateLimitResult is the outcome of taking one token
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+
+	// RetryAfter is how long until a token is back, 0 when Allowed
+	RetryAfter time.Duration
+}
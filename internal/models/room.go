@@ -24,6 +24,9 @@ type Room struct {
 	IsPrivate            bool       `json:"is_private"           db:"is_private"`
 	SyncWithFloorMembers bool       `json:"sync_with_floor_members"    db:"sync_with_floor_members"`
 
+	// SlowmodeSeconds is the cooldown between one member's messages, 0 is off
+	SlowmodeSeconds int `json:"slowmode_seconds"     db:"slowmode_seconds"`
+
 	CreatedAt time.Time `json:"created_at"           db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"           db:"updated_at"`
 }
@@ -43,6 +46,7 @@ func roomToRes(r *Room) dto.RoomRes {
 		Position:             r.Position,
 		IsPrivate:            r.IsPrivate,
 		SyncWithFloorMembers: r.SyncWithFloorMembers,
+		SlowmodeSeconds:      r.SlowmodeSeconds,
 		CreatedAt:            r.CreatedAt,
 		UpdatedAt:            r.UpdatedAt,
 	}
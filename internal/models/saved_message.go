@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedMessage is a user's bookmark on a message. RemindedAt is set once the
+// reminder went out, changing RemindAt clears it again.
+type SavedMessage struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	MessageID  uuid.UUID  `json:"message_id" db:"message_id"`
+	Note       *string    `json:"note,omitempty" db:"note"`
+	RemindAt   *time.Time `json:"remind_at,omitempty" db:"remind_at"`
+	RemindedAt *time.Time `json:"reminded_at,omitempty" db:"reminded_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduledMessageStatus string
+
+const (
+	ScheduledMessagePending ScheduledMessageStatus = "pending"
+	ScheduledMessageSending ScheduledMessageStatus = "sending"
+	ScheduledMessageFailed  ScheduledMessageStatus = "failed"
+)
+
+// ScheduledMessage is posted to RoomID as AuthorID once SendAt has passed,
+// the row goes away with that. Failure says why it couldn't be posted.
+type ScheduledMessage struct {
+	ID              uuid.UUID              `json:"id" db:"id"`
+	AuthorID        uuid.UUID              `json:"author_id" db:"author_id"`
+	RoomID          uuid.UUID              `json:"room_id" db:"room_id"`
+	Content         *string                `json:"content,omitempty" db:"content"`
+	Attachments     []ScheduledAttachment  `json:"attachments" db:"attachments"`
+	MentionEveryone bool                   `json:"mention_everyone" db:"mention_everyone"`
+	Mentions        []uuid.UUID            `json:"mentions" db:"mentions"`
+	SendAt          time.Time              `json:"send_at" db:"send_at"`
+	Status          ScheduledMessageStatus `json:"status" db:"status"`
+	Failure         *string                `json:"failure,omitempty" db:"failure"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// ScheduledAttachment is kept as the author sent it, CreateMessage checks it
+// again when the message is posted
+type ScheduledAttachment struct {
+	FileName string  `json:"file_name"`
+	URL      string  `json:"url"`
+	FileType *string `json:"file_type,omitempty"`
+	FileSize *int64  `json:"file_size,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SigninMethod string
+
+const (
+	SigninMethodPassword  SigninMethod = "password"
+	SigninMethodTwoFactor SigninMethod = "two_factor"
+	SigninMethodOIDC      SigninMethod = "oidc"
+)
+
+const (
+	SigninFailureUnknownAccount = "unknown_account"
+	SigninFailureWrongPassword  = "wrong_password"
+	SigninFailureWrongCode      = "wrong_code"
+	SigninFailureThrottled      = "throttled"
+)
+
+type SigninAttempt struct {
+	ID            uuid.UUID    `json:"id" db:"id"`
+	UserID        *uuid.UUID   `json:"user_id,omitempty" db:"user_id"`
+	DeviceID      *uuid.UUID   `json:"device_id,omitempty" db:"device_id"`
+	Method        SigninMethod `json:"method" db:"method"`
+	Succeeded     bool         `json:"succeeded" db:"succeeded"`
+	FailureReason *string      `json:"failure_reason,omitempty" db:"failure_reason"`
+	IP            *string      `json:"ip,omitempty" db:"ip"`
+	UserAgent     *string      `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+
+	// joined from user_metadatas
+	DeviceName *string `json:"device_name,omitempty" db:"device_name"`
+}
+
+// UserDevice is a row of user_metadatas, one per browser or app a user signed
+// in from. DeviceKey is the keyed hash of the device cookie.
+type UserDevice struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	DeviceKey  string    `json:"-" db:"device_id"`
+	DeviceName *string   `json:"device_name,omitempty" db:"device_name"`
+	LastIP     *string   `json:"last_ip,omitempty" db:"last_ip"`
+	LastSeen   time.Time `json:"last_seen" db:"last_seen"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -15,30 +15,37 @@ const (
 	FriendPolicyNoOne    FriendPolicy = "no_one"
 )
 
+// AppProvider names an entry of the applinks registry (spotify, reddit, ...)
 type AppProvider string
 
-const (
-	AppProviderSpotify AppProvider = "spotify"
-	AppProviderReddit  AppProvider = "reddit"
-	AppProviderTwitter AppProvider = "twitter"
-	AppProviderSteam   AppProvider = "steam"
-)
-
 type User struct {
 	ID                 uuid.UUID    `json:"id" db:"id"`
 	Username           string       `json:"username" db:"username"`
 	DisplayName        string       `json:"display_name" db:"display_name"`
 	Email              string       `json:"email" db:"email"`
-	PasswordHash       string       `json:"password_hash" db:"password_hash"`
+	PasswordHash       string       `json:"password_hash" db:"password_hash"` // empty for accounts created through an external login
 	Description        *string      `json:"description,omitempty" db:"description"`
 	PhoneNumber        *string      `json:"phone_number,omitempty" db:"phone_number"`
 	AvatarURL          *string      `json:"avatar_url,omitempty" db:"avatar_url"`
 	AvatarThumbnailURL *string      `json:"avatar_thumbnail_url,omitempty" db:"avatar_thumbnail_url"`
 	FriendPolicy       FriendPolicy `json:"friend_policy" db:"friend_policy"`
+	EmailVerifiedAt    *time.Time   `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	IsBot              bool         `json:"is_bot" db:"is_bot"`
+	BotOwnerID         *uuid.UUID   `json:"bot_owner_id,omitempty" db:"bot_owner_id"` // the human a bot belongs to
 	CreatedAt          time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time    `json:"updated_at" db:"updated_at"`
 }
 
+// IsEmailVerified : unverified accounts can sign in but may be kept away from some actions
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasPassword : accounts created through OpenID Connect have none until they reset it
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
 type Friend struct {
 	UserID1   uuid.UUID `json:"user_id_1" db:"user_id_1"`
 	UserID2   uuid.UUID `json:"user_id_2" db:"user_id_2"`
@@ -58,6 +65,31 @@ type UserAppLink struct {
 	Provider      AppProvider `json:"provider" db:"provider"`
 	URL           string      `json:"url" db:"url"`
 	ShowOnProfile bool        `json:"show_on_profile" db:"show_on_profile"`
+
+	ExternalID     *string    `json:"external_id,omitempty" db:"external_id"`
+	ExternalHandle *string    `json:"external_handle,omitempty" db:"external_handle"`
+	VerifiedAt     *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+
+	// sealed with auth.Seal, never leave the server
+	AccessTokenEnc  []byte     `json:"-" db:"access_token_enc"`
+	RefreshTokenEnc []byte     `json:"-" db:"refresh_token_enc"`
+	TokenExpiresAt  *time.Time `json:"-" db:"token_expires_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (l *UserAppLink) IsVerified() bool {
+	return l.VerifiedAt != nil
+}
+
+// AppLinkState remembers a connection attempt while the user is at the provider.
+type AppLinkState struct {
+	StateHash     []byte      `json:"-" db:"state_hash"`
+	UserID        uuid.UUID   `json:"user_id" db:"user_id"`
+	Provider      AppProvider `json:"provider" db:"provider"`
+	CodeVerifier  string      `json:"-" db:"code_verifier"`
+	ShowOnProfile bool        `json:"show_on_profile" db:"show_on_profile"`
+	ExpiresAt     time.Time   `json:"expires_at" db:"expires_at"`
 	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time   `json:"updated_at" db:"updated_at"`
 }
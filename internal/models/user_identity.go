@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links an account to a login at an external OpenID Connect provider.
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Provider    string     `json:"provider" db:"provider"`
+	Subject     string     `json:"-" db:"subject"`
+	Email       *string    `json:"email,omitempty" db:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OIDCLoginState is what we remember between sending the browser to the
+// provider and it coming back with a code.
+type OIDCLoginState struct {
+	StateHash    []byte     `json:"-" db:"state_hash"`
+	Provider     string     `json:"provider" db:"provider"`
+	Nonce        string     `json:"-" db:"nonce"`
+	CodeVerifier string     `json:"-" db:"code_verifier"`
+	LinkUserID   *uuid.UUID `json:"link_user_id,omitempty" db:"link_user_id"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook lets an outside system post into one room through a secret URL.
+// It writes as UserID, an ownerless bot created alongside it.
+type Webhook struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	RoomID     uuid.UUID  `json:"room_id" db:"room_id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	AvatarURL  *string    `json:"avatar_url,omitempty" db:"avatar_url"`
+	TokenHash  []byte     `json:"-" db:"token_hash"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
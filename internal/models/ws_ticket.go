@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WSTicket lets one WebSocket upgrade through without putting the access
+// token in the URL. It only works from the origin that asked for it.
+type WSTicket struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	IsBot     bool      `json:"is_bot"`
+	Origin    string    `json:"origin"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
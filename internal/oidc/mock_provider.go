@@ -0,0 +1,176 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const mockKeyID = "mock-1"
+
+// MockProvider is a tiny OpenID Connect provider for local development and
+// manual testing, mounted at /dev/oidc when running in development. Configure
+// it like any other provider:
+//
+//	OIDC_PROVIDERS=mock
+//	OIDC_MOCK_ISSUER=http://localhost:8080/dev/oidc
+//	OIDC_MOCK_CLIENT_ID=yapp
+//
+// There is no login page, /authorize signs in whoever is named by the
+// login_hint query parameter ("alice" becomes sub "alice", alice@mock.local).
+type MockProvider struct {
+	issuer string
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	subject       string
+	expiresAt     time.Time
+}
+
+func NewMockProvider(issuer string) (*MockProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MockProvider{
+		issuer: strings.TrimRight(issuer, "/"),
+		key:    key,
+		codes:  make(map[string]mockAuthorization),
+	}, nil
+}
+
+func (m *MockProvider) Register(r *gin.Engine) {
+	group := r.Group("/dev/oidc")
+	{
+		group.GET("/.well-known/openid-configuration", m.discovery)
+		group.GET("/jwks", m.jwks)
+		group.GET("/authorize", m.authorize)
+		group.POST("/token", m.token)
+	}
+}
+
+func (m *MockProvider) discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                m.issuer,
+		"authorization_endpoint":                m.issuer + "/authorize",
+		"token_endpoint":                        m.issuer + "/token",
+		"jwks_uri":                              m.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (m *MockProvider) jwks(c *gin.Context) {
+	c.JSON(http.StatusOK, jsonWebKeySet{
+		Keys: []jsonWebKey{rsaJSONWebKey(mockKeyID, &m.key.PublicKey)},
+	})
+}
+
+func (m *MockProvider) authorize(c *gin.Context) {
+	redirectURI := c.Query("redirect_uri")
+	target, err := url.Parse(redirectURI)
+	if err != nil || redirectURI == "" {
+		c.String(http.StatusBadRequest, "invalid redirect_uri")
+		return
+	}
+
+	if c.Query("response_type") != "code" || c.Query("code_challenge_method") != "S256" || c.Query("code_challenge") == "" {
+		c.String(http.StatusBadRequest, "only the code flow with S256 PKCE is supported")
+		return
+	}
+
+	subject := strings.ToLower(strings.TrimSpace(c.Query("login_hint")))
+	if subject == "" {
+		subject = "mockuser"
+	}
+
+	code, err := randomString(24)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	m.mu.Lock()
+	m.codes[code] = mockAuthorization{
+		clientID:      c.Query("client_id"),
+		redirectURI:   redirectURI,
+		codeChallenge: c.Query("code_challenge"),
+		nonce:         c.Query("nonce"),
+		subject:       subject,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	m.mu.Unlock()
+
+	q := target.Query()
+	q.Set("code", code)
+	q.Set("state", c.Query("state"))
+	target.RawQuery = q.Encode()
+
+	c.Redirect(http.StatusFound, target.String())
+}
+
+func (m *MockProvider) token(c *gin.Context) {
+	code := c.PostForm("code")
+
+	m.mu.Lock()
+	authz, ok := m.codes[code]
+	delete(m.codes, code)
+	m.mu.Unlock()
+
+	if !ok || time.Now().After(authz.expiresAt) ||
+		c.PostForm("grant_type") != "authorization_code" ||
+		c.PostForm("client_id") != authz.clientID ||
+		c.PostForm("redirect_uri") != authz.redirectURI ||
+		CodeChallengeS256(c.PostForm("code_verifier")) != authz.codeChallenge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, idTokenClaims{
+		Email:             authz.subject + "@mock.local",
+		EmailVerified:     true,
+		Name:              authz.subject,
+		PreferredUsername: authz.subject,
+		Nonce:             authz.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   authz.subject,
+			Audience:  jwt.ClaimStrings{authz.clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	token.Header["kid"] = mockKeyID
+
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": idToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
@@ -0,0 +1,124 @@
+package outgoing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Yapp-Event"
+	HeaderDelivery  = "X-Yapp-Delivery"
+	HeaderTimestamp = "X-Yapp-Timestamp"
+	HeaderSignature = "X-Yapp-Signature"
+
+	sendTimeout = 10 * time.Second
+)
+
+var ErrBlockedAddress = errors.New("outgoing: destination address is not allowed")
+
+// Request is one signed POST to a receiver
+type Request struct {
+	URL        string
+	Secret     []byte
+	EventType  string
+	DeliveryID string
+	Body       []byte
+}
+
+// Sender POSTs a delivery and reports the receiver's HTTP status
+type Sender interface {
+	Send(ctx context.Context, req *Request) (int, error)
+}
+
+// Sign is what receivers recompute to trust a delivery: hex HMAC-SHA256 over
+// "<timestamp>.<body>", so an old body can't be replayed with a fresh timestamp
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender refuses private, loopback and link-local destinations unless
+// allowPrivate is set, otherwise any hall manager could probe the internal
+// network through us
+func NewHTTPSender(allowPrivate bool) Sender {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return ErrBlockedAddress
+			}
+			return nil
+		}
+	}
+
+	return &httpSender{
+		client: &http.Client{
+			Timeout: sendTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+				MaxIdleConnsPerHost: 2,
+			},
+			// a redirect would be a second, unsigned destination
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (s *httpSender) Send(ctx context.Context, req *Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "yapp-webhooks/1")
+	httpReq.Header.Set(HeaderEvent, req.EventType)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	res, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	return res.StatusCode, nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified())
+}
@@ -0,0 +1,80 @@
+package push
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FakeEndpoint stands in for FCM/Mozilla autopush during local development.
+// Point a subscription's endpoint at /dev/push/<any-token> and every push the
+// worker sends ends up here. Tokens starting with "gone" answer 410 so the
+// pruning path can be exercised too.
+type FakeEndpoint struct {
+	mu       sync.Mutex
+	received map[string][]FakeDelivery
+}
+
+type FakeDelivery struct {
+	ReceivedAt      time.Time `json:"received_at"`
+	ContentEncoding string    `json:"content_encoding"`
+	TTL             string    `json:"ttl"`
+	Urgency         string    `json:"urgency"`
+	HasVAPID        bool      `json:"has_vapid"`
+	Size            int       `json:"size"`
+}
+
+func NewFakeEndpoint() *FakeEndpoint {
+	return &FakeEndpoint{received: make(map[string][]FakeDelivery)}
+}
+
+func (f *FakeEndpoint) Register(r *gin.Engine) {
+	group := r.Group("/dev/push")
+	{
+		group.POST("/:token", f.receive)
+		group.GET("/:token", f.list)
+	}
+}
+
+func (f *FakeEndpoint) receive(c *gin.Context) {
+	token := c.Param("token")
+	if strings.HasPrefix(token, "gone") {
+		c.Status(http.StatusGone)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.received[token] = append(f.received[token], FakeDelivery{
+		ReceivedAt:      time.Now().UTC(),
+		ContentEncoding: c.GetHeader("Content-Encoding"),
+		TTL:             c.GetHeader("TTL"),
+		Urgency:         c.GetHeader("Urgency"),
+		HasVAPID:        strings.HasPrefix(c.GetHeader("Authorization"), "vapid "),
+		Size:            len(body),
+	})
+	f.mu.Unlock()
+
+	c.Status(http.StatusCreated)
+}
+
+func (f *FakeEndpoint) list(c *gin.Context) {
+	f.mu.Lock()
+	deliveries := append([]FakeDelivery{}, f.received[c.Param("token")]...)
+	f.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Fake push deliveries",
+		"data":    deliveries,
+	})
+}
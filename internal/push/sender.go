@@ -0,0 +1,83 @@
+package push
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/suck-seed/yapp/config"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// Sender delivers an already-serialized payload to one subscription and
+// reports the push service's HTTP status so callers can prune dead endpoints.
+type Sender interface {
+	Send(ctx context.Context, sub *models.PushSubscription, payload []byte) (int, error)
+}
+
+// NewSender picks the VAPID sender when keys are configured and falls back
+// to logging so local setups without keys still exercise the whole pipeline.
+func NewSender(cfg config.VAPIDConfig) Sender {
+	if cfg.Enabled() {
+		return NewWebPushSender(cfg)
+	}
+
+	log.Printf("VAPID keys not configured, web push will only be logged")
+	return NewLogSender()
+}
+
+// ── VAPID ─────────────────────────────────────────────────────────────────────
+
+type webPushSender struct {
+	cfg    config.VAPIDConfig
+	client *http.Client
+	ttl    int
+}
+
+func NewWebPushSender(cfg config.VAPIDConfig) Sender {
+	return &webPushSender{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		ttl:    int((12 * time.Hour).Seconds()),
+	}
+}
+
+func (s *webPushSender) Send(ctx context.Context, sub *models.PushSubscription, payload []byte) (int, error) {
+	res, err := webpush.SendNotificationWithContext(ctx, payload, &webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		Keys: webpush.Keys{
+			Auth:   sub.Auth,
+			P256dh: sub.P256dh,
+		},
+	}, &webpush.Options{
+		HTTPClient:      s.client,
+		Subscriber:      s.cfg.Subject,
+		VAPIDPublicKey:  s.cfg.PublicKey,
+		VAPIDPrivateKey: s.cfg.PrivateKey,
+		TTL:             s.ttl,
+		Urgency:         webpush.UrgencyHigh,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	return res.StatusCode, nil
+}
+
+// ── LOG ONLY ──────────────────────────────────────────────────────────────────
+
+type logSender struct{}
+
+func NewLogSender() Sender {
+	return &logSender{}
+}
+
+func (s *logSender) Send(ctx context.Context, sub *models.PushSubscription, payload []byte) (int, error) {
+	log.Printf("[push] user=%s subscription=%s payload=%s", sub.UserID, sub.ID, payload)
+	return http.StatusCreated, nil
+}
@@ -35,6 +35,22 @@ const (
 	// Role/permission events
 	HubEventUserAccessResync HubEventType = "user_access_resync"
 	HubEventHallAccessResync HubEventType = "hall_access_resync"
+
+	// Per-user delivery events
+	HubEventNotificationCreated HubEventType = "notification_created"
+
+	// Message events, Payload is the frame. Socket messages never come
+	// through the bus, updates and deletes only go to hall webhooks.
+	HubEventMessageCreated  HubEventType = "message_created"
+	HubEventMessageUpdated  HubEventType = "message_updated"
+	HubEventMessageDeleted  HubEventType = "message_deleted"
+	HubEventMessagePinned   HubEventType = "message_pinned"
+	HubEventMessageUnpinned HubEventType = "message_unpinned"
+
+	// Slash command events, Payload is the frame. Invocations go to the bot
+	// in UserID, responses to ClientID (or every tab of UserID without one).
+	HubEventCommandInvoked  HubEventType = "command_invoked"
+	HubEventCommandResponse HubEventType = "command_response"
 )
 
 type HubEvent struct {
@@ -43,6 +59,7 @@ type HubEvent struct {
 	HallID  uuid.UUID
 	RoomID  uuid.UUID
 	FloorID uuid.UUID
+	RoleID  uuid.UUID
 
 	// UserID is actual users.id, not hall_members.id.
 	UserID uuid.UUID
@@ -50,13 +67,32 @@ type HubEvent struct {
 	// MemberID is hall_members.id, useful for service-side context/debug.
 	MemberID uuid.UUID
 
+	// ClientID is one websocket connection, for replies meant for one tab only
+	ClientID uuid.UUID
+
 	IsPrivate bool
+
+	// Payload carries the already-built body for events that are delivered
+	// as-is to a user, e.g. the notification for HubEventNotificationCreated.
+	Payload any
 }
 
 type Publisher interface {
 	PublishHubEvent(event HubEvent)
 }
 
+// Fanout hands every event to each of its publishers, e.g. the hub's bus and
+// the outgoing webhook dispatcher
+type Fanout []Publisher
+
+func (f Fanout) PublishHubEvent(event HubEvent) {
+	for _, p := range f {
+		if p != nil {
+			p.PublishHubEvent(event)
+		}
+	}
+}
+
 type EventBus struct {
 	Events chan HubEvent
 }
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IBotCommandRepository interface {
+	// ListHallBotCommands and GetHallBotCommand skip commands of bots that
+	// are no longer in the hall
+	ListHallBotCommands(ctx context.Context, db database.DBRunner, hallID uuid.UUID) ([]*models.BotCommand, error)
+	GetHallBotCommand(ctx context.Context, db database.DBRunner, hallID uuid.UUID, name string) (*models.BotCommand, error)
+
+	CreateBotCommand(ctx context.Context, db database.DBRunner, command *models.BotCommand) (*models.BotCommand, error)
+	DeleteBotHallCommands(ctx context.Context, db database.DBRunner, hallID uuid.UUID, botID uuid.UUID) error
+
+	// PruneLeftBotCommands frees the names held by bots that left the hall
+	PruneLeftBotCommands(ctx context.Context, db database.DBRunner, hallID uuid.UUID) error
+}
+
+type botCommandRepository struct{}
+
+func NewBotCommandRepository() IBotCommandRepository {
+	return &botCommandRepository{}
+}
+
+const botCommandColumns = `
+	c.id, c.hall_id, c.bot_id, c.name, c.description, c.options, c.created_at, c.updated_at
+`
+
+func scanBotCommand(row pgx.Row) (*models.BotCommand, error) {
+	command := &models.BotCommand{}
+	err := row.Scan(
+		&command.ID,
+		&command.HallID,
+		&command.BotID,
+		&command.Name,
+		&command.Description,
+		&command.Options,
+		&command.CreatedAt,
+		&command.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return command, nil
+}
+
+func (r *botCommandRepository) ListHallBotCommands(ctx context.Context, db database.DBRunner, hallID uuid.UUID) ([]*models.BotCommand, error) {
+	query := `
+		SELECT ` + botCommandColumns + `
+		FROM bot_commands c
+		JOIN hall_members hm ON hm.hall_id = c.hall_id AND hm.user_id = c.bot_id
+		WHERE c.hall_id = $1
+		ORDER BY c.name ASC
+	`
+
+	rows, err := db.Query(ctx, query, hallID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commands := make([]*models.BotCommand, 0)
+	for rows.Next() {
+		command, err := scanBotCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+
+	return commands, rows.Err()
+}
+
+func (r *botCommandRepository) GetHallBotCommand(ctx context.Context, db database.DBRunner, hallID uuid.UUID, name string) (*models.BotCommand, error) {
+	query := `
+		SELECT ` + botCommandColumns + `
+		FROM bot_commands c
+		JOIN hall_members hm ON hm.hall_id = c.hall_id AND hm.user_id = c.bot_id
+		WHERE c.hall_id = $1 AND c.name = $2
+	`
+	return scanBotCommand(db.QueryRow(ctx, query, hallID, name))
+}
+
+func (r *botCommandRepository) CreateBotCommand(ctx context.Context, db database.DBRunner, command *models.BotCommand) (*models.BotCommand, error) {
+	query := `
+		WITH c AS (
+			INSERT INTO bot_commands (id, hall_id, bot_id, name, description, options)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING *
+		)
+		SELECT ` + botCommandColumns + ` FROM c
+	`
+
+	return scanBotCommand(db.QueryRow(ctx, query,
+		command.ID,
+		command.HallID,
+		command.BotID,
+		command.Name,
+		command.Description,
+		command.Options,
+	))
+}
+
+func (r *botCommandRepository) DeleteBotHallCommands(ctx context.Context, db database.DBRunner, hallID uuid.UUID, botID uuid.UUID) error {
+	_, err := db.Exec(ctx, `DELETE FROM bot_commands WHERE hall_id = $1 AND bot_id = $2`, hallID, botID)
+	return err
+}
+
+func (r *botCommandRepository) PruneLeftBotCommands(ctx context.Context, db database.DBRunner, hallID uuid.UUID) error {
+	query := `
+		DELETE FROM bot_commands c
+		WHERE c.hall_id = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM hall_members hm
+			WHERE hm.hall_id = c.hall_id AND hm.user_id = c.bot_id
+		  )
+	`
+	_, err := db.Exec(ctx, query, hallID)
+	return err
+}
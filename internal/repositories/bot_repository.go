@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IBotRepository interface {
+	// -------------- BOTS
+	CreateBot(ctx context.Context, db database.DBRunner, bot *models.User) (*models.User, error)
+	ListBotsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) ([]*models.User, error)
+	CountBotsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) (int, error)
+
+	// GetOwnedBot is pgx.ErrNoRows unless the bot exists and belongs to ownerID
+	GetOwnedBot(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, botID uuid.UUID) (*models.User, error)
+	DeleteOwnedBot(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, botID uuid.UUID) error
+
+	// -------------- TOKENS
+	CreateBotToken(ctx context.Context, db database.DBRunner, token *models.BotToken) error
+	DeleteBotTokens(ctx context.Context, db database.DBRunner, botID uuid.UUID) error
+	GetBotByTokenHash(ctx context.Context, db database.DBRunner, tokenHash []byte) (*models.User, error)
+
+	// TouchBotToken records a use, at most once a minute per token
+	TouchBotToken(ctx context.Context, db database.DBRunner, tokenHash []byte) error
+}
+
+type botRepository struct{}
+
+func NewBotRepository() IBotRepository {
+	return &botRepository{}
+}
+
+const botUserColumns = `
+	u.id, u.username, u.display_name, u.email, u.password_hash, u.description, u.phone_number,
+	u.avatar_url, u.avatar_thumbnail_url, u.friend_policy, u.email_verified_at, u.is_bot, u.bot_owner_id,
+	u.created_at, u.updated_at
+`
+
+func (r *botRepository) CreateBot(ctx context.Context, db database.DBRunner, bot *models.User) (*models.User, error) {
+	query := `
+		INSERT INTO users AS u (id, username, display_name, email, password_hash, description, is_bot, bot_owner_id)
+		VALUES ($1, $2, $3, $4, '', $5, true, $6)
+		RETURNING ` + botUserColumns
+
+	return scanUser(db.QueryRow(ctx, query,
+		bot.ID,
+		bot.Username,
+		bot.DisplayName,
+		bot.Email,
+		bot.Description,
+		bot.BotOwnerID,
+	))
+}
+
+func (r *botRepository) ListBotsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) ([]*models.User, error) {
+	query := `
+		SELECT ` + botUserColumns + `
+		FROM users u
+		WHERE u.is_bot AND u.bot_owner_id = $1
+		ORDER BY u.created_at ASC
+	`
+
+	rows, err := db.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bots := make([]*models.User, 0)
+	for rows.Next() {
+		bot, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		bots = append(bots, bot)
+	}
+
+	return bots, rows.Err()
+}
+
+func (r *botRepository) CountBotsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE is_bot AND bot_owner_id = $1`, ownerID).Scan(&count)
+	return count, err
+}
+
+func (r *botRepository) GetOwnedBot(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, botID uuid.UUID) (*models.User, error) {
+	query := `
+		SELECT ` + botUserColumns + `
+		FROM users u
+		WHERE u.id = $1 AND u.is_bot AND u.bot_owner_id = $2
+	`
+	return scanUser(db.QueryRow(ctx, query, botID, ownerID))
+}
+
+func (r *botRepository) DeleteOwnedBot(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, botID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `DELETE FROM users WHERE id = $1 AND is_bot AND bot_owner_id = $2`, botID, ownerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *botRepository) CreateBotToken(ctx context.Context, db database.DBRunner, token *models.BotToken) error {
+	_, err := db.Exec(ctx,
+		`INSERT INTO bot_tokens (id, bot_id, token_hash) VALUES ($1, $2, $3)`,
+		token.ID,
+		token.BotID,
+		token.TokenHash,
+	)
+	return err
+}
+
+func (r *botRepository) DeleteBotTokens(ctx context.Context, db database.DBRunner, botID uuid.UUID) error {
+	_, err := db.Exec(ctx, `DELETE FROM bot_tokens WHERE bot_id = $1`, botID)
+	return err
+}
+
+func (r *botRepository) GetBotByTokenHash(ctx context.Context, db database.DBRunner, tokenHash []byte) (*models.User, error) {
+	query := `
+		SELECT ` + botUserColumns + `
+		FROM bot_tokens t
+		INNER JOIN users u ON u.id = t.bot_id
+		WHERE t.token_hash = $1 AND u.is_bot
+	`
+	return scanUser(db.QueryRow(ctx, query, tokenHash))
+}
+
+func (r *botRepository) TouchBotToken(ctx context.Context, db database.DBRunner, tokenHash []byte) error {
+	query := `
+		UPDATE bot_tokens
+		SET last_used_at = now()
+		WHERE token_hash = $1
+		  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	_, err := db.Exec(ctx, query, tokenHash)
+	return err
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+// ICommandStateRepository keeps the short-lived state slash commands need:
+// open bot interactions and pending reminders
+type ICommandStateRepository interface {
+	SaveCommandInteraction(ctx context.Context, interaction *models.CommandInteraction, ttl time.Duration) error
+
+	// GetCommandInteraction is redis.Nil once the interaction expired
+	GetCommandInteraction(ctx context.Context, interactionID uuid.UUID) (*models.CommandInteraction, error)
+
+	AddReminder(ctx context.Context, reminder *models.Reminder) error
+	CountUserReminders(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// ClaimDueReminders leases up to limit reminders due by now until
+	// leaseUntil and returns them, a reminder is only ever leased to one
+	// caller at a time. One that is neither deleted nor rescheduled before the
+	// lease runs out is claimed again.
+	ClaimDueReminders(ctx context.Context, now time.Time, leaseUntil time.Time, limit int64) ([]*models.Reminder, error)
+
+	// DeleteReminder drops a delivered reminder
+	DeleteReminder(ctx context.Context, reminder *models.Reminder) error
+
+	// RescheduleReminder hands a leased reminder back to be claimed again at at
+	RescheduleReminder(ctx context.Context, reminderID uuid.UUID, at time.Time) error
+}
+
+type commandStateRepository struct {
+	client *redis.Client
+}
+
+func NewCommandStateRepository(client *redis.Client) ICommandStateRepository {
+	return &commandStateRepository{client: client}
+}
+
+const remindersDueKey = "commands:reminders"
+
+func commandInteractionKey(interactionID uuid.UUID) string {
+	return "commands:interaction:" + interactionID.String()
+}
+
+func reminderKey(reminderID string) string {
+	return "commands:reminder:" + reminderID
+}
+
+func userRemindersKey(userID uuid.UUID) string {
+	return "commands:reminders:user:" + userID.String()
+}
+
+func (r *commandStateRepository) SaveCommandInteraction(ctx context.Context, interaction *models.CommandInteraction, ttl time.Duration) error {
+	payload, err := json.Marshal(interaction)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, commandInteractionKey(interaction.ID), payload, ttl).Err()
+}
+
+func (r *commandStateRepository) GetCommandInteraction(ctx context.Context, interactionID uuid.UUID) (*models.CommandInteraction, error) {
+	payload, err := r.client.Get(ctx, commandInteractionKey(interactionID)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &models.CommandInteraction{}
+	if err := json.Unmarshal(payload, interaction); err != nil {
+		return nil, err
+	}
+	return interaction, nil
+}
+
+func (r *commandStateRepository) AddReminder(ctx context.Context, reminder *models.Reminder) error {
+	payload, err := json.Marshal(reminder)
+	if err != nil {
+		return err
+	}
+
+	id := reminder.ID.String()
+	due := float64(reminder.DueAt.Unix())
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, reminderKey(id), payload, time.Until(reminder.DueAt)+24*time.Hour)
+	pipe.ZAdd(ctx, remindersDueKey, redis.Z{Score: due, Member: id})
+	pipe.ZAdd(ctx, userRemindersKey(reminder.UserID), redis.Z{Score: due, Member: id})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *commandStateRepository) CountUserReminders(ctx context.Context, userID uuid.UUID) (int64, error) {
+	// reminders that already fired are dropped from the user's set once
+	// delivered, anything older than a day is left over from an expired payload
+	key := userRemindersKey(userID)
+	stale := strconv.FormatInt(time.Now().Add(-24*time.Hour).Unix(), 10)
+	if err := r.client.ZRemRangeByScore(ctx, key, "-inf", stale).Err(); err != nil {
+		return 0, err
+	}
+	return r.client.ZCard(ctx, key).Result()
+}
+
+// The due set doubles as the lease, a claimed reminder's score moves to when
+// its lease runs out so nobody else picks it up until then. Moving them in
+// one script keeps two instances from claiming the same reminder.
+var claimDueRemindersScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[1], ARGV[3], id)
+end
+return ids
+`)
+
+func (r *commandStateRepository) ClaimDueReminders(ctx context.Context, now time.Time, leaseUntil time.Time, limit int64) ([]*models.Reminder, error) {
+	ids, err := claimDueRemindersScript.Run(ctx, r.client, []string{remindersDueKey},
+		now.Unix(), limit, leaseUntil.Unix(),
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]*models.Reminder, 0, len(ids))
+	for _, id := range ids {
+		payload, err := r.client.Get(ctx, reminderKey(id)).Bytes()
+		if err != nil {
+			// the payload outlived its due time by a day, nothing to deliver
+			if err == redis.Nil {
+				r.client.ZRem(ctx, remindersDueKey, id)
+				continue
+			}
+			return reminders, err
+		}
+
+		reminder := &models.Reminder{}
+		if err := json.Unmarshal(payload, reminder); err != nil {
+			r.client.ZRem(ctx, remindersDueKey, id)
+			continue
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+func (r *commandStateRepository) DeleteReminder(ctx context.Context, reminder *models.Reminder) error {
+	id := reminder.ID.String()
+
+	pipe := r.client.TxPipeline()
+	pipe.ZRem(ctx, remindersDueKey, id)
+	pipe.Del(ctx, reminderKey(id))
+	pipe.ZRem(ctx, userRemindersKey(reminder.UserID), id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *commandStateRepository) RescheduleReminder(ctx context.Context, reminderID uuid.UUID, at time.Time) error {
+	return r.client.ZAdd(ctx, remindersDueKey, redis.Z{Score: float64(at.Unix()), Member: reminderID.String()}).Err()
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IEmailTokenRepository interface {
+	CreateEmailToken(ctx context.Context, db database.DBRunner, token *models.EmailToken) (*models.EmailToken, error)
+
+	// ConsumeEmailToken marks a live token used and returns it, pgx.ErrNoRows
+	// when it is unknown, expired or already spent.
+	ConsumeEmailToken(ctx context.Context, db database.DBRunner, purpose models.EmailTokenPurpose, tokenHash []byte) (*models.EmailToken, error)
+
+	// issuing a fresh token retires the ones still outstanding
+	InvalidateEmailTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID, purpose models.EmailTokenPurpose) error
+	GetLastEmailTokenIssuedAt(ctx context.Context, db database.DBRunner, userID uuid.UUID, purpose models.EmailTokenPurpose) (*time.Time, error)
+}
+
+type emailTokenRepository struct{}
+
+func NewEmailTokenRepository() IEmailTokenRepository {
+	return &emailTokenRepository{}
+}
+
+const emailTokenColumns = `
+	id, user_id, purpose, token_hash, email, expires_at, used_at, created_at
+`
+
+func scanEmailToken(row pgx.Row) (*models.EmailToken, error) {
+	t := &models.EmailToken{}
+	err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Purpose,
+		&t.TokenHash,
+		&t.Email,
+		&t.ExpiresAt,
+		&t.UsedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *emailTokenRepository) CreateEmailToken(ctx context.Context, db database.DBRunner, token *models.EmailToken) (*models.EmailToken, error) {
+	query := `
+		INSERT INTO email_tokens (id, user_id, purpose, token_hash, email, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + emailTokenColumns
+
+	return scanEmailToken(db.QueryRow(ctx, query,
+		token.ID,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.Email,
+		token.ExpiresAt,
+	))
+}
+
+func (r *emailTokenRepository) ConsumeEmailToken(ctx context.Context, db database.DBRunner, purpose models.EmailTokenPurpose, tokenHash []byte) (*models.EmailToken, error) {
+	query := `
+		UPDATE email_tokens
+		SET used_at = now()
+		WHERE token_hash = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+		  AND expires_at > now()
+		RETURNING ` + emailTokenColumns
+
+	return scanEmailToken(db.QueryRow(ctx, query, tokenHash, purpose))
+}
+
+func (r *emailTokenRepository) InvalidateEmailTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID, purpose models.EmailTokenPurpose) error {
+	query := `
+		UPDATE email_tokens
+		SET used_at = now()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`
+
+	_, err := db.Exec(ctx, query, userID, purpose)
+	return err
+}
+
+func (r *emailTokenRepository) GetLastEmailTokenIssuedAt(ctx context.Context, db database.DBRunner, userID uuid.UUID, purpose models.EmailTokenPurpose) (*time.Time, error) {
+	query := `
+		SELECT max(created_at)
+		FROM email_tokens
+		WHERE user_id = $1 AND purpose = $2
+	`
+
+	var issuedAt *time.Time
+	if err := db.QueryRow(ctx, query, userID, purpose).Scan(&issuedAt); err != nil {
+		return nil, err
+	}
+	return issuedAt, nil
+}
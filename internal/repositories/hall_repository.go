@@ -45,6 +45,10 @@ type IHallRepository interface {
 	// -------------- HALL PROFILE
 	UpdateHallProfile(ctx context.Context, db database.DBRunner, hallID uuid.UUID, fields map[string]any) (*models.Hall, error)
 
+	// -------------- HALL SECURITY
+	GetHallRequiresMod2FA(ctx context.Context, db database.DBRunner, hallID uuid.UUID) (bool, error)
+	SetHallRequiresMod2FA(ctx context.Context, db database.DBRunner, hallID uuid.UUID, required bool) error
+
 	// ------------- CHECK OPERATION
 	DoesHallExist(ctx context.Context, db database.DBRunner, hallID uuid.UUID) (bool, error)
 	IsUserHallMember(ctx context.Context, db database.DBRunner, hallID uuid.UUID, userID uuid.UUID) (bool, error)
@@ -242,6 +246,26 @@ func (r *hallRepository) GetHallOwnerID(ctx context.Context, db database.DBRunne
 	return ownerID, nil
 }
 
+func (r *hallRepository) GetHallRequiresMod2FA(ctx context.Context, db database.DBRunner, hallID uuid.UUID) (bool, error) {
+	var required bool
+	err := db.QueryRow(ctx, `SELECT require_mod_2fa FROM halls WHERE id = $1`, hallID).Scan(&required)
+	if err != nil {
+		return false, err
+	}
+	return required, nil
+}
+
+func (r *hallRepository) SetHallRequiresMod2FA(ctx context.Context, db database.DBRunner, hallID uuid.UUID, required bool) error {
+	tag, err := db.Exec(ctx, `UPDATE halls SET require_mod_2fa = $2, updated_at = now() WHERE id = $1`, hallID, required)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
 func (r *hallRepository) GetHallMemberByUserID(ctx context.Context, db database.DBRunner, hallID uuid.UUID, userID uuid.UUID) (*models.HallMember, error) {
 	query := `
 		SELECT id, hall_id, user_id, role_id, nickname, joined_at, created_at, updated_at
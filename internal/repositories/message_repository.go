@@ -25,8 +25,16 @@ type IMessageRepository interface {
 	GetMessagesByRoomID(ctx context.Context, db database.DBRunner, roomID uuid.UUID, limit int, offset int) ([]*models.Message, error)
 	GetMessages(ctx context.Context, db database.DBRunner, params *dto.MessageQueryParams) ([]*dto.MessageDetailed, error)
 
+	// GetLastMessageTimeByAuthor is when the author last wrote in the room,
+	// deleted messages included. pgx.ErrNoRows if they never did.
+	GetLastMessageTimeByAuthor(ctx context.Context, db database.DBRunner, roomID uuid.UUID, authorID uuid.UUID) (time.Time, error)
+
 	// Write
 	UpdateMessageContent(ctx context.Context, db database.DBRunner, messageID uuid.UUID, content string) (*models.Message, error)
+	// AddMessageRevision copies the message's current text into its history,
+	// it locks the row so call it in the transaction that edits the message
+	AddMessageRevision(ctx context.Context, db database.DBRunner, revisionID uuid.UUID, messageID uuid.UUID) error
+	GetMessageRevisions(ctx context.Context, db database.DBRunner, messageID uuid.UUID) ([]models.MessageRevision, error)
 	SoftDeleteMessage(ctx context.Context, db database.DBRunner, messageID uuid.UUID) error
 	UpdateMessage(ctx context.Context, db database.DBRunner, message *models.Message) (*models.Message, error)
 	DeleteMessage(ctx context.Context, db database.DBRunner, message *models.Message) error
@@ -35,6 +43,15 @@ type IMessageRepository interface {
 	AddReaction(ctx context.Context, db database.DBRunner, reactionID uuid.UUID, messageID uuid.UUID, userID uuid.UUID, emoji string) error
 	RemoveReaction(ctx context.Context, db database.DBRunner, messageID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)
 
+	// Pins
+	LockRoomPins(ctx context.Context, db database.DBRunner, roomID uuid.UUID) error
+	PinMessage(ctx context.Context, db database.DBRunner, roomID uuid.UUID, messageID uuid.UUID, pinnedBy uuid.UUID) (*models.PinnedMessage, error)
+	UnpinMessage(ctx context.Context, db database.DBRunner, messageID uuid.UUID) (bool, error)
+	GetPinnedMessage(ctx context.Context, db database.DBRunner, messageID uuid.UUID) (*models.PinnedMessage, error)
+	GetPinnedMessages(ctx context.Context, db database.DBRunner, roomID uuid.UUID) ([]models.PinnedMessage, error)
+	CountPinnedMessages(ctx context.Context, db database.DBRunner, roomID uuid.UUID) (int, error)
+	GetMessagesDetailedByIDs(ctx context.Context, db database.DBRunner, messageIDs []uuid.UUID) ([]*dto.MessageDetailed, error)
+
 	// Message Reads
 	MarkMessageRead(ctx context.Context, db database.DBRunner, roomID uuid.UUID, userID uuid.UUID, messageID uuid.UUID) (*models.MessageRead, error)
 	GetMessageRead(ctx context.Context, db database.DBRunner, roomID uuid.UUID, userID uuid.UUID) (*models.MessageRead, error)
@@ -50,18 +67,21 @@ func NewMessageRepository() IMessageRepository {
 
 func (r *messageRepository) CreateMessage(ctx context.Context, db database.DBRunner, message *models.Message) (*models.Message, error) {
 	query := `
-		INSERT INTO messages (id, room_id, author_id, content, mention_everyone)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, room_id, author_id, content, sent_at, edited_at, deleted_at, mention_everyone, created_at, updated_at
+		INSERT INTO messages (id, room_id, author_id, content, mention_everyone, webhook_id, author_name, author_avatar_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, room_id, author_id, content, sent_at, edited_at, deleted_at, mention_everyone, created_at, updated_at,
+			webhook_id, author_name, author_avatar_url
 	`
 	out := &models.Message{}
 	err := db.QueryRow(ctx, query,
 		message.ID, message.RoomID, message.AuthorID,
 		message.Content, message.MentionEveryone,
+		message.WebhookID, message.AuthorName, message.AuthorAvatarURL,
 	).Scan(
 		&out.ID, &out.RoomID, &out.AuthorID, &out.Content, &out.SentAt,
 		&out.EditedAt, &out.DeletedAt, &out.MentionEveryone,
 		&out.CreatedAt, &out.UpdatedAt,
+		&out.WebhookID, &out.AuthorName, &out.AuthorAvatarURL,
 	)
 	if err != nil {
 		return nil, err
@@ -130,6 +150,8 @@ func (r *messageRepository) GetMessageDetailed(ctx context.Context, db database.
 		SELECT
 			m.id, m.room_id, m.author_id, m.content, m.mention_everyone,
 			m.sent_at, m.edited_at, m.created_at, m.updated_at,
+			m.webhook_id, m.author_name, m.author_avatar_url,
+			(SELECT count(*) FROM message_revisions mr WHERE mr.message_id = m.id),
 
 			u.id, u.username, u.email, u.avatar_url,
 
@@ -203,7 +225,9 @@ func (r *messageRepository) GetMessages(ctx context.Context, db database.DBRunne
 	query := `
 		WITH target_messages AS (
 			SELECT m.id, m.room_id, m.author_id, m.content, m.mention_everyone,
-				   m.sent_at, m.edited_at, m.created_at, m.updated_at
+				   m.sent_at, m.edited_at, m.created_at, m.updated_at,
+				   m.webhook_id, m.author_name, m.author_avatar_url,
+				   (SELECT count(*) FROM message_revisions mr WHERE mr.message_id = m.id) AS revision_count
 			FROM messages m
 			WHERE m.room_id = $1
 			  AND m.deleted_at IS NULL
@@ -251,6 +275,7 @@ func (r *messageRepository) GetMessages(ctx context.Context, db database.DBRunne
 		SELECT
 			tm.id, tm.room_id, tm.author_id, tm.content, tm.mention_everyone,
 			tm.sent_at, tm.edited_at, tm.created_at, tm.updated_at,
+			tm.webhook_id, tm.author_name, tm.author_avatar_url, tm.revision_count,
 
 			u.id, u.username, u.email, u.avatar_url,
 
@@ -286,7 +311,9 @@ func (r *messageRepository) getMessagesAround(ctx context.Context, db database.D
 		WITH target_messages AS (
 			(
 				SELECT m.id, m.room_id, m.author_id, m.content, m.mention_everyone,
-					   m.sent_at, m.edited_at, m.created_at, m.updated_at
+					   m.sent_at, m.edited_at, m.created_at, m.updated_at,
+					   m.webhook_id, m.author_name, m.author_avatar_url,
+					   (SELECT count(*) FROM message_revisions mr WHERE mr.message_id = m.id) AS revision_count
 				FROM messages m
 				WHERE m.room_id = $1
 				  AND m.deleted_at IS NULL
@@ -303,7 +330,9 @@ func (r *messageRepository) getMessagesAround(ctx context.Context, db database.D
 			UNION ALL
 			(
 				SELECT m.id, m.room_id, m.author_id, m.content, m.mention_everyone,
-					   m.sent_at, m.edited_at, m.created_at, m.updated_at
+					   m.sent_at, m.edited_at, m.created_at, m.updated_at,
+					   m.webhook_id, m.author_name, m.author_avatar_url,
+					   (SELECT count(*) FROM message_revisions mr WHERE mr.message_id = m.id) AS revision_count
 				FROM messages m
 				WHERE m.room_id = $1
 				  AND m.deleted_at IS NULL
@@ -321,6 +350,7 @@ func (r *messageRepository) getMessagesAround(ctx context.Context, db database.D
 		SELECT
 			tm.id, tm.room_id, tm.author_id, tm.content, tm.mention_everyone,
 			tm.sent_at, tm.edited_at, tm.created_at, tm.updated_at,
+			tm.webhook_id, tm.author_name, tm.author_avatar_url, tm.revision_count,
 
 			u.id, u.username, u.email, u.avatar_url,
 
@@ -369,6 +399,56 @@ func (r *messageRepository) UpdateMessageContent(ctx context.Context, db databas
 	return out, nil
 }
 
+// ── AddMessageRevision ────────────────────────────────────────────────────────
+// FOR UPDATE makes a concurrent edit wait, it then records the text the
+// first one wrote instead of both saving the same original.
+
+func (r *messageRepository) AddMessageRevision(ctx context.Context, db database.DBRunner, revisionID uuid.UUID, messageID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `
+		INSERT INTO message_revisions (id, message_id, content, written_at)
+		SELECT $1, id, content, COALESCE(edited_at, sent_at)
+		FROM (
+			SELECT id, content, edited_at, sent_at
+			FROM messages
+			WHERE id = $2 AND deleted_at IS NULL
+			FOR UPDATE
+		) m
+	`, revisionID, messageID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ── GetMessageRevisions ───────────────────────────────────────────────────────
+// Oldest first, the message itself holds the newest text.
+
+func (r *messageRepository) GetMessageRevisions(ctx context.Context, db database.DBRunner, messageID uuid.UUID) ([]models.MessageRevision, error) {
+	rows, err := db.Query(ctx, `
+		SELECT id, message_id, content, written_at, replaced_at
+		FROM message_revisions
+		WHERE message_id = $1
+		ORDER BY replaced_at ASC, written_at ASC
+	`, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.MessageRevision{}
+	for rows.Next() {
+		var revision models.MessageRevision
+		if err := rows.Scan(&revision.ID, &revision.MessageID, &revision.Content, &revision.WrittenAt, &revision.ReplacedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
 // ── SoftDeleteMessage ─────────────────────────────────────────────────────────
 
 func (r *messageRepository) SoftDeleteMessage(ctx context.Context, db database.DBRunner, messageID uuid.UUID) error {
@@ -403,6 +483,132 @@ func (r *messageRepository) RemoveReaction(ctx context.Context, db database.DBRu
 	return tag.RowsAffected() == 1, nil
 }
 
+// ── LockRoomPins ──────────────────────────────────────────────────────────────
+// Locks the room row so concurrent pins in one room count one at a time.
+
+func (r *messageRepository) LockRoomPins(ctx context.Context, db database.DBRunner, roomID uuid.UUID) error {
+	var id uuid.UUID
+	return db.QueryRow(ctx, `SELECT id FROM rooms WHERE id = $1 FOR UPDATE`, roomID).Scan(&id)
+}
+
+// ── PinMessage ────────────────────────────────────────────────────────────────
+// pgx.ErrNoRows when the message was pinned already.
+
+func (r *messageRepository) PinMessage(ctx context.Context, db database.DBRunner, roomID uuid.UUID, messageID uuid.UUID, pinnedBy uuid.UUID) (*models.PinnedMessage, error) {
+	pin := &models.PinnedMessage{}
+	err := db.QueryRow(ctx, `
+		INSERT INTO pinned_messages (message_id, room_id, pinned_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (message_id) DO NOTHING
+		RETURNING message_id, room_id, pinned_by, pinned_at
+	`, messageID, roomID, pinnedBy).Scan(&pin.MessageID, &pin.RoomID, &pin.PinnedBy, &pin.PinnedAt)
+	if err != nil {
+		return nil, err
+	}
+	return pin, nil
+}
+
+// ── UnpinMessage ──────────────────────────────────────────────────────────────
+
+func (r *messageRepository) UnpinMessage(ctx context.Context, db database.DBRunner, messageID uuid.UUID) (bool, error) {
+	tag, err := db.Exec(ctx, `DELETE FROM pinned_messages WHERE message_id = $1`, messageID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ── GetPinnedMessage ──────────────────────────────────────────────────────────
+
+func (r *messageRepository) GetPinnedMessage(ctx context.Context, db database.DBRunner, messageID uuid.UUID) (*models.PinnedMessage, error) {
+	pin := &models.PinnedMessage{}
+	err := db.QueryRow(ctx, `
+		SELECT message_id, room_id, pinned_by, pinned_at
+		FROM pinned_messages
+		WHERE message_id = $1
+	`, messageID).Scan(&pin.MessageID, &pin.RoomID, &pin.PinnedBy, &pin.PinnedAt)
+	if err != nil {
+		return nil, err
+	}
+	return pin, nil
+}
+
+// ── GetPinnedMessages ─────────────────────────────────────────────────────────
+// Latest pin first.
+
+func (r *messageRepository) GetPinnedMessages(ctx context.Context, db database.DBRunner, roomID uuid.UUID) ([]models.PinnedMessage, error) {
+	rows, err := db.Query(ctx, `
+		SELECT message_id, room_id, pinned_by, pinned_at
+		FROM pinned_messages
+		WHERE room_id = $1
+		ORDER BY pinned_at DESC, message_id DESC
+	`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pins := []models.PinnedMessage{}
+	for rows.Next() {
+		var pin models.PinnedMessage
+		if err := rows.Scan(&pin.MessageID, &pin.RoomID, &pin.PinnedBy, &pin.PinnedAt); err != nil {
+			return nil, err
+		}
+		pins = append(pins, pin)
+	}
+	return pins, rows.Err()
+}
+
+// ── CountPinnedMessages ───────────────────────────────────────────────────────
+
+func (r *messageRepository) CountPinnedMessages(ctx context.Context, db database.DBRunner, roomID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT count(*) FROM pinned_messages WHERE room_id = $1`, roomID).Scan(&count)
+	return count, err
+}
+
+// ── GetMessagesDetailedByIDs ──────────────────────────────────────────────────
+// Like GetMessageDetailed for a set of messages, deleted ones are left out.
+// Ordered by sent_at, callers that need another order sort themselves.
+
+func (r *messageRepository) GetMessagesDetailedByIDs(ctx context.Context, db database.DBRunner, messageIDs []uuid.UUID) ([]*dto.MessageDetailed, error) {
+	if len(messageIDs) == 0 {
+		return []*dto.MessageDetailed{}, nil
+	}
+
+	query := `
+		SELECT
+			m.id, m.room_id, m.author_id, m.content, m.mention_everyone,
+			m.sent_at, m.edited_at, m.created_at, m.updated_at,
+			m.webhook_id, m.author_name, m.author_avatar_url,
+			(SELECT count(*) FROM message_revisions mr WHERE mr.message_id = m.id),
+
+			u.id, u.username, u.email, u.avatar_url,
+
+			a.id, a.message_id, a.url, a.file_name, a.file_type, a.created_at, a.updated_at,
+
+			r.id, r.emoji, r.user_id, ru.username, ru.avatar_url,
+
+			mu.id, mu.username, mu.email, mu.avatar_url
+		FROM messages m
+		INNER JOIN users u ON m.author_id = u.id
+		LEFT JOIN attachments a ON m.id = a.message_id
+		LEFT JOIN reactions r ON m.id = r.message_id
+		LEFT JOIN users ru ON r.user_id = ru.id
+		LEFT JOIN message_mentions mm ON m.id = mm.message_id
+		LEFT JOIN users mu ON mm.user_id = mu.id
+		WHERE m.id = ANY($1) AND m.deleted_at IS NULL
+		ORDER BY m.sent_at ASC, m.id ASC
+	`
+	rows, err := db.Query(ctx, query, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanMessagesWithDetails(rows)
+}
+
 // ── scanMessagesWithDetails ───────────────────────────────────────────────────
 // Collapses the JOIN-expanded rows (one row per attachment per reaction) back into
 // MessageDetailed structs, deduplicating attachments and grouping reactions by emoji.
@@ -416,8 +622,9 @@ func (r *messageRepository) scanMessagesWithDetails(rows interface {
 
 	for rows.Next() {
 		var (
-			message models.Message
-			author  dto.UserBasic
+			message       models.Message
+			revisionCount int
+			author        dto.UserBasic
 
 			attachmentID    *uuid.UUID
 			attachmentMsgID *uuid.UUID
@@ -442,6 +649,8 @@ func (r *messageRepository) scanMessagesWithDetails(rows interface {
 		if err := rows.Scan(
 			&message.ID, &message.RoomID, &message.AuthorID, &message.Content, &message.MentionEveryone,
 			&message.SentAt, &message.EditedAt, &message.CreatedAt, &message.UpdatedAt,
+			&message.WebhookID, &message.AuthorName, &message.AuthorAvatarURL,
+			&revisionCount,
 
 			&author.ID, &author.Username, &author.Email, &author.AvatarURL,
 
@@ -462,6 +671,8 @@ func (r *messageRepository) scanMessagesWithDetails(rows interface {
 				Attachments: []dto.AttachmentResponseMinimal{},
 				Reactions:   []dto.ReactionGroup{},
 				Mentions:    []dto.UserBasic{},
+
+				RevisionCount: revisionCount,
 			}
 			messageMap[message.ID] = msgDetailed
 			messageOrder = append(messageOrder, message.ID)
@@ -646,3 +857,17 @@ func (r *messageRepository) GetMessageRead(
 
 	return out, nil
 }
+
+func (r *messageRepository) GetLastMessageTimeByAuthor(ctx context.Context, db database.DBRunner, roomID uuid.UUID, authorID uuid.UUID) (time.Time, error) {
+	query := `
+		SELECT created_at
+		FROM messages
+		WHERE room_id = $1 AND author_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var last time.Time
+	err := db.QueryRow(ctx, query, roomID, authorID).Scan(&last)
+	return last, err
+}
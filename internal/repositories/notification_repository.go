@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/notification"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type INotificationRepository interface {
+	// CreateNotifications writes the whole fan out in one statement, an
+	// @everyone in a big room would otherwise be thousands of round trips
+	CreateNotifications(ctx context.Context, db database.DBRunner, notifications []*models.Notification) ([]*models.Notification, error)
+
+	// Read
+	ListNotifications(ctx context.Context, db database.DBRunner, userID uuid.UUID, before *uuid.UUID, limit int, unreadOnly bool) ([]*dto.NotificationRes, error)
+	CountUnreadNotifications(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error)
+
+	// Write
+	MarkNotificationRead(ctx context.Context, db database.DBRunner, userID uuid.UUID, notificationID uuid.UUID) error
+	MarkAllNotificationsRead(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int64, error)
+	DeleteNotification(ctx context.Context, db database.DBRunner, userID uuid.UUID, notificationID uuid.UUID) error
+	ClearNotifications(ctx context.Context, db database.DBRunner, userID uuid.UUID) error
+}
+
+type notificationRepository struct{}
+
+func NewNotificationRepository() INotificationRepository {
+	return &notificationRepository{}
+}
+
+func scanNotification(row pgx.Row) (*models.Notification, error) {
+	n := &models.Notification{}
+	err := row.Scan(
+		&n.ID,
+		&n.UserID,
+		&n.Type,
+		&n.ActorID,
+		&n.HallID,
+		&n.RoomID,
+		&n.MessageID,
+		&n.ReferenceID,
+		&n.Preview,
+		&n.ReadAt,
+		&n.CreatedAt,
+		&n.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// nullUUID is how optional ids go into an array parameter, pgx can't encode
+// a nil *uuid.UUID inside a slice
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func (r *notificationRepository) CreateNotifications(ctx context.Context, db database.DBRunner, notifications []*models.Notification) ([]*models.Notification, error) {
+	if len(notifications) == 0 {
+		return []*models.Notification{}, nil
+	}
+
+	var (
+		ids          = make([]uuid.UUID, len(notifications))
+		userIDs      = make([]uuid.UUID, len(notifications))
+		types        = make([]string, len(notifications))
+		actorIDs     = make([]uuid.NullUUID, len(notifications))
+		hallIDs      = make([]uuid.NullUUID, len(notifications))
+		roomIDs      = make([]uuid.NullUUID, len(notifications))
+		messageIDs   = make([]uuid.NullUUID, len(notifications))
+		referenceIDs = make([]uuid.NullUUID, len(notifications))
+		previews     = make([]*string, len(notifications))
+	)
+	for i, n := range notifications {
+		ids[i] = n.ID
+		userIDs[i] = n.UserID
+		types[i] = string(n.Type)
+		actorIDs[i] = nullUUID(n.ActorID)
+		hallIDs[i] = nullUUID(n.HallID)
+		roomIDs[i] = nullUUID(n.RoomID)
+		messageIDs[i] = nullUUID(n.MessageID)
+		referenceIDs[i] = nullUUID(n.ReferenceID)
+		previews[i] = n.Preview
+	}
+
+	query := `
+		INSERT INTO notifications (
+			id, user_id, type, actor_id, hall_id, room_id, message_id, reference_id, preview
+		)
+		SELECT t.id, t.user_id, t.type::notification_type, t.actor_id, t.hall_id, t.room_id, t.message_id, t.reference_id, t.preview
+		FROM unnest(
+			$1::uuid[], $2::uuid[], $3::text[], $4::uuid[], $5::uuid[], $6::uuid[], $7::uuid[], $8::uuid[], $9::text[]
+		) AS t(id, user_id, type, actor_id, hall_id, room_id, message_id, reference_id, preview)
+		RETURNING id, user_id, type, actor_id, hall_id, room_id, message_id, reference_id, preview, read_at, created_at, updated_at
+	`
+
+	rows, err := db.Query(ctx, query, ids, userIDs, types, actorIDs, hallIDs, roomIDs, messageIDs, referenceIDs, previews)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]*models.Notification, 0, len(notifications))
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *notificationRepository) ListNotifications(ctx context.Context, db database.DBRunner, userID uuid.UUID, before *uuid.UUID, limit int, unreadOnly bool) ([]*dto.NotificationRes, error) {
+	query := `
+		SELECT
+			n.id, n.user_id, n.type,
+			n.hall_id, n.room_id, n.message_id, n.reference_id, n.preview,
+			n.read_at, n.created_at,
+			u.id, u.username, u.display_name, u.avatar_url
+		FROM notifications n
+		LEFT JOIN users u ON u.id = n.actor_id
+		WHERE n.user_id = $1
+		  AND ($2::boolean = false OR n.read_at IS NULL)
+		  AND (
+			$3::uuid IS NULL
+			OR (n.created_at, n.id) < (
+				SELECT c.created_at, c.id FROM notifications c WHERE c.id = $3 AND c.user_id = $1
+			)
+		  )
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $4
+	`
+
+	rows, err := db.Query(ctx, query, userID, unreadOnly, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*dto.NotificationRes
+	for rows.Next() {
+		var (
+			n           dto.NotificationRes
+			actorID     *uuid.UUID
+			actorName   *string
+			actorDName  *string
+			actorAvatar *string
+		)
+
+		if err := rows.Scan(
+			&n.ID, &n.UserID, &n.Type,
+			&n.HallID, &n.RoomID, &n.MessageID, &n.ReferenceID, &n.Preview,
+			&n.ReadAt, &n.CreatedAt,
+			&actorID, &actorName, &actorDName, &actorAvatar,
+		); err != nil {
+			return nil, err
+		}
+
+		if actorID != nil {
+			n.Actor = &dto.NotificationActor{
+				ID:        *actorID,
+				AvatarURL: actorAvatar,
+			}
+			if actorName != nil {
+				n.Actor.Username = *actorName
+			}
+			if actorDName != nil {
+				n.Actor.DisplayName = *actorDName
+			}
+		}
+
+		list = append(list, &n)
+	}
+
+	return list, rows.Err()
+}
+
+func (r *notificationRepository) CountUnreadNotifications(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	var count int
+	if err := db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *notificationRepository) MarkNotificationRead(ctx context.Context, db database.DBRunner, userID uuid.UUID, notificationID uuid.UUID) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND user_id = $2
+	`
+
+	tag, err := db.Exec(ctx, query, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllNotificationsRead(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = now()
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+
+	tag, err := db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *notificationRepository) DeleteNotification(ctx context.Context, db database.DBRunner, userID uuid.UUID, notificationID uuid.UUID) error {
+	query := `DELETE FROM notifications WHERE id = $1 AND user_id = $2`
+
+	tag, err := db.Exec(ctx, query, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *notificationRepository) ClearNotifications(ctx context.Context, db database.DBRunner, userID uuid.UUID) error {
+	query := `DELETE FROM notifications WHERE user_id = $1`
+
+	_, err := db.Exec(ctx, query, userID)
+	return err
+}
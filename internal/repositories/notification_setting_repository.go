@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/notification"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type INotificationSettingRepository interface {
+	UpsertNotificationSetting(ctx context.Context, db database.DBRunner, setting *models.NotificationSetting) (*models.NotificationSetting, error)
+	DeleteNotificationSetting(ctx context.Context, db database.DBRunner, userID uuid.UUID, scope models.NotificationScope, scopeID uuid.UUID) error
+	ListUserNotificationSettings(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.NotificationSetting, error)
+
+	// every hall/floor/room row that applies to roomID, grouped per user
+	GetRoomNotificationSettings(ctx context.Context, db database.DBRunner, roomID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID][]*models.NotificationSetting, error)
+
+	// Badges
+	ListRoomUnreadCounts(ctx context.Context, db database.DBRunner, userID uuid.UUID, roomIDs []uuid.UUID) ([]*dto.RoomUnreadCount, error)
+}
+
+type notificationSettingRepository struct{}
+
+func NewNotificationSettingRepository() INotificationSettingRepository {
+	return &notificationSettingRepository{}
+}
+
+const notificationSettingColumns = `
+	id, user_id, scope, scope_id, hall_id, level, suppress_everyone, muted_until, created_at, updated_at
+`
+
+func scanNotificationSetting(row pgx.Row) (*models.NotificationSetting, error) {
+	s := &models.NotificationSetting{}
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Scope,
+		&s.ScopeID,
+		&s.HallID,
+		&s.Level,
+		&s.SuppressEveryone,
+		&s.MutedUntil,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *notificationSettingRepository) UpsertNotificationSetting(ctx context.Context, db database.DBRunner, setting *models.NotificationSetting) (*models.NotificationSetting, error) {
+	query := `
+		INSERT INTO notification_settings (
+			id, user_id, scope, scope_id, hall_id, level, suppress_everyone, muted_until
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, scope, scope_id)
+		DO UPDATE SET
+			hall_id = EXCLUDED.hall_id,
+			level = EXCLUDED.level,
+			suppress_everyone = EXCLUDED.suppress_everyone,
+			muted_until = EXCLUDED.muted_until
+		RETURNING ` + notificationSettingColumns
+
+	return scanNotificationSetting(db.QueryRow(ctx, query,
+		setting.ID,
+		setting.UserID,
+		setting.Scope,
+		setting.ScopeID,
+		setting.HallID,
+		setting.Level,
+		setting.SuppressEveryone,
+		setting.MutedUntil,
+	))
+}
+
+func (r *notificationSettingRepository) DeleteNotificationSetting(ctx context.Context, db database.DBRunner, userID uuid.UUID, scope models.NotificationScope, scopeID uuid.UUID) error {
+	query := `
+		DELETE FROM notification_settings
+		WHERE user_id = $1 AND scope = $2 AND scope_id = $3
+	`
+
+	tag, err := db.Exec(ctx, query, userID, scope, scopeID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *notificationSettingRepository) ListUserNotificationSettings(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.NotificationSetting, error) {
+	query := `
+		SELECT ` + notificationSettingColumns + `
+		FROM notification_settings
+		WHERE user_id = $1
+		ORDER BY hall_id, scope, created_at
+	`
+
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make([]*models.NotificationSetting, 0)
+	for rows.Next() {
+		s, err := scanNotificationSetting(rows)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+
+	return settings, rows.Err()
+}
+
+func (r *notificationSettingRepository) GetRoomNotificationSettings(ctx context.Context, db database.DBRunner, roomID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID][]*models.NotificationSetting, error) {
+	query := `
+		SELECT ns.id, ns.user_id, ns.scope, ns.scope_id, ns.hall_id, ns.level,
+		       ns.suppress_everyone, ns.muted_until, ns.created_at, ns.updated_at
+		FROM notification_settings ns
+		JOIN rooms r ON r.id = $1
+		WHERE ns.user_id = ANY($2)
+		  AND (
+		        (ns.scope = 'room'  AND ns.scope_id = r.id)
+		     OR (ns.scope = 'floor' AND ns.scope_id = r.floor_id)
+		     OR (ns.scope = 'hall'  AND ns.scope_id = r.hall_id)
+		  )
+	`
+
+	rows, err := db.Query(ctx, query, roomID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[uuid.UUID][]*models.NotificationSetting)
+	for rows.Next() {
+		s, err := scanNotificationSetting(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[s.UserID] = append(out[s.UserID], s)
+	}
+
+	return out, rows.Err()
+}
+
+// ListRoomUnreadCounts tallies messages newer than the user's read marker in
+// each room. Rooms without anything unread are left out.
+func (r *notificationSettingRepository) ListRoomUnreadCounts(ctx context.Context, db database.DBRunner, userID uuid.UUID, roomIDs []uuid.UUID) ([]*dto.RoomUnreadCount, error) {
+	query := `
+		SELECT
+			r.id, r.hall_id, r.floor_id,
+			COUNT(m.id) AS unread_count,
+			COUNT(m.id) FILTER (WHERE mm.user_id IS NOT NULL) AS mention_count,
+			COUNT(m.id) FILTER (WHERE m.mention_everyone AND mm.user_id IS NULL) AS everyone_count
+		FROM rooms r
+		LEFT JOIN message_reads mr ON mr.room_id = r.id AND mr.user_id = $1
+		LEFT JOIN messages lm ON lm.id = mr.message_id
+		JOIN messages m
+		  ON m.room_id = r.id
+		 AND m.deleted_at IS NULL
+		 AND m.author_id <> $1
+		 AND (lm.sent_at IS NULL OR m.sent_at > lm.sent_at)
+		LEFT JOIN message_mentions mm ON mm.message_id = m.id AND mm.user_id = $1
+		WHERE r.id = ANY($2)
+		GROUP BY r.id, r.hall_id, r.floor_id
+	`
+
+	rows, err := db.Query(ctx, query, userID, roomIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]*dto.RoomUnreadCount, 0)
+	for rows.Next() {
+		c := &dto.RoomUnreadCount{}
+		if err := rows.Scan(
+			&c.RoomID,
+			&c.HallID,
+			&c.FloorID,
+			&c.UnreadCount,
+			&c.MentionCount,
+			&c.EveryoneCount,
+		); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IOAuthCodeRepository interface {
+	CreateOAuthCode(ctx context.Context, codeHash []byte, code *models.OAuthCode, ttl time.Duration) error
+
+	// ConsumeOAuthCode reads and deletes in one step, a code is only ever
+	// redeemed once. redis.Nil when it is unknown, expired or already used.
+	ConsumeOAuthCode(ctx context.Context, codeHash []byte) (*models.OAuthCode, error)
+}
+
+type oauthCodeRepository struct {
+	client *redis.Client
+}
+
+func NewOAuthCodeRepository(client *redis.Client) IOAuthCodeRepository {
+	return &oauthCodeRepository{client: client}
+}
+
+func oauthCodeKey(codeHash []byte) string {
+	return "oauth:code:" + hex.EncodeToString(codeHash)
+}
+
+func (r *oauthCodeRepository) CreateOAuthCode(ctx context.Context, codeHash []byte, code *models.OAuthCode, ttl time.Duration) error {
+	payload, err := json.Marshal(code)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, oauthCodeKey(codeHash), payload, ttl).Err()
+}
+
+func (r *oauthCodeRepository) ConsumeOAuthCode(ctx context.Context, codeHash []byte) (*models.OAuthCode, error) {
+	payload, err := r.client.GetDel(ctx, oauthCodeKey(codeHash)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	code := &models.OAuthCode{}
+	if err := json.Unmarshal(payload, code); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
@@ -0,0 +1,351 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IOAuthRepository interface {
+	// -------------- APPS
+	CreateOAuthApp(ctx context.Context, db database.DBRunner, app *models.OAuthApp) (*models.OAuthApp, error)
+	GetOAuthApp(ctx context.Context, db database.DBRunner, appID uuid.UUID) (*models.OAuthApp, error)
+	ListOAuthAppsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) ([]*models.OAuthApp, error)
+	CountOAuthAppsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) (int, error)
+
+	// UpdateOwnedOAuthApp and DeleteOwnedOAuthApp are pgx.ErrNoRows unless
+	// the app belongs to ownerID
+	UpdateOwnedOAuthApp(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, appID uuid.UUID, fields map[string]any) (*models.OAuthApp, error)
+	DeleteOwnedOAuthApp(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, appID uuid.UUID) error
+
+	// -------------- TOKENS
+	CreateOAuthToken(ctx context.Context, db database.DBRunner, token *models.OAuthToken) error
+
+	// GetActiveOAuthToken looks a token up by its access hash and
+	// GetRefreshableOAuthToken by its refresh hash, both are pgx.ErrNoRows once
+	// that token expired
+	GetActiveOAuthToken(ctx context.Context, db database.DBRunner, accessTokenHash []byte) (*models.OAuthToken, error)
+	GetRefreshableOAuthToken(ctx context.Context, db database.DBRunner, refreshTokenHash []byte) (*models.OAuthToken, error)
+
+	// RotateOAuthToken swaps in fresh hashes, pgx.ErrNoRows when the refresh
+	// token was already used, so a replayed one can't refresh twice
+	RotateOAuthToken(ctx context.Context, db database.DBRunner, tokenID uuid.UUID, oldRefreshHash []byte, next *models.OAuthToken) error
+
+	// DeleteOAuthTokenByHash revokes the grant either token belongs to
+	DeleteOAuthTokenByHash(ctx context.Context, db database.DBRunner, appID uuid.UUID, tokenHash []byte) error
+	DeleteUserAppTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID, appID uuid.UUID) error
+	PruneExpiredOAuthTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID) error
+
+	// TouchOAuthToken records a use, at most once a minute per token
+	TouchOAuthToken(ctx context.Context, db database.DBRunner, tokenID uuid.UUID) error
+
+	// -------------- AUTHORIZATIONS
+	ListUserAuthorizations(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.OAuthAuthorization, error)
+
+	// GetUserAppScopes is every scope the user currently grants the app, empty
+	// when they never authorized it
+	GetUserAppScopes(ctx context.Context, db database.DBRunner, userID uuid.UUID, appID uuid.UUID) ([]string, error)
+}
+
+type oauthRepository struct{}
+
+func NewOAuthRepository() IOAuthRepository {
+	return &oauthRepository{}
+}
+
+const oauthAppColumns = `
+	id, owner_id, name, description, redirect_uris, client_secret_hash, created_at, updated_at
+`
+
+const oauthTokenColumns = `
+	id, app_id, user_id, access_token_hash, refresh_token_hash, scopes,
+	access_expires_at, refresh_expires_at, last_used_at, created_at
+`
+
+func scanOAuthApp(row pgx.Row) (*models.OAuthApp, error) {
+	app := &models.OAuthApp{}
+	err := row.Scan(
+		&app.ID,
+		&app.OwnerID,
+		&app.Name,
+		&app.Description,
+		&app.RedirectURIs,
+		&app.ClientSecretHash,
+		&app.CreatedAt,
+		&app.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func scanOAuthToken(row pgx.Row) (*models.OAuthToken, error) {
+	token := &models.OAuthToken{}
+	err := row.Scan(
+		&token.ID,
+		&token.AppID,
+		&token.UserID,
+		&token.AccessTokenHash,
+		&token.RefreshTokenHash,
+		&token.Scopes,
+		&token.AccessExpiresAt,
+		&token.RefreshExpiresAt,
+		&token.LastUsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// ── APPS ──────────────────────────────────────────────────────────────────────
+
+func (r *oauthRepository) CreateOAuthApp(ctx context.Context, db database.DBRunner, app *models.OAuthApp) (*models.OAuthApp, error) {
+	query := `
+		INSERT INTO oauth_apps (id, owner_id, name, description, redirect_uris, client_secret_hash)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + oauthAppColumns
+
+	return scanOAuthApp(db.QueryRow(ctx, query,
+		app.ID,
+		app.OwnerID,
+		app.Name,
+		app.Description,
+		app.RedirectURIs,
+		app.ClientSecretHash,
+	))
+}
+
+func (r *oauthRepository) GetOAuthApp(ctx context.Context, db database.DBRunner, appID uuid.UUID) (*models.OAuthApp, error) {
+	query := `SELECT ` + oauthAppColumns + ` FROM oauth_apps WHERE id = $1`
+	return scanOAuthApp(db.QueryRow(ctx, query, appID))
+}
+
+func (r *oauthRepository) ListOAuthAppsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) ([]*models.OAuthApp, error) {
+	query := `
+		SELECT ` + oauthAppColumns + `
+		FROM oauth_apps
+		WHERE owner_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := db.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apps := make([]*models.OAuthApp, 0)
+	for rows.Next() {
+		app, err := scanOAuthApp(rows)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, app)
+	}
+
+	return apps, rows.Err()
+}
+
+func (r *oauthRepository) CountOAuthAppsByOwner(ctx context.Context, db database.DBRunner, ownerID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM oauth_apps WHERE owner_id = $1`, ownerID).Scan(&count)
+	return count, err
+}
+
+func (r *oauthRepository) UpdateOwnedOAuthApp(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, appID uuid.UUID, fields map[string]any) (*models.OAuthApp, error) {
+
+	// Allowed columns are picked by the service
+	setClauses := make([]string, 0, len(fields)+1)
+	args := make([]any, 0, len(fields)+2)
+
+	i := 1
+	for col, val := range fields {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", col, i))
+		args = append(args, val)
+		i++
+	}
+	setClauses = append(setClauses, "updated_at = now()")
+	args = append(args, appID, ownerID)
+
+	query := fmt.Sprintf(`
+		UPDATE oauth_apps
+		SET %s
+		WHERE id = $%d AND owner_id = $%d
+		RETURNING `+oauthAppColumns, strings.Join(setClauses, ", "), i, i+1)
+
+	return scanOAuthApp(db.QueryRow(ctx, query, args...))
+}
+
+func (r *oauthRepository) DeleteOwnedOAuthApp(ctx context.Context, db database.DBRunner, ownerID uuid.UUID, appID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `DELETE FROM oauth_apps WHERE id = $1 AND owner_id = $2`, appID, ownerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ── TOKENS ────────────────────────────────────────────────────────────────────
+
+func (r *oauthRepository) CreateOAuthToken(ctx context.Context, db database.DBRunner, token *models.OAuthToken) error {
+	query := `
+		INSERT INTO oauth_tokens (
+			id, app_id, user_id, access_token_hash, refresh_token_hash, scopes,
+			access_expires_at, refresh_expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := db.Exec(ctx, query,
+		token.ID,
+		token.AppID,
+		token.UserID,
+		token.AccessTokenHash,
+		token.RefreshTokenHash,
+		token.Scopes,
+		token.AccessExpiresAt,
+		token.RefreshExpiresAt,
+	)
+	return err
+}
+
+func (r *oauthRepository) GetActiveOAuthToken(ctx context.Context, db database.DBRunner, accessTokenHash []byte) (*models.OAuthToken, error) {
+	query := `
+		SELECT ` + oauthTokenColumns + `
+		FROM oauth_tokens
+		WHERE access_token_hash = $1 AND access_expires_at > now()
+	`
+	return scanOAuthToken(db.QueryRow(ctx, query, accessTokenHash))
+}
+
+func (r *oauthRepository) GetRefreshableOAuthToken(ctx context.Context, db database.DBRunner, refreshTokenHash []byte) (*models.OAuthToken, error) {
+	query := `
+		SELECT ` + oauthTokenColumns + `
+		FROM oauth_tokens
+		WHERE refresh_token_hash = $1 AND refresh_expires_at > now()
+	`
+	return scanOAuthToken(db.QueryRow(ctx, query, refreshTokenHash))
+}
+
+func (r *oauthRepository) RotateOAuthToken(ctx context.Context, db database.DBRunner, tokenID uuid.UUID, oldRefreshHash []byte, next *models.OAuthToken) error {
+	query := `
+		UPDATE oauth_tokens
+		SET access_token_hash = $3,
+		    refresh_token_hash = $4,
+		    access_expires_at = $5,
+		    refresh_expires_at = $6
+		WHERE id = $1 AND refresh_token_hash = $2
+	`
+	tag, err := db.Exec(ctx, query,
+		tokenID,
+		oldRefreshHash,
+		next.AccessTokenHash,
+		next.RefreshTokenHash,
+		next.AccessExpiresAt,
+		next.RefreshExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *oauthRepository) DeleteOAuthTokenByHash(ctx context.Context, db database.DBRunner, appID uuid.UUID, tokenHash []byte) error {
+	query := `
+		DELETE FROM oauth_tokens
+		WHERE app_id = $1 AND (access_token_hash = $2 OR refresh_token_hash = $2)
+	`
+	_, err := db.Exec(ctx, query, appID, tokenHash)
+	return err
+}
+
+func (r *oauthRepository) DeleteUserAppTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID, appID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `DELETE FROM oauth_tokens WHERE user_id = $1 AND app_id = $2`, userID, appID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *oauthRepository) PruneExpiredOAuthTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID) error {
+	_, err := db.Exec(ctx, `DELETE FROM oauth_tokens WHERE user_id = $1 AND refresh_expires_at <= $2`, userID, time.Now())
+	return err
+}
+
+func (r *oauthRepository) TouchOAuthToken(ctx context.Context, db database.DBRunner, tokenID uuid.UUID) error {
+	query := `
+		UPDATE oauth_tokens
+		SET last_used_at = now()
+		WHERE id = $1
+		  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	_, err := db.Exec(ctx, query, tokenID)
+	return err
+}
+
+// ── AUTHORIZATIONS ────────────────────────────────────────────────────────────
+
+func (r *oauthRepository) ListUserAuthorizations(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.OAuthAuthorization, error) {
+	query := `
+		SELECT a.id, a.name,
+		       ARRAY(SELECT DISTINCT s FROM oauth_tokens t2, unnest(t2.scopes) s
+		             WHERE t2.app_id = a.id AND t2.user_id = $1 AND t2.refresh_expires_at > now()
+		             ORDER BY s),
+		       MIN(t.created_at), MAX(t.last_used_at)
+		FROM oauth_tokens t
+		INNER JOIN oauth_apps a ON a.id = t.app_id
+		WHERE t.user_id = $1 AND t.refresh_expires_at > now()
+		GROUP BY a.id, a.name
+		ORDER BY MIN(t.created_at) DESC
+	`
+
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	authorizations := make([]*models.OAuthAuthorization, 0)
+	for rows.Next() {
+		authorization := &models.OAuthAuthorization{}
+		if err := rows.Scan(
+			&authorization.AppID,
+			&authorization.AppName,
+			&authorization.Scopes,
+			&authorization.AuthorizedAt,
+			&authorization.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		authorizations = append(authorizations, authorization)
+	}
+
+	return authorizations, rows.Err()
+}
+
+func (r *oauthRepository) GetUserAppScopes(ctx context.Context, db database.DBRunner, userID uuid.UUID, appID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT COALESCE(ARRAY_AGG(DISTINCT s), '{}')
+		FROM oauth_tokens t, unnest(t.scopes) s
+		WHERE t.user_id = $1 AND t.app_id = $2 AND t.refresh_expires_at > now()
+	`
+	var scopes []string
+	err := db.QueryRow(ctx, query, userID, appID).Scan(&scopes)
+	return scopes, err
+}
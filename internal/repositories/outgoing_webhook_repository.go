@@ -0,0 +1,369 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IOutgoingWebhookRepository interface {
+	// -------------- WEBHOOKS
+	CreateOutgoingWebhook(ctx context.Context, db database.DBRunner, webhook *models.OutgoingWebhook) (*models.OutgoingWebhook, error)
+	GetOutgoingWebhookByID(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (*models.OutgoingWebhook, error)
+	ListHallOutgoingWebhooks(ctx context.Context, db database.DBRunner, hallID uuid.UUID) ([]*models.OutgoingWebhook, error)
+	CountHallOutgoingWebhooks(ctx context.Context, db database.DBRunner, hallID uuid.UUID) (int, error)
+	UpdateOutgoingWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID, fields map[string]any) (*models.OutgoingWebhook, error)
+	DeleteOutgoingWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error
+
+	// ListSubscribedOutgoingWebhooks returns the hall's enabled webhooks that
+	// asked for eventType
+	ListSubscribedOutgoingWebhooks(ctx context.Context, db database.DBRunner, hallID uuid.UUID, eventType string) ([]*models.OutgoingWebhook, error)
+
+	// RecordOutgoingWebhookFailure returns the consecutive failure count
+	RecordOutgoingWebhookSuccess(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error
+	RecordOutgoingWebhookFailure(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (int, error)
+
+	// DisableOutgoingWebhook also gives up on its pending deliveries
+	DisableOutgoingWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (*models.OutgoingWebhook, error)
+
+	// -------------- DELIVERIES
+	CreateOutgoingDelivery(ctx context.Context, db database.DBRunner, delivery *models.OutgoingWebhookDelivery) error
+	ListOutgoingDeliveries(ctx context.Context, db database.DBRunner, webhookID uuid.UUID, limit int) ([]*models.OutgoingWebhookDelivery, error)
+
+	// ClaimDueOutgoingDeliveries leases up to limit due deliveries by pushing
+	// their next attempt past lease, so concurrent workers never share one.
+	// Each claim counts as an attempt.
+	ClaimDueOutgoingDeliveries(ctx context.Context, db database.DBRunner, limit int, lease time.Duration) ([]*models.OutgoingWebhookDelivery, error)
+
+	MarkOutgoingDeliverySucceeded(ctx context.Context, db database.DBRunner, deliveryID uuid.UUID, statusCode int) error
+	MarkOutgoingDeliveryRetry(ctx context.Context, db database.DBRunner, deliveryID uuid.UUID, statusCode *int, lastError string, nextAttemptAt time.Time) error
+	MarkOutgoingDeliveryFailed(ctx context.Context, db database.DBRunner, deliveryID uuid.UUID, statusCode *int, lastError string) error
+
+	PruneOutgoingDeliveries(ctx context.Context, db database.DBRunner, olderThan time.Time) (int64, error)
+}
+
+type outgoingWebhookRepository struct{}
+
+func NewOutgoingWebhookRepository() IOutgoingWebhookRepository {
+	return &outgoingWebhookRepository{}
+}
+
+const outgoingWebhookColumns = `
+	id, hall_id, url, secret_enc, events, enabled, failure_count, disabled_at, created_by, created_at, updated_at
+`
+
+const outgoingDeliveryColumns = `
+	id, webhook_id, event_type, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, delivered_at, created_at, updated_at
+`
+
+func scanOutgoingWebhook(row pgx.Row) (*models.OutgoingWebhook, error) {
+	webhook := &models.OutgoingWebhook{}
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.HallID,
+		&webhook.URL,
+		&webhook.SecretEnc,
+		&webhook.Events,
+		&webhook.Enabled,
+		&webhook.FailureCount,
+		&webhook.DisabledAt,
+		&webhook.CreatedBy,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func scanOutgoingDelivery(row pgx.Row) (*models.OutgoingWebhookDelivery, error) {
+	delivery := &models.OutgoingWebhookDelivery{}
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func collectOutgoingWebhooks(rows pgx.Rows) ([]*models.OutgoingWebhook, error) {
+	defer rows.Close()
+
+	webhooks := make([]*models.OutgoingWebhook, 0)
+	for rows.Next() {
+		webhook, err := scanOutgoingWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func collectOutgoingDeliveries(rows pgx.Rows) ([]*models.OutgoingWebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*models.OutgoingWebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanOutgoingDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// ── WEBHOOKS ──────────────────────────────────────────────────────────────────
+
+func (r *outgoingWebhookRepository) CreateOutgoingWebhook(ctx context.Context, db database.DBRunner, webhook *models.OutgoingWebhook) (*models.OutgoingWebhook, error) {
+	query := `
+		INSERT INTO outgoing_webhooks (id, hall_id, url, secret_enc, events, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + outgoingWebhookColumns
+
+	return scanOutgoingWebhook(db.QueryRow(ctx, query,
+		webhook.ID,
+		webhook.HallID,
+		webhook.URL,
+		webhook.SecretEnc,
+		webhook.Events,
+		webhook.CreatedBy,
+	))
+}
+
+func (r *outgoingWebhookRepository) GetOutgoingWebhookByID(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (*models.OutgoingWebhook, error) {
+	query := `SELECT ` + outgoingWebhookColumns + ` FROM outgoing_webhooks WHERE id = $1`
+	return scanOutgoingWebhook(db.QueryRow(ctx, query, webhookID))
+}
+
+func (r *outgoingWebhookRepository) ListHallOutgoingWebhooks(ctx context.Context, db database.DBRunner, hallID uuid.UUID) ([]*models.OutgoingWebhook, error) {
+	query := `
+		SELECT ` + outgoingWebhookColumns + `
+		FROM outgoing_webhooks
+		WHERE hall_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := db.Query(ctx, query, hallID)
+	if err != nil {
+		return nil, err
+	}
+	return collectOutgoingWebhooks(rows)
+}
+
+func (r *outgoingWebhookRepository) CountHallOutgoingWebhooks(ctx context.Context, db database.DBRunner, hallID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM outgoing_webhooks WHERE hall_id = $1`, hallID).Scan(&count)
+	return count, err
+}
+
+func (r *outgoingWebhookRepository) UpdateOutgoingWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID, fields map[string]any) (*models.OutgoingWebhook, error) {
+
+	// Allowed columns are picked by the service
+	setClauses := make([]string, 0, len(fields)+1)
+	args := make([]any, 0, len(fields)+1)
+
+	i := 1
+	for col, val := range fields {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", col, i))
+		args = append(args, val)
+		i++
+	}
+	setClauses = append(setClauses, "updated_at = now()")
+	args = append(args, webhookID)
+
+	query := fmt.Sprintf(`
+		UPDATE outgoing_webhooks
+		SET %s
+		WHERE id = $%d
+		RETURNING `+outgoingWebhookColumns, strings.Join(setClauses, ", "), i)
+
+	return scanOutgoingWebhook(db.QueryRow(ctx, query, args...))
+}
+
+func (r *outgoingWebhookRepository) DeleteOutgoingWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `DELETE FROM outgoing_webhooks WHERE id = $1`, webhookID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *outgoingWebhookRepository) ListSubscribedOutgoingWebhooks(ctx context.Context, db database.DBRunner, hallID uuid.UUID, eventType string) ([]*models.OutgoingWebhook, error) {
+	query := `
+		SELECT ` + outgoingWebhookColumns + `
+		FROM outgoing_webhooks
+		WHERE hall_id = $1
+		  AND enabled
+		  AND $2 = ANY(events)
+	`
+
+	rows, err := db.Query(ctx, query, hallID, eventType)
+	if err != nil {
+		return nil, err
+	}
+	return collectOutgoingWebhooks(rows)
+}
+
+func (r *outgoingWebhookRepository) RecordOutgoingWebhookSuccess(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error {
+	_, err := db.Exec(ctx, `UPDATE outgoing_webhooks SET failure_count = 0 WHERE id = $1 AND failure_count > 0`, webhookID)
+	return err
+}
+
+func (r *outgoingWebhookRepository) RecordOutgoingWebhookFailure(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (int, error) {
+	var failures int
+	err := db.QueryRow(ctx, `
+		UPDATE outgoing_webhooks
+		SET failure_count = failure_count + 1
+		WHERE id = $1
+		RETURNING failure_count
+	`, webhookID).Scan(&failures)
+	return failures, err
+}
+
+func (r *outgoingWebhookRepository) DisableOutgoingWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (*models.OutgoingWebhook, error) {
+	query := `
+		UPDATE outgoing_webhooks
+		SET enabled = false, disabled_at = now(), updated_at = now()
+		WHERE id = $1
+		RETURNING ` + outgoingWebhookColumns
+
+	webhook, err := scanOutgoingWebhook(db.QueryRow(ctx, query, webhookID))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(ctx, `
+		UPDATE outgoing_webhook_deliveries
+		SET status = 'failed', last_error = 'webhook disabled', updated_at = now()
+		WHERE webhook_id = $1 AND status = 'pending'
+	`, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// ── DELIVERIES ────────────────────────────────────────────────────────────────
+
+func (r *outgoingWebhookRepository) CreateOutgoingDelivery(ctx context.Context, db database.DBRunner, delivery *models.OutgoingWebhookDelivery) error {
+	query := `
+		INSERT INTO outgoing_webhook_deliveries (id, webhook_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := db.Exec(ctx, query, delivery.ID, delivery.WebhookID, delivery.EventType, delivery.Payload)
+	return err
+}
+
+func (r *outgoingWebhookRepository) ListOutgoingDeliveries(ctx context.Context, db database.DBRunner, webhookID uuid.UUID, limit int) ([]*models.OutgoingWebhookDelivery, error) {
+	query := `
+		SELECT ` + outgoingDeliveryColumns + `
+		FROM outgoing_webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := db.Query(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectOutgoingDeliveries(rows)
+}
+
+func (r *outgoingWebhookRepository) ClaimDueOutgoingDeliveries(ctx context.Context, db database.DBRunner, limit int, lease time.Duration) ([]*models.OutgoingWebhookDelivery, error) {
+	query := `
+		UPDATE outgoing_webhook_deliveries
+		SET attempts = attempts + 1,
+		    next_attempt_at = now() + $2 * interval '1 second',
+		    updated_at = now()
+		WHERE id IN (
+			SELECT d.id
+			FROM outgoing_webhook_deliveries d
+			JOIN outgoing_webhooks w ON w.id = d.webhook_id
+			WHERE d.status = 'pending'
+			  AND d.next_attempt_at <= now()
+			  AND w.enabled
+			ORDER BY d.next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING ` + outgoingDeliveryColumns
+
+	rows, err := db.Query(ctx, query, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	return collectOutgoingDeliveries(rows)
+}
+
+func (r *outgoingWebhookRepository) MarkOutgoingDeliverySucceeded(ctx context.Context, db database.DBRunner, deliveryID uuid.UUID, statusCode int) error {
+	query := `
+		UPDATE outgoing_webhook_deliveries
+		SET status = 'succeeded', last_status_code = $2, last_error = NULL,
+		    delivered_at = now(), updated_at = now()
+		WHERE id = $1
+	`
+	_, err := db.Exec(ctx, query, deliveryID, statusCode)
+	return err
+}
+
+func (r *outgoingWebhookRepository) MarkOutgoingDeliveryRetry(ctx context.Context, db database.DBRunner, deliveryID uuid.UUID, statusCode *int, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE outgoing_webhook_deliveries
+		SET last_status_code = $2, last_error = $3, next_attempt_at = $4, updated_at = now()
+		WHERE id = $1 AND status = 'pending'
+	`
+	_, err := db.Exec(ctx, query, deliveryID, statusCode, lastError, nextAttemptAt)
+	return err
+}
+
+func (r *outgoingWebhookRepository) MarkOutgoingDeliveryFailed(ctx context.Context, db database.DBRunner, deliveryID uuid.UUID, statusCode *int, lastError string) error {
+	query := `
+		UPDATE outgoing_webhook_deliveries
+		SET status = 'failed', last_status_code = $2, last_error = $3, updated_at = now()
+		WHERE id = $1
+	`
+	_, err := db.Exec(ctx, query, deliveryID, statusCode, lastError)
+	return err
+}
+
+func (r *outgoingWebhookRepository) PruneOutgoingDeliveries(ctx context.Context, db database.DBRunner, olderThan time.Time) (int64, error) {
+	query := `
+		DELETE FROM outgoing_webhook_deliveries
+		WHERE status <> 'pending' AND created_at < $1
+	`
+
+	tag, err := db.Exec(ctx, query, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IPersonalTokenRepository interface {
+	CreatePersonalToken(ctx context.Context, db database.DBRunner, token *models.PersonalAccessToken) (*models.PersonalAccessToken, error)
+	ListUserPersonalTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.PersonalAccessToken, error)
+	CountUserPersonalTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error)
+	DeleteUserPersonalToken(ctx context.Context, db database.DBRunner, userID uuid.UUID, tokenID uuid.UUID) error
+
+	// GetActivePersonalToken is pgx.ErrNoRows for unknown and expired tokens
+	GetActivePersonalToken(ctx context.Context, db database.DBRunner, tokenHash []byte) (*models.PersonalAccessToken, error)
+
+	// TouchPersonalToken records a use, at most once a minute per token
+	TouchPersonalToken(ctx context.Context, db database.DBRunner, tokenID uuid.UUID) error
+}
+
+type personalTokenRepository struct{}
+
+func NewPersonalTokenRepository() IPersonalTokenRepository {
+	return &personalTokenRepository{}
+}
+
+const personalTokenColumns = `
+	id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at
+`
+
+func scanPersonalToken(row pgx.Row) (*models.PersonalAccessToken, error) {
+	token := &models.PersonalAccessToken{}
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.Scopes,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *personalTokenRepository) CreatePersonalToken(ctx context.Context, db database.DBRunner, token *models.PersonalAccessToken) (*models.PersonalAccessToken, error) {
+	query := `
+		INSERT INTO personal_access_tokens (id, user_id, name, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + personalTokenColumns
+
+	return scanPersonalToken(db.QueryRow(ctx, query,
+		token.ID,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.Scopes,
+		token.ExpiresAt,
+	))
+}
+
+func (r *personalTokenRepository) ListUserPersonalTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	query := `
+		SELECT ` + personalTokenColumns + `
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*models.PersonalAccessToken, 0)
+	for rows.Next() {
+		token, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *personalTokenRepository) CountUserPersonalTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM personal_access_tokens WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *personalTokenRepository) DeleteUserPersonalToken(ctx context.Context, db database.DBRunner, userID uuid.UUID, tokenID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2`, tokenID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *personalTokenRepository) GetActivePersonalToken(ctx context.Context, db database.DBRunner, tokenHash []byte) (*models.PersonalAccessToken, error) {
+	query := `
+		SELECT ` + personalTokenColumns + `
+		FROM personal_access_tokens
+		WHERE token_hash = $1
+		  AND (expires_at IS NULL OR expires_at > now())
+	`
+	return scanPersonalToken(db.QueryRow(ctx, query, tokenHash))
+}
+
+func (r *personalTokenRepository) TouchPersonalToken(ctx context.Context, db database.DBRunner, tokenID uuid.UUID) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = now()
+		WHERE id = $1
+		  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	_, err := db.Exec(ctx, query, tokenID)
+	return err
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IPushSubscriptionRepository interface {
+	// endpoint is unique per browser install, re-subscribing simply refreshes the keys/owner
+	UpsertPushSubscription(ctx context.Context, db database.DBRunner, sub *models.PushSubscription) (*models.PushSubscription, error)
+	ListUserPushSubscriptions(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, db database.DBRunner, userID uuid.UUID, subscriptionID uuid.UUID) error
+
+	// Delivery bookkeeping
+	MarkPushDelivered(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) error
+	IncrementPushFailure(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) (int, error)
+	DeletePushSubscriptionByID(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) error
+	PruneStalePushSubscriptions(ctx context.Context, db database.DBRunner, maxFailures int) (int64, error)
+}
+
+type pushSubscriptionRepository struct{}
+
+func NewPushSubscriptionRepository() IPushSubscriptionRepository {
+	return &pushSubscriptionRepository{}
+}
+
+const pushSubscriptionColumns = `
+	id, user_id, endpoint, p256dh, auth, user_agent, device_name,
+	expires_at, failure_count, last_success_at, created_at, updated_at
+`
+
+func scanPushSubscription(row pgx.Row) (*models.PushSubscription, error) {
+	sub := &models.PushSubscription{}
+	err := row.Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.Endpoint,
+		&sub.P256dh,
+		&sub.Auth,
+		&sub.UserAgent,
+		&sub.DeviceName,
+		&sub.ExpiresAt,
+		&sub.FailureCount,
+		&sub.LastSuccessAt,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (r *pushSubscriptionRepository) UpsertPushSubscription(ctx context.Context, db database.DBRunner, sub *models.PushSubscription) (*models.PushSubscription, error) {
+	query := `
+		INSERT INTO push_subscriptions (
+			id, user_id, endpoint, p256dh, auth, user_agent, device_name, expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (endpoint)
+		DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth,
+			user_agent = EXCLUDED.user_agent,
+			device_name = EXCLUDED.device_name,
+			expires_at = EXCLUDED.expires_at,
+			failure_count = 0
+		RETURNING ` + pushSubscriptionColumns
+
+	return scanPushSubscription(db.QueryRow(ctx, query,
+		sub.ID,
+		sub.UserID,
+		sub.Endpoint,
+		sub.P256dh,
+		sub.Auth,
+		sub.UserAgent,
+		sub.DeviceName,
+		sub.ExpiresAt,
+	))
+}
+
+func (r *pushSubscriptionRepository) ListUserPushSubscriptions(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.PushSubscription, error) {
+	query := `
+		SELECT ` + pushSubscriptionColumns + `
+		FROM push_subscriptions
+		WHERE user_id = $1
+		  AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY created_at ASC
+	`
+
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := make([]*models.PushSubscription, 0)
+	for rows.Next() {
+		sub, err := scanPushSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (r *pushSubscriptionRepository) DeletePushSubscription(ctx context.Context, db database.DBRunner, userID uuid.UUID, subscriptionID uuid.UUID) error {
+	query := `DELETE FROM push_subscriptions WHERE id = $1 AND user_id = $2`
+
+	tag, err := db.Exec(ctx, query, subscriptionID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *pushSubscriptionRepository) MarkPushDelivered(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) error {
+	query := `
+		UPDATE push_subscriptions
+		SET failure_count = 0, last_success_at = now()
+		WHERE id = $1
+	`
+
+	_, err := db.Exec(ctx, query, subscriptionID)
+	return err
+}
+
+func (r *pushSubscriptionRepository) IncrementPushFailure(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) (int, error) {
+	query := `
+		UPDATE push_subscriptions
+		SET failure_count = failure_count + 1
+		WHERE id = $1
+		RETURNING failure_count
+	`
+
+	var failures int
+	if err := db.QueryRow(ctx, query, subscriptionID).Scan(&failures); err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (r *pushSubscriptionRepository) DeletePushSubscriptionByID(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) error {
+	query := `DELETE FROM push_subscriptions WHERE id = $1`
+
+	_, err := db.Exec(ctx, query, subscriptionID)
+	return err
+}
+
+func (r *pushSubscriptionRepository) PruneStalePushSubscriptions(ctx context.Context, db database.DBRunner, maxFailures int) (int64, error) {
+	query := `
+		DELETE FROM push_subscriptions
+		WHERE (expires_at IS NOT NULL AND expires_at <= now())
+		   OR failure_count >= $1
+	`
+
+	tag, err := db.Exec(ctx, query, maxFailures)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
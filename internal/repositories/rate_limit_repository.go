@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IRateLimitRepository interface {
+	// TakeRateLimitToken takes one token from the bucket under key, refilling
+	// it for the time since the last take first.
+	TakeRateLimitToken(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error)
+}
+
+type rateLimitRepository struct {
+	client *redis.Client
+}
+
+func NewRateLimitRepository(client *redis.Client) IRateLimitRepository {
+	return &rateLimitRepository{client: client}
+}
+
+func rateLimitKey(key string) string {
+	return "ratelimit:" + key
+}
+
+// The bucket is a hash of the tokens left and when they were counted. Redis'
+// own clock is used so every instance refills the same bucket the same way,
+// and the key expires once it would have filled up again anyway.
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local refill = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * refill)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / refill)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / refill) + 1000)
+
+return {allowed, math.floor(tokens), retry}
+`)
+
+func (r *rateLimitRepository) TakeRateLimitToken(ctx context.Context, key string, limit models.RateLimit) (*models.RateLimitResult, error) {
+	// tokens per millisecond
+	refill := float64(limit.Requests) / float64(limit.Per.Milliseconds())
+
+	res, err := takeTokenScript.Run(ctx, r.client, []string{rateLimitKey(key)}, limit.Capacity(), refill).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RateLimitResult{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
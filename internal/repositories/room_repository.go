@@ -35,6 +35,7 @@ type IRoomRepository interface {
 	ReplaceRoomMembersFromFloor(ctx context.Context, db database.DBRunner, roomID uuid.UUID, floorID uuid.UUID) error
 	ListRoomMembers(ctx context.Context, db database.DBRunner, hallID uuid.UUID, roomID uuid.UUID) ([]*models.HallMember, error)
 	GetRoomMember(ctx context.Context, db database.DBRunner, hallID uuid.UUID, roomID uuid.UUID, memberID uuid.UUID) (*models.HallMember, error)
+	GetRoomAudienceUserIDs(ctx context.Context, db database.DBRunner, roomID uuid.UUID) ([]uuid.UUID, error)
 
 	SyncRoomsInFloorFromFloorMembers(ctx context.Context, db database.DBRunner, floorID uuid.UUID) error
 	SetRoomFloorMemberSync(ctx context.Context, db database.DBRunner, roomID uuid.UUID, sync bool) error
@@ -55,11 +56,11 @@ func (r *roomRepository) CreateRoom(ctx context.Context, db database.DBRunner, r
 	query := `
         INSERT INTO rooms (
 			id, hall_id, floor_id, name, room_type, position,
-			is_private, sync_with_floor_members, created_at, updated_at
+			is_private, sync_with_floor_members, slowmode_seconds, created_at, updated_at
 		)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
         RETURNING id, hall_id, floor_id, name, room_type, position,
-		          is_private, sync_with_floor_members, created_at, updated_at
+		          is_private, sync_with_floor_members, slowmode_seconds, created_at, updated_at
     `
 
 	out := &models.Room{}
@@ -72,6 +73,7 @@ func (r *roomRepository) CreateRoom(ctx context.Context, db database.DBRunner, r
 		room.Position,
 		room.IsPrivate,
 		room.SyncWithFloorMembers,
+		room.SlowmodeSeconds,
 		room.CreatedAt,
 		room.UpdatedAt,
 	).Scan(
@@ -83,6 +85,7 @@ func (r *roomRepository) CreateRoom(ctx context.Context, db database.DBRunner, r
 		&out.Position,
 		&out.IsPrivate,
 		&out.SyncWithFloorMembers,
+		&out.SlowmodeSeconds,
 		&out.CreatedAt,
 		&out.UpdatedAt,
 	)
@@ -97,7 +100,7 @@ func (r *roomRepository) CreateRoom(ctx context.Context, db database.DBRunner, r
 func (r *roomRepository) GetRoomByID(ctx context.Context, db database.DBRunner, roomID uuid.UUID) (*models.Room, error) {
 	query := `
         SELECT id, hall_id, floor_id, name, room_type, position,
-		       is_private, sync_with_floor_members, created_at, updated_at
+		       is_private, sync_with_floor_members, slowmode_seconds, created_at, updated_at
         FROM rooms
 		WHERE id = $1
     `
@@ -112,6 +115,7 @@ func (r *roomRepository) GetRoomByID(ctx context.Context, db database.DBRunner,
 		&out.Position,
 		&out.IsPrivate,
 		&out.SyncWithFloorMembers,
+		&out.SlowmodeSeconds,
 		&out.CreatedAt,
 		&out.UpdatedAt,
 	)
@@ -130,7 +134,7 @@ func (r *roomRepository) GetRoomsByHallID(ctx context.Context, db database.DBRun
 
 	query := `
         SELECT id, hall_id, floor_id, name, room_type, position,
-		       is_private, sync_with_floor_members, created_at, updated_at
+		       is_private, sync_with_floor_members, slowmode_seconds, created_at, updated_at
         FROM rooms
         WHERE hall_id = $1
         ORDER BY
@@ -157,6 +161,7 @@ func (r *roomRepository) GetRoomsByHallID(ctx context.Context, db database.DBRun
 			&rm.Position,
 			&rm.IsPrivate,
 			&rm.SyncWithFloorMembers,
+			&rm.SlowmodeSeconds,
 			&rm.CreatedAt,
 			&rm.UpdatedAt,
 		); err != nil {
@@ -253,7 +258,7 @@ func (r *roomRepository) UpdateRoom(ctx context.Context, db database.DBRunner, r
 		SET %s
 		WHERE id = $%d
 		RETURNING id, hall_id, floor_id, name, room_type, position,
-		          is_private, sync_with_floor_members, created_at, updated_at
+		          is_private, sync_with_floor_members, slowmode_seconds, created_at, updated_at
 	`, strings.Join(setClauses, ", "), i)
 
 	out := &models.Room{}
@@ -266,6 +271,7 @@ func (r *roomRepository) UpdateRoom(ctx context.Context, db database.DBRunner, r
 		&out.Position,
 		&out.IsPrivate,
 		&out.SyncWithFloorMembers,
+		&out.SlowmodeSeconds,
 		&out.CreatedAt,
 		&out.UpdatedAt,
 	)
@@ -334,7 +340,7 @@ func (r *roomRepository) MoveRoom(ctx context.Context, db database.DBRunner, roo
                updated_at = now()
         WHERE  id = $3
         RETURNING id, hall_id, floor_id, name, room_type, position,
-		          is_private, sync_with_floor_members, created_at, updated_at
+		          is_private, sync_with_floor_members, slowmode_seconds, created_at, updated_at
     `
 
 	out := &models.Room{}
@@ -347,6 +353,7 @@ func (r *roomRepository) MoveRoom(ctx context.Context, db database.DBRunner, roo
 		&out.Position,
 		&out.IsPrivate,
 		&out.SyncWithFloorMembers,
+		&out.SlowmodeSeconds,
 		&out.CreatedAt,
 		&out.UpdatedAt,
 	)
@@ -645,3 +652,38 @@ func (r *roomRepository) GetRoomMember(
 
 	return m, nil
 }
+
+// GetRoomAudienceUserIDs returns users.id of everyone who can read the room:
+// every hall member for public rooms, only room_members for private ones.
+func (r *roomRepository) GetRoomAudienceUserIDs(ctx context.Context, db database.DBRunner, roomID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT hm.user_id
+		FROM rooms r
+		INNER JOIN hall_members hm ON hm.hall_id = r.hall_id
+		WHERE r.id = $1
+		  AND (
+			r.is_private = false
+			OR EXISTS (
+				SELECT 1 FROM room_members rm
+				WHERE rm.room_id = r.id AND rm.member_id = hm.id
+			)
+		  )
+	`
+
+	rows, err := db.Query(ctx, query, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
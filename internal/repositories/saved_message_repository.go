@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type ISavedMessageRepository interface {
+	// CreateSavedMessage is pgx.ErrNoRows when the user saved the message already
+	CreateSavedMessage(ctx context.Context, db database.DBRunner, saved *models.SavedMessage) (*models.SavedMessage, error)
+	GetSavedMessage(ctx context.Context, db database.DBRunner, userID uuid.UUID, savedID uuid.UUID) (*models.SavedMessage, error)
+	UpdateSavedMessage(ctx context.Context, db database.DBRunner, saved *models.SavedMessage) (*models.SavedMessage, error)
+	DeleteSavedMessage(ctx context.Context, db database.DBRunner, userID uuid.UUID, savedID uuid.UUID) (bool, error)
+	CountSavedMessages(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error)
+
+	// ListSavedMessages pages newest first and only returns items whose
+	// message is still there and sits in one of roomIDs
+	ListSavedMessages(ctx context.Context, db database.DBRunner, userID uuid.UUID, roomIDs []uuid.UUID, before *uuid.UUID, limit int) ([]*models.SavedMessage, error)
+
+	// ClaimDueSavedReminders marks up to limit due reminders as delivered and
+	// returns them, concurrent callers never get the same one
+	ClaimDueSavedReminders(ctx context.Context, db database.DBRunner, now time.Time, limit int) ([]*models.SavedMessage, error)
+
+	// ReleaseSavedReminders undoes a claim so the next pass picks them up again
+	ReleaseSavedReminders(ctx context.Context, db database.DBRunner, savedIDs []uuid.UUID) error
+}
+
+type savedMessageRepository struct{}
+
+func NewSavedMessageRepository() ISavedMessageRepository {
+	return &savedMessageRepository{}
+}
+
+const savedMessageColumns = `
+	id, user_id, message_id, note, remind_at, reminded_at, created_at, updated_at
+`
+
+func scanSavedMessage(row pgx.Row) (*models.SavedMessage, error) {
+	saved := &models.SavedMessage{}
+	err := row.Scan(
+		&saved.ID,
+		&saved.UserID,
+		&saved.MessageID,
+		&saved.Note,
+		&saved.RemindAt,
+		&saved.RemindedAt,
+		&saved.CreatedAt,
+		&saved.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func scanSavedMessages(rows pgx.Rows) ([]*models.SavedMessage, error) {
+	defer rows.Close()
+
+	list := []*models.SavedMessage{}
+	for rows.Next() {
+		saved, err := scanSavedMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, saved)
+	}
+	return list, rows.Err()
+}
+
+func (r *savedMessageRepository) CreateSavedMessage(ctx context.Context, db database.DBRunner, saved *models.SavedMessage) (*models.SavedMessage, error) {
+	query := `
+		INSERT INTO saved_messages (id, user_id, message_id, note, remind_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, message_id) DO NOTHING
+		RETURNING ` + savedMessageColumns
+
+	return scanSavedMessage(db.QueryRow(ctx, query,
+		saved.ID,
+		saved.UserID,
+		saved.MessageID,
+		saved.Note,
+		saved.RemindAt,
+	))
+}
+
+func (r *savedMessageRepository) GetSavedMessage(ctx context.Context, db database.DBRunner, userID uuid.UUID, savedID uuid.UUID) (*models.SavedMessage, error) {
+	query := `
+		SELECT ` + savedMessageColumns + `
+		FROM saved_messages
+		WHERE id = $1 AND user_id = $2
+	`
+
+	return scanSavedMessage(db.QueryRow(ctx, query, savedID, userID))
+}
+
+func (r *savedMessageRepository) UpdateSavedMessage(ctx context.Context, db database.DBRunner, saved *models.SavedMessage) (*models.SavedMessage, error) {
+	query := `
+		UPDATE saved_messages
+		SET note = $3, remind_at = $4, reminded_at = $5, updated_at = now()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + savedMessageColumns
+
+	return scanSavedMessage(db.QueryRow(ctx, query,
+		saved.ID,
+		saved.UserID,
+		saved.Note,
+		saved.RemindAt,
+		saved.RemindedAt,
+	))
+}
+
+func (r *savedMessageRepository) DeleteSavedMessage(ctx context.Context, db database.DBRunner, userID uuid.UUID, savedID uuid.UUID) (bool, error) {
+	tag, err := db.Exec(ctx, `DELETE FROM saved_messages WHERE id = $1 AND user_id = $2`, savedID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *savedMessageRepository) CountSavedMessages(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT count(*) FROM saved_messages WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *savedMessageRepository) ListSavedMessages(ctx context.Context, db database.DBRunner, userID uuid.UUID, roomIDs []uuid.UUID, before *uuid.UUID, limit int) ([]*models.SavedMessage, error) {
+	query := `
+		SELECT s.id, s.user_id, s.message_id, s.note, s.remind_at, s.reminded_at, s.created_at, s.updated_at
+		FROM saved_messages s
+		INNER JOIN messages m ON m.id = s.message_id
+		WHERE s.user_id = $1
+		  AND m.deleted_at IS NULL
+		  AND m.room_id = ANY($2)
+		  AND (
+			$3::uuid IS NULL
+			OR (s.created_at, s.id) < (
+				SELECT c.created_at, c.id FROM saved_messages c WHERE c.id = $3 AND c.user_id = $1
+			)
+		  )
+		ORDER BY s.created_at DESC, s.id DESC
+		LIMIT $4
+	`
+
+	rows, err := db.Query(ctx, query, userID, roomIDs, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSavedMessages(rows)
+}
+
+func (r *savedMessageRepository) ClaimDueSavedReminders(ctx context.Context, db database.DBRunner, now time.Time, limit int) ([]*models.SavedMessage, error) {
+	query := `
+		UPDATE saved_messages
+		SET reminded_at = now(), updated_at = now()
+		WHERE id IN (
+			SELECT id
+			FROM saved_messages
+			WHERE remind_at <= $1 AND reminded_at IS NULL
+			ORDER BY remind_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + savedMessageColumns
+
+	rows, err := db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSavedMessages(rows)
+}
+
+func (r *savedMessageRepository) ReleaseSavedReminders(ctx context.Context, db database.DBRunner, savedIDs []uuid.UUID) error {
+	query := `
+		UPDATE saved_messages
+		SET reminded_at = NULL
+		WHERE id = ANY($1)
+	`
+
+	_, err := db.Exec(ctx, query, savedIDs)
+	return err
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IScheduledMessageRepository interface {
+	CreateScheduledMessage(ctx context.Context, db database.DBRunner, scheduled *models.ScheduledMessage) (*models.ScheduledMessage, error)
+
+	// GetScheduledMessageForUpdate locks the row, the dispatcher can't claim
+	// it while an edit is in flight
+	GetScheduledMessageForUpdate(ctx context.Context, db database.DBRunner, authorID uuid.UUID, scheduledID uuid.UUID) (*models.ScheduledMessage, error)
+	UpdateScheduledMessage(ctx context.Context, db database.DBRunner, scheduled *models.ScheduledMessage) (*models.ScheduledMessage, error)
+	DeleteScheduledMessage(ctx context.Context, db database.DBRunner, authorID uuid.UUID, scheduledID uuid.UUID) (bool, error)
+	CountScheduledMessages(ctx context.Context, db database.DBRunner, authorID uuid.UUID) (int, error)
+
+	// ListScheduledMessages is soonest first, roomID narrows it to one room
+	ListScheduledMessages(ctx context.Context, db database.DBRunner, authorID uuid.UUID, roomID *uuid.UUID) ([]*models.ScheduledMessage, error)
+
+	// ClaimDueScheduledMessages moves up to limit due messages to sending and
+	// returns them, concurrent callers never get the same one
+	ClaimDueScheduledMessages(ctx context.Context, db database.DBRunner, now time.Time, limit int) ([]*models.ScheduledMessage, error)
+	RequeueScheduledMessage(ctx context.Context, db database.DBRunner, scheduledID uuid.UUID, sendAt time.Time) error
+	FailScheduledMessage(ctx context.Context, db database.DBRunner, scheduledID uuid.UUID, failure string) error
+
+	// FailStaleScheduledMessages gives up on messages stuck in sending since
+	// before olderThan, returns how many
+	FailStaleScheduledMessages(ctx context.Context, db database.DBRunner, olderThan time.Time, failure string) (int64, error)
+}
+
+type scheduledMessageRepository struct{}
+
+func NewScheduledMessageRepository() IScheduledMessageRepository {
+	return &scheduledMessageRepository{}
+}
+
+const scheduledMessageColumns = `
+	id, author_id, room_id, content, attachments, mention_everyone, mentions,
+	send_at, status, failure, created_at, updated_at
+`
+
+func scanScheduledMessage(row pgx.Row) (*models.ScheduledMessage, error) {
+	scheduled := &models.ScheduledMessage{}
+	err := row.Scan(
+		&scheduled.ID,
+		&scheduled.AuthorID,
+		&scheduled.RoomID,
+		&scheduled.Content,
+		&scheduled.Attachments,
+		&scheduled.MentionEveryone,
+		&scheduled.Mentions,
+		&scheduled.SendAt,
+		&scheduled.Status,
+		&scheduled.Failure,
+		&scheduled.CreatedAt,
+		&scheduled.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+func scanScheduledMessages(rows pgx.Rows) ([]*models.ScheduledMessage, error) {
+	defer rows.Close()
+
+	list := []*models.ScheduledMessage{}
+	for rows.Next() {
+		scheduled, err := scanScheduledMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, scheduled)
+	}
+	return list, rows.Err()
+}
+
+func (r *scheduledMessageRepository) CreateScheduledMessage(ctx context.Context, db database.DBRunner, scheduled *models.ScheduledMessage) (*models.ScheduledMessage, error) {
+	query := `
+		INSERT INTO scheduled_messages (id, author_id, room_id, content, attachments, mention_everyone, mentions, send_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + scheduledMessageColumns
+
+	return scanScheduledMessage(db.QueryRow(ctx, query,
+		scheduled.ID,
+		scheduled.AuthorID,
+		scheduled.RoomID,
+		scheduled.Content,
+		scheduled.Attachments,
+		scheduled.MentionEveryone,
+		scheduled.Mentions,
+		scheduled.SendAt,
+	))
+}
+
+func (r *scheduledMessageRepository) GetScheduledMessageForUpdate(ctx context.Context, db database.DBRunner, authorID uuid.UUID, scheduledID uuid.UUID) (*models.ScheduledMessage, error) {
+	query := `
+		SELECT ` + scheduledMessageColumns + `
+		FROM scheduled_messages
+		WHERE id = $1 AND author_id = $2
+		FOR UPDATE
+	`
+
+	return scanScheduledMessage(db.QueryRow(ctx, query, scheduledID, authorID))
+}
+
+func (r *scheduledMessageRepository) UpdateScheduledMessage(ctx context.Context, db database.DBRunner, scheduled *models.ScheduledMessage) (*models.ScheduledMessage, error) {
+	query := `
+		UPDATE scheduled_messages
+		SET content = $3, attachments = $4, mention_everyone = $5, mentions = $6,
+		    send_at = $7, status = $8, failure = $9, updated_at = now()
+		WHERE id = $1 AND author_id = $2
+		RETURNING ` + scheduledMessageColumns
+
+	return scanScheduledMessage(db.QueryRow(ctx, query,
+		scheduled.ID,
+		scheduled.AuthorID,
+		scheduled.Content,
+		scheduled.Attachments,
+		scheduled.MentionEveryone,
+		scheduled.Mentions,
+		scheduled.SendAt,
+		scheduled.Status,
+		scheduled.Failure,
+	))
+}
+
+func (r *scheduledMessageRepository) DeleteScheduledMessage(ctx context.Context, db database.DBRunner, authorID uuid.UUID, scheduledID uuid.UUID) (bool, error) {
+	tag, err := db.Exec(ctx, `DELETE FROM scheduled_messages WHERE id = $1 AND author_id = $2`, scheduledID, authorID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *scheduledMessageRepository) CountScheduledMessages(ctx context.Context, db database.DBRunner, authorID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT count(*) FROM scheduled_messages WHERE author_id = $1`, authorID).Scan(&count)
+	return count, err
+}
+
+func (r *scheduledMessageRepository) ListScheduledMessages(ctx context.Context, db database.DBRunner, authorID uuid.UUID, roomID *uuid.UUID) ([]*models.ScheduledMessage, error) {
+	query := `
+		SELECT ` + scheduledMessageColumns + `
+		FROM scheduled_messages
+		WHERE author_id = $1 AND ($2::uuid IS NULL OR room_id = $2)
+		ORDER BY send_at, id
+	`
+
+	rows, err := db.Query(ctx, query, authorID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return scanScheduledMessages(rows)
+}
+
+func (r *scheduledMessageRepository) ClaimDueScheduledMessages(ctx context.Context, db database.DBRunner, now time.Time, limit int) ([]*models.ScheduledMessage, error) {
+	query := `
+		UPDATE scheduled_messages
+		SET status = 'sending', updated_at = now()
+		WHERE id IN (
+			SELECT id
+			FROM scheduled_messages
+			WHERE status = 'pending' AND send_at <= $1
+			ORDER BY send_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduledMessageColumns
+
+	rows, err := db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanScheduledMessages(rows)
+}
+
+func (r *scheduledMessageRepository) RequeueScheduledMessage(ctx context.Context, db database.DBRunner, scheduledID uuid.UUID, sendAt time.Time) error {
+	_, err := db.Exec(ctx, `
+		UPDATE scheduled_messages
+		SET status = 'pending', send_at = $2, updated_at = now()
+		WHERE id = $1 AND status = 'sending'
+	`, scheduledID, sendAt)
+	return err
+}
+
+func (r *scheduledMessageRepository) FailScheduledMessage(ctx context.Context, db database.DBRunner, scheduledID uuid.UUID, failure string) error {
+	_, err := db.Exec(ctx, `
+		UPDATE scheduled_messages
+		SET status = 'failed', failure = $2, updated_at = now()
+		WHERE id = $1 AND status = 'sending'
+	`, scheduledID, failure)
+	return err
+}
+
+func (r *scheduledMessageRepository) FailStaleScheduledMessages(ctx context.Context, db database.DBRunner, olderThan time.Time, failure string) (int64, error) {
+	tag, err := db.Exec(ctx, `
+		UPDATE scheduled_messages
+		SET status = 'failed', failure = $2, updated_at = now()
+		WHERE status = 'sending' AND updated_at < $1
+	`, olderThan, failure)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
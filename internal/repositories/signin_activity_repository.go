@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type ISigninActivityRepository interface {
+	// -------------- ATTEMPTS
+	CreateSigninAttempt(ctx context.Context, db database.DBRunner, attempt *models.SigninAttempt) error
+	ListSigninAttempts(ctx context.Context, db database.DBRunner, userID uuid.UUID, limit int) ([]*models.SigninAttempt, error)
+
+	// -------------- DEVICES
+	CountUserDevices(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error)
+
+	// TouchUserDevice creates the device on first sight and refreshes name,
+	// address and last_seen afterwards. The bool is true when it was created.
+	TouchUserDevice(ctx context.Context, db database.DBRunner, device *models.UserDevice) (*models.UserDevice, bool, error)
+}
+
+type signinActivityRepository struct{}
+
+func NewSigninActivityRepository() ISigninActivityRepository {
+	return &signinActivityRepository{}
+}
+
+// inet goes through text both ways, pgx has no string codec for it
+const userDeviceColumns = `
+	id, user_id, device_id, device_name, host(last_ip), last_seen, created_at, updated_at
+`
+
+func scanUserDevice(row pgx.Row, extra ...any) (*models.UserDevice, error) {
+	d := &models.UserDevice{}
+	dest := append([]any{
+		&d.ID,
+		&d.UserID,
+		&d.DeviceKey,
+		&d.DeviceName,
+		&d.LastIP,
+		&d.LastSeen,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *signinActivityRepository) CreateSigninAttempt(ctx context.Context, db database.DBRunner, attempt *models.SigninAttempt) error {
+	query := `
+		INSERT INTO signin_attempts (id, user_id, device_id, method, succeeded, failure_reason, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::text::inet, $8)
+	`
+
+	_, err := db.Exec(ctx, query,
+		attempt.ID,
+		attempt.UserID,
+		attempt.DeviceID,
+		attempt.Method,
+		attempt.Succeeded,
+		attempt.FailureReason,
+		attempt.IP,
+		attempt.UserAgent,
+	)
+	return err
+}
+
+func (r *signinActivityRepository) ListSigninAttempts(ctx context.Context, db database.DBRunner, userID uuid.UUID, limit int) ([]*models.SigninAttempt, error) {
+	query := `
+		SELECT a.id, a.user_id, a.device_id, a.method, a.succeeded, a.failure_reason,
+		       host(a.ip), a.user_agent, a.created_at, d.device_name
+		FROM signin_attempts a
+		LEFT JOIN user_metadatas d ON d.id = a.device_id
+		WHERE a.user_id = $1
+		ORDER BY a.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := make([]*models.SigninAttempt, 0)
+	for rows.Next() {
+		a := &models.SigninAttempt{}
+		err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.DeviceID,
+			&a.Method,
+			&a.Succeeded,
+			&a.FailureReason,
+			&a.IP,
+			&a.UserAgent,
+			&a.CreatedAt,
+			&a.DeviceName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+
+	return attempts, rows.Err()
+}
+
+func (r *signinActivityRepository) CountUserDevices(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM user_metadatas WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *signinActivityRepository) TouchUserDevice(ctx context.Context, db database.DBRunner, device *models.UserDevice) (*models.UserDevice, bool, error) {
+	// xmax is only set on rows the conflict branch updated
+	query := `
+		INSERT INTO user_metadatas (id, user_id, device_id, device_name, last_ip, last_seen)
+		VALUES ($1, $2, $3, $4, $5::text::inet, now())
+		ON CONFLICT (user_id, device_id) DO UPDATE
+		SET device_name = EXCLUDED.device_name,
+		    last_ip = EXCLUDED.last_ip,
+		    last_seen = now()
+		RETURNING ` + userDeviceColumns + `, (xmax = 0)`
+
+	var created bool
+	saved, err := scanUserDevice(db.QueryRow(ctx, query,
+		device.ID,
+		device.UserID,
+		device.DeviceKey,
+		device.DeviceName,
+		device.LastIP,
+	), &created)
+	if err != nil {
+		return nil, false, err
+	}
+	return saved, created, nil
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type ISigninThrottleRepository interface {
+	// RecordSigninFailure adds a failure to the sliding window under key and
+	// returns how many failures the window now holds.
+	RecordSigninFailure(ctx context.Context, key string, at time.Time, window time.Duration) (int, error)
+
+	// GetSigninFailures counts the failures in the window and returns the
+	// time of the latest one.
+	GetSigninFailures(ctx context.Context, key string, at time.Time, window time.Duration) (int, time.Time, error)
+	ClearSigninFailures(ctx context.Context, key string) error
+
+	LockSignin(ctx context.Context, key string, ttl time.Duration) error
+
+	// GetSigninLock returns how much longer key stays locked, 0 if it isn't.
+	GetSigninLock(ctx context.Context, key string) (time.Duration, error)
+}
+
+type signinThrottleRepository struct {
+	client *redis.Client
+}
+
+func NewSigninThrottleRepository(client *redis.Client) ISigninThrottleRepository {
+	return &signinThrottleRepository{client: client}
+}
+
+func signinFailuresKey(key string) string {
+	return "signin:failures:" + key
+}
+
+func signinLockKey(key string) string {
+	return "signin:lock:" + key
+}
+
+func (r *signinThrottleRepository) RecordSigninFailure(ctx context.Context, key string, at time.Time, window time.Duration) (int, error) {
+	k := signinFailuresKey(key)
+	now := at.UnixNano()
+
+	var card *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, k, "-inf", strconv.FormatInt(now-window.Nanoseconds(), 10))
+		// the member only has to be unique, two failures can share a timestamp
+		pipe.ZAdd(ctx, k, redis.Z{Score: float64(now), Member: uuid.NewString()})
+		card = pipe.ZCard(ctx, k)
+		pipe.Expire(ctx, k, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(card.Val()), nil
+}
+
+func (r *signinThrottleRepository) GetSigninFailures(ctx context.Context, key string, at time.Time, window time.Duration) (int, time.Time, error) {
+	k := signinFailuresKey(key)
+	from := strconv.FormatInt(at.UnixNano()-window.Nanoseconds(), 10)
+
+	latest, err := r.client.ZRevRangeByScoreWithScores(ctx, k, &redis.ZRangeBy{
+		Min: from,
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if len(latest) == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	return len(latest), time.Unix(0, int64(latest[0].Score)), nil
+}
+
+func (r *signinThrottleRepository) ClearSigninFailures(ctx context.Context, key string) error {
+	return r.client.Del(ctx, signinFailuresKey(key)).Err()
+}
+
+func (r *signinThrottleRepository) LockSignin(ctx context.Context, key string, ttl time.Duration) error {
+	return r.client.Set(ctx, signinLockKey(key), "1", ttl).Err()
+}
+
+func (r *signinThrottleRepository) GetSigninLock(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, signinLockKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// -2 missing key, -1 no expiry which we never set
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type ISigningKeyRepository interface {
+	CreateSigningKey(ctx context.Context, db database.DBRunner, key *models.JWTSigningKey) (*models.JWTSigningKey, error)
+
+	// ListSigningKeys is ordered by activation, expired keys only when asked
+	ListSigningKeys(ctx context.Context, db database.DBRunner, includeExpired bool) ([]*models.JWTSigningKey, error)
+
+	// RetireSigningKey stops a key from signing at retiresAt and from
+	// verifying at expiresAt. Dates already earlier are kept, and a key that
+	// is not active yet never signs.
+	RetireSigningKey(ctx context.Context, db database.DBRunner, kid string, retiresAt time.Time, expiresAt time.Time) (*models.JWTSigningKey, error)
+
+	// RetireOtherSigningKeys does the same for every key except kid that
+	// would still sign at retiresAt.
+	RetireOtherSigningKeys(ctx context.Context, db database.DBRunner, kid string, retiresAt time.Time, expiresAt time.Time) ([]*models.JWTSigningKey, error)
+	DeleteExpiredSigningKeys(ctx context.Context, db database.DBRunner) (int64, error)
+}
+
+type signingKeyRepository struct{}
+
+func NewSigningKeyRepository() ISigningKeyRepository {
+	return &signingKeyRepository{}
+}
+
+const signingKeyColumns = `
+	kid, algorithm, private_key_enc, activates_at, retires_at, expires_at, created_at
+`
+
+func scanSigningKey(row pgx.Row) (*models.JWTSigningKey, error) {
+	k := &models.JWTSigningKey{}
+	err := row.Scan(
+		&k.ID,
+		&k.Algorithm,
+		&k.PrivateKeyEnc,
+		&k.ActivatesAt,
+		&k.RetiresAt,
+		&k.ExpiresAt,
+		&k.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func collectSigningKeys(rows pgx.Rows) ([]*models.JWTSigningKey, error) {
+	defer rows.Close()
+
+	keys := make([]*models.JWTSigningKey, 0)
+	for rows.Next() {
+		k, err := scanSigningKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *signingKeyRepository) CreateSigningKey(ctx context.Context, db database.DBRunner, key *models.JWTSigningKey) (*models.JWTSigningKey, error) {
+	query := `
+		INSERT INTO jwt_signing_keys (kid, algorithm, private_key_enc, activates_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + signingKeyColumns
+
+	return scanSigningKey(db.QueryRow(ctx, query,
+		key.ID,
+		key.Algorithm,
+		key.PrivateKeyEnc,
+		key.ActivatesAt,
+	))
+}
+
+func (r *signingKeyRepository) ListSigningKeys(ctx context.Context, db database.DBRunner, includeExpired bool) ([]*models.JWTSigningKey, error) {
+	query := `
+		SELECT ` + signingKeyColumns + `
+		FROM jwt_signing_keys
+		WHERE $1 OR expires_at IS NULL OR expires_at > now()
+		ORDER BY activates_at, created_at
+	`
+
+	rows, err := db.Query(ctx, query, includeExpired)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSigningKeys(rows)
+}
+
+func (r *signingKeyRepository) RetireSigningKey(ctx context.Context, db database.DBRunner, kid string, retiresAt time.Time, expiresAt time.Time) (*models.JWTSigningKey, error) {
+	query := `
+		UPDATE jwt_signing_keys
+		SET retires_at = GREATEST(activates_at, LEAST(COALESCE(retires_at, $2), $2)),
+		    expires_at = GREATEST(activates_at, LEAST(COALESCE(expires_at, $3), $3))
+		WHERE kid = $1
+		RETURNING ` + signingKeyColumns
+
+	return scanSigningKey(db.QueryRow(ctx, query, kid, retiresAt, expiresAt))
+}
+
+func (r *signingKeyRepository) RetireOtherSigningKeys(ctx context.Context, db database.DBRunner, kid string, retiresAt time.Time, expiresAt time.Time) ([]*models.JWTSigningKey, error) {
+	query := `
+		UPDATE jwt_signing_keys
+		SET retires_at = GREATEST(activates_at, LEAST(COALESCE(retires_at, $2), $2)),
+		    expires_at = GREATEST(activates_at, LEAST(COALESCE(expires_at, $3), $3))
+		WHERE kid <> $1 AND (retires_at IS NULL OR retires_at > $2)
+		RETURNING ` + signingKeyColumns
+
+	rows, err := db.Query(ctx, query, kid, retiresAt, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSigningKeys(rows)
+}
+
+func (r *signingKeyRepository) DeleteExpiredSigningKeys(ctx context.Context, db database.DBRunner) (int64, error) {
+	tag, err := db.Exec(ctx, `DELETE FROM jwt_signing_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type ITwoFactorRepository interface {
+	// ---------------- TOTP
+	// starting over replaces an unconfirmed secret, callers make sure 2FA isn't enabled yet
+	UpsertPendingTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID, secret string) (*models.UserTOTP, error)
+	GetUserTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID) (*models.UserTOTP, error)
+	EnableTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID) error
+	// UseTOTPStep returns pgx.ErrNoRows when the step (or a later one) was already used
+	UseTOTPStep(ctx context.Context, db database.DBRunner, userID uuid.UUID, step int64) error
+	DeleteTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID) error
+	IsTwoFactorEnabled(ctx context.Context, db database.DBRunner, userID uuid.UUID) (bool, error)
+
+	// ---------------- RECOVERY CODES
+	ReplaceRecoveryCodes(ctx context.Context, db database.DBRunner, userID uuid.UUID, codes []*models.RecoveryCode) error
+	ConsumeRecoveryCode(ctx context.Context, db database.DBRunner, userID uuid.UUID, codeHash []byte) error
+	CountUnusedRecoveryCodes(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error)
+	DeleteRecoveryCodes(ctx context.Context, db database.DBRunner, userID uuid.UUID) error
+}
+
+type twoFactorRepository struct{}
+
+func NewTwoFactorRepository() ITwoFactorRepository {
+	return &twoFactorRepository{}
+}
+
+func scanUserTOTP(row pgx.Row) (*models.UserTOTP, error) {
+	t := &models.UserTOTP{}
+	err := row.Scan(
+		&t.UserID,
+		&t.Secret,
+		&t.EnabledAt,
+		&t.LastUsedStep,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *twoFactorRepository) UpsertPendingTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID, secret string) (*models.UserTOTP, error) {
+	query := `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id)
+		DO UPDATE SET secret = EXCLUDED.secret, enabled_at = NULL, last_used_step = NULL
+		RETURNING user_id, secret, enabled_at, last_used_step, created_at, updated_at
+	`
+
+	return scanUserTOTP(db.QueryRow(ctx, query, userID, secret))
+}
+
+func (r *twoFactorRepository) GetUserTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID) (*models.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at, updated_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+
+	return scanUserTOTP(db.QueryRow(ctx, query, userID))
+}
+
+func (r *twoFactorRepository) EnableTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `UPDATE user_totp SET enabled_at = now() WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseTOTPStep(ctx context.Context, db database.DBRunner, userID uuid.UUID, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1
+		  AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+
+	tag, err := db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) DeleteTOTP(ctx context.Context, db database.DBRunner, userID uuid.UUID) error {
+	_, err := db.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	return err
+}
+
+func (r *twoFactorRepository) IsTwoFactorEnabled(ctx context.Context, db database.DBRunner, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM user_totp WHERE user_id = $1 AND enabled_at IS NOT NULL)`
+
+	var enabled bool
+	if err := db.QueryRow(ctx, query, userID).Scan(&enabled); err != nil {
+		return false, err
+	}
+	return enabled, nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, db database.DBRunner, userID uuid.UUID, codes []*models.RecoveryCode) error {
+	if err := r.DeleteRecoveryCodes(ctx, db, userID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_recovery_codes (id, user_id, code_hash)
+		VALUES ($1, $2, $3)
+	`
+
+	for _, code := range codes {
+		if _, err := db.Exec(ctx, query, code.ID, userID, code.CodeHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) ConsumeRecoveryCode(ctx context.Context, db database.DBRunner, userID uuid.UUID, codeHash []byte) error {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	tag, err := db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) CountUnusedRecoveryCodes(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+	if err := db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *twoFactorRepository) DeleteRecoveryCodes(ctx context.Context, db database.DBRunner, userID uuid.UUID) error {
+	_, err := db.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	return err
+}
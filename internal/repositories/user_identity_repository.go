@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IUserIdentityRepository interface {
+	// -------------- IDENTITIES
+	CreateUserIdentity(ctx context.Context, db database.DBRunner, identity *models.UserIdentity) (*models.UserIdentity, error)
+	GetUserIdentity(ctx context.Context, db database.DBRunner, provider string, subject string) (*models.UserIdentity, error)
+	ListUserIdentities(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.UserIdentity, error)
+	CountUserIdentities(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error)
+	TouchUserIdentity(ctx context.Context, db database.DBRunner, identityID uuid.UUID) error
+	DeleteUserIdentity(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider string) error
+
+	// -------------- LOGIN STATES
+	CreateOIDCLoginState(ctx context.Context, db database.DBRunner, state *models.OIDCLoginState) error
+
+	// ConsumeOIDCLoginState deletes and returns a live state, pgx.ErrNoRows
+	// when it is unknown, expired or belongs to another provider.
+	ConsumeOIDCLoginState(ctx context.Context, db database.DBRunner, provider string, stateHash []byte) (*models.OIDCLoginState, error)
+	DeleteExpiredOIDCLoginStates(ctx context.Context, db database.DBRunner) error
+}
+
+type userIdentityRepository struct{}
+
+func NewUserIdentityRepository() IUserIdentityRepository {
+	return &userIdentityRepository{}
+}
+
+const userIdentityColumns = `
+	id, user_id, provider, subject, email, last_login_at, created_at
+`
+
+func scanUserIdentity(row pgx.Row) (*models.UserIdentity, error) {
+	i := &models.UserIdentity{}
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.Email,
+		&i.LastLoginAt,
+		&i.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (r *userIdentityRepository) CreateUserIdentity(ctx context.Context, db database.DBRunner, identity *models.UserIdentity) (*models.UserIdentity, error) {
+	query := `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+		RETURNING ` + userIdentityColumns
+
+	return scanUserIdentity(db.QueryRow(ctx, query,
+		identity.ID,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	))
+}
+
+func (r *userIdentityRepository) GetUserIdentity(ctx context.Context, db database.DBRunner, provider string, subject string) (*models.UserIdentity, error) {
+	query := `
+		SELECT ` + userIdentityColumns + `
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	return scanUserIdentity(db.QueryRow(ctx, query, provider, subject))
+}
+
+func (r *userIdentityRepository) ListUserIdentities(ctx context.Context, db database.DBRunner, userID uuid.UUID) ([]*models.UserIdentity, error) {
+	query := `
+		SELECT ` + userIdentityColumns + `
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := make([]*models.UserIdentity, 0)
+	for rows.Next() {
+		i, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+
+	return identities, rows.Err()
+}
+
+func (r *userIdentityRepository) CountUserIdentities(ctx context.Context, db database.DBRunner, userID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM user_identities WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *userIdentityRepository) TouchUserIdentity(ctx context.Context, db database.DBRunner, identityID uuid.UUID) error {
+	_, err := db.Exec(ctx, `UPDATE user_identities SET last_login_at = now() WHERE id = $1`, identityID)
+	return err
+}
+
+func (r *userIdentityRepository) DeleteUserIdentity(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider string) error {
+	tag, err := db.Exec(ctx, `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *userIdentityRepository) CreateOIDCLoginState(ctx context.Context, db database.DBRunner, state *models.OIDCLoginState) error {
+	query := `
+		INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, link_user_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := db.Exec(ctx, query,
+		state.StateHash,
+		state.Provider,
+		state.Nonce,
+		state.CodeVerifier,
+		state.LinkUserID,
+		state.ExpiresAt,
+	)
+	return err
+}
+
+func (r *userIdentityRepository) ConsumeOIDCLoginState(ctx context.Context, db database.DBRunner, provider string, stateHash []byte) (*models.OIDCLoginState, error) {
+	query := `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND provider = $2 AND expires_at > now()
+		RETURNING state_hash, provider, nonce, code_verifier, link_user_id, expires_at, created_at
+	`
+
+	s := &models.OIDCLoginState{}
+	err := db.QueryRow(ctx, query, stateHash, provider).Scan(
+		&s.StateHash,
+		&s.Provider,
+		&s.Nonce,
+		&s.CodeVerifier,
+		&s.LinkUserID,
+		&s.ExpiresAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *userIdentityRepository) DeleteExpiredOIDCLoginStates(ctx context.Context, db database.DBRunner) error {
+	_, err := db.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at <= now()`)
+	return err
+}
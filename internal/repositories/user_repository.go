@@ -20,11 +20,14 @@ type IUserRepository interface {
 	GetUserByUsername(ctx context.Context, db database.DBRunner, username string) (*models.User, error)
 	GetUserByNumber(ctx context.Context, db database.DBRunner, number string) (*models.User, error)
 	GetUserById(ctx context.Context, db database.DBRunner, userID uuid.UUID) (*models.User, error)
+	GetUsersByIds(ctx context.Context, db database.DBRunner, userIDs []uuid.UUID) ([]*models.User, error)
 	DoesUserExists(ctx context.Context, db database.DBRunner, userID uuid.UUID) (bool, error)
 
 	UpdateUserById(ctx context.Context, db database.DBRunner, userID uuid.UUID, fields map[string]any) (*models.User, error)
 	UpdateUsername(ctx context.Context, db database.DBRunner, userID uuid.UUID, username string) (*models.User, error)
 	UpdateEmail(ctx context.Context, db database.DBRunner, userID uuid.UUID, email string) (*models.User, error)
+	MarkEmailVerified(ctx context.Context, db database.DBRunner, userID uuid.UUID, email string) error
+	UpdatePasswordHash(ctx context.Context, db database.DBRunner, userID uuid.UUID, passwordHash string) error
 	DeleteUserById(ctx context.Context, db database.DBRunner, userID uuid.UUID) error
 
 	// ---------------- FRIEND REQUESTS
@@ -51,6 +54,12 @@ type IUserRepository interface {
 	DeleteAppLink(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider) error
 	GetUserAppLinks(ctx context.Context, db database.DBRunner, userID uuid.UUID, onlyVisible bool) ([]*models.UserAppLink, error)
 	GetUserAppLinkByProvider(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider) (*models.UserAppLink, error)
+	SetAppLinkVisibility(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider, show bool) (*models.UserAppLink, error)
+
+	// connection attempts, consumed once when the provider sends the user back
+	CreateAppLinkState(ctx context.Context, db database.DBRunner, state *models.AppLinkState) error
+	ConsumeAppLinkState(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider, stateHash []byte) (*models.AppLinkState, error)
+	DeleteExpiredAppLinkStates(ctx context.Context, db database.DBRunner) error
 }
 
 type userRepository struct{}
@@ -79,6 +88,9 @@ func scanUser(row pgx.Row) (*models.User, error) {
 		&user.AvatarURL,
 		&user.AvatarThumbnailURL,
 		&user.FriendPolicy,
+		&user.EmailVerifiedAt,
+		&user.IsBot,
+		&user.BotOwnerID,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -93,7 +105,7 @@ func (r *userRepository) CreateUser(ctx context.Context, db database.DBRunner, u
 		INSERT INTO users (id, username, display_name, email, password_hash)
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, username, display_name, email, password_hash, description, phone_number,
-		          avatar_url, avatar_thumbnail_url, friend_policy, created_at, updated_at
+		          avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
 	`
 
 	return scanUser(db.QueryRow(ctx, query,
@@ -108,7 +120,7 @@ func (r *userRepository) CreateUser(ctx context.Context, db database.DBRunner, u
 func (r *userRepository) GetUserWithPasswordHashByEmail(ctx context.Context, db database.DBRunner, email string) (*models.User, error) {
 	query := `
 		SELECT id, username, display_name, email, password_hash, description, phone_number,
-		       avatar_url, avatar_thumbnail_url, friend_policy, created_at, updated_at
+		       avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
 		FROM users
 		WHERE lower(email) = lower($1)
 	`
@@ -118,7 +130,7 @@ func (r *userRepository) GetUserWithPasswordHashByEmail(ctx context.Context, db
 func (r *userRepository) GetUserByEmail(ctx context.Context, db database.DBRunner, email string) (*models.User, error) {
 	query := `
 		SELECT id, username, display_name, email, password_hash, description, phone_number,
-		       avatar_url, avatar_thumbnail_url, friend_policy, created_at, updated_at
+		       avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
 		FROM users
 		WHERE lower(email) = lower($1)
 	`
@@ -128,7 +140,7 @@ func (r *userRepository) GetUserByEmail(ctx context.Context, db database.DBRunne
 func (r *userRepository) GetUserByUsername(ctx context.Context, db database.DBRunner, username string) (*models.User, error) {
 	query := `
 		SELECT id, username, display_name, email, password_hash, description, phone_number,
-		       avatar_url, avatar_thumbnail_url, friend_policy, created_at, updated_at
+		       avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
 		FROM users
 		WHERE lower(username) = lower($1)
 	`
@@ -138,7 +150,7 @@ func (r *userRepository) GetUserByUsername(ctx context.Context, db database.DBRu
 func (r *userRepository) GetUserByNumber(ctx context.Context, db database.DBRunner, number string) (*models.User, error) {
 	query := `
 		SELECT id, username, display_name, email, password_hash, description, phone_number,
-		       avatar_url, avatar_thumbnail_url, friend_policy, created_at, updated_at
+		       avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
 		FROM users
 		WHERE phone_number = $1
 	`
@@ -148,13 +160,40 @@ func (r *userRepository) GetUserByNumber(ctx context.Context, db database.DBRunn
 func (r *userRepository) GetUserById(ctx context.Context, db database.DBRunner, userID uuid.UUID) (*models.User, error) {
 	query := `
 		SELECT id, username, display_name, email, password_hash, description, phone_number,
-		       avatar_url, avatar_thumbnail_url, friend_policy, created_at, updated_at
+		       avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 	return scanUser(db.QueryRow(ctx, query, userID))
 }
 
+// GetUsersByIds skips IDs that don't exist, callers match the result up by ID
+func (r *userRepository) GetUsersByIds(ctx context.Context, db database.DBRunner, userIDs []uuid.UUID) ([]*models.User, error) {
+	query := `
+		SELECT id, username, display_name, email, password_hash, description, phone_number,
+		       avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
+		FROM users
+		WHERE id = ANY($1)
+	`
+
+	rows, err := db.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0, len(userIDs))
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 func (r *userRepository) DoesUserExists(ctx context.Context, db database.DBRunner, userID uuid.UUID) (bool, error) {
 	query := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`
 	var exists bool
@@ -187,7 +226,7 @@ func (r *userRepository) UpdateUserById(ctx context.Context, db database.DBRunne
 		SET %s
 		WHERE id = $%d
 		RETURNING id, username, display_name, email, password_hash, description, phone_number,
-		          avatar_url, avatar_thumbnail_url, friend_policy, created_at, updated_at
+		          avatar_url, avatar_thumbnail_url, friend_policy, email_verified_at, is_bot, bot_owner_id, created_at, updated_at
 	`, strings.Join(setClauses, ", "), i)
 
 	return scanUser(db.QueryRow(ctx, query, args...))
@@ -199,12 +238,44 @@ func (r *userRepository) UpdateUsername(ctx context.Context, db database.DBRunne
 	})
 }
 
+// UpdateEmail also drops the verified flag, the new address has to be verified again
 func (r *userRepository) UpdateEmail(ctx context.Context, db database.DBRunner, userID uuid.UUID, email string) (*models.User, error) {
 	return r.UpdateUserById(ctx, db, userID, map[string]any{
-		"email": email,
+		"email":             email,
+		"email_verified_at": nil,
 	})
 }
 
+// MarkEmailVerified only succeeds while email is still the user's address,
+// so a link sent to a previous address can't verify the current one.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, db database.DBRunner, userID uuid.UUID, email string) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, now())
+		WHERE id = $1 AND lower(email) = lower($2)
+	`
+
+	tag, err := db.Exec(ctx, query, userID, email)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *userRepository) UpdatePasswordHash(ctx context.Context, db database.DBRunner, userID uuid.UUID, passwordHash string) error {
+	tag, err := db.Exec(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1`, userID, passwordHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
 func (r *userRepository) DeleteUserById(ctx context.Context, db database.DBRunner, userID uuid.UUID) error {
 	tag, err := db.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
 	if err != nil {
@@ -428,7 +499,7 @@ func (r *userRepository) ListFriends(ctx context.Context, db database.DBRunner,
 	query := `
 		SELECT u.id, u.username, u.display_name, u.email, u.password_hash, u.description,
 		       u.phone_number, u.avatar_url, u.avatar_thumbnail_url, u.friend_policy,
-		       u.created_at, u.updated_at
+		       u.email_verified_at, u.is_bot, u.bot_owner_id, u.created_at, u.updated_at
 		FROM friends f
 		INNER JOIN users u
 			ON u.id = CASE
@@ -489,7 +560,7 @@ func (r *userRepository) GetMutualFriends(ctx context.Context, db database.DBRun
 		)
 		SELECT u.id, u.username, u.display_name, u.email, u.password_hash, u.description,
 		       u.phone_number, u.avatar_url, u.avatar_thumbnail_url, u.friend_policy,
-		       u.created_at, u.updated_at
+		       u.email_verified_at, u.is_bot, u.bot_owner_id, u.created_at, u.updated_at
 		FROM users u
 		INNER JOIN current_user_friends cuf ON cuf.friend_id = u.id
 		INNER JOIN target_user_friends tuf ON tuf.friend_id = u.id
@@ -542,39 +613,67 @@ func (r *userRepository) CountMutualFriends(ctx context.Context, db database.DBR
 	return count, nil
 }
 
+const userAppLinkColumns = `
+	id, user_id, provider, url, show_on_profile, external_id, external_handle,
+	verified_at, access_token_enc, refresh_token_enc, token_expires_at, created_at, updated_at
+`
+
+func scanUserAppLink(row pgx.Row) (*models.UserAppLink, error) {
+	link := &models.UserAppLink{}
+	err := row.Scan(
+		&link.ID,
+		&link.UserID,
+		&link.Provider,
+		&link.URL,
+		&link.ShowOnProfile,
+		&link.ExternalID,
+		&link.ExternalHandle,
+		&link.VerifiedAt,
+		&link.AccessTokenEnc,
+		&link.RefreshTokenEnc,
+		&link.TokenExpiresAt,
+		&link.CreatedAt,
+		&link.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
 func (r *userRepository) UpsertAppLink(ctx context.Context, db database.DBRunner, link *models.UserAppLink) (*models.UserAppLink, error) {
 	query := `
-		INSERT INTO user_app_links (id, user_id, provider, url, show_on_profile)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO user_app_links (
+			id, user_id, provider, url, show_on_profile, external_id, external_handle,
+			verified_at, access_token_enc, refresh_token_enc, token_expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (user_id, provider)
 		DO UPDATE SET
 			url = EXCLUDED.url,
 			show_on_profile = EXCLUDED.show_on_profile,
+			external_id = EXCLUDED.external_id,
+			external_handle = EXCLUDED.external_handle,
+			verified_at = EXCLUDED.verified_at,
+			access_token_enc = EXCLUDED.access_token_enc,
+			refresh_token_enc = EXCLUDED.refresh_token_enc,
+			token_expires_at = EXCLUDED.token_expires_at,
 			updated_at = now()
-		RETURNING id, user_id, provider, url, show_on_profile, created_at, updated_at
-	`
+		RETURNING ` + userAppLinkColumns
 
-	out := &models.UserAppLink{}
-	err := db.QueryRow(ctx, query,
+	return scanUserAppLink(db.QueryRow(ctx, query,
 		link.ID,
 		link.UserID,
 		link.Provider,
 		link.URL,
 		link.ShowOnProfile,
-	).Scan(
-		&out.ID,
-		&out.UserID,
-		&out.Provider,
-		&out.URL,
-		&out.ShowOnProfile,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return out, nil
+		link.ExternalID,
+		link.ExternalHandle,
+		link.VerifiedAt,
+		link.AccessTokenEnc,
+		link.RefreshTokenEnc,
+		link.TokenExpiresAt,
+	))
 }
 
 func (r *userRepository) DeleteAppLink(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider) error {
@@ -593,7 +692,7 @@ func (r *userRepository) DeleteAppLink(ctx context.Context, db database.DBRunner
 
 func (r *userRepository) GetUserAppLinks(ctx context.Context, db database.DBRunner, userID uuid.UUID, onlyVisible bool) ([]*models.UserAppLink, error) {
 	query := `
-		SELECT id, user_id, provider, url, show_on_profile, created_at, updated_at
+		SELECT ` + userAppLinkColumns + `
 		FROM user_app_links
 		WHERE user_id = $1
 	`
@@ -613,16 +712,8 @@ func (r *userRepository) GetUserAppLinks(ctx context.Context, db database.DBRunn
 
 	var links []*models.UserAppLink
 	for rows.Next() {
-		current := &models.UserAppLink{}
-		if err := rows.Scan(
-			&current.ID,
-			&current.UserID,
-			&current.Provider,
-			&current.URL,
-			&current.ShowOnProfile,
-			&current.CreatedAt,
-			&current.UpdatedAt,
-		); err != nil {
+		current, err := scanUserAppLink(rows)
+		if err != nil {
 			return nil, err
 		}
 		links = append(links, current)
@@ -633,24 +724,65 @@ func (r *userRepository) GetUserAppLinks(ctx context.Context, db database.DBRunn
 
 func (r *userRepository) GetUserAppLinkByProvider(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider) (*models.UserAppLink, error) {
 	query := `
-		SELECT id, user_id, provider, url, show_on_profile, created_at, updated_at
+		SELECT ` + userAppLinkColumns + `
 		FROM user_app_links
 		WHERE user_id = $1 AND provider = $2
 	`
 
-	link := &models.UserAppLink{}
-	err := db.QueryRow(ctx, query, userID, provider).Scan(
-		&link.ID,
-		&link.UserID,
-		&link.Provider,
-		&link.URL,
-		&link.ShowOnProfile,
-		&link.CreatedAt,
-		&link.UpdatedAt,
+	return scanUserAppLink(db.QueryRow(ctx, query, userID, provider))
+}
+
+func (r *userRepository) SetAppLinkVisibility(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider, show bool) (*models.UserAppLink, error) {
+	query := `
+		UPDATE user_app_links
+		SET show_on_profile = $3, updated_at = now()
+		WHERE user_id = $1 AND provider = $2
+		RETURNING ` + userAppLinkColumns
+
+	return scanUserAppLink(db.QueryRow(ctx, query, userID, provider, show))
+}
+
+func (r *userRepository) CreateAppLinkState(ctx context.Context, db database.DBRunner, state *models.AppLinkState) error {
+	query := `
+		INSERT INTO app_link_states (state_hash, user_id, provider, code_verifier, show_on_profile, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := db.Exec(ctx, query,
+		state.StateHash,
+		state.UserID,
+		state.Provider,
+		state.CodeVerifier,
+		state.ShowOnProfile,
+		state.ExpiresAt,
+	)
+	return err
+}
+
+func (r *userRepository) ConsumeAppLinkState(ctx context.Context, db database.DBRunner, userID uuid.UUID, provider models.AppProvider, stateHash []byte) (*models.AppLinkState, error) {
+	query := `
+		DELETE FROM app_link_states
+		WHERE state_hash = $1 AND user_id = $2 AND provider = $3 AND expires_at > now()
+		RETURNING state_hash, user_id, provider, code_verifier, show_on_profile, expires_at, created_at
+	`
+
+	state := &models.AppLinkState{}
+	err := db.QueryRow(ctx, query, stateHash, userID, provider).Scan(
+		&state.StateHash,
+		&state.UserID,
+		&state.Provider,
+		&state.CodeVerifier,
+		&state.ShowOnProfile,
+		&state.ExpiresAt,
+		&state.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	return state, nil
+}
 
-	return link, nil
+func (r *userRepository) DeleteExpiredAppLinkStates(ctx context.Context, db database.DBRunner) error {
+	_, err := db.Exec(ctx, `DELETE FROM app_link_states WHERE expires_at <= now()`)
+	return err
 }
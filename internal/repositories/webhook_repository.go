@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IWebhookRepository interface {
+	CreateWebhook(ctx context.Context, db database.DBRunner, webhook *models.Webhook) (*models.Webhook, error)
+	GetWebhookByID(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (*models.Webhook, error)
+	ListRoomWebhooks(ctx context.Context, db database.DBRunner, roomID uuid.UUID) ([]*models.Webhook, error)
+	CountRoomWebhooks(ctx context.Context, db database.DBRunner, roomID uuid.UUID) (int, error)
+
+	UpdateWebhookToken(ctx context.Context, db database.DBRunner, webhookID uuid.UUID, tokenHash []byte) (*models.Webhook, error)
+
+	// DeleteWebhook keeps the webhook's user, old messages still point at it
+	DeleteWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error
+
+	// TouchWebhook records a use, at most once a minute per webhook
+	TouchWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error
+}
+
+type webhookRepository struct{}
+
+func NewWebhookRepository() IWebhookRepository {
+	return &webhookRepository{}
+}
+
+const webhookColumns = `
+	id, room_id, user_id, name, avatar_url, token_hash, created_by, last_used_at, created_at, updated_at
+`
+
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.RoomID,
+		&webhook.UserID,
+		&webhook.Name,
+		&webhook.AvatarURL,
+		&webhook.TokenHash,
+		&webhook.CreatedBy,
+		&webhook.LastUsedAt,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, db database.DBRunner, webhook *models.Webhook) (*models.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (id, room_id, user_id, name, avatar_url, token_hash, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + webhookColumns
+
+	return scanWebhook(db.QueryRow(ctx, query,
+		webhook.ID,
+		webhook.RoomID,
+		webhook.UserID,
+		webhook.Name,
+		webhook.AvatarURL,
+		webhook.TokenHash,
+		webhook.CreatedBy,
+	))
+}
+
+func (r *webhookRepository) GetWebhookByID(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+	return scanWebhook(db.QueryRow(ctx, query, webhookID))
+}
+
+func (r *webhookRepository) ListRoomWebhooks(ctx context.Context, db database.DBRunner, roomID uuid.UUID) ([]*models.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE room_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := db.Query(ctx, query, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]*models.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *webhookRepository) CountRoomWebhooks(ctx context.Context, db database.DBRunner, roomID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM webhooks WHERE room_id = $1`, roomID).Scan(&count)
+	return count, err
+}
+
+func (r *webhookRepository) UpdateWebhookToken(ctx context.Context, db database.DBRunner, webhookID uuid.UUID, tokenHash []byte) (*models.Webhook, error) {
+	query := `
+		UPDATE webhooks
+		SET token_hash = $2, updated_at = now()
+		WHERE id = $1
+		RETURNING ` + webhookColumns
+
+	return scanWebhook(db.QueryRow(ctx, query, webhookID, tokenHash))
+}
+
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error {
+	tag, err := db.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, webhookID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *webhookRepository) TouchWebhook(ctx context.Context, db database.DBRunner, webhookID uuid.UUID) error {
+	query := `
+		UPDATE webhooks
+		SET last_used_at = now()
+		WHERE id = $1
+		  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	_, err := db.Exec(ctx, query, webhookID)
+	return err
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/suck-seed/yapp/internal/models"
+)
+
+type IWSTicketRepository interface {
+	CreateWSTicket(ctx context.Context, ticketHash []byte, ticket *models.WSTicket, ttl time.Duration) error
+
+	// ConsumeWSTicket deletes the ticket while reading it, so two upgrades
+	// racing with the same ticket can't both get it. redis.Nil when it is
+	// unknown, expired or already used.
+	ConsumeWSTicket(ctx context.Context, ticketHash []byte) (*models.WSTicket, error)
+}
+
+type wsTicketRepository struct {
+	client *redis.Client
+}
+
+func NewWSTicketRepository(client *redis.Client) IWSTicketRepository {
+	return &wsTicketRepository{client: client}
+}
+
+func wsTicketKey(ticketHash []byte) string {
+	return "ws:ticket:" + hex.EncodeToString(ticketHash)
+}
+
+func (r *wsTicketRepository) CreateWSTicket(ctx context.Context, ticketHash []byte, ticket *models.WSTicket, ttl time.Duration) error {
+	payload, err := json.Marshal(ticket)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, wsTicketKey(ticketHash), payload, ttl).Err()
+}
+
+func (r *wsTicketRepository) ConsumeWSTicket(ctx context.Context, ticketHash []byte) (*models.WSTicket, error) {
+	payload, err := r.client.GetDel(ctx, wsTicketKey(ticketHash)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &models.WSTicket{}
+	if err := json.Unmarshal(payload, ticket); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/mail"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+
+	// minimum gap between two e-mails of the same kind to the same account
+	emailTokenCooldown = time.Minute
+
+	mailDeliveryTimeout = 15 * time.Second
+)
+
+type IAccountService interface {
+	// -------------- EMAIL VERIFICATION
+	// SendVerificationEmail issues a fresh link for the user's current address,
+	// called after signup and after the address changed.
+	SendVerificationEmail(c context.Context, user *models.User) error
+	ResendVerificationEmail(c context.Context, userInfo *auth.UserInfo) error
+	VerifyEmail(c context.Context, req *dto.VerifyEmailReq) error
+	IsEmailVerified(c context.Context, userID uuid.UUID) (bool, error)
+
+	// -------------- PASSWORD RESET
+	RequestPasswordReset(c context.Context, req *dto.ForgotPasswordReq) error
+	ResetPassword(c context.Context, req *dto.ResetPasswordReq) error
+}
+
+type accountService struct {
+	repositories.IUserRepository
+	repositories.IEmailTokenRepository
+
+	mailer     mail.Mailer
+	appBaseURL string
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewAccountService(
+	userRepo repositories.IUserRepository,
+	emailTokenRepo repositories.IEmailTokenRepository,
+	mailer mail.Mailer,
+	appBaseURL string,
+	pool *pgxpool.Pool,
+) IAccountService {
+	return &accountService{
+		userRepo,
+		emailTokenRepo,
+		mailer,
+		strings.TrimRight(appBaseURL, "/"),
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+// issueEmailToken retires any outstanding token of the same purpose and
+// stores a new one, returning the raw value for the link.
+func (s *accountService) issueEmailToken(ctx context.Context, runner database.DBRunner, user *models.User, purpose models.EmailTokenPurpose, ttl time.Duration) (string, error) {
+	lastIssued, err := s.IEmailTokenRepository.GetLastEmailTokenIssuedAt(ctx, runner, user.ID, purpose)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return "", utils.ErrorRequestTimeout
+		}
+		return "", utils.ErrorIssuingEmailToken
+	}
+	if lastIssued != nil && time.Since(*lastIssued) < emailTokenCooldown {
+		return "", utils.ErrorEmailTokenCooldown
+	}
+
+	if err := s.IEmailTokenRepository.InvalidateEmailTokens(ctx, runner, user.ID, purpose); err != nil {
+		if utils.IsDeadline(err) {
+			return "", utils.ErrorRequestTimeout
+		}
+		return "", utils.ErrorIssuingEmailToken
+	}
+
+	raw, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", utils.ErrorInternal
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", utils.ErrorInternal
+	}
+
+	_, err = s.IEmailTokenRepository.CreateEmailToken(ctx, runner, &models.EmailToken{
+		ID:        id,
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return "", utils.ErrorRequestTimeout
+		}
+		return "", utils.ErrorIssuingEmailToken
+	}
+
+	return raw, nil
+}
+
+// consumeEmailToken burns a raw token from a link, whatever went wrong with
+// it the caller only learns that it is not valid.
+func (s *accountService) consumeEmailToken(ctx context.Context, runner database.DBRunner, purpose models.EmailTokenPurpose, raw string) (*models.EmailToken, error) {
+	token, err := s.IEmailTokenRepository.ConsumeEmailToken(ctx, runner, purpose, auth.HashOpaqueToken(raw))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidEmailToken
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return token, nil
+}
+
+func (s *accountService) redeemVerifyEmailToken(ctx context.Context, runner database.DBRunner, raw string) error {
+	token, err := s.consumeEmailToken(ctx, runner, models.EmailTokenVerifyEmail, raw)
+	if err != nil {
+		return err
+	}
+
+	// the address changed since the link went out
+	if err := s.IUserRepository.MarkEmailVerified(ctx, runner, token.UserID, token.Email); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorInvalidEmailToken
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+func (s *accountService) redeemResetPasswordToken(ctx context.Context, runner database.DBRunner, raw string, passwordHash string) error {
+	token, err := s.consumeEmailToken(ctx, runner, models.EmailTokenResetPassword, raw)
+	if err != nil {
+		return err
+	}
+
+	if err := s.IUserRepository.UpdatePasswordHash(ctx, runner, token.UserID, passwordHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorInvalidEmailToken
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	// following the link proved the inbox belongs to the user
+	if err := s.IUserRepository.MarkEmailVerified(ctx, runner, token.UserID, token.Email); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+func (s *accountService) link(path string, token string) string {
+	return s.appBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// deliverMail sends in the background, SMTP round trips don't fit in a request timeout
+func deliverMail(mailer mail.Mailer, msg *mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailDeliveryTimeout)
+		defer cancel()
+
+		if err := mailer.Send(ctx, msg); err != nil {
+			log.Printf("mail: send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+func (s *accountService) deliver(msg *mail.Message) {
+	deliverMail(s.mailer, msg)
+}
+
+// ── EMAIL VERIFICATION ────────────────────────────────────────────────────────
+
+func (s *accountService) SendVerificationEmail(c context.Context, user *models.User) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	raw, err := s.issueEmailToken(ctx, runner, user, models.EmailTokenVerifyEmail, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	s.deliver(mail.VerifyEmailMessage(user.Email, user.DisplayName, s.link("/verify-email", raw)))
+
+	return nil
+}
+
+func (s *accountService) ResendVerificationEmail(c context.Context, userInfo *auth.UserInfo) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewConnWrapper(conn)
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, userInfo.ID)
+	conn.Release()
+	if err != nil {
+		return utils.ErrorUserNotFound
+	}
+
+	if user.IsEmailVerified() {
+		return utils.ErrorEmailAlreadyVerified
+	}
+
+	return s.SendVerificationEmail(c, user)
+}
+
+func (s *accountService) VerifyEmail(c context.Context, req *dto.VerifyEmailReq) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.redeemVerifyEmailToken(ctx, runner, req.Token); err != nil {
+		return err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+func (s *accountService) IsEmailVerified(c context.Context, userID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return false, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, userID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return false, utils.ErrorRequestTimeout
+		}
+		return false, utils.ErrorUserNotFound
+	}
+
+	return user.IsEmailVerified(), nil
+}
+
+// ── PASSWORD RESET ────────────────────────────────────────────────────────────
+
+// RequestPasswordReset never reveals whether the address has an account,
+// unknown addresses and throttled requests look exactly like a sent e-mail.
+func (s *accountService) RequestPasswordReset(c context.Context, req *dto.ForgotPasswordReq) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	canonEmail, err := utils.SanitizeEmail(req.Email)
+	if err != nil {
+		return utils.ErrorInvalidEmail
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	user, err := s.IUserRepository.GetUserByEmail(ctx, runner, canonEmail)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	raw, err := s.issueEmailToken(ctx, runner, user, models.EmailTokenResetPassword, passwordResetTTL)
+	if err != nil {
+		if errors.Is(err, utils.ErrorEmailTokenCooldown) {
+			return nil
+		}
+		return err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	s.deliver(mail.PasswordResetMessage(user.Email, user.DisplayName, s.link("/reset-password", raw)))
+
+	return nil
+}
+
+func (s *accountService) ResetPassword(c context.Context, req *dto.ResetPasswordReq) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	canonPassword, err := utils.SanitizeNewPassword(req.NewPassword)
+	if err != nil {
+		if errors.Is(err, utils.ErrorBreachedPassword) {
+			return err
+		}
+		return utils.ErrorInvalidPassword
+	}
+
+	passwordHash, err := utils.HashPassword(canonPassword)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.redeemResetPasswordToken(ctx, runner, req.Token, passwordHash); err != nil {
+		return err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
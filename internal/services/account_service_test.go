@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/mail"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const testAppBaseURL = "https://yapp.test"
+
+// fakeEmailTokenRepo keeps the same rules as the email_tokens queries: a
+// token is consumed once, before it expires and only for its own purpose
+type fakeEmailTokenRepo struct {
+	mu     sync.Mutex
+	tokens []*models.EmailToken
+}
+
+func (r *fakeEmailTokenRepo) CreateEmailToken(ctx context.Context, db database.DBRunner, token *models.EmailToken) (*models.EmailToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *token
+	saved.CreatedAt = time.Now()
+	r.tokens = append(r.tokens, &saved)
+	return &saved, nil
+}
+
+func (r *fakeEmailTokenRepo) ConsumeEmailToken(ctx context.Context, db database.DBRunner, purpose models.EmailTokenPurpose, tokenHash []byte) (*models.EmailToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if string(t.TokenHash) != string(tokenHash) || t.Purpose != purpose {
+			continue
+		}
+		if t.UsedAt != nil || !t.ExpiresAt.After(time.Now()) {
+			return nil, pgx.ErrNoRows
+		}
+		now := time.Now()
+		t.UsedAt = &now
+		used := *t
+		return &used, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeEmailTokenRepo) InvalidateEmailTokens(ctx context.Context, db database.DBRunner, userID uuid.UUID, purpose models.EmailTokenPurpose) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeEmailTokenRepo) GetLastEmailTokenIssuedAt(ctx context.Context, db database.DBRunner, userID uuid.UUID, purpose models.EmailTokenPurpose) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last *time.Time
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && (last == nil || t.CreatedAt.After(*last)) {
+			createdAt := t.CreatedAt
+			last = &createdAt
+		}
+	}
+	return last, nil
+}
+
+// age moves every token back in time, to get past the resend cooldown or
+// past the expiry
+func (r *fakeEmailTokenRepo) age(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		t.CreatedAt = t.CreatedAt.Add(-d)
+		t.ExpiresAt = t.ExpiresAt.Add(-d)
+	}
+}
+
+type fakeAccountUserRepo struct {
+	repositories.IUserRepository
+
+	mu            sync.Mutex
+	user          *models.User
+	passwordHash  string
+	passwordSaves int
+}
+
+func (r *fakeAccountUserRepo) MarkEmailVerified(ctx context.Context, db database.DBRunner, userID uuid.UUID, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.user.ID != userID || r.user.Email != email {
+		return pgx.ErrNoRows
+	}
+	now := time.Now()
+	r.user.EmailVerifiedAt = &now
+	return nil
+}
+
+func (r *fakeAccountUserRepo) UpdatePasswordHash(ctx context.Context, db database.DBRunner, userID uuid.UUID, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.user.ID != userID {
+		return pgx.ErrNoRows
+	}
+	r.passwordHash = passwordHash
+	r.passwordSaves++
+	return nil
+}
+
+// fakeMailer catches everything the service sends
+type fakeMailer struct {
+	sent chan *mail.Message
+}
+
+func newFakeMailer() *fakeMailer {
+	return &fakeMailer{sent: make(chan *mail.Message, 8)}
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.sent <- msg
+	return nil
+}
+
+type accountTestEnv struct {
+	s      *accountService
+	tokens *fakeEmailTokenRepo
+	users  *fakeAccountUserRepo
+	mailer *fakeMailer
+	user   *models.User
+}
+
+func newAccountTestEnv(t *testing.T) *accountTestEnv {
+	t.Helper()
+
+	user := &models.User{
+		ID:          uuid.New(),
+		Email:       "ana@yapp.test",
+		DisplayName: "Ana",
+	}
+	tokens := &fakeEmailTokenRepo{}
+	users := &fakeAccountUserRepo{user: user}
+	mailer := newFakeMailer()
+
+	s := NewAccountService(users, tokens, mailer, testAppBaseURL+"/", nil).(*accountService)
+
+	return &accountTestEnv{s: s, tokens: tokens, users: users, mailer: mailer, user: user}
+}
+
+// mailLink issues a token the way the service does and returns the one that
+// arrived in the inbox, read back from the link in the e-mail
+func (e *accountTestEnv) mailLink(t *testing.T, purpose models.EmailTokenPurpose) string {
+	t.Helper()
+
+	ttl, path, message := emailVerificationTTL, "/verify-email", mail.VerifyEmailMessage
+	if purpose == models.EmailTokenResetPassword {
+		ttl, path, message = passwordResetTTL, "/reset-password", mail.PasswordResetMessage
+	}
+
+	raw, err := e.s.issueEmailToken(context.Background(), nil, e.user, purpose, ttl)
+	if err != nil {
+		t.Fatalf("issue %s token: %v", purpose, err)
+	}
+	deliverMail(e.mailer, message(e.user.Email, e.user.DisplayName, e.s.link(path, raw)))
+
+	var msg *mail.Message
+	select {
+	case msg = <-e.mailer.sent:
+	case <-time.After(time.Second):
+		t.Fatal("no e-mail was sent")
+	}
+	if msg.To != e.user.Email {
+		t.Fatalf("mailed %s, want %s", msg.To, e.user.Email)
+	}
+
+	for _, line := range strings.Split(msg.Text, "\n") {
+		if !strings.HasPrefix(line, testAppBaseURL+path+"?") {
+			continue
+		}
+		link, err := url.Parse(line)
+		if err != nil {
+			t.Fatalf("parse link %q: %v", line, err)
+		}
+		return link.Query().Get("token")
+	}
+
+	t.Fatalf("no %s link in e-mail:\n%s", path, msg.Text)
+	return ""
+}
+
+func TestVerifyEmailTokenWorksOnce(t *testing.T) {
+	e := newAccountTestEnv(t)
+	token := e.mailLink(t, models.EmailTokenVerifyEmail)
+
+	if err := e.s.redeemVerifyEmailToken(context.Background(), nil, token); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if !e.user.IsEmailVerified() {
+		t.Fatal("user is not verified after following the link")
+	}
+
+	err := e.s.redeemVerifyEmailToken(context.Background(), nil, token)
+	if !errors.Is(err, utils.ErrorInvalidEmailToken) {
+		t.Fatalf("second use: got %v, want %v", err, utils.ErrorInvalidEmailToken)
+	}
+}
+
+func TestResetPasswordTokenWorksOnce(t *testing.T) {
+	e := newAccountTestEnv(t)
+	token := e.mailLink(t, models.EmailTokenResetPassword)
+
+	if err := e.s.redeemResetPasswordToken(context.Background(), nil, token, "hash-1"); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+
+	err := e.s.redeemResetPasswordToken(context.Background(), nil, token, "hash-2")
+	if !errors.Is(err, utils.ErrorInvalidEmailToken) {
+		t.Fatalf("second use: got %v, want %v", err, utils.ErrorInvalidEmailToken)
+	}
+	if e.users.passwordSaves != 1 || e.users.passwordHash != "hash-1" {
+		t.Errorf("password saved %d times, last %q, want once with hash-1", e.users.passwordSaves, e.users.passwordHash)
+	}
+	if !e.user.IsEmailVerified() {
+		t.Error("a reset link should verify the address it was sent to")
+	}
+}
+
+func TestEmailTokenOnlyRedeemsForItsPurpose(t *testing.T) {
+	e := newAccountTestEnv(t)
+	token := e.mailLink(t, models.EmailTokenResetPassword)
+
+	err := e.s.redeemVerifyEmailToken(context.Background(), nil, token)
+	if !errors.Is(err, utils.ErrorInvalidEmailToken) {
+		t.Fatalf("reset token used to verify: got %v, want %v", err, utils.ErrorInvalidEmailToken)
+	}
+
+	// the failed attempt must not have burnt it
+	if err := e.s.redeemResetPasswordToken(context.Background(), nil, token, "hash"); err != nil {
+		t.Fatalf("reset after misuse: %v", err)
+	}
+}
+
+func TestExpiredEmailTokenIsRejected(t *testing.T) {
+	e := newAccountTestEnv(t)
+	token := e.mailLink(t, models.EmailTokenResetPassword)
+
+	e.tokens.age(passwordResetTTL + time.Second)
+
+	err := e.s.redeemResetPasswordToken(context.Background(), nil, token, "hash")
+	if !errors.Is(err, utils.ErrorInvalidEmailToken) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorInvalidEmailToken)
+	}
+	if e.users.passwordSaves != 0 {
+		t.Error("an expired link changed the password")
+	}
+}
+
+func TestReissuedEmailTokenRetiresTheOldOne(t *testing.T) {
+	e := newAccountTestEnv(t)
+	first := e.mailLink(t, models.EmailTokenVerifyEmail)
+
+	e.tokens.age(emailTokenCooldown)
+	second := e.mailLink(t, models.EmailTokenVerifyEmail)
+
+	err := e.s.redeemVerifyEmailToken(context.Background(), nil, first)
+	if !errors.Is(err, utils.ErrorInvalidEmailToken) {
+		t.Fatalf("old link: got %v, want %v", err, utils.ErrorInvalidEmailToken)
+	}
+	if err := e.s.redeemVerifyEmailToken(context.Background(), nil, second); err != nil {
+		t.Fatalf("new link: %v", err)
+	}
+}
+
+func TestEmailTokenResendCooldown(t *testing.T) {
+	e := newAccountTestEnv(t)
+	e.mailLink(t, models.EmailTokenVerifyEmail)
+
+	_, err := e.s.issueEmailToken(context.Background(), nil, e.user, models.EmailTokenVerifyEmail, emailVerificationTTL)
+	if !errors.Is(err, utils.ErrorEmailTokenCooldown) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorEmailTokenCooldown)
+	}
+}
+
+func TestVerifyEmailTokenForOldAddressIsRejected(t *testing.T) {
+	e := newAccountTestEnv(t)
+	token := e.mailLink(t, models.EmailTokenVerifyEmail)
+
+	e.user.Email = "ana@elsewhere.test"
+
+	err := e.s.redeemVerifyEmailToken(context.Background(), nil, token)
+	if !errors.Is(err, utils.ErrorInvalidEmailToken) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorInvalidEmailToken)
+	}
+	if e.user.IsEmailVerified() {
+		t.Error("the new address was verified by a link sent to the old one")
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/applinks"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const appLinkStateTTL = 10 * time.Minute
+
+type IAppLinkService interface {
+	ListAppLinkProviders(c context.Context) []dto.AppLinkProviderRes
+
+	// -------------- CONNECT
+	BeginAppLink(c context.Context, userInfo *auth.UserInfo, provider models.AppProvider, req *dto.BeginAppLinkReq) (*dto.AppLinkAuthorizationRes, error)
+	CompleteAppLink(c context.Context, userInfo *auth.UserInfo, provider models.AppProvider, req *dto.CompleteAppLinkReq) (*dto.AppLinkRes, error)
+
+	UpdateAppLink(c context.Context, userInfo *auth.UserInfo, provider models.AppProvider, req *dto.UpdateAppLinkReq) (*dto.AppLinkRes, error)
+}
+
+type appLinkService struct {
+	repositories.IUserRepository
+
+	providers *applinks.Registry
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewAppLinkService(
+	userRepo repositories.IUserRepository,
+	providers *applinks.Registry,
+	pool *pgxpool.Pool,
+) IAppLinkService {
+	return &appLinkService{
+		userRepo,
+		providers,
+		pool,
+		// the provider round trips don't fit in the usual 2s
+		time.Duration(15) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+func appLinkToRes(link *models.UserAppLink) *dto.AppLinkRes {
+	return &dto.AppLinkRes{
+		ID:            link.ID,
+		UserID:        link.UserID,
+		Provider:      link.Provider,
+		URL:           link.URL,
+		ShowOnProfile: link.ShowOnProfile,
+		Handle:        link.ExternalHandle,
+		Verified:      link.IsVerified(),
+		VerifiedAt:    link.VerifiedAt,
+		CreatedAt:     link.CreatedAt,
+		UpdatedAt:     link.UpdatedAt,
+	}
+}
+
+func (s *appLinkService) provider(name models.AppProvider) (applinks.Provider, error) {
+	p, ok := s.providers.Get(strings.ToLower(string(name)))
+	if !ok {
+		return nil, utils.ErrorAppLinkProviderNotFound
+	}
+	return p, nil
+}
+
+func (s *appLinkService) ListAppLinkProviders(c context.Context) []dto.AppLinkProviderRes {
+	out := make([]dto.AppLinkProviderRes, 0)
+	for _, p := range s.providers.List() {
+		out = append(out, dto.AppLinkProviderRes{
+			Name:        p.Name(),
+			DisplayName: p.DisplayName(),
+		})
+	}
+	return out
+}
+
+func (s *appLinkService) BeginAppLink(c context.Context, userInfo *auth.UserInfo, providerName models.AppProvider, req *dto.BeginAppLinkReq) (*dto.AppLinkAuthorizationRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	rawState, stateHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	verifier, err := applinks.NewCodeVerifier()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	show := true
+	if req.ShowOnProfile != nil {
+		show = *req.ShowOnProfile
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.IUserRepository.DeleteExpiredAppLinkStates(ctx, runner); err != nil {
+		log.Printf("app links: prune states: %v", err)
+	}
+
+	err = s.IUserRepository.CreateAppLinkState(ctx, runner, &models.AppLinkState{
+		StateHash:     stateHash,
+		UserID:        userInfo.ID,
+		Provider:      models.AppProvider(provider.Name()),
+		CodeVerifier:  verifier,
+		ShowOnProfile: show,
+		ExpiresAt:     time.Now().Add(appLinkStateTTL),
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.AppLinkAuthorizationRes{
+		AuthorizationURL: provider.AuthorizationURL(rawState, verifier),
+	}, nil
+}
+
+func (s *appLinkService) CompleteAppLink(c context.Context, userInfo *auth.UserInfo, providerName models.AppProvider, req *dto.CompleteAppLinkReq) (*dto.AppLinkRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	name := models.AppProvider(provider.Name())
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	state, err := s.IUserRepository.ConsumeAppLinkState(ctx, database.NewConnWrapper(conn), userInfo.ID, name, auth.HashOpaqueToken(req.Params["state"]))
+	conn.Release()
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidAppLinkState
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	connection, err := provider.Complete(ctx, req.Params, state.CodeVerifier)
+	if err != nil {
+		log.Printf("app links: %s complete: %v", provider.Name(), err)
+		if errors.Is(err, applinks.ErrAuthorizationFailed) {
+			return nil, utils.ErrorAppLinkAuthorizationFailed
+		}
+		return nil, utils.ErrorAppLinkProviderFailed
+	}
+
+	now := time.Now()
+	link := &models.UserAppLink{
+		UserID:         userInfo.ID,
+		Provider:       name,
+		URL:            connection.Account.ProfileURL,
+		ShowOnProfile:  state.ShowOnProfile,
+		ExternalID:     &connection.Account.ExternalID,
+		ExternalHandle: &connection.Account.Handle,
+		VerifiedAt:     &now,
+	}
+
+	if connection.Token != nil {
+		link.AccessTokenEnc, err = auth.Seal([]byte(connection.Token.AccessToken))
+		if err != nil {
+			return nil, utils.ErrorInternal
+		}
+		if connection.Token.RefreshToken != "" {
+			link.RefreshTokenEnc, err = auth.Seal([]byte(connection.Token.RefreshToken))
+			if err != nil {
+				return nil, utils.ErrorInternal
+			}
+		}
+		link.TokenExpiresAt = connection.Token.ExpiresAt
+	}
+
+	link.ID, err = uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	saved, err := s.IUserRepository.UpsertAppLink(ctx, runner, link)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, utils.ErrorAppAccountAlreadyLinked
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return appLinkToRes(saved), nil
+}
+
+func (s *appLinkService) UpdateAppLink(c context.Context, userInfo *auth.UserInfo, provider models.AppProvider, req *dto.UpdateAppLinkReq) (*dto.AppLinkRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	link, err := s.IUserRepository.SetAppLinkVisibility(ctx, runner, userInfo.ID, models.AppProvider(strings.ToLower(string(provider))), *req.ShowOnProfile)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorAppLinkNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return appLinkToRes(link), nil
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	hallDto "github.com/suck-seed/yapp/internal/dto/hall"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/realtime"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// MAX_BOTS_PER_OWNER keeps one account from minting an army of bots
+const MAX_BOTS_PER_OWNER = 25
+
+type IBotService interface {
+	CreateBot(c context.Context, userInfo *auth.UserInfo, req *dto.CreateBotReq) (*dto.BotTokenRes, error)
+	ListMyBots(c context.Context, userInfo *auth.UserInfo) (*dto.BotsRes, error)
+	ResetBotToken(c context.Context, userInfo *auth.UserInfo, botID uuid.UUID) (*dto.BotTokenRes, error)
+	DeleteBot(c context.Context, userInfo *auth.UserInfo, botID uuid.UUID) error
+
+	// AddBotToHall puts one of the caller's bots straight into a hall they
+	// manage, no invite or join request involved
+	AddBotToHall(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.AddBotToHallReq) (*hallDto.JoinHallRes, error)
+
+	// VerifyBotToken is the auth.BotTokenVerifier
+	VerifyBotToken(c context.Context, token string) (*auth.UserInfo, error)
+}
+
+type botService struct {
+	repositories.IBotRepository
+	repositories.IUserRepository
+	repositories.IHallRepository
+	repositories.IRoleRepository
+	repositories.IBanRepsitory
+
+	IPermissionCheckerService
+
+	EventPublisher realtime.Publisher
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewBotService(
+	botRepo repositories.IBotRepository,
+	userRepo repositories.IUserRepository,
+	hallRepo repositories.IHallRepository,
+	roleRepo repositories.IRoleRepository,
+	banRepo repositories.IBanRepsitory,
+	permissionChecker IPermissionCheckerService,
+	eventPublisher realtime.Publisher,
+	pool *pgxpool.Pool,
+) IBotService {
+	return &botService{
+		botRepo,
+		userRepo,
+		hallRepo,
+		roleRepo,
+		banRepo,
+		permissionChecker,
+		eventPublisher,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── BOTS ───────────────────────────────────────────────────────────────────
+
+func (s *botService) CreateBot(c context.Context, userInfo *auth.UserInfo, req *dto.CreateBotReq) (*dto.BotTokenRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	username, err := utils.SanitizeUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	displayName, err := utils.SanitizeDisplayName(req.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	description, err := utils.SanitizeText(req.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	count, err := s.IBotRepository.CountBotsByOwner(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingUser
+	}
+	if count >= MAX_BOTS_PER_OWNER {
+		return nil, utils.ErrorTooManyBots
+	}
+
+	existing, _ := s.IUserRepository.GetUserByUsername(ctx, runner, username)
+	if existing != nil {
+		return nil, utils.ErrorUsernameExists
+	}
+
+	botID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	// bots never sign in with a password, the address only fills the
+	// NOT NULL UNIQUE column and can't collide with a real one
+	bot, err := s.IBotRepository.CreateBot(ctx, runner, &models.User{
+		ID:          botID,
+		Username:    username,
+		DisplayName: displayName,
+		Email:       botID.String() + "@bots.yapp.invalid",
+		Description: description,
+		BotOwnerID:  &userInfo.ID,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorCreatingUser
+	}
+
+	token, err := s.issueBotToken(ctx, runner, bot.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.BotTokenRes{
+		Bot:   dto.ToBotRes(*bot),
+		Token: token,
+	}, nil
+}
+
+func (s *botService) ListMyBots(c context.Context, userInfo *auth.UserInfo) (*dto.BotsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	bots, err := s.IBotRepository.ListBotsByOwner(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingUser
+	}
+
+	res := &dto.BotsRes{Bots: make([]dto.BotRes, 0, len(bots))}
+	for _, bot := range bots {
+		res.Bots = append(res.Bots, dto.ToBotRes(*bot))
+	}
+	return res, nil
+}
+
+func (s *botService) ResetBotToken(c context.Context, userInfo *auth.UserInfo, botID uuid.UUID) (*dto.BotTokenRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	bot, err := s.IBotRepository.GetOwnedBot(ctx, runner, userInfo.ID, botID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorBotNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingUser
+	}
+
+	// the old token stops working as soon as this commits
+	if err := s.IBotRepository.DeleteBotTokens(ctx, runner, bot.ID); err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	token, err := s.issueBotToken(ctx, runner, bot.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.BotTokenRes{
+		Bot:   dto.ToBotRes(*bot),
+		Token: token,
+	}, nil
+}
+
+func (s *botService) DeleteBot(c context.Context, userInfo *auth.UserInfo, botID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.IBotRepository.DeleteOwnedBot(ctx, runner, userInfo.ID, botID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorBotNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+// ── HALLS ──────────────────────────────────────────────────────────────────
+
+func (s *botService) AddBotToHall(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.AddBotToHallReq) (*hallDto.JoinHallRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	bot, err := s.IBotRepository.GetOwnedBot(ctx, runner, userInfo.ID, req.BotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorBotNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingUser
+	}
+
+	canManage, err := s.CanManageServers(ctx, runner, userInfo.ID, hallID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, utils.ErrorUserCannotManageServer
+	}
+
+	var role *models.Role
+	if req.RoleID != nil {
+		role, err = s.IRoleRepository.GetRole(ctx, runner, *req.RoleID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, utils.ErrorRoleNotFound
+			}
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorFetchingRole
+		}
+		if role.HallID != hallID {
+			return nil, utils.ErrorRoleNotFound
+		}
+
+		// handing out anything above the default is a role assignment
+		if !role.IsDefault {
+			canManageRoles, err := s.CanManageRoles(ctx, runner, userInfo.ID, hallID)
+			if err != nil {
+				return nil, err
+			}
+			if !canManageRoles {
+				return nil, utils.ErrorUserCannotManageRoles
+			}
+		}
+	} else {
+		role, err = s.IRoleRepository.GetHallDefaultRole(ctx, runner, hallID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, utils.ErrorHallDefaultRoleNotFound
+			}
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorFetchingRole
+		}
+	}
+
+	isMember, err := s.IHallRepository.IsUserHallMember(ctx, runner, hallID, bot.ID)
+	if err != nil {
+		return nil, utils.ErrorFetchingUser
+	}
+	if isMember {
+		return nil, utils.ErrorAlreadyHallMember
+	}
+
+	isBanned, err := s.IBanRepsitory.IsUserBanned(ctx, runner, hallID, bot.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingBan
+	}
+	if isBanned {
+		return nil, utils.ErrorUserAlreadyBanned
+	}
+
+	memberID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	member, err := s.IHallRepository.CreateHallMember(ctx, runner, &models.HallMember{
+		ID:     memberID,
+		HallID: hallID,
+		UserID: bot.ID,
+		RoleID: role.ID,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorCreatingHallMember
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	publishHubEvent(s.EventPublisher, realtime.HubEvent{
+		Type:   realtime.HubEventUserJoinedHall,
+		HallID: hallID,
+		UserID: bot.ID,
+	})
+
+	return &hallDto.JoinHallRes{
+		Status:    "joined",
+		MemberID:  &member.ID,
+		HallID:    member.HallID,
+		UserID:    member.UserID,
+		RoleID:    &member.RoleID,
+		Nickname:  member.Nickname,
+		JoinedAt:  &member.JoinedAt,
+		CreatedAt: member.CreatedAt,
+		UpdatedAt: member.UpdatedAt,
+	}, nil
+}
+
+// ── TOKENS ─────────────────────────────────────────────────────────────────
+
+func (s *botService) VerifyBotToken(c context.Context, token string) (*auth.UserInfo, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	tokenHash := auth.HashOpaqueToken(token)
+
+	bot, err := s.IBotRepository.GetBotByTokenHash(ctx, runner, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidBotToken
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	// bookkeeping only, a failed touch doesn't fail the request
+	_ = s.IBotRepository.TouchBotToken(ctx, runner, tokenHash)
+
+	return &auth.UserInfo{
+		ID:       bot.ID,
+		Username: bot.Username,
+		IsBot:    true,
+	}, nil
+}
+
+// issueBotToken stores the hash of a fresh token and returns the token
+func (s *botService) issueBotToken(ctx context.Context, runner database.DBRunner, botID uuid.UUID) (string, error) {
+	raw, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", utils.ErrorInternal
+	}
+	token := models.BotTokenPrefix + raw
+
+	tokenID, err := uuid.NewV7()
+	if err != nil {
+		return "", utils.ErrorInternal
+	}
+
+	if err := s.IBotRepository.CreateBotToken(ctx, runner, &models.BotToken{
+		ID:        tokenID,
+		BotID:     botID,
+		TokenHash: auth.HashOpaqueToken(token),
+	}); err != nil {
+		if utils.IsDeadline(err) {
+			return "", utils.ErrorRequestTimeout
+		}
+		return "", utils.ErrorInternal
+	}
+
+	return token, nil
+}
@@ -0,0 +1,905 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	hallDto "github.com/suck-seed/yapp/internal/dto/hall"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/realtime"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	MAX_COMMANDS_PER_BOT   = 50
+	MAX_REMINDERS_PER_USER = 25
+
+	commandInteractionTTL = 15 * time.Minute
+
+	minReminderDelay   = 10 * time.Second
+	maxReminderDelay   = 30 * 24 * time.Hour
+	reminderTick       = 5 * time.Second
+	reminderClaimBatch = 100
+	// a claimed reminder comes back on its own when the process dies before
+	// it was delivered, a failed delivery is retried sooner
+	reminderLease      = 2 * time.Minute
+	reminderRetryDelay = 30 * time.Second
+
+	minPollAnswers = 2
+	maxPollAnswers = 10
+)
+
+var pollAnswerEmojis = []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣", "6️⃣", "7️⃣", "8️⃣", "9️⃣", "🔟"}
+
+type ICommandService interface {
+	// -------------- INVOKE
+	// ExecuteCommand runs "/name args..." typed in roomID. clientID is the
+	// invoking connection, bot replies marked ephemeral go back to it.
+	ExecuteCommand(c context.Context, userInfo *auth.UserInfo, clientID uuid.UUID, roomID uuid.UUID, input string) (*dto.CommandResult, error)
+
+	// ListCommands is the autocompletion source: the built-ins the caller may
+	// use plus the hall's bot commands, optionally narrowed to a name prefix
+	ListCommands(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, prefix string) (*dto.CommandsRes, error)
+
+	// -------------- BOTS
+	RegisterBotCommands(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.RegisterBotCommandsReq) (*dto.CommandsRes, error)
+	ReplyToInteraction(c context.Context, userInfo *auth.UserInfo, interactionID uuid.UUID, req *dto.CommandReplyReq) (*dto.CreateMessageRes, error)
+
+	// -------------- REMINDERS
+	Run()
+}
+
+// builtinCommand is a command implemented here. allowed is nil when every
+// hall member may use it.
+type builtinCommand struct {
+	spec    dto.CommandSpec
+	allowed func(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error)
+	run     func(c context.Context, inv *commandInvocation) (*dto.CommandResult, error)
+}
+
+type commandInvocation struct {
+	userInfo *auth.UserInfo
+	clientID uuid.UUID
+	room     *models.Room
+
+	// string, int64, bool, time.Duration or uuid.UUID for users
+	args map[string]any
+}
+
+type commandService struct {
+	repositories.IRoomRepository
+	repositories.IHallRepository
+	repositories.IUserRepository
+	repositories.IBotCommandRepository
+	repositories.ICommandStateRepository
+
+	IPermissionCheckerService
+	IBanService
+	IMessageService
+	INotificationService
+
+	EventPublisher realtime.Publisher
+
+	builtins map[string]*builtinCommand
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewCommandService(
+	roomRepo repositories.IRoomRepository,
+	hallRepo repositories.IHallRepository,
+	userRepo repositories.IUserRepository,
+	botCommandRepo repositories.IBotCommandRepository,
+	commandStateRepo repositories.ICommandStateRepository,
+	permissionChecker IPermissionCheckerService,
+	banService IBanService,
+	messageService IMessageService,
+	notificationService INotificationService,
+	eventPublisher realtime.Publisher,
+	pool *pgxpool.Pool,
+) ICommandService {
+	s := &commandService{
+		roomRepo,
+		hallRepo,
+		userRepo,
+		botCommandRepo,
+		commandStateRepo,
+		permissionChecker,
+		banService,
+		messageService,
+		notificationService,
+		eventPublisher,
+		nil,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+	s.builtins = s.builtinCommands()
+	return s
+}
+
+// ── BUILT-INS ─────────────────────────────────────────────────────────────────
+
+func (s *commandService) builtinCommands() map[string]*builtinCommand {
+	commands := []*builtinCommand{
+		{
+			spec: dto.CommandSpec{
+				Name:        "help",
+				Description: "List the commands you can use here",
+			},
+			run: s.runHelp,
+		},
+		{
+			spec: dto.CommandSpec{
+				Name:        "ban",
+				Description: "Ban a member from this hall",
+				Options: []models.CommandOption{
+					{Name: "user", Description: "Who to ban", Type: models.CommandOptionUser, Required: true},
+					{Name: "reason", Description: "Why, shown in the ban list", Type: models.CommandOptionText},
+				},
+			},
+			allowed: s.IPermissionCheckerService.CanBanMembers,
+			run:     s.runBan,
+		},
+		{
+			spec: dto.CommandSpec{
+				Name:        "remind",
+				Description: "Get a notification about something later",
+				Options: []models.CommandOption{
+					{Name: "in", Description: "When, like 10m, 2h or 1d", Type: models.CommandOptionDuration, Required: true},
+					{Name: "text", Description: "What to remind you of", Type: models.CommandOptionText, Required: true},
+				},
+			},
+			run: s.runRemind,
+		},
+		{
+			spec: dto.CommandSpec{
+				Name:        "poll",
+				Description: "Ask the room a question, answers are voted on with reactions",
+				Options: []models.CommandOption{
+					{Name: "question", Description: "Quote it if it has spaces", Type: models.CommandOptionString, Required: true},
+					{Name: "answers", Description: "2 to 10 answers separated by |", Type: models.CommandOptionText, Required: true},
+				},
+			},
+			run: s.runPoll,
+		},
+	}
+
+	out := make(map[string]*builtinCommand, len(commands))
+	for _, command := range commands {
+		command.spec.Usage = commandUsage(command.spec.Name, command.spec.Options)
+		if command.spec.Options == nil {
+			command.spec.Options = []models.CommandOption{}
+		}
+		out[command.spec.Name] = command
+	}
+	return out
+}
+
+func (s *commandService) runHelp(c context.Context, inv *commandInvocation) (*dto.CommandResult, error) {
+	commands, err := s.ListCommands(c, inv.userInfo, inv.room.HallID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	for i, command := range commands.Commands {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(command.Usage + " - " + command.Description)
+	}
+
+	return &dto.CommandResult{Command: "help", Content: b.String()}, nil
+}
+
+func (s *commandService) runBan(c context.Context, inv *commandInvocation) (*dto.CommandResult, error) {
+	targetID := inv.args["user"].(uuid.UUID)
+	if targetID == inv.userInfo.ID {
+		return nil, utils.ErrorCannotBanYourself
+	}
+
+	reason := "Banned with /ban"
+	if text, ok := inv.args["reason"].(string); ok && text != "" {
+		reason = text
+	}
+
+	ban, err := s.IBanService.BanUser(c, inv.userInfo, inv.room.HallID, &hallDto.BanUserReq{
+		UserID: targetID,
+		Reason: reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.CommandResult{Command: "ban", Content: "Banned @" + ban.User.Username}, nil
+}
+
+func (s *commandService) runRemind(c context.Context, inv *commandInvocation) (*dto.CommandResult, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	delay := inv.args["in"].(time.Duration)
+	if delay < minReminderDelay || delay > maxReminderDelay {
+		return nil, utils.CommandUsageError(s.builtins["remind"].spec.Usage + ", between 10s and 30d")
+	}
+
+	pending, err := s.ICommandStateRepository.CountUserReminders(ctx, inv.userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	if pending >= MAX_REMINDERS_PER_USER {
+		return nil, utils.ErrorTooManyReminders
+	}
+
+	reminderID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	now := time.Now().UTC()
+	err = s.ICommandStateRepository.AddReminder(ctx, &models.Reminder{
+		ID:        reminderID,
+		UserID:    inv.userInfo.ID,
+		HallID:    inv.room.HallID,
+		RoomID:    inv.room.ID,
+		Text:      inv.args["text"].(string),
+		DueAt:     now.Add(delay),
+		CreatedAt: now,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.CommandResult{Command: "remind", Content: "Okay, I'll remind you in " + delay.String()}, nil
+}
+
+func (s *commandService) runPoll(c context.Context, inv *commandInvocation) (*dto.CommandResult, error) {
+	answers := make([]string, 0, maxPollAnswers)
+	for _, answer := range strings.Split(inv.args["answers"].(string), "|") {
+		if answer = strings.TrimSpace(answer); answer != "" {
+			answers = append(answers, answer)
+		}
+	}
+	if len(answers) < minPollAnswers || len(answers) > maxPollAnswers {
+		return nil, utils.CommandUsageError(s.builtins["poll"].spec.Usage + ", with 2 to 10 answers")
+	}
+
+	var b strings.Builder
+	b.WriteString("📊 " + inv.args["question"].(string))
+	for i, answer := range answers {
+		b.WriteString("\n" + pollAnswerEmojis[i] + " " + answer)
+	}
+	content := b.String()
+
+	// goes through the usual checks, slow mode included
+	saved, err := s.IMessageService.CreateMessage(c, &dto.CreateMessageReq{
+		RoomID:   inv.room.ID,
+		AuthorID: inv.userInfo.ID,
+		Content:  &content,
+		SentAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range answers {
+		if _, err := s.IMessageService.AddReaction(c, inv.userInfo, inv.room.ID, saved.ID, pollAnswerEmojis[i]); err != nil {
+			log.Printf("commands: seed poll reaction on %s: %v", saved.ID, err)
+		}
+	}
+
+	return nil, nil
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+// commandUsage renders "/name <required> [optional]"
+func commandUsage(name string, options []models.CommandOption) string {
+	var b strings.Builder
+	b.WriteString("/" + name)
+	for _, option := range options {
+		if option.Required {
+			b.WriteString(" <" + option.Name + ">")
+		} else {
+			b.WriteString(" [" + option.Name + "]")
+		}
+	}
+	return b.String()
+}
+
+// parseCommandArgs types the raw arguments against options, any problem is
+// answered with the usage line
+func (s *commandService) parseCommandArgs(ctx context.Context, runner database.DBRunner, usage string, options []models.CommandOption, rest string) (map[string]any, error) {
+	tokens := utils.SplitCommandArgs(rest)
+	args := make(map[string]any, len(options))
+
+	i := 0
+	for _, option := range options {
+		if i >= len(tokens) {
+			if option.Required {
+				return nil, utils.CommandUsageError(usage)
+			}
+			continue
+		}
+
+		raw := tokens[i]
+		i++
+
+		switch option.Type {
+		case models.CommandOptionText:
+			args[option.Name] = strings.Join(tokens[i-1:], " ")
+			i = len(tokens)
+
+		case models.CommandOptionInteger:
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, utils.CommandUsageError(usage + ", " + option.Name + " is a number")
+			}
+			args[option.Name] = n
+
+		case models.CommandOptionBoolean:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				switch strings.ToLower(raw) {
+				case "yes", "on":
+					b = true
+				case "no", "off":
+					b = false
+				default:
+					return nil, utils.CommandUsageError(usage + ", " + option.Name + " is yes or no")
+				}
+			}
+			args[option.Name] = b
+
+		case models.CommandOptionDuration:
+			d, ok := utils.ParseCommandDuration(raw)
+			if !ok {
+				return nil, utils.CommandUsageError(usage + ", " + option.Name + " is a duration like 10m or 2h")
+			}
+			args[option.Name] = d
+
+		case models.CommandOptionUser:
+			userID, err := s.resolveCommandUser(ctx, runner, raw)
+			if err != nil {
+				return nil, err
+			}
+			args[option.Name] = userID
+
+		default:
+			if len(option.Choices) > 0 {
+				matched := ""
+				for _, choice := range option.Choices {
+					if strings.EqualFold(choice, raw) {
+						matched = choice
+					}
+				}
+				if matched == "" {
+					return nil, utils.CommandUsageError(usage + ", " + option.Name + " is one of " + strings.Join(option.Choices, ", "))
+				}
+				raw = matched
+			}
+			args[option.Name] = raw
+		}
+	}
+
+	if i < len(tokens) {
+		return nil, utils.CommandUsageError(usage)
+	}
+
+	return args, nil
+}
+
+// resolveCommandUser accepts "@username", "username" or a user ID
+func (s *commandService) resolveCommandUser(ctx context.Context, runner database.DBRunner, raw string) (uuid.UUID, error) {
+	raw = strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(raw, "<"), ">"), "@")
+	if id, err := uuid.Parse(raw); err == nil {
+		exists, err := s.IUserRepository.DoesUserExists(ctx, runner, id)
+		if err != nil {
+			return uuid.Nil, utils.ErrorInternal
+		}
+		if !exists {
+			return uuid.Nil, utils.ErrorUserNotFound
+		}
+		return id, nil
+	}
+
+	user, err := s.IUserRepository.GetUserByUsername(ctx, runner, strings.ToLower(raw))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, utils.ErrorUserNotFound
+		}
+		if utils.IsDeadline(err) {
+			return uuid.Nil, utils.ErrorRequestTimeout
+		}
+		return uuid.Nil, utils.ErrorFetchingUser
+	}
+	return user.ID, nil
+}
+
+// validateBotCommand keeps bot definitions parseable: text options last,
+// required ones before optional ones, choices only on strings
+func validateBotCommand(req *dto.BotCommandReq) ([]models.CommandOption, error) {
+	options := make([]models.CommandOption, 0, len(req.Options))
+	seen := make(map[string]bool, len(req.Options))
+	optionalSeen := false
+
+	for i, option := range req.Options {
+		name := strings.ToLower(option.Name)
+		if !utils.IsValidCommandName(name) || seen[name] || !option.Type.Valid() {
+			return nil, utils.ErrorInvalidCommandDefinition
+		}
+		if option.Type == models.CommandOptionText && i != len(req.Options)-1 {
+			return nil, utils.ErrorInvalidCommandDefinition
+		}
+		if len(option.Choices) > 0 && option.Type != models.CommandOptionString {
+			return nil, utils.ErrorInvalidCommandDefinition
+		}
+		if option.Required && optionalSeen {
+			return nil, utils.ErrorInvalidCommandDefinition
+		}
+		optionalSeen = optionalSeen || !option.Required
+		seen[name] = true
+
+		options = append(options, models.CommandOption{
+			Name:        name,
+			Description: strings.TrimSpace(option.Description),
+			Type:        option.Type,
+			Required:    option.Required,
+			Choices:     option.Choices,
+		})
+	}
+
+	return options, nil
+}
+
+// ── INVOKE ────────────────────────────────────────────────────────────────────
+
+func (s *commandService) ExecuteCommand(c context.Context, userInfo *auth.UserInfo, clientID uuid.UUID, roomID uuid.UUID, input string) (*dto.CommandResult, error) {
+	name, rest, ok := utils.ParseCommandLine(input)
+	if !ok {
+		return nil, utils.ErrorUnknownCommand
+	}
+
+	inv, builtin, botCommand, err := s.prepareInvocation(c, userInfo, clientID, roomID, name, rest)
+	if err != nil {
+		return nil, err
+	}
+
+	if builtin != nil {
+		return builtin.run(c, inv)
+	}
+	return nil, s.invokeBotCommand(c, inv, botCommand)
+}
+
+// prepareInvocation finds the command and types its arguments, holding a
+// connection only for that
+func (s *commandService) prepareInvocation(c context.Context, userInfo *auth.UserInfo, clientID uuid.UUID, roomID uuid.UUID, name string, rest string) (*commandInvocation, *builtinCommand, *models.BotCommand, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	room, err := s.IRoomRepository.GetRoomByID(ctx, runner, roomID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil, utils.ErrorRoomNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, nil, nil, utils.ErrorRequestTimeout
+		}
+		return nil, nil, nil, utils.ErrorFetchingRoom
+	}
+
+	isMember, err := s.IHallRepository.IsUserHallMember(ctx, runner, room.HallID, userInfo.ID)
+	if err != nil {
+		return nil, nil, nil, utils.ErrorInternal
+	}
+	if !isMember {
+		return nil, nil, nil, utils.ErrorUserDoesntBelongHall
+	}
+
+	inv := &commandInvocation{userInfo: userInfo, clientID: clientID, room: room}
+
+	if builtin, ok := s.builtins[name]; ok {
+		if builtin.allowed != nil {
+			ok, err := builtin.allowed(ctx, runner, userInfo.ID, room.HallID)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if !ok {
+				return nil, nil, nil, utils.ErrorForbidden
+			}
+		}
+
+		inv.args, err = s.parseCommandArgs(ctx, runner, builtin.spec.Usage, builtin.spec.Options, rest)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return inv, builtin, nil, nil
+	}
+
+	botCommand, err := s.IBotCommandRepository.GetHallBotCommand(ctx, runner, room.HallID, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil, utils.ErrorUnknownCommand
+		}
+		if utils.IsDeadline(err) {
+			return nil, nil, nil, utils.ErrorRequestTimeout
+		}
+		return nil, nil, nil, utils.ErrorFetchingCommands
+	}
+
+	inv.args, err = s.parseCommandArgs(ctx, runner, commandUsage(botCommand.Name, botCommand.Options), botCommand.Options, rest)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return inv, nil, botCommand, nil
+}
+
+// invokeBotCommand hands the invocation to the bot's connections, whatever it
+// answers comes back through ReplyToInteraction
+func (s *commandService) invokeBotCommand(c context.Context, inv *commandInvocation, command *models.BotCommand) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	args := make(map[string]any, len(inv.args))
+	for name, value := range inv.args {
+		if d, ok := value.(time.Duration); ok {
+			value = int64(d.Seconds())
+		}
+		args[name] = value
+	}
+
+	interactionID, err := uuid.NewV7()
+	if err != nil {
+		return utils.ErrorInternal
+	}
+
+	interaction := &models.CommandInteraction{
+		ID:        interactionID,
+		Name:      command.Name,
+		BotID:     command.BotID,
+		HallID:    inv.room.HallID,
+		RoomID:    inv.room.ID,
+		InvokerID: inv.userInfo.ID,
+		ClientID:  inv.clientID,
+		Args:      args,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.ICommandStateRepository.SaveCommandInteraction(ctx, interaction, commandInteractionTTL); err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	publishHubEvent(s.EventPublisher, realtime.HubEvent{
+		Type:   realtime.HubEventCommandInvoked,
+		HallID: inv.room.HallID,
+		RoomID: inv.room.ID,
+		UserID: command.BotID,
+		Payload: &dto.OutboundMessage{
+			Type:        dto.MessageTypeCommand,
+			RoomID:      inv.room.ID,
+			HallID:      inv.room.HallID,
+			AuthorID:    inv.userInfo.ID,
+			SentAt:      interaction.CreatedAt,
+			Interaction: dto.ToCommandInvocation(interaction),
+		},
+	})
+
+	return nil
+}
+
+func (s *commandService) ListCommands(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, prefix string) (*dto.CommandsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	prefix = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(prefix), "/"))
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	isMember, err := s.IHallRepository.IsUserHallMember(ctx, runner, hallID, userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	if !isMember {
+		return nil, utils.ErrorUserDoesntBelongHall
+	}
+
+	res := &dto.CommandsRes{Commands: make([]dto.CommandSpec, 0)}
+
+	for name, builtin := range s.builtins {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if builtin.allowed != nil {
+			ok, err := builtin.allowed(ctx, runner, userInfo.ID, hallID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		res.Commands = append(res.Commands, builtin.spec)
+	}
+
+	botCommands, err := s.IBotCommandRepository.ListHallBotCommands(ctx, runner, hallID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingCommands
+	}
+	for _, command := range botCommands {
+		if strings.HasPrefix(command.Name, prefix) {
+			res.Commands = append(res.Commands, dto.ToCommandSpec(command, commandUsage(command.Name, command.Options)))
+		}
+	}
+
+	sort.Slice(res.Commands, func(i, j int) bool {
+		return res.Commands[i].Name < res.Commands[j].Name
+	})
+
+	return res, nil
+}
+
+// ── BOTS ──────────────────────────────────────────────────────────────────────
+
+func (s *commandService) RegisterBotCommands(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.RegisterBotCommandsReq) (*dto.CommandsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	if !userInfo.IsBot {
+		return nil, utils.ErrorBotsOnly
+	}
+	if len(req.Commands) > MAX_COMMANDS_PER_BOT {
+		return nil, utils.ErrorTooManyCommands
+	}
+
+	commands := make([]*models.BotCommand, 0, len(req.Commands))
+	seen := make(map[string]bool, len(req.Commands))
+	for i := range req.Commands {
+		name := strings.ToLower(req.Commands[i].Name)
+		if !utils.IsValidCommandName(name) || seen[name] {
+			return nil, utils.ErrorInvalidCommandDefinition
+		}
+		if _, builtin := s.builtins[name]; builtin {
+			return nil, utils.ErrorCommandNameTaken
+		}
+		seen[name] = true
+
+		options, err := validateBotCommand(&req.Commands[i])
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, utils.ErrorInternal
+		}
+		commands = append(commands, &models.BotCommand{
+			ID:          id,
+			HallID:      hallID,
+			BotID:       userInfo.ID,
+			Name:        name,
+			Description: strings.TrimSpace(req.Commands[i].Description),
+			Options:     options,
+		})
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	isMember, err := s.IHallRepository.IsUserHallMember(ctx, runner, hallID, userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	if !isMember {
+		return nil, utils.ErrorUserDoesntBelongHall
+	}
+
+	if err := s.IBotCommandRepository.PruneLeftBotCommands(ctx, runner, hallID); err != nil {
+		return nil, utils.ErrorSavingCommands
+	}
+	if err := s.IBotCommandRepository.DeleteBotHallCommands(ctx, runner, hallID, userInfo.ID); err != nil {
+		return nil, utils.ErrorSavingCommands
+	}
+
+	res := &dto.CommandsRes{Commands: make([]dto.CommandSpec, 0, len(commands))}
+	for _, command := range commands {
+		saved, err := s.IBotCommandRepository.CreateBotCommand(ctx, runner, command)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return nil, utils.ErrorCommandNameTaken
+			}
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorSavingCommands
+		}
+		res.Commands = append(res.Commands, dto.ToCommandSpec(saved, commandUsage(saved.Name, saved.Options)))
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return res, nil
+}
+
+func (s *commandService) ReplyToInteraction(c context.Context, userInfo *auth.UserInfo, interactionID uuid.UUID, req *dto.CommandReplyReq) (*dto.CreateMessageRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	if !userInfo.IsBot {
+		return nil, utils.ErrorBotsOnly
+	}
+
+	interaction, err := s.ICommandStateRepository.GetCommandInteraction(ctx, interactionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, utils.ErrorInteractionNotFound
+		}
+		return nil, utils.ErrorInternal
+	}
+	if interaction.BotID != userInfo.ID {
+		return nil, utils.ErrorInteractionNotFound
+	}
+
+	if req.Ephemeral {
+		content := utils.SanitizeMessageContent(&req.Content)
+		if content == nil || *content == "" {
+			return nil, utils.ErrorInvalidInput
+		}
+
+		publishHubEvent(s.EventPublisher, realtime.HubEvent{
+			Type:     realtime.HubEventCommandResponse,
+			HallID:   interaction.HallID,
+			RoomID:   interaction.RoomID,
+			UserID:   interaction.InvokerID,
+			ClientID: interaction.ClientID,
+			Payload: &dto.OutboundMessage{
+				Type:     dto.MessageTypeCommandResponse,
+				RoomID:   interaction.RoomID,
+				HallID:   interaction.HallID,
+				AuthorID: userInfo.ID,
+				Content:  content,
+				Command:  &interaction.Name,
+				SentAt:   time.Now().UTC(),
+			},
+		})
+		return nil, nil
+	}
+
+	return s.IMessageService.CreateMessage(c, &dto.CreateMessageReq{
+		RoomID:   interaction.RoomID,
+		AuthorID: userInfo.ID,
+		Content:  &req.Content,
+		SentAt:   time.Now().UTC(),
+	})
+}
+
+// ── REMINDERS ─────────────────────────────────────────────────────────────────
+
+func (s *commandService) Run() {
+	ticker := time.NewTicker(reminderTick)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.fireDueReminders()
+	}
+}
+
+func (s *commandService) fireDueReminders() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	now := time.Now()
+	reminders, err := s.ICommandStateRepository.ClaimDueReminders(ctx, now, now.Add(reminderLease), reminderClaimBatch)
+	if err != nil {
+		log.Printf("commands: claim reminders: %v", err)
+	}
+
+	for _, reminder := range reminders {
+		s.settleReminder(reminder, s.deliverReminder(reminder))
+	}
+}
+
+// settleReminder drops a delivered reminder and puts a failed one back. If
+// neither reaches redis the lease runs out and the reminder is claimed again.
+func (s *commandService) settleReminder(reminder *models.Reminder, deliverErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	if deliverErr != nil {
+		log.Printf("commands: deliver reminder %s: %v", reminder.ID, deliverErr)
+
+		if err := s.ICommandStateRepository.RescheduleReminder(ctx, reminder.ID, time.Now().Add(reminderRetryDelay)); err != nil {
+			log.Printf("commands: reschedule reminder %s: %v", reminder.ID, err)
+		}
+		return
+	}
+
+	if err := s.ICommandStateRepository.DeleteReminder(ctx, reminder); err != nil {
+		log.Printf("commands: delete reminder %s: %v", reminder.ID, err)
+	}
+}
+
+// deliverReminder puts the reminder in its owner's inbox, which also takes
+// care of the websocket and push delivery
+func (s *commandService) deliverReminder(reminder *models.Reminder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+
+	preview := reminder.Text
+	notifications, err := s.INotificationService.CreateNotifications(ctx, runner, []*models.Notification{{
+		ID:          id,
+		UserID:      reminder.UserID,
+		Type:        models.NotificationReminder,
+		HallID:      &reminder.HallID,
+		RoomID:      &reminder.RoomID,
+		ReferenceID: &reminder.ID,
+		Preview:     &preview,
+	}})
+	if err != nil {
+		return err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	s.INotificationService.PublishNotifications(notifications)
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+)
+
+// fakeReminderStore hands out its reminders once and records what the
+// worker does with them afterwards
+type fakeReminderStore struct {
+	repositories.ICommandStateRepository
+
+	mu          sync.Mutex
+	due         []*models.Reminder
+	leaseUntil  time.Time
+	deleted     []uuid.UUID
+	rescheduled map[uuid.UUID]time.Time
+}
+
+func (r *fakeReminderStore) ClaimDueReminders(ctx context.Context, now time.Time, leaseUntil time.Time, limit int64) ([]*models.Reminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimed := r.due
+	r.due = nil
+	r.leaseUntil = leaseUntil
+	return claimed, nil
+}
+
+func (r *fakeReminderStore) DeleteReminder(ctx context.Context, reminder *models.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, reminder.ID)
+	return nil
+}
+
+func (r *fakeReminderStore) RescheduleReminder(ctx context.Context, reminderID uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rescheduled[reminderID] = at
+	return nil
+}
+
+func newTestReminder() *models.Reminder {
+	return &models.Reminder{
+		ID:     uuid.New(),
+		UserID: uuid.New(),
+		HallID: uuid.New(),
+		RoomID: uuid.New(),
+		Text:   "stand-up",
+		DueAt:  time.Now().Add(-time.Second),
+	}
+}
+
+// unreachablePool fails every Begin, like a database that is down
+func unreachablePool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	pool, err := pgxpool.New(context.Background(), "postgres://yapp@127.0.0.1:1/yapp?connect_timeout=1")
+	if err != nil {
+		t.Fatalf("pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// newTestReminderWorker is only the reminder half of the service, the
+// built-ins would need every dependency
+func newTestReminderWorker(store repositories.ICommandStateRepository, pool *pgxpool.Pool) *commandService {
+	return &commandService{
+		ICommandStateRepository: store,
+		pool:                    pool,
+		timeout:                 2 * time.Second,
+	}
+}
+
+func TestFireDueRemindersRequeuesFailedDelivery(t *testing.T) {
+	reminder := newTestReminder()
+	store := &fakeReminderStore{due: []*models.Reminder{reminder}, rescheduled: make(map[uuid.UUID]time.Time)}
+
+	s := newTestReminderWorker(store, unreachablePool(t))
+
+	before := time.Now()
+	s.fireDueReminders()
+
+	if len(store.deleted) != 0 {
+		t.Fatalf("deleted %v although delivery failed", store.deleted)
+	}
+	at, ok := store.rescheduled[reminder.ID]
+	if !ok {
+		t.Fatal("failed reminder was not put back")
+	}
+	if at.Before(before.Add(reminderRetryDelay)) || at.After(time.Now().Add(reminderRetryDelay)) {
+		t.Errorf("rescheduled for %s, want about %s from now", at, reminderRetryDelay)
+	}
+	if !store.leaseUntil.After(before) {
+		t.Errorf("claimed with lease until %s, want a lease into the future", store.leaseUntil)
+	}
+}
+
+func TestSettleReminderDeletesDelivered(t *testing.T) {
+	reminder := newTestReminder()
+	store := &fakeReminderStore{rescheduled: make(map[uuid.UUID]time.Time)}
+
+	s := newTestReminderWorker(store, nil)
+	s.settleReminder(reminder, nil)
+
+	if len(store.deleted) != 1 || store.deleted[0] != reminder.ID {
+		t.Errorf("deleted %v, want [%s]", store.deleted, reminder.ID)
+	}
+	if len(store.rescheduled) != 0 {
+		t.Errorf("rescheduled %v a delivered reminder", store.rescheduled)
+	}
+}
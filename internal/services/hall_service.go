@@ -48,6 +48,10 @@ type IHallService interface {
 	GetHallProfile(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID) (*dto.GetHallProfileRes, error)
 	UpdateHallProfile(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.HallProfileUpdateReq) (*dto.HallProfileUpdateRes, error)
 
+	// -------------- HALL SECURITY
+	GetHallSecuritySettings(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID) (*dto.HallSecuritySettingsRes, error)
+	UpdateHallSecuritySettings(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.UpdateHallSecuritySettingsReq) (*dto.HallSecuritySettingsRes, error)
+
 	// -------------- MEMBERS
 	GetHallMembers(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID) (*dto.GetHallMembersRes, error)
 	GetHallMember(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, memberID uuid.UUID) (*dto.HallMemberRes, error)
@@ -67,9 +71,11 @@ type hallService struct {
 	repositories.IRoleRepository
 	repositories.IRoomRepository
 	repositories.IBanRepsitory
+	repositories.ITwoFactorRepository
 
 	IPermissionCheckerService
 	IPresenceService
+	INotificationService
 
 	EventPublisher realtime.Publisher
 
@@ -85,8 +91,10 @@ func NewHallService(
 	roleRepo repositories.IRoleRepository,
 	roomRepo repositories.IRoomRepository,
 	banRepo repositories.IBanRepsitory,
+	twoFactorRepo repositories.ITwoFactorRepository,
 	permissionChecker IPermissionCheckerService,
 	presenceService IPresenceService,
+	notificationService INotificationService,
 	eventPublisher realtime.Publisher,
 	pool *pgxpool.Pool,
 ) IHallService {
@@ -96,8 +104,10 @@ func NewHallService(
 		roleRepo,
 		roomRepo,
 		banRepo,
+		twoFactorRepo,
 		permissionChecker,
 		presenceService,
+		notificationService,
 		eventPublisher,
 		pool,
 		time.Duration(2) * time.Second,
@@ -993,6 +1003,106 @@ func (s *hallService) UpdateHallProfile(c context.Context, userInfo *auth.UserIn
 	}, nil
 }
 
+func (s *hallService) GetHallSecuritySettings(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID) (*dto.HallSecuritySettingsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	ownerID, err := s.IHallRepository.GetHallOwnerID(ctx, runner, hallID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorHallNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingHall
+	}
+
+	if userInfo.ID != ownerID {
+		return nil, utils.ErrorUnauthorizedToUpdateHall
+	}
+
+	required, err := s.IHallRepository.GetHallRequiresMod2FA(ctx, runner, hallID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingHall
+	}
+
+	return &dto.HallSecuritySettingsRes{
+		HallID:        hallID,
+		RequireMod2FA: required,
+	}, nil
+}
+
+func (s *hallService) UpdateHallSecuritySettings(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.UpdateHallSecuritySettingsReq) (*dto.HallSecuritySettingsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	ownerID, err := s.IHallRepository.GetHallOwnerID(ctx, runner, hallID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorHallNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingHall
+	}
+
+	if userInfo.ID != ownerID {
+		return nil, utils.ErrorUnauthorizedToUpdateHall
+	}
+
+	// an owner without 2FA can't ask it of their moderators
+	if *req.RequireMod2FA {
+		enabled, err := s.ITwoFactorRepository.IsTwoFactorEnabled(ctx, runner, userInfo.ID)
+		if err != nil {
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorInternal
+		}
+		if !enabled {
+			return nil, utils.ErrorTwoFactorRequired
+		}
+	}
+
+	if err := s.IHallRepository.SetHallRequiresMod2FA(ctx, runner, hallID, *req.RequireMod2FA); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorHallNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.HallSecuritySettingsRes{
+		HallID:        hallID,
+		RequireMod2FA: *req.RequireMod2FA,
+	}, nil
+}
+
 func (s *hallService) GetHallMembers(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID) (*dto.GetHallMembersRes, error) {
 	ctx, cancel := context.WithTimeout(c, s.timeout)
 	defer cancel()
@@ -1150,6 +1260,7 @@ func (s *hallService) UpdateHallMemberRole(c context.Context, userInfo *auth.Use
 	publishHubEvent(s.EventPublisher, realtime.HubEvent{
 		Type:     realtime.HubEventUserAccessResync,
 		HallID:   hallID,
+		RoleID:   role.ID,
 		UserID:   target.UserID,
 		MemberID: target.ID,
 	})
@@ -1464,6 +1575,17 @@ func (s *hallService) AcceptJoinRequest(c context.Context, userInfo *auth.UserIn
 		return nil, utils.ErrorDeletingJoinRequest
 	}
 
+	notifications, err := s.INotificationService.CreateNotifications(ctx, runner, []*models.Notification{{
+		UserID:      request.UserID,
+		Type:        models.NotificationJoinRequestAccepted,
+		ActorID:     &userInfo.ID,
+		HallID:      &hallID,
+		ReferenceID: &request.ID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
 	if err := runner.Commit(ctx); err != nil {
 		return nil, utils.ErrorInternal
 	}
@@ -1475,6 +1597,7 @@ func (s *hallService) AcceptJoinRequest(c context.Context, userInfo *auth.UserIn
 		UserID:   member.UserID,
 		MemberID: member.ID,
 	})
+	s.INotificationService.PublishNotifications(notifications)
 
 	return &dto.AcceptJoinRequestRes{
 		RequestID: request.ID,
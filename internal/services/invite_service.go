@@ -49,6 +49,7 @@ type inviteService struct {
 	repositories.IRoleRepository
 
 	IPermissionCheckerService
+	INotificationService
 
 	EventPublisher realtime.Publisher
 
@@ -62,6 +63,7 @@ func NewInviteService(
 	hallRepo repositories.IHallRepository,
 	roleRepo repositories.IRoleRepository,
 	permSvc IPermissionCheckerService,
+	notificationService INotificationService,
 	eventPublisher realtime.Publisher,
 	pool *pgxpool.Pool,
 ) IInviteService {
@@ -70,6 +72,7 @@ func NewInviteService(
 		hallRepo,
 		roleRepo,
 		permSvc,
+		notificationService,
 		eventPublisher,
 		pool,
 		2 * time.Second,
@@ -436,6 +439,17 @@ func (s *inviteService) AcceptInviteLink(ctx context.Context, userInfo *auth.Use
 		return nil, utils.ErrorCreatingHallMember
 	}
 
+	notifications, err := s.INotificationService.CreateNotifications(ctx, runner, []*models.Notification{{
+		UserID:      updated.CreatedBy,
+		Type:        models.NotificationInviteAccepted,
+		ActorID:     &userInfo.ID,
+		HallID:      &updated.HallID,
+		ReferenceID: &updated.ID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
 	if err := runner.Commit(ctx); err != nil {
 		return nil, utils.ErrorTest4
 	}
@@ -447,6 +461,7 @@ func (s *inviteService) AcceptInviteLink(ctx context.Context, userInfo *auth.Use
 		UserID:   member.UserID,
 		MemberID: member.ID,
 	})
+	s.INotificationService.PublishNotifications(notifications)
 
 	return &dto.AcceptInviteLinkRes{
 		HallID:   member.HallID,
@@ -12,7 +12,9 @@ import (
 	"github.com/suck-seed/yapp/internal/auth"
 	"github.com/suck-seed/yapp/internal/database"
 	dto "github.com/suck-seed/yapp/internal/dto/message"
+	notificationDto "github.com/suck-seed/yapp/internal/dto/notification"
 	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/realtime"
 	"github.com/suck-seed/yapp/internal/repositories"
 	"github.com/suck-seed/yapp/internal/utils"
 )
@@ -22,14 +24,22 @@ type IMessageService interface {
 	FetchMessages(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, params *dto.FetchMessagesQuery) (*dto.MessageListResponse, error)
 	GetMessage(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) (*dto.MessageDetailed, error)
 	UpdateMessage(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID, req *dto.UpdateMessageReq) (*dto.MessageDetailed, error)
+	GetMessageHistory(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) ([]models.MessageRevision, error)
 	DeleteMessage(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) error
 	AddReaction(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID, emoji string) (*dto.ReactionRes, error)
 	RemoveReaction(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID, emoji string) error
 
+	// Pins
+	PinMessage(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) (*models.PinnedMessage, error)
+	UnpinMessage(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) error
+	GetPinnedMessages(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID) ([]*dto.PinnedMessageRes, error)
+
 	// Message read
 	MarkMessageRead(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) (*dto.MessageReadRes, error)
 }
 
+const MAX_PINS_PER_ROOM = 50
+
 type messageService struct {
 	repositories.IHallRepository
 	repositories.IRoomRepository
@@ -37,6 +47,11 @@ type messageService struct {
 	repositories.IUserRepository
 
 	IPermissionCheckerService
+	INotificationService
+
+	// EventPublisher reaches the hub, OutgoingPublisher only the hall webhooks
+	EventPublisher    realtime.Publisher
+	OutgoingPublisher realtime.Publisher
 
 	pool    *pgxpool.Pool
 	timeout time.Duration
@@ -49,6 +64,9 @@ func NewMessageService(
 	messageRepo repositories.IMessageRepository,
 	userRepo repositories.IUserRepository,
 	permissionChecker IPermissionCheckerService,
+	notificationService INotificationService,
+	eventPublisher realtime.Publisher,
+	outgoingPublisher realtime.Publisher,
 	pool *pgxpool.Pool,
 ) IMessageService {
 	return &messageService{
@@ -57,6 +75,9 @@ func NewMessageService(
 		messageRepo,
 		userRepo,
 		permissionChecker,
+		notificationService,
+		eventPublisher,
+		outgoingPublisher,
 		pool,
 		time.Duration(2) * time.Second,
 		sync.RWMutex{},
@@ -110,6 +131,45 @@ func (s *messageService) resolveRoomWithPrivateCheck(ctx context.Context, runner
 	return room, nil
 }
 
+// checkSlowmode refuses a message until the room's cooldown since the
+// author's previous one has passed. Members who can manage the room or its
+// messages are exempt.
+func (s *messageService) checkSlowmode(ctx context.Context, runner database.DBRunner, room *models.Room, authorID uuid.UUID) error {
+	if room.SlowmodeSeconds <= 0 {
+		return nil
+	}
+
+	for _, exempt := range []func(context.Context, database.DBRunner, uuid.UUID, uuid.UUID) (bool, error){
+		s.IPermissionCheckerService.CanManageChannels,
+		s.IPermissionCheckerService.CanManageMessages,
+	} {
+		ok, err := exempt(ctx, runner, authorID, room.HallID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	last, err := s.IMessageRepository.GetLastMessageTimeByAuthor(ctx, runner, room.ID, authorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	remaining := time.Until(last.Add(time.Duration(room.SlowmodeSeconds) * time.Second))
+	if remaining > 0 {
+		return &utils.CooldownError{AppError: utils.ErrorSlowmode, Remaining: remaining}
+	}
+	return nil
+}
+
 // ── CreateMessage ─────────────────────────────────────────────────────────────
 // Called internally by the WebSocket hub, not directly from HTTP.
 
@@ -124,14 +184,34 @@ func (s *messageService) CreateMessage(c context.Context, req *dto.CreateMessage
 	runner := database.NewTxWrapper(tx)
 	defer runner.Rollback(ctx)
 
-	// Checking if the author of message belongs in the room or not (if private)
-	if _, err := s.resolveRoomWithPrivateCheck(ctx, runner, req.RoomID, req.AuthorID); err != nil {
-		return nil, utils.ErrorUserDoesntBelongRoom
-	}
+	var room *models.Room
+	if req.Webhook != nil {
+		// webhooks are bound to their room and skip membership and slow mode
+		room, err = s.IRoomRepository.GetRoomByID(ctx, runner, req.RoomID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, utils.ErrorRoomNotFound
+			}
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorFetchingRoom
+		}
+	} else {
+		// Checking if the author of message belongs in the room or not (if private)
+		room, err = s.resolveRoomWithPrivateCheck(ctx, runner, req.RoomID, req.AuthorID)
+		if err != nil {
+			return nil, utils.ErrorUserDoesntBelongRoom
+		}
 
-	// Checking if the author belongs to the hall Or not, using the room.ID
-	if _, err := s.resolveRoom(ctx, runner, req.RoomID, req.AuthorID); err != nil {
-		return nil, utils.ErrorUserDoesntBelongHall
+		// Checking if the author belongs to the hall Or not, using the room.ID
+		if _, err := s.resolveRoom(ctx, runner, req.RoomID, req.AuthorID); err != nil {
+			return nil, utils.ErrorUserDoesntBelongHall
+		}
+
+		if err := s.checkSlowmode(ctx, runner, room, req.AuthorID); err != nil {
+			return nil, err
+		}
 	}
 
 	normalizedContent := utils.SanitizeMessageContent(req.Content)
@@ -153,6 +233,12 @@ func (s *messageService) CreateMessage(c context.Context, req *dto.CreateMessage
 		message.MentionEveryone = true
 	}
 
+	if req.Webhook != nil {
+		message.WebhookID = &req.Webhook.WebhookID
+		message.AuthorName = req.Webhook.Name
+		message.AuthorAvatarURL = req.Webhook.AvatarURL
+	}
+
 	messageCRES, err := s.IMessageRepository.CreateMessage(ctx, runner, message)
 	if err != nil {
 		return nil, utils.ErrorWritingMessage
@@ -224,11 +310,26 @@ func (s *messageService) CreateMessage(c context.Context, req *dto.CreateMessage
 		}
 	}
 
+	var notifications []*notificationDto.NotificationRes
+	if messageCRES.MentionEveryone || len(mentions) > 0 {
+		drafts, err := s.buildMentionNotifications(ctx, runner, room, messageCRES, mentions)
+		if err != nil {
+			return nil, err
+		}
+
+		notifications, err = s.INotificationService.CreateNotifications(ctx, runner, drafts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if err := runner.Commit(ctx); err != nil {
 		return nil, utils.ErrorInternal
 	}
 
-	return &dto.CreateMessageRes{
+	s.INotificationService.PublishNotifications(notifications)
+
+	res := &dto.CreateMessageRes{
 		ID:               messageCRES.ID,
 		RoomID:           messageCRES.RoomID,
 		AuthorID:         messageCRES.AuthorID,
@@ -237,11 +338,78 @@ func (s *messageService) CreateMessage(c context.Context, req *dto.CreateMessage
 		MentionsEveryone: messageCRES.MentionEveryone,
 		Mentions:         mentions,
 		Attachments:      attachments,
+		WebhookID:        messageCRES.WebhookID,
+		AuthorName:       messageCRES.AuthorName,
+		AuthorAvatarURL:  messageCRES.AuthorAvatarURL,
 		CreatedAt:        messageCRES.CreatedAt,
 		EditedAt:         messageCRES.EditedAt,
 		DeletedAt:        messageCRES.DeletedAt,
 		UpdatedAt:        messageCRES.UpdatedAt,
-	}, nil
+	}
+
+	created := realtime.HubEvent{
+		Type:      realtime.HubEventMessageCreated,
+		HallID:    room.HallID,
+		RoomID:    room.ID,
+		UserID:    res.AuthorID,
+		IsPrivate: room.IsPrivate,
+		Payload:   res.ToOutbound(room.HallID),
+	}
+
+	publishHubEvent(s.OutgoingPublisher, created)
+
+	// the hub hands socket messages to the room directly, everything else
+	// (webhooks, bots, GraphQL) gets there through the bus
+	if !req.FromSocket {
+		publishHubEvent(s.EventPublisher, created)
+	}
+
+	return res, nil
+}
+
+// buildMentionNotifications only targets users who can actually read the room,
+// so mentioning someone outside a private room does not leak its content.
+func (s *messageService) buildMentionNotifications(ctx context.Context, runner database.DBRunner, room *models.Room, message *models.Message, mentions []dto.UserBasic) ([]*models.Notification, error) {
+	audience, err := s.IRoomRepository.GetRoomAudienceUserIDs(ctx, runner, room.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingRoom
+	}
+
+	newDraft := func(userID uuid.UUID, kind models.NotificationType) *models.Notification {
+		return &models.Notification{
+			UserID:    userID,
+			Type:      kind,
+			ActorID:   &message.AuthorID,
+			HallID:    &room.HallID,
+			RoomID:    &room.ID,
+			MessageID: &message.ID,
+			Preview:   notificationPreview(message.Content),
+		}
+	}
+
+	drafts := make([]*models.Notification, 0)
+
+	mentioned := make(map[uuid.UUID]struct{}, len(mentions))
+	for _, m := range mentions {
+		mentioned[m.ID] = struct{}{}
+	}
+
+	// a direct mention wins over @everyone so that suppressing @everyone
+	// doesn't also swallow pings aimed at the user
+	for _, userID := range audience {
+		if _, ok := mentioned[userID]; ok {
+			drafts = append(drafts, newDraft(userID, models.NotificationMention))
+			continue
+		}
+		if message.MentionEveryone {
+			drafts = append(drafts, newDraft(userID, models.NotificationMentionEveryone))
+		}
+	}
+
+	return drafts, nil
 }
 
 // ── FetchMessages ─────────────────────────────────────────────────────────────
@@ -349,7 +517,8 @@ func (s *messageService) UpdateMessage(c context.Context, userInfo *auth.UserInf
 	runner := database.NewTxWrapper(tx)
 	defer runner.Rollback(ctx)
 
-	if _, err := s.resolveRoomWithPrivateCheck(ctx, runner, roomID, userInfo.ID); err != nil {
+	room, err := s.resolveRoomWithPrivateCheck(ctx, runner, roomID, userInfo.ID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -377,6 +546,21 @@ func (s *messageService) UpdateMessage(c context.Context, userInfo *auth.UserInf
 		return nil, utils.ErrorInvalidInput
 	}
 
+	revisionID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	if err := s.IMessageRepository.AddMessageRevision(ctx, runner, revisionID, messageID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorMessageNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
 	if _, err := s.IMessageRepository.UpdateMessageContent(ctx, runner, messageID, *content); err != nil {
 		if utils.IsDeadline(err) {
 			return nil, utils.ErrorRequestTimeout
@@ -400,9 +584,74 @@ func (s *messageService) UpdateMessage(c context.Context, userInfo *auth.UserInf
 		return nil, utils.ErrorFetchingMessages
 	}
 
+	publishHubEvent(s.OutgoingPublisher, realtime.HubEvent{
+		Type:      realtime.HubEventMessageUpdated,
+		HallID:    room.HallID,
+		RoomID:    room.ID,
+		UserID:    userInfo.ID,
+		IsPrivate: room.IsPrivate,
+		Payload:   updated.ToEditOutbound(room.HallID),
+	})
+
 	return updated, nil
 }
 
+// ── GetMessageHistory ─────────────────────────────────────────────────────────
+// The author sees their own edits, text_manage_messages holders see anyone's.
+// Deleted messages keep their history so abuse can still be looked into.
+
+func (s *messageService) GetMessageHistory(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) ([]models.MessageRevision, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	room, err := s.resolveRoomWithPrivateCheck(ctx, runner, roomID, userInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.IMessageRepository.GetMessageByID(ctx, runner, messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorMessageNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingMessages
+	}
+
+	if message.RoomID != roomID {
+		return nil, utils.ErrorMessageNotFound
+	}
+
+	if message.AuthorID != userInfo.ID {
+		ok, err := s.IPermissionCheckerService.CanManageMessages(ctx, runner, userInfo.ID, room.HallID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, utils.ErrorForbidden
+		}
+	}
+
+	revisions, err := s.IMessageRepository.GetMessageRevisions(ctx, runner, messageID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingMessages
+	}
+
+	return revisions, nil
+}
+
 // ── DeleteMessage ─────────────────────────────────────────────────────────────
 // Author can delete their own message.
 // Anyone with manage_servers (admin, owner, manage_servers permission) can also delete.
@@ -456,7 +705,41 @@ func (s *messageService) DeleteMessage(c context.Context, userInfo *auth.UserInf
 		return utils.ErrorInternal
 	}
 
-	return runner.Commit(ctx)
+	// a deleted message would hold one of the room's pin slots forever
+	if _, err := s.IMessageRepository.UnpinMessage(ctx, runner, messageID); err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	// UserID is whoever deleted it, the frame still names the author
+	deletedAt := time.Now().UTC()
+	publishHubEvent(s.OutgoingPublisher, realtime.HubEvent{
+		Type:      realtime.HubEventMessageDeleted,
+		HallID:    room.HallID,
+		RoomID:    room.ID,
+		UserID:    userInfo.ID,
+		IsPrivate: room.IsPrivate,
+		Payload: &dto.OutboundMessage{
+			Type:      dto.MessageTypeDelete,
+			ID:        message.ID,
+			RoomID:    message.RoomID,
+			HallID:    room.HallID,
+			AuthorID:  message.AuthorID,
+			SentAt:    message.SentAt,
+			CreatedAt: message.CreatedAt,
+			DeletedAt: &deletedAt,
+			UpdatedAt: deletedAt,
+			WebhookID: message.WebhookID,
+		},
+	})
+
+	return nil
 }
 
 // ── AddReaction ───────────────────────────────────────────────────────────────
@@ -562,6 +845,241 @@ func (s *messageService) RemoveReaction(c context.Context, userInfo *auth.UserIn
 	return runner.Commit(ctx)
 }
 
+// ── Pins ──────────────────────────────────────────────────────────────────────
+// Pinning and unpinning need text_manage_messages, anyone who can read the
+// room sees its pins.
+
+func (s *messageService) resolvePinTarget(ctx context.Context, runner database.DBRunner, userID uuid.UUID, roomID uuid.UUID, messageID uuid.UUID) (*models.Room, *models.Message, error) {
+	room, err := s.resolveRoomWithPrivateCheck(ctx, runner, roomID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ok, err := s.IPermissionCheckerService.CanManageMessages(ctx, runner, userID, room.HallID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, utils.ErrorForbidden
+	}
+
+	message, err := s.IMessageRepository.GetMessageByID(ctx, runner, messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, utils.ErrorMessageNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, nil, utils.ErrorRequestTimeout
+		}
+		return nil, nil, utils.ErrorFetchingMessages
+	}
+
+	if message.RoomID != roomID || message.DeletedAt != nil {
+		return nil, nil, utils.ErrorMessageNotFound
+	}
+
+	return room, message, nil
+}
+
+// PinMessage is idempotent, pinning a pinned message returns the existing pin
+// and broadcasts nothing
+func (s *messageService) PinMessage(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) (*models.PinnedMessage, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	room, message, err := s.resolvePinTarget(ctx, runner, userInfo.ID, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// held until commit, so two pins can't both see room under the cap
+	if err := s.IMessageRepository.LockRoomPins(ctx, runner, roomID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorRoomNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	existing, err := s.IMessageRepository.GetPinnedMessage(ctx, runner, messageID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingPins
+	}
+
+	count, err := s.IMessageRepository.CountPinnedMessages(ctx, runner, roomID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingPins
+	}
+	if count >= MAX_PINS_PER_ROOM {
+		return nil, utils.ErrorTooManyPins
+	}
+
+	pin, err := s.IMessageRepository.PinMessage(ctx, runner, roomID, messageID, userInfo.ID)
+	if err != nil {
+		// someone else pinned it in between
+		if errors.Is(err, pgx.ErrNoRows) {
+			existing, err := s.IMessageRepository.GetPinnedMessage(ctx, runner, messageID)
+			if err != nil {
+				return nil, utils.ErrorFetchingPins
+			}
+			return existing, nil
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	publishHubEvent(s.EventPublisher, realtime.HubEvent{
+		Type:   realtime.HubEventMessagePinned,
+		HallID: room.HallID,
+		RoomID: room.ID,
+		UserID: userInfo.ID,
+		Payload: &dto.OutboundMessage{
+			Type:     dto.MessageTypePin,
+			ID:       message.ID,
+			RoomID:   room.ID,
+			HallID:   room.HallID,
+			AuthorID: message.AuthorID,
+			SentAt:   message.SentAt,
+			PinnedBy: pin.PinnedBy,
+			PinnedAt: &pin.PinnedAt,
+		},
+	})
+
+	return pin, nil
+}
+
+func (s *messageService) UnpinMessage(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID, messageID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	room, message, err := s.resolvePinTarget(ctx, runner, userInfo.ID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.IMessageRepository.UnpinMessage(ctx, runner, messageID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+	if !deleted {
+		return utils.ErrorMessageNotPinned
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	publishHubEvent(s.EventPublisher, realtime.HubEvent{
+		Type:   realtime.HubEventMessageUnpinned,
+		HallID: room.HallID,
+		RoomID: room.ID,
+		UserID: userInfo.ID,
+		Payload: &dto.OutboundMessage{
+			Type:     dto.MessageTypeUnpin,
+			ID:       message.ID,
+			RoomID:   room.ID,
+			HallID:   room.HallID,
+			AuthorID: message.AuthorID,
+			SentAt:   message.SentAt,
+			PinnedBy: &userInfo.ID,
+		},
+	})
+
+	return nil
+}
+
+func (s *messageService) GetPinnedMessages(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID) ([]*dto.PinnedMessageRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if _, err := s.resolveRoomWithPrivateCheck(ctx, runner, roomID, userInfo.ID); err != nil {
+		return nil, err
+	}
+
+	pins, err := s.IMessageRepository.GetPinnedMessages(ctx, runner, roomID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingPins
+	}
+
+	messageIDs := make([]uuid.UUID, 0, len(pins))
+	for _, pin := range pins {
+		messageIDs = append(messageIDs, pin.MessageID)
+	}
+
+	messages, err := s.IMessageRepository.GetMessagesDetailedByIDs(ctx, runner, messageIDs)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingMessages
+	}
+
+	byID := make(map[uuid.UUID]*dto.MessageDetailed, len(messages))
+	for _, message := range messages {
+		byID[message.ID] = message
+	}
+
+	// keep the pin order, the messages come back by sent_at
+	res := make([]*dto.PinnedMessageRes, 0, len(pins))
+	for _, pin := range pins {
+		message, ok := byID[pin.MessageID]
+		if !ok {
+			continue
+		}
+		res = append(res, &dto.PinnedMessageRes{
+			Message:  message,
+			PinnedBy: pin.PinnedBy,
+			PinnedAt: pin.PinnedAt,
+		})
+	}
+
+	return res, nil
+}
+
 // -- Message Read -----------------------------------------------------------
 
 func (s *messageService) MarkMessageRead(
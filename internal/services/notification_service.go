@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/notification"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/realtime"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const notificationPreviewLength = 140
+
+type INotificationService interface {
+	// -------------- INBOX
+	ListNotifications(c context.Context, userInfo *auth.UserInfo, query *dto.ListNotificationsQuery) (*dto.NotificationListRes, error)
+	GetUnreadCount(c context.Context, userInfo *auth.UserInfo) (*dto.UnreadCountRes, error)
+	MarkNotificationRead(c context.Context, userInfo *auth.UserInfo, notificationID uuid.UUID) error
+	MarkAllNotificationsRead(c context.Context, userInfo *auth.UserInfo) (*dto.MarkAllReadRes, error)
+	DeleteNotification(c context.Context, userInfo *auth.UserInfo, notificationID uuid.UUID) error
+	ClearNotifications(c context.Context, userInfo *auth.UserInfo) error
+
+	// -------------- FAN OUT
+	// CreateNotifications runs inside the caller's transaction so the inbox
+	// rows commit (or roll back) together with whatever triggered them.
+	// PublishNotifications must be called once that transaction committed.
+	CreateNotifications(ctx context.Context, runner database.DBRunner, drafts []*models.Notification) ([]*dto.NotificationRes, error)
+	PublishNotifications(notifications []*dto.NotificationRes)
+}
+
+type notificationService struct {
+	repositories.INotificationRepository
+	repositories.INotificationSettingRepository
+	repositories.IUserRepository
+
+	IPushService
+
+	EventPublisher realtime.Publisher
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewNotificationService(
+	notificationRepo repositories.INotificationRepository,
+	notificationSettingRepo repositories.INotificationSettingRepository,
+	userRepo repositories.IUserRepository,
+	pushService IPushService,
+	eventPublisher realtime.Publisher,
+	pool *pgxpool.Pool,
+) INotificationService {
+	return &notificationService{
+		notificationRepo,
+		notificationSettingRepo,
+		userRepo,
+		pushService,
+		eventPublisher,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func notificationToRes(n *models.Notification, actor *dto.NotificationActor) *dto.NotificationRes {
+	return &dto.NotificationRes{
+		ID:          n.ID,
+		UserID:      n.UserID,
+		Type:        n.Type,
+		Actor:       actor,
+		HallID:      n.HallID,
+		RoomID:      n.RoomID,
+		MessageID:   n.MessageID,
+		ReferenceID: n.ReferenceID,
+		Preview:     n.Preview,
+		ReadAt:      n.ReadAt,
+		CreatedAt:   n.CreatedAt,
+	}
+}
+
+// notificationPreview trims message content down to something that fits a toast.
+func notificationPreview(content *string) *string {
+	if content == nil || *content == "" {
+		return nil
+	}
+
+	runes := []rune(*content)
+	if len(runes) <= notificationPreviewLength {
+		return content
+	}
+
+	return utils.StringToPointer(string(runes[:notificationPreviewLength]) + "…")
+}
+
+// filterBySettings drops room notifications the recipient opted out of
+// through their hall/floor/room notification settings.
+func (s *notificationService) filterBySettings(ctx context.Context, runner database.DBRunner, drafts []*models.Notification) ([]*models.Notification, error) {
+	byRoom := make(map[uuid.UUID][]uuid.UUID)
+	for _, draft := range drafts {
+		if draft.RoomID != nil {
+			byRoom[*draft.RoomID] = append(byRoom[*draft.RoomID], draft.UserID)
+		}
+	}
+	if len(byRoom) == 0 {
+		return drafts, nil
+	}
+
+	now := time.Now()
+	effective := make(map[uuid.UUID]map[uuid.UUID]*models.EffectiveNotificationSetting, len(byRoom))
+
+	for roomID, userIDs := range byRoom {
+		settings, err := s.INotificationSettingRepository.GetRoomNotificationSettings(ctx, runner, roomID, userIDs)
+		if err != nil {
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorFetchingNotificationSettings
+		}
+
+		perUser := make(map[uuid.UUID]*models.EffectiveNotificationSetting, len(settings))
+		for userID, rows := range settings {
+			perUser[userID] = models.ResolveNotificationSettings(rows, now)
+		}
+		effective[roomID] = perUser
+	}
+
+	kept := make([]*models.Notification, 0, len(drafts))
+	for _, draft := range drafts {
+		if draft.RoomID != nil {
+			// users without any override get the defaults, which allow everything
+			if setting, ok := effective[*draft.RoomID][draft.UserID]; ok {
+				switch draft.Type {
+				case models.NotificationMention:
+					if !setting.AllowsMention() {
+						continue
+					}
+				case models.NotificationMentionEveryone:
+					if !setting.AllowsEveryone() {
+						continue
+					}
+				}
+			}
+		}
+		kept = append(kept, draft)
+	}
+
+	return kept, nil
+}
+
+// ── INBOX ─────────────────────────────────────────────────────────────────────
+
+func (s *notificationService) ListNotifications(c context.Context, userInfo *auth.UserInfo, query *dto.ListNotificationsQuery) (*dto.NotificationListRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	if query.Limit <= 0 {
+		return nil, utils.ErrorInvalidCursorLimit
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	// fetch one extra row to know if there is another page
+	rows, err := s.INotificationRepository.ListNotifications(ctx, runner, userInfo.ID, query.Before, query.Limit+1, query.UnreadOnly)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingNotifications
+	}
+
+	hasMore := len(rows) > query.Limit
+	if hasMore {
+		rows = rows[:query.Limit]
+	}
+
+	unread, err := s.INotificationRepository.CountUnreadNotifications(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingNotifications
+	}
+
+	if rows == nil {
+		rows = []*dto.NotificationRes{}
+	}
+
+	return &dto.NotificationListRes{
+		Notifications: rows,
+		UnreadCount:   unread,
+		HasMore:       hasMore,
+	}, nil
+}
+
+func (s *notificationService) GetUnreadCount(c context.Context, userInfo *auth.UserInfo) (*dto.UnreadCountRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	unread, err := s.INotificationRepository.CountUnreadNotifications(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingNotifications
+	}
+
+	return &dto.UnreadCountRes{UnreadCount: unread}, nil
+}
+
+func (s *notificationService) MarkNotificationRead(c context.Context, userInfo *auth.UserInfo, notificationID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.INotificationRepository.MarkNotificationRead(ctx, runner, userInfo.ID, notificationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorNotificationNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorUpdatingNotification
+	}
+
+	return nil
+}
+
+func (s *notificationService) MarkAllNotificationsRead(c context.Context, userInfo *auth.UserInfo) (*dto.MarkAllReadRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	updated, err := s.INotificationRepository.MarkAllNotificationsRead(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorUpdatingNotification
+	}
+
+	return &dto.MarkAllReadRes{Updated: updated}, nil
+}
+
+func (s *notificationService) DeleteNotification(c context.Context, userInfo *auth.UserInfo, notificationID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.INotificationRepository.DeleteNotification(ctx, runner, userInfo.ID, notificationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorNotificationNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorDeletingNotification
+	}
+
+	return nil
+}
+
+func (s *notificationService) ClearNotifications(c context.Context, userInfo *auth.UserInfo) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.INotificationRepository.ClearNotifications(ctx, runner, userInfo.ID); err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorDeletingNotification
+	}
+
+	return nil
+}
+
+// ── FAN OUT ───────────────────────────────────────────────────────────────────
+
+func (s *notificationService) CreateNotifications(ctx context.Context, runner database.DBRunner, drafts []*models.Notification) ([]*dto.NotificationRes, error) {
+	drafts, err := s.filterBySettings(ctx, runner, drafts)
+	if err != nil {
+		return nil, err
+	}
+
+	// nobody needs to be told about their own actions
+	kept := make([]*models.Notification, 0, len(drafts))
+	for _, draft := range drafts {
+		if draft.ActorID != nil && *draft.ActorID == draft.UserID {
+			continue
+		}
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, utils.ErrorInternal
+		}
+		draft.ID = id
+
+		kept = append(kept, draft)
+	}
+
+	saved, err := s.INotificationRepository.CreateNotifications(ctx, runner, kept)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorCreatingNotification
+	}
+
+	out := make([]*dto.NotificationRes, 0, len(saved))
+
+	// most fan outs share a single actor, so look each one up only once
+	actors := make(map[uuid.UUID]*dto.NotificationActor)
+
+	for _, n := range saved {
+		var actor *dto.NotificationActor
+		if n.ActorID != nil {
+			cached, ok := actors[*n.ActorID]
+			if !ok {
+				user, err := s.IUserRepository.GetUserById(ctx, runner, *n.ActorID)
+				if err != nil {
+					return nil, utils.ErrorFetchingUser
+				}
+				cached = &dto.NotificationActor{
+					ID:          user.ID,
+					Username:    user.Username,
+					DisplayName: user.DisplayName,
+					AvatarURL:   user.AvatarURL,
+				}
+				actors[*n.ActorID] = cached
+			}
+			actor = cached
+		}
+
+		out = append(out, notificationToRes(n, actor))
+	}
+
+	return out, nil
+}
+
+func (s *notificationService) PublishNotifications(notifications []*dto.NotificationRes) {
+	for _, n := range notifications {
+		publishHubEvent(s.EventPublisher, realtime.HubEvent{
+			Type:    realtime.HubEventNotificationCreated,
+			UserID:  n.UserID,
+			Payload: n,
+		})
+
+		// offline devices are handled by the push worker, it checks presence itself
+		if s.IPushService != nil {
+			s.IPushService.EnqueueNotification(n)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/notification"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+type INotificationSettingService interface {
+	ListNotificationSettings(c context.Context, userInfo *auth.UserInfo) ([]*models.NotificationSetting, error)
+	UpsertNotificationSetting(c context.Context, userInfo *auth.UserInfo, scope string, scopeID uuid.UUID, req *dto.UpsertNotificationSettingReq) (*models.NotificationSetting, error)
+	DeleteNotificationSetting(c context.Context, userInfo *auth.UserInfo, scope string, scopeID uuid.UUID) error
+	GetEffectiveNotificationSetting(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID) (*dto.EffectiveNotificationSettingRes, error)
+
+	// unread badges with the user's settings already applied
+	GetUnreadBadges(c context.Context, userInfo *auth.UserInfo) (*dto.UnreadBadgesRes, error)
+}
+
+type notificationSettingService struct {
+	repositories.INotificationSettingRepository
+	repositories.IHallRepository
+	repositories.IFloorRepository
+	repositories.IRoomRepository
+
+	IRoomService
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewNotificationSettingService(
+	notificationSettingRepo repositories.INotificationSettingRepository,
+	hallRepo repositories.IHallRepository,
+	floorRepo repositories.IFloorRepository,
+	roomRepo repositories.IRoomRepository,
+	roomService IRoomService,
+	pool *pgxpool.Pool,
+) INotificationSettingService {
+	return &notificationSettingService{
+		notificationSettingRepo,
+		hallRepo,
+		floorRepo,
+		roomRepo,
+		roomService,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func parseNotificationScope(scope string) (models.NotificationScope, error) {
+	switch s := models.NotificationScope(scope); s {
+	case models.NotificationScopeHall, models.NotificationScopeFloor, models.NotificationScopeRoom:
+		return s, nil
+	}
+	return "", utils.ErrorInvalidNotificationScope
+}
+
+// resolveScopeHall makes sure the user can actually see the scope target and
+// returns the hall it lives in.
+func (s *notificationSettingService) resolveScopeHall(ctx context.Context, runner database.DBRunner, userID uuid.UUID, scope models.NotificationScope, scopeID uuid.UUID) (uuid.UUID, error) {
+	var hallID uuid.UUID
+
+	switch scope {
+	case models.NotificationScopeHall:
+		hallID = scopeID
+
+	case models.NotificationScopeFloor:
+		floor, err := s.IFloorRepository.GetFloorByID(ctx, runner, scopeID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return uuid.Nil, utils.ErrorFloorNotFound
+			}
+			if utils.IsDeadline(err) {
+				return uuid.Nil, utils.ErrorRequestTimeout
+			}
+			return uuid.Nil, utils.ErrorFetchingFloor
+		}
+		hallID = floor.HallID
+
+	case models.NotificationScopeRoom:
+		room, err := s.IRoomRepository.GetRoomByID(ctx, runner, scopeID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return uuid.Nil, utils.ErrorRoomNotFound
+			}
+			if utils.IsDeadline(err) {
+				return uuid.Nil, utils.ErrorRequestTimeout
+			}
+			return uuid.Nil, utils.ErrorFetchingRoom
+		}
+
+		if room.IsPrivate {
+			isRoomMember, err := s.IRoomRepository.IsUserRoomMember(ctx, runner, room.ID, userID)
+			if err != nil {
+				if utils.IsDeadline(err) {
+					return uuid.Nil, utils.ErrorRequestTimeout
+				}
+				return uuid.Nil, utils.ErrorFetchingRoom
+			}
+			if !isRoomMember {
+				return uuid.Nil, utils.ErrorUserDoesntBelongRoom
+			}
+		}
+		hallID = room.HallID
+	}
+
+	isMember, err := s.IHallRepository.IsUserHallMember(ctx, runner, hallID, userID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return uuid.Nil, utils.ErrorRequestTimeout
+		}
+		return uuid.Nil, utils.ErrorFetchingHall
+	}
+	if !isMember {
+		return uuid.Nil, utils.ErrorUserDoesntBelongHall
+	}
+
+	return hallID, nil
+}
+
+// ── SETTINGS ──────────────────────────────────────────────────────────────────
+
+func (s *notificationSettingService) ListNotificationSettings(c context.Context, userInfo *auth.UserInfo) ([]*models.NotificationSetting, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	settings, err := s.INotificationSettingRepository.ListUserNotificationSettings(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingNotificationSettings
+	}
+
+	return settings, nil
+}
+
+func (s *notificationSettingService) UpsertNotificationSetting(c context.Context, userInfo *auth.UserInfo, scope string, scopeID uuid.UUID, req *dto.UpsertNotificationSettingReq) (*models.NotificationSetting, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	parsedScope, err := parseNotificationScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// an already expired mute is the same as no mute
+	mutedUntil := req.MutedUntil
+	if mutedUntil != nil && !mutedUntil.After(time.Now()) {
+		mutedUntil = nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	hallID, err := s.resolveScopeHall(ctx, runner, userInfo.ID, parsedScope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	saved, err := s.INotificationSettingRepository.UpsertNotificationSetting(ctx, runner, &models.NotificationSetting{
+		ID:               id,
+		UserID:           userInfo.ID,
+		Scope:            parsedScope,
+		ScopeID:          scopeID,
+		HallID:           hallID,
+		Level:            req.Level,
+		SuppressEveryone: req.SuppressEveryone,
+		MutedUntil:       mutedUntil,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorSavingNotificationSetting
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return saved, nil
+}
+
+func (s *notificationSettingService) DeleteNotificationSetting(c context.Context, userInfo *auth.UserInfo, scope string, scopeID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	parsedScope, err := parseNotificationScope(scope)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.INotificationSettingRepository.DeleteNotificationSetting(ctx, runner, userInfo.ID, parsedScope, scopeID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorNotificationSettingNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorDeletingNotificationSetting
+	}
+
+	return nil
+}
+
+func (s *notificationSettingService) GetEffectiveNotificationSetting(c context.Context, userInfo *auth.UserInfo, roomID uuid.UUID) (*dto.EffectiveNotificationSettingRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	// also rejects rooms the user can't see
+	if _, err := s.resolveScopeHall(ctx, runner, userInfo.ID, models.NotificationScopeRoom, roomID); err != nil {
+		return nil, err
+	}
+
+	room, err := s.IRoomRepository.GetRoomByID(ctx, runner, roomID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingRoom
+	}
+
+	settings, err := s.INotificationSettingRepository.GetRoomNotificationSettings(ctx, runner, roomID, []uuid.UUID{userInfo.ID})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingNotificationSettings
+	}
+
+	return &dto.EffectiveNotificationSettingRes{
+		RoomID:                       room.ID,
+		HallID:                       room.HallID,
+		FloorID:                      room.FloorID,
+		EffectiveNotificationSetting: models.ResolveNotificationSettings(settings[userInfo.ID], time.Now()),
+	}, nil
+}
+
+// ── BADGES ────────────────────────────────────────────────────────────────────
+
+func (s *notificationSettingService) GetUnreadBadges(c context.Context, userInfo *auth.UserInfo) (*dto.UnreadBadgesRes, error) {
+	accessible, err := s.IRoomService.GetAccessibleRoomsForUser(c, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dto.UnreadBadgesRes{
+		Rooms: []*dto.RoomBadgeRes{},
+		Halls: []*dto.HallBadgeRes{},
+	}
+	if len(accessible) == 0 {
+		return out, nil
+	}
+
+	roomIDs := make([]uuid.UUID, 0, len(accessible))
+	for roomID := range accessible {
+		roomIDs = append(roomIDs, roomID)
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	counts, err := s.INotificationSettingRepository.ListRoomUnreadCounts(ctx, runner, userInfo.ID, roomIDs)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingMessages
+	}
+
+	settings, err := s.INotificationSettingRepository.ListUserNotificationSettings(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingNotificationSettings
+	}
+
+	byScope := make(map[uuid.UUID]*models.NotificationSetting, len(settings))
+	for _, setting := range settings {
+		byScope[setting.ScopeID] = setting
+	}
+
+	now := time.Now()
+	halls := make(map[uuid.UUID]*dto.HallBadgeRes)
+
+	for _, count := range counts {
+		applicable := make([]*models.NotificationSetting, 0, 3)
+		for _, scopeID := range []*uuid.UUID{&count.RoomID, count.FloorID, &count.HallID} {
+			if scopeID == nil {
+				continue
+			}
+			if setting, ok := byScope[*scopeID]; ok {
+				applicable = append(applicable, setting)
+			}
+		}
+		effective := models.ResolveNotificationSettings(applicable, now)
+
+		badge := &dto.RoomBadgeRes{
+			RoomID: count.RoomID,
+			HallID: count.HallID,
+			Muted:  effective.IsMuted() || effective.Level == models.NotificationLevelNothing,
+		}
+		if effective.AllowsUnread() {
+			badge.UnreadCount = count.UnreadCount
+		}
+		if effective.AllowsMention() {
+			badge.MentionCount = count.MentionCount
+		}
+		if effective.AllowsEveryone() {
+			badge.MentionCount += count.EveryoneCount
+		}
+
+		out.Rooms = append(out.Rooms, badge)
+
+		hall, ok := halls[count.HallID]
+		if !ok {
+			hall = &dto.HallBadgeRes{HallID: count.HallID}
+			halls[count.HallID] = hall
+			out.Halls = append(out.Halls, hall)
+		}
+		hall.HasUnread = hall.HasUnread || badge.UnreadCount > 0
+		hall.MentionCount += badge.MentionCount
+	}
+
+	return out, nil
+}
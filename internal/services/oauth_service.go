@@ -0,0 +1,881 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	MAX_OAUTH_APPS_PER_OWNER = 25
+
+	oauthCodeTTL    = 5 * time.Minute
+	oauthAccessTTL  = time.Hour
+	oauthRefreshTTL = 30 * 24 * time.Hour
+	oauthTokenType  = "Bearer"
+)
+
+type IOAuthService interface {
+	// -------------- APPS
+	CreateOAuthApp(c context.Context, userInfo *auth.UserInfo, req *dto.CreateOAuthAppReq) (*dto.OAuthAppSecretRes, error)
+	ListMyOAuthApps(c context.Context, userInfo *auth.UserInfo) (*dto.OAuthAppsRes, error)
+	UpdateOAuthApp(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID, req *dto.UpdateOAuthAppReq) (*dto.OAuthAppRes, error)
+	RotateOAuthAppSecret(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID) (*dto.OAuthAppSecretRes, error)
+	DeleteOAuthApp(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID) error
+
+	// -------------- CONSENT
+	// GetConsent validates an authorization request and describes it for the
+	// consent page, Authorize answers it
+	GetConsent(c context.Context, userInfo *auth.UserInfo, req *dto.OAuthAuthorizeReq) (*dto.OAuthConsentRes, error)
+	Authorize(c context.Context, userInfo *auth.UserInfo, req *dto.OAuthDecisionReq) (*dto.OAuthRedirectRes, error)
+
+	// -------------- AUTHORIZATIONS
+	ListMyAuthorizations(c context.Context, userInfo *auth.UserInfo) (*dto.OAuthAuthorizationsRes, error)
+	RevokeAuthorization(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID) error
+
+	// -------------- PROTOCOL
+	// these fail with *utils.OAuthError
+	ExchangeToken(c context.Context, client *dto.OAuthClientCredentials, req *dto.OAuthTokenReq) (*dto.OAuthTokenRes, error)
+	RevokeToken(c context.Context, client *dto.OAuthClientCredentials, token string) error
+	IntrospectToken(c context.Context, client *dto.OAuthClientCredentials, token string) (*dto.OAuthIntrospectionRes, error)
+
+	// VerifyOAuthToken is the auth.ScopedTokenVerifier for app access tokens
+	VerifyOAuthToken(c context.Context, token string) (*auth.UserInfo, error)
+}
+
+type oauthService struct {
+	repositories.IOAuthRepository
+	repositories.IOAuthCodeRepository
+	repositories.IUserRepository
+
+	// plain http redirect URIs beyond localhost, for development only
+	allowInsecure bool
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewOAuthService(
+	oauthRepo repositories.IOAuthRepository,
+	oauthCodeRepo repositories.IOAuthCodeRepository,
+	userRepo repositories.IUserRepository,
+	allowInsecureRedirects bool,
+	pool *pgxpool.Pool,
+) IOAuthService {
+	return &oauthService{
+		oauthRepo,
+		oauthCodeRepo,
+		userRepo,
+		allowInsecureRedirects,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func (s *oauthService) validateRedirectURIs(raw []string) ([]string, error) {
+	uris := make([]string, 0, len(raw))
+	for _, r := range raw {
+		uri, ok := utils.ValidateRedirectURI(r, s.allowInsecure)
+		if !ok {
+			return nil, utils.ErrorInvalidRedirectURI
+		}
+		if !slices.Contains(uris, uri) {
+			uris = append(uris, uri)
+		}
+	}
+	return uris, nil
+}
+
+func validOAuthScopes(scopes []string) bool {
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, scope := range scopes {
+		if !models.IsValidTokenScope(scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// newOAuthSecret returns a prefixed opaque secret and the keyed hash to store
+func newOAuthSecret(prefix string) (string, []byte, error) {
+	raw, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	secret := prefix + raw
+	return secret, auth.HashOpaqueToken(secret), nil
+}
+
+// checkAuthorizeRequest is shared by the consent page and the decision, the
+// app is only trusted with a redirect once redirect_uri matched
+func (s *oauthService) checkAuthorizeRequest(ctx context.Context, runner database.DBRunner, req *dto.OAuthAuthorizeReq) (*models.OAuthApp, []string, error) {
+	app, err := s.IOAuthRepository.GetOAuthApp(ctx, runner, req.ClientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, utils.ErrorOAuthAppNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, nil, utils.ErrorRequestTimeout
+		}
+		return nil, nil, utils.ErrorFetchingOAuthApps
+	}
+
+	if !slices.Contains(app.RedirectURIs, req.RedirectURI) {
+		return nil, nil, utils.ErrorRedirectURIMismatch
+	}
+	if req.ResponseType != "code" {
+		return nil, nil, utils.ErrorUnsupportedResponseType
+	}
+
+	// only S256, plain would hand the verifier to anyone reading the URL
+	switch {
+	case req.CodeChallenge != "" && req.CodeChallengeMethod != "S256":
+		return nil, nil, utils.ErrorPKCERequired
+	case req.CodeChallenge == "" && !app.IsConfidential():
+		return nil, nil, utils.ErrorPKCERequired
+	}
+
+	scopes := utils.ParseOAuthScopes(req.Scope)
+	if !validOAuthScopes(scopes) {
+		return nil, nil, utils.ErrorInvalidTokenScope
+	}
+
+	return app, scopes, nil
+}
+
+// authenticateClient checks the client secret of confidential apps, public
+// apps must not send one
+func (s *oauthService) authenticateClient(ctx context.Context, runner database.DBRunner, client *dto.OAuthClientCredentials) (*models.OAuthApp, error) {
+	appID, err := uuid.Parse(client.ClientID)
+	if err != nil {
+		return nil, utils.ErrorOAuthInvalidClient
+	}
+
+	app, err := s.IOAuthRepository.GetOAuthApp(ctx, runner, appID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOAuthInvalidClient
+		}
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	if app.IsConfidential() {
+		if client.ClientSecret == "" || !hmac.Equal(auth.HashOpaqueToken(client.ClientSecret), app.ClientSecretHash) {
+			return nil, utils.ErrorOAuthInvalidClient
+		}
+	} else if client.ClientSecret != "" {
+		return nil, utils.ErrorOAuthInvalidClient
+	}
+
+	return app, nil
+}
+
+// issueOAuthTokens fills in fresh token hashes and expiries on grant and
+// returns the response carrying the raw tokens
+func issueOAuthTokens(grant *models.OAuthToken) (*dto.OAuthTokenRes, error) {
+	accessToken, accessHash, err := newOAuthSecret(models.OAuthAccessTokenPrefix)
+	if err != nil {
+		return nil, utils.ErrorOAuthServerError
+	}
+	refreshToken, refreshHash, err := newOAuthSecret(models.OAuthRefreshTokenPrefix)
+	if err != nil {
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	now := time.Now().UTC()
+	grant.AccessTokenHash = accessHash
+	grant.RefreshTokenHash = refreshHash
+	grant.AccessExpiresAt = now.Add(oauthAccessTTL)
+	grant.RefreshExpiresAt = now.Add(oauthRefreshTTL)
+
+	return &dto.OAuthTokenRes{
+		AccessToken:  accessToken,
+		TokenType:    oauthTokenType,
+		ExpiresIn:    int(oauthAccessTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(grant.Scopes, " "),
+	}, nil
+}
+
+// ── APPS ──────────────────────────────────────────────────────────────────────
+
+func (s *oauthService) CreateOAuthApp(c context.Context, userInfo *auth.UserInfo, req *dto.CreateOAuthAppReq) (*dto.OAuthAppSecretRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, utils.ErrorInvalidInput
+	}
+	description, err := utils.SanitizeText(req.Description)
+	if err != nil {
+		return nil, err
+	}
+	redirectURIs, err := s.validateRedirectURIs(req.RedirectURIs)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret *string
+	var secretHash []byte
+	if req.Confidential {
+		raw, hash, err := newOAuthSecret(models.OAuthClientSecretPrefix)
+		if err != nil {
+			return nil, utils.ErrorInternal
+		}
+		secret, secretHash = &raw, hash
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	count, err := s.IOAuthRepository.CountOAuthAppsByOwner(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOAuthApps
+	}
+	if count >= MAX_OAUTH_APPS_PER_OWNER {
+		return nil, utils.ErrorTooManyOAuthApps
+	}
+
+	appID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	app, err := s.IOAuthRepository.CreateOAuthApp(ctx, runner, &models.OAuthApp{
+		ID:               appID,
+		OwnerID:          userInfo.ID,
+		Name:             name,
+		Description:      description,
+		RedirectURIs:     redirectURIs,
+		ClientSecretHash: secretHash,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorSavingOAuthApp
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.OAuthAppSecretRes{
+		OAuthAppRes:  dto.ToOAuthAppRes(*app),
+		ClientSecret: secret,
+	}, nil
+}
+
+func (s *oauthService) ListMyOAuthApps(c context.Context, userInfo *auth.UserInfo) (*dto.OAuthAppsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	apps, err := s.IOAuthRepository.ListOAuthAppsByOwner(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOAuthApps
+	}
+
+	res := &dto.OAuthAppsRes{Apps: make([]dto.OAuthAppRes, 0, len(apps))}
+	for _, app := range apps {
+		res.Apps = append(res.Apps, dto.ToOAuthAppRes(*app))
+	}
+	return res, nil
+}
+
+func (s *oauthService) UpdateOAuthApp(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID, req *dto.UpdateOAuthAppReq) (*dto.OAuthAppRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	fields := make(map[string]any)
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		if name == "" {
+			return nil, utils.ErrorInvalidInput
+		}
+		fields["name"] = name
+	}
+	if req.Description != nil {
+		description, err := utils.SanitizeText(req.Description)
+		if err != nil {
+			return nil, err
+		}
+		fields["description"] = description
+	}
+	if req.RedirectURIs != nil {
+		redirectURIs, err := s.validateRedirectURIs(*req.RedirectURIs)
+		if err != nil {
+			return nil, err
+		}
+		fields["redirect_uris"] = redirectURIs
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	app, err := s.IOAuthRepository.UpdateOwnedOAuthApp(ctx, runner, userInfo.ID, appID, fields)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOAuthAppNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorSavingOAuthApp
+	}
+
+	res := dto.ToOAuthAppRes(*app)
+	return &res, nil
+}
+
+func (s *oauthService) RotateOAuthAppSecret(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID) (*dto.OAuthAppSecretRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	app, err := s.IOAuthRepository.GetOAuthApp(ctx, runner, appID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOAuthAppNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOAuthApps
+	}
+	if app.OwnerID != userInfo.ID {
+		return nil, utils.ErrorOAuthAppNotFound
+	}
+	if !app.IsConfidential() {
+		return nil, utils.ErrorOAuthAppIsPublic
+	}
+
+	secret, secretHash, err := newOAuthSecret(models.OAuthClientSecretPrefix)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	app, err = s.IOAuthRepository.UpdateOwnedOAuthApp(ctx, runner, userInfo.ID, appID, map[string]any{
+		"client_secret_hash": secretHash,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOAuthAppNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorSavingOAuthApp
+	}
+
+	return &dto.OAuthAppSecretRes{
+		OAuthAppRes:  dto.ToOAuthAppRes(*app),
+		ClientSecret: &secret,
+	}, nil
+}
+
+func (s *oauthService) DeleteOAuthApp(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	// its tokens go with it
+	if err := s.IOAuthRepository.DeleteOwnedOAuthApp(ctx, runner, userInfo.ID, appID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorOAuthAppNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+// ── CONSENT ───────────────────────────────────────────────────────────────────
+
+func (s *oauthService) GetConsent(c context.Context, userInfo *auth.UserInfo, req *dto.OAuthAuthorizeReq) (*dto.OAuthConsentRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	app, scopes, err := s.checkAuthorizeRequest(ctx, runner, req)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.IUserRepository.GetUserById(ctx, runner, app.OwnerID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingUser
+	}
+
+	granted, err := s.IOAuthRepository.GetUserAppScopes(ctx, runner, userInfo.ID, app.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOAuthApps
+	}
+
+	alreadyAuthorized := true
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			alreadyAuthorized = false
+		}
+	}
+
+	return &dto.OAuthConsentRes{
+		App: dto.OAuthConsentAppRes{
+			ClientID:      app.ID,
+			Name:          app.Name,
+			Description:   app.Description,
+			OwnerUsername: owner.Username,
+		},
+		Scopes:            scopes,
+		RedirectURI:       req.RedirectURI,
+		AlreadyAuthorized: alreadyAuthorized,
+	}, nil
+}
+
+func (s *oauthService) Authorize(c context.Context, userInfo *auth.UserInfo, req *dto.OAuthDecisionReq) (*dto.OAuthRedirectRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	app, scopes, err := s.checkAuthorizeRequest(ctx, runner, &req.OAuthAuthorizeReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.Approve {
+		return &dto.OAuthRedirectRes{
+			RedirectTo: utils.AppendQuery(req.RedirectURI, map[string]string{
+				"error": "access_denied",
+				"state": req.State,
+			}),
+		}, nil
+	}
+
+	code, codeHash, err := newOAuthSecret(models.OAuthCodePrefix)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	err = s.IOAuthCodeRepository.CreateOAuthCode(ctx, codeHash, &models.OAuthCode{
+		AppID:         app.ID,
+		UserID:        userInfo.ID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().UTC().Add(oauthCodeTTL),
+	}, oauthCodeTTL)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorIssuingOAuthCode
+	}
+
+	return &dto.OAuthRedirectRes{
+		RedirectTo: utils.AppendQuery(req.RedirectURI, map[string]string{
+			"code":  code,
+			"state": req.State,
+		}),
+	}, nil
+}
+
+// ── AUTHORIZATIONS ────────────────────────────────────────────────────────────
+
+func (s *oauthService) ListMyAuthorizations(c context.Context, userInfo *auth.UserInfo) (*dto.OAuthAuthorizationsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	authorizations, err := s.IOAuthRepository.ListUserAuthorizations(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOAuthApps
+	}
+
+	res := &dto.OAuthAuthorizationsRes{Authorizations: make([]dto.OAuthAuthorizationRes, 0, len(authorizations))}
+	for _, authorization := range authorizations {
+		res.Authorizations = append(res.Authorizations, dto.ToOAuthAuthorizationRes(*authorization))
+	}
+	return res, nil
+}
+
+func (s *oauthService) RevokeAuthorization(c context.Context, userInfo *auth.UserInfo, appID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.IOAuthRepository.DeleteUserAppTokens(ctx, runner, userInfo.ID, appID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorOAuthAuthorizationNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+// ── PROTOCOL ──────────────────────────────────────────────────────────────────
+
+func (s *oauthService) ExchangeToken(c context.Context, client *dto.OAuthClientCredentials, req *dto.OAuthTokenReq) (*dto.OAuthTokenRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorOAuthServerError
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	app, err := s.authenticateClient(ctx, runner, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *dto.OAuthTokenRes
+	switch req.GrantType {
+	case "authorization_code":
+		res, err = s.exchangeCode(ctx, runner, app, req)
+	case "refresh_token":
+		res, err = s.refreshToken(ctx, runner, app, req)
+	case "":
+		err = utils.ErrorOAuthInvalidRequest
+	default:
+		err = utils.ErrorOAuthUnsupportedGrantType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	return res, nil
+}
+
+func (s *oauthService) exchangeCode(ctx context.Context, runner database.DBRunner, app *models.OAuthApp, req *dto.OAuthTokenReq) (*dto.OAuthTokenRes, error) {
+	if req.Code == "" || req.RedirectURI == "" {
+		return nil, utils.ErrorOAuthInvalidRequest
+	}
+
+	code, err := s.IOAuthCodeRepository.ConsumeOAuthCode(ctx, auth.HashOpaqueToken(req.Code))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, utils.ErrorOAuthInvalidGrant
+		}
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	if code.AppID != app.ID || code.RedirectURI != req.RedirectURI || time.Now().After(code.ExpiresAt) {
+		return nil, utils.ErrorOAuthInvalidGrant
+	}
+	if code.CodeChallenge != "" {
+		if !utils.VerifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+			return nil, utils.ErrorOAuthInvalidGrant
+		}
+	} else if req.CodeVerifier != "" {
+		return nil, utils.ErrorOAuthInvalidGrant
+	}
+
+	grantID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	grant := &models.OAuthToken{
+		ID:     grantID,
+		AppID:  app.ID,
+		UserID: code.UserID,
+		Scopes: code.Scopes,
+	}
+	res, err := issueOAuthTokens(grant)
+	if err != nil {
+		return nil, err
+	}
+
+	// bookkeeping only, expired grants would linger in the list otherwise
+	_ = s.IOAuthRepository.PruneExpiredOAuthTokens(ctx, runner, code.UserID)
+
+	if err := s.IOAuthRepository.CreateOAuthToken(ctx, runner, grant); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOAuthInvalidGrant
+		}
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	return res, nil
+}
+
+func (s *oauthService) refreshToken(ctx context.Context, runner database.DBRunner, app *models.OAuthApp, req *dto.OAuthTokenReq) (*dto.OAuthTokenRes, error) {
+	if req.RefreshToken == "" {
+		return nil, utils.ErrorOAuthInvalidRequest
+	}
+
+	refreshHash := auth.HashOpaqueToken(req.RefreshToken)
+	grant, err := s.IOAuthRepository.GetRefreshableOAuthToken(ctx, runner, refreshHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOAuthInvalidGrant
+		}
+		return nil, utils.ErrorOAuthServerError
+	}
+	if grant.AppID != app.ID {
+		return nil, utils.ErrorOAuthInvalidGrant
+	}
+
+	// a refresh may narrow the scopes, never widen them
+	if req.Scope != "" {
+		scopes := utils.ParseOAuthScopes(req.Scope)
+		for _, scope := range scopes {
+			if !slices.Contains(grant.Scopes, scope) {
+				return nil, utils.ErrorOAuthInvalidScope
+			}
+		}
+		grant.Scopes = scopes
+	}
+
+	res, err := issueOAuthTokens(grant)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.IOAuthRepository.RotateOAuthToken(ctx, runner, grant.ID, refreshHash, grant); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOAuthInvalidGrant
+		}
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	return res, nil
+}
+
+func (s *oauthService) RevokeToken(c context.Context, client *dto.OAuthClientCredentials, token string) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorOAuthServerError
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	app, err := s.authenticateClient(ctx, runner, client)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return utils.ErrorOAuthInvalidRequest
+	}
+
+	// RFC 7009, unknown tokens are not an error
+	if err := s.IOAuthRepository.DeleteOAuthTokenByHash(ctx, runner, app.ID, auth.HashOpaqueToken(token)); err != nil {
+		return utils.ErrorOAuthServerError
+	}
+
+	return nil
+}
+
+func (s *oauthService) IntrospectToken(c context.Context, client *dto.OAuthClientCredentials, token string) (*dto.OAuthIntrospectionRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorOAuthServerError
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	app, err := s.authenticateClient(ctx, runner, client)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, utils.ErrorOAuthInvalidRequest
+	}
+
+	tokenHash := auth.HashOpaqueToken(token)
+	tokenType := "access_token"
+	grant, err := s.IOAuthRepository.GetActiveOAuthToken(ctx, runner, tokenHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		tokenType = "refresh_token"
+		grant, err = s.IOAuthRepository.GetRefreshableOAuthToken(ctx, runner, tokenHash)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &dto.OAuthIntrospectionRes{Active: false}, nil
+		}
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	// clients only learn about their own tokens
+	if grant.AppID != app.ID {
+		return &dto.OAuthIntrospectionRes{Active: false}, nil
+	}
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, grant.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &dto.OAuthIntrospectionRes{Active: false}, nil
+		}
+		return nil, utils.ErrorOAuthServerError
+	}
+
+	exp := grant.AccessExpiresAt
+	if tokenType == "refresh_token" {
+		exp = grant.RefreshExpiresAt
+	}
+
+	return &dto.OAuthIntrospectionRes{
+		Active:    true,
+		Scope:     strings.Join(grant.Scopes, " "),
+		ClientID:  app.ID.String(),
+		Username:  user.Username,
+		Sub:       user.ID.String(),
+		TokenType: tokenType,
+		Exp:       exp.Unix(),
+		Iat:       grant.CreatedAt.Unix(),
+	}, nil
+}
+
+func (s *oauthService) VerifyOAuthToken(c context.Context, token string) (*auth.UserInfo, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	grant, err := s.IOAuthRepository.GetActiveOAuthToken(ctx, runner, auth.HashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidPersonalToken
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, grant.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidPersonalToken
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	// bookkeeping only, a failed touch doesn't fail the request
+	_ = s.IOAuthRepository.TouchOAuthToken(ctx, runner, grant.ID)
+
+	scopes := grant.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &auth.UserInfo{
+		ID:       user.ID,
+		Username: user.Username,
+		Scopes:   scopes,
+	}, nil
+}
@@ -0,0 +1,621 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/oidc"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// time between starting a login and the provider sending the user back
+const oidcLoginStateTTL = 10 * time.Minute
+
+type IOIDCService interface {
+	ListProviders(c context.Context) []dto.OIDCProviderRes
+
+	// -------------- LOGIN
+	// BeginLogin returns the provider URL to send the browser to. With a
+	// signed in user the returning identity is linked to that account instead.
+	BeginLogin(c context.Context, provider string, linkUser *auth.UserInfo) (*dto.OIDCAuthorizationRes, error)
+	CompleteLogin(c context.Context, provider string, req *dto.OIDCCallbackReq, client *dto.ClientInfo) (*dto.OIDCLoginRes, error)
+	CompleteSignup(c context.Context, req *dto.OIDCSignupReq, client *dto.ClientInfo) (*dto.OIDCLoginRes, error)
+
+	// -------------- LINKED LOGINS
+	ListMyIdentities(c context.Context, userInfo *auth.UserInfo) (*dto.UserIdentitiesRes, error)
+	UnlinkIdentity(c context.Context, userInfo *auth.UserInfo, provider string) error
+}
+
+type oidcService struct {
+	repositories.IUserIdentityRepository
+	repositories.IUserRepository
+	repositories.ITwoFactorRepository
+
+	IUserService
+	IAccountService
+	ISigninSecurityService
+
+	providers *oidc.Registry
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewOIDCService(
+	identityRepo repositories.IUserIdentityRepository,
+	userRepo repositories.IUserRepository,
+	twoFactorRepo repositories.ITwoFactorRepository,
+	userService IUserService,
+	accountService IAccountService,
+	signinSecurityService ISigninSecurityService,
+	providers *oidc.Registry,
+	pool *pgxpool.Pool,
+) IOIDCService {
+	return &oidcService{
+		identityRepo,
+		userRepo,
+		twoFactorRepo,
+		userService,
+		accountService,
+		signinSecurityService,
+		providers,
+		pool,
+		// the provider round trips don't fit in the usual 2s
+		time.Duration(15) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+var usernameDisallowed = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// suggestUsername turns whatever the provider knows about the user into
+// something that passes SanitizeUsername, the user can still change it.
+func suggestUsername(payload *auth.ExternalSignupPayload) string {
+	candidates := []string{payload.Name}
+	if local, _, ok := strings.Cut(payload.Email, "@"); ok {
+		candidates = append([]string{local}, candidates...)
+	}
+
+	for _, c := range candidates {
+		c = usernameDisallowed.ReplaceAllString(strings.ToLower(strings.TrimSpace(c)), "_")
+		c = strings.Trim(c, "_.-")
+		if len(c) > 32 {
+			c = c[:32]
+		}
+		if canon, err := utils.SanitizeUsername(c); err == nil {
+			return canon
+		}
+	}
+	return ""
+}
+
+func (s *oidcService) provider(name string) (*oidc.Provider, error) {
+	p, ok := s.providers.Get(strings.ToLower(name))
+	if !ok {
+		return nil, utils.ErrorOIDCProviderNotFound
+	}
+	return p, nil
+}
+
+// newOIDCLoginState starts the code flow, the state returned has to be stored
+// until the browser comes back with the raw state in the URL.
+func newOIDCLoginState(ctx context.Context, provider *oidc.Provider, linkUser *auth.UserInfo) (string, *models.OIDCLoginState, error) {
+	rawState, stateHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", nil, utils.ErrorInternal
+	}
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		return "", nil, utils.ErrorInternal
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", nil, utils.ErrorInternal
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, rawState, nonce, verifier)
+	if err != nil {
+		log.Printf("oidc: %s discovery: %v", provider.Name(), err)
+		return "", nil, utils.ErrorOIDCProviderFailed
+	}
+
+	state := &models.OIDCLoginState{
+		StateHash:    stateHash,
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcLoginStateTTL),
+	}
+	if linkUser != nil {
+		state.LinkUserID = &linkUser.ID
+	}
+
+	return authURL, state, nil
+}
+
+func (s *oidcService) consumeOIDCLoginState(ctx context.Context, runner database.DBRunner, provider *oidc.Provider, rawState string) (*models.OIDCLoginState, error) {
+	state, err := s.IUserIdentityRepository.ConsumeOIDCLoginState(ctx, runner, provider.Name(), auth.HashOpaqueToken(rawState))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidOIDCState
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return state, nil
+}
+
+// exchangeOIDCCode redeems the code with the verifier and nonce kept in state.
+func exchangeOIDCCode(ctx context.Context, provider *oidc.Provider, state *models.OIDCLoginState, code string) (*oidc.Claims, error) {
+	claims, err := provider.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("oidc: %s exchange: %v", provider.Name(), err)
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			return nil, utils.ErrorInvalidOIDCLogin
+		}
+		return nil, utils.ErrorOIDCProviderFailed
+	}
+
+	return claims, nil
+}
+
+// signIn finishes a login for an existing account, 2FA still applies.
+func (s *oidcService) signIn(c context.Context, user *models.User, client *dto.ClientInfo) (*dto.OIDCLoginRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewConnWrapper(conn)
+	twoFactorEnabled, err := s.ITwoFactorRepository.IsTwoFactorEnabled(ctx, runner, user.ID)
+	conn.Release()
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if twoFactorEnabled {
+		mfaToken, err := auth.GetMFAChallengeToken(user)
+		if err != nil {
+			return nil, utils.ErrorInternal
+		}
+		return &dto.OIDCLoginRes{
+			Status:   dto.OIDCLoginMFARequired,
+			MFAToken: mfaToken,
+		}, nil
+	}
+
+	signedToken, err := auth.GetSignedToken(user)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	s.ISigninSecurityService.RecordSigninSuccess(c, client, user, models.SigninMethodOIDC)
+
+	userMe, err := s.IUserService.GetUserMe(c, &auth.UserInfo{ID: user.ID, Username: user.Username})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OIDCLoginRes{
+		Status:      dto.OIDCLoginSignedIn,
+		AccessToken: signedToken,
+		UserMe:      userMe,
+	}, nil
+}
+
+// ── LOGIN ─────────────────────────────────────────────────────────────────────
+
+func (s *oidcService) ListProviders(c context.Context) []dto.OIDCProviderRes {
+	out := make([]dto.OIDCProviderRes, 0)
+	for _, p := range s.providers.List() {
+		out = append(out, dto.OIDCProviderRes{
+			Name:        p.Name(),
+			DisplayName: p.DisplayName(),
+		})
+	}
+	return out
+}
+
+func (s *oidcService) BeginLogin(c context.Context, providerName string, linkUser *auth.UserInfo) (*dto.OIDCAuthorizationRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, state, err := newOIDCLoginState(ctx, provider, linkUser)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	// abandoned logins pile up otherwise
+	if err := s.IUserIdentityRepository.DeleteExpiredOIDCLoginStates(ctx, runner); err != nil {
+		log.Printf("oidc: prune login states: %v", err)
+	}
+
+	if err := s.IUserIdentityRepository.CreateOIDCLoginState(ctx, runner, state); err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.OIDCAuthorizationRes{AuthorizationURL: authURL}, nil
+}
+
+func (s *oidcService) CompleteLogin(c context.Context, providerName string, req *dto.OIDCCallbackReq, client *dto.ClientInfo) (*dto.OIDCLoginRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	// the state is burned even if the exchange fails below
+	state, err := s.consumeOIDCLoginState(ctx, runner, provider, req.State)
+	if err != nil {
+		return nil, err
+	}
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	claims, err := exchangeOIDCCode(ctx, provider, state, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err = s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner = database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	identity, err := s.IUserIdentityRepository.GetUserIdentity(ctx, runner, provider.Name(), claims.Subject)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	var email *string
+	if claims.Email != "" {
+		email = &claims.Email
+	}
+
+	// -------- LINKING FROM SETTINGS
+	if state.LinkUserID != nil {
+		if identity != nil {
+			if identity.UserID == *state.LinkUserID {
+				return &dto.OIDCLoginRes{Status: dto.OIDCLoginLinked}, nil
+			}
+			return nil, utils.ErrorIdentityAlreadyLinked
+		}
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, utils.ErrorInternal
+		}
+
+		_, err = s.IUserIdentityRepository.CreateUserIdentity(ctx, runner, &models.UserIdentity{
+			ID:       id,
+			UserID:   *state.LinkUserID,
+			Provider: provider.Name(),
+			Subject:  claims.Subject,
+			Email:    email,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return nil, utils.ErrorProviderAlreadyLinked
+			}
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorInternal
+		}
+
+		if err := runner.Commit(ctx); err != nil {
+			return nil, utils.ErrorInternal
+		}
+
+		return &dto.OIDCLoginRes{Status: dto.OIDCLoginLinked}, nil
+	}
+
+	// -------- RETURNING USER
+	if identity != nil {
+		user, err := s.IUserRepository.GetUserById(ctx, runner, identity.UserID)
+		if err != nil {
+			return nil, utils.ErrorUserNotFound
+		}
+
+		if err := s.IUserIdentityRepository.TouchUserIdentity(ctx, runner, identity.ID); err != nil {
+			return nil, utils.ErrorInternal
+		}
+
+		if err := runner.Commit(ctx); err != nil {
+			return nil, utils.ErrorInternal
+		}
+
+		return s.signIn(c, user, client)
+	}
+
+	// -------- FIRST LOGIN
+	// never attach to an existing account by e-mail alone, the owner links it
+	// themselves after signing in with their password
+	if claims.Email != "" {
+		canonEmail, err := utils.SanitizeEmail(claims.Email)
+		if err != nil {
+			return nil, utils.ErrorInvalidEmail
+		}
+
+		existing, err := s.IUserRepository.GetUserByEmail(ctx, runner, canonEmail)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorInternal
+		}
+		if existing != nil {
+			return nil, utils.ErrorOIDCEmailInUse
+		}
+	}
+
+	payload := auth.ExternalSignupPayload{
+		Provider:      provider.Name(),
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}
+	if claims.PreferredUsername != "" {
+		payload.Name = claims.PreferredUsername
+	}
+
+	signupToken, err := auth.GetExternalSignupToken(claims.Subject, payload)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.OIDCLoginRes{
+		Status:            dto.OIDCLoginSignupRequired,
+		SignupToken:       signupToken,
+		SuggestedUsername: suggestUsername(&payload),
+		Email:             claims.Email,
+	}, nil
+}
+
+func (s *oidcService) CompleteSignup(c context.Context, req *dto.OIDCSignupReq, client *dto.ClientInfo) (*dto.OIDCLoginRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	payload, err := auth.ParseExternalSignupToken(req.SignupToken)
+	if err != nil {
+		return nil, utils.ErrorInvalidSignupToken
+	}
+	if _, err := s.provider(payload.Provider); err != nil {
+		return nil, err
+	}
+
+	canonUsername, err := utils.SanitizeUsername(req.Username)
+	if err != nil {
+		return nil, utils.ErrorInvalidUserName
+	}
+
+	// accounts need an address, providers that withhold it can't create one
+	canonEmail, err := utils.SanitizeEmail(payload.Email)
+	if err != nil {
+		return nil, utils.ErrorInvalidEmail
+	}
+
+	displayName := payload.Name
+	if req.DisplayName != nil {
+		displayName = *req.DisplayName
+	}
+	canonDisplayName, err := utils.SanitizeDisplayName(displayName)
+	if err != nil {
+		canonDisplayName = canonUsername
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if existing, _ := s.IUserIdentityRepository.GetUserIdentity(ctx, runner, payload.Provider, payload.Subject); existing != nil {
+		return nil, utils.ErrorIdentityAlreadyLinked
+	}
+	if userByUsername, _ := s.IUserRepository.GetUserByUsername(ctx, runner, canonUsername); userByUsername != nil {
+		return nil, utils.ErrorUsernameExists
+	}
+	if userByEmail, _ := s.IUserRepository.GetUserByEmail(ctx, runner, canonEmail); userByEmail != nil {
+		return nil, utils.ErrorOIDCEmailInUse
+	}
+
+	userID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	// no password, the account signs in through the provider until one is set
+	user, err := s.IUserRepository.CreateUser(ctx, runner, &models.User{
+		ID:          userID,
+		Username:    canonUsername,
+		Email:       canonEmail,
+		DisplayName: canonDisplayName,
+	})
+	if err != nil {
+		return nil, utils.ErrorCreatingUser
+	}
+
+	identityID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	_, err = s.IUserIdentityRepository.CreateUserIdentity(ctx, runner, &models.UserIdentity{
+		ID:       identityID,
+		UserID:   user.ID,
+		Provider: payload.Provider,
+		Subject:  payload.Subject,
+		Email:    &canonEmail,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, utils.ErrorIdentityAlreadyLinked
+		}
+		return nil, utils.ErrorCreatingUser
+	}
+
+	if payload.EmailVerified {
+		if err := s.IUserRepository.MarkEmailVerified(ctx, runner, user.ID, canonEmail); err != nil {
+			return nil, utils.ErrorInternal
+		}
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	if !payload.EmailVerified {
+		if err := s.IAccountService.SendVerificationEmail(c, user); err != nil {
+			log.Printf("oidc signup: verification email for %s: %v", user.ID, err)
+		}
+	}
+
+	return s.signIn(c, user, client)
+}
+
+// ── LINKED LOGINS ─────────────────────────────────────────────────────────────
+
+func (s *oidcService) ListMyIdentities(c context.Context, userInfo *auth.UserInfo) (*dto.UserIdentitiesRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorUserNotFound
+	}
+
+	identities, err := s.IUserIdentityRepository.ListUserIdentities(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	res := &dto.UserIdentitiesRes{
+		HasPassword: user.HasPassword(),
+		Identities:  make([]dto.UserIdentityRes, 0, len(identities)),
+	}
+	for _, i := range identities {
+		res.Identities = append(res.Identities, dto.UserIdentityRes{
+			Provider:    i.Provider,
+			Email:       i.Email,
+			LastLoginAt: i.LastLoginAt,
+			CreatedAt:   i.CreatedAt,
+		})
+	}
+
+	return res, nil
+}
+
+func (s *oidcService) UnlinkIdentity(c context.Context, userInfo *auth.UserInfo, provider string) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, userInfo.ID)
+	if err != nil {
+		return utils.ErrorUserNotFound
+	}
+
+	count, err := s.IUserIdentityRepository.CountUserIdentities(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	// the account would be left with no way in
+	if !user.HasPassword() && count <= 1 {
+		return utils.ErrorCannotRemoveLastLogin
+	}
+
+	if err := s.IUserIdentityRepository.DeleteUserIdentity(ctx, runner, userInfo.ID, strings.ToLower(provider)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorIdentityNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
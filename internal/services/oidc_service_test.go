@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/suck-seed/yapp/config"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/oidc"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	testOIDCClientID    = "yapp"
+	testOIDCRedirectURL = "https://yapp.test/oidc/callback"
+)
+
+// fakeOIDCStateRepo keeps login states like oidc_login_states: a state is
+// deleted when it is read and only comes back for the provider it was made for
+type fakeOIDCStateRepo struct {
+	repositories.IUserIdentityRepository
+
+	mu     sync.Mutex
+	states map[string]*models.OIDCLoginState
+}
+
+func (r *fakeOIDCStateRepo) CreateOIDCLoginState(ctx context.Context, db database.DBRunner, state *models.OIDCLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *state
+	r.states[string(state.StateHash)] = &saved
+	return nil
+}
+
+func (r *fakeOIDCStateRepo) ConsumeOIDCLoginState(ctx context.Context, db database.DBRunner, provider string, stateHash []byte) (*models.OIDCLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[string(stateHash)]
+	if !ok || state.Provider != provider {
+		return nil, pgx.ErrNoRows
+	}
+	delete(r.states, string(stateHash))
+
+	if !state.ExpiresAt.After(time.Now()) {
+		return nil, pgx.ErrNoRows
+	}
+	return state, nil
+}
+
+type oidcTestEnv struct {
+	s        *oidcService
+	states   *fakeOIDCStateRepo
+	provider *oidc.Provider
+	other    *oidc.Provider
+}
+
+// newOIDCTestEnv runs the mock provider on a local server and registers it
+// next to a second provider that is never reached
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	issuer := server.URL + "/dev/oidc"
+	mock, err := oidc.NewMockProvider(issuer)
+	if err != nil {
+		t.Fatalf("mock provider: %v", err)
+	}
+	mock.Register(r)
+
+	registry := oidc.NewRegistry([]config.OIDCProviderConfig{
+		{
+			Name:        "mock",
+			Issuer:      issuer,
+			ClientID:    testOIDCClientID,
+			Scopes:      []string{"openid", "email", "profile"},
+			RedirectURL: testOIDCRedirectURL,
+		},
+		{
+			Name:        "other",
+			Issuer:      "https://other.invalid",
+			ClientID:    testOIDCClientID,
+			Scopes:      []string{"openid"},
+			RedirectURL: testOIDCRedirectURL,
+		},
+	})
+
+	states := &fakeOIDCStateRepo{states: make(map[string]*models.OIDCLoginState)}
+	s := NewOIDCService(states, nil, nil, nil, nil, nil, registry, nil).(*oidcService)
+
+	provider, _ := registry.Get("mock")
+	other, _ := registry.Get("other")
+
+	return &oidcTestEnv{s: s, states: states, provider: provider, other: other}
+}
+
+// signIn starts a login, lets the mock provider sign in login and returns
+// the code and raw state it sent back to the redirect URL
+func (e *oidcTestEnv) signIn(t *testing.T, login string) (code string, rawState string) {
+	t.Helper()
+	ctx := context.Background()
+
+	authURL, state, err := newOIDCLoginState(ctx, e.provider, nil)
+	if err != nil {
+		t.Fatalf("begin login: %v", err)
+	}
+	if err := e.states.CreateOIDCLoginState(ctx, nil, state); err != nil {
+		t.Fatalf("store state: %v", err)
+	}
+
+	authorize, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse authorization URL: %v", err)
+	}
+	q := authorize.Query()
+	if got, want := q.Get("code_challenge"), oidc.CodeChallengeS256(state.CodeVerifier); got != want {
+		t.Fatalf("code_challenge %q, want S256 of the stored verifier %q", got, want)
+	}
+	if q.Get("nonce") != state.Nonce {
+		t.Fatalf("nonce %q, want the stored %q", q.Get("nonce"), state.Nonce)
+	}
+	q.Set("login_hint", login)
+	authorize.RawQuery = q.Encode()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(authorize.String())
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize answered %d, want a redirect", res.StatusCode)
+	}
+
+	back, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if back.Scheme+"://"+back.Host+back.Path != testOIDCRedirectURL {
+		t.Fatalf("redirected to %s, want %s", back, testOIDCRedirectURL)
+	}
+
+	return back.Query().Get("code"), back.Query().Get("state")
+}
+
+func TestOIDCCallbackVerifiesIdentity(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	ctx := context.Background()
+	code, rawState := e.signIn(t, "alice")
+
+	state, err := e.s.consumeOIDCLoginState(ctx, nil, e.provider, rawState)
+	if err != nil {
+		t.Fatalf("consume state: %v", err)
+	}
+
+	claims, err := exchangeOIDCCode(ctx, e.provider, state, code)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if claims.Subject != "alice" || claims.Email != "alice@mock.local" || !claims.EmailVerified {
+		t.Errorf("claims %+v, want verified alice@mock.local with sub alice", claims)
+	}
+}
+
+func TestOIDCStateWorksOnce(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	ctx := context.Background()
+	_, rawState := e.signIn(t, "alice")
+
+	if _, err := e.s.consumeOIDCLoginState(ctx, nil, e.provider, rawState); err != nil {
+		t.Fatalf("first callback: %v", err)
+	}
+
+	_, err := e.s.consumeOIDCLoginState(ctx, nil, e.provider, rawState)
+	if !errors.Is(err, utils.ErrorInvalidOIDCState) {
+		t.Fatalf("replayed callback: got %v, want %v", err, utils.ErrorInvalidOIDCState)
+	}
+}
+
+func TestOIDCStateIsRejectedWhenForged(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	e.signIn(t, "alice")
+
+	_, err := e.s.consumeOIDCLoginState(context.Background(), nil, e.provider, "not-the-state")
+	if !errors.Is(err, utils.ErrorInvalidOIDCState) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorInvalidOIDCState)
+	}
+}
+
+func TestOIDCStateIsBoundToItsProvider(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	_, rawState := e.signIn(t, "alice")
+
+	_, err := e.s.consumeOIDCLoginState(context.Background(), nil, e.other, rawState)
+	if !errors.Is(err, utils.ErrorInvalidOIDCState) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorInvalidOIDCState)
+	}
+}
+
+func TestOIDCStateExpires(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	_, rawState := e.signIn(t, "alice")
+
+	for _, state := range e.states.states {
+		state.ExpiresAt = time.Now().Add(-time.Second)
+	}
+
+	_, err := e.s.consumeOIDCLoginState(context.Background(), nil, e.provider, rawState)
+	if !errors.Is(err, utils.ErrorInvalidOIDCState) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorInvalidOIDCState)
+	}
+}
+
+func TestOIDCCodeNeedsTheStoredVerifier(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	ctx := context.Background()
+	code, rawState := e.signIn(t, "alice")
+
+	state, err := e.s.consumeOIDCLoginState(ctx, nil, e.provider, rawState)
+	if err != nil {
+		t.Fatalf("consume state: %v", err)
+	}
+
+	// a stolen code is useless without the verifier that never left the server
+	state.CodeVerifier, err = oidc.NewCodeVerifier()
+	if err != nil {
+		t.Fatalf("code verifier: %v", err)
+	}
+
+	_, err = exchangeOIDCCode(ctx, e.provider, state, code)
+	if !errors.Is(err, utils.ErrorOIDCProviderFailed) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorOIDCProviderFailed)
+	}
+}
+
+func TestOIDCIDTokenNeedsTheStoredNonce(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	ctx := context.Background()
+	code, rawState := e.signIn(t, "alice")
+
+	state, err := e.s.consumeOIDCLoginState(ctx, nil, e.provider, rawState)
+	if err != nil {
+		t.Fatalf("consume state: %v", err)
+	}
+
+	state.Nonce, err = oidc.NewNonce()
+	if err != nil {
+		t.Fatalf("nonce: %v", err)
+	}
+
+	_, err = exchangeOIDCCode(ctx, e.provider, state, code)
+	if !errors.Is(err, utils.ErrorInvalidOIDCLogin) {
+		t.Fatalf("got %v, want %v", err, utils.ErrorInvalidOIDCLogin)
+	}
+}
+
+func TestOIDCCodeWorksOnce(t *testing.T) {
+	e := newOIDCTestEnv(t)
+	ctx := context.Background()
+	code, rawState := e.signIn(t, "alice")
+
+	state, err := e.s.consumeOIDCLoginState(ctx, nil, e.provider, rawState)
+	if err != nil {
+		t.Fatalf("consume state: %v", err)
+	}
+
+	if _, err := exchangeOIDCCode(ctx, e.provider, state, code); err != nil {
+		t.Fatalf("first exchange: %v", err)
+	}
+
+	_, err = exchangeOIDCCode(ctx, e.provider, state, code)
+	if !errors.Is(err, utils.ErrorOIDCProviderFailed) {
+		t.Fatalf("replayed code: got %v, want %v", err, utils.ErrorOIDCProviderFailed)
+	}
+}
@@ -0,0 +1,774 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/hall"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/outgoing"
+	"github.com/suck-seed/yapp/internal/realtime"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	MAX_OUTGOING_WEBHOOKS_PER_HALL = 10
+
+	outgoingQueueSize        = 1024
+	outgoingDeliverInterval  = 5 * time.Second
+	outgoingDeliverBatch     = 20
+	outgoingDeliverLease     = time.Minute
+	outgoingSendTimeout      = 15 * time.Second
+	outgoingPruneInterval    = time.Hour
+	outgoingDeliveryRetained = 30 * 24 * time.Hour
+	outgoingDeliveryLogLimit = 50
+
+	// a delivery is retried after 10s, 20s, 40s ... capped at an hour, then given up
+	outgoingBaseBackoff = 10 * time.Second
+	outgoingMaxBackoff  = time.Hour
+	maxOutgoingAttempts = 8
+
+	// consecutive failures across all deliveries before the endpoint is turned off
+	maxOutgoingFailures = 20
+)
+
+type IOutgoingWebhookService interface {
+	// -------------- MANAGE
+	CreateOutgoingWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.CreateOutgoingWebhookReq) (*dto.OutgoingWebhookSecretRes, error)
+	ListOutgoingWebhooks(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID) (*dto.OutgoingWebhooksRes, error)
+	UpdateOutgoingWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID, req *dto.UpdateOutgoingWebhookReq) (*dto.OutgoingWebhookRes, error)
+	RotateOutgoingWebhookSecret(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID) (*dto.OutgoingWebhookSecretRes, error)
+	DeleteOutgoingWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID) error
+	ListOutgoingDeliveries(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID) (*dto.OutgoingDeliveriesRes, error)
+
+	// -------------- DELIVERY WORKER
+	// PublishHubEvent makes the service a realtime.Publisher, so it sees the
+	// same events as the hub
+	PublishHubEvent(event realtime.HubEvent)
+	Run()
+}
+
+type outgoingWebhookService struct {
+	repositories.IOutgoingWebhookRepository
+
+	IPermissionCheckerService
+
+	sender        outgoing.Sender
+	allowInsecure bool
+	events        chan realtime.HubEvent
+	wake          chan struct{}
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewOutgoingWebhookService(
+	outgoingWebhookRepo repositories.IOutgoingWebhookRepository,
+	permissionChecker IPermissionCheckerService,
+	sender outgoing.Sender,
+	allowInsecureURLs bool,
+	pool *pgxpool.Pool,
+) IOutgoingWebhookService {
+	return &outgoingWebhookService{
+		outgoingWebhookRepo,
+		permissionChecker,
+		sender,
+		allowInsecureURLs,
+		make(chan realtime.HubEvent, outgoingQueueSize),
+		make(chan struct{}, 1),
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func (s *outgoingWebhookService) requireManageHall(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) error {
+	ok, err := s.IPermissionCheckerService.CanManageServers(ctx, runner, userID, hallID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return utils.ErrorUserCannotManageServer
+	}
+	return nil
+}
+
+// getHallOutgoingWebhook is ErrorOutgoingWebhookNotFound for other halls' webhooks too
+func (s *outgoingWebhookService) getHallOutgoingWebhook(ctx context.Context, runner database.DBRunner, hallID, webhookID uuid.UUID) (*models.OutgoingWebhook, error) {
+	webhook, err := s.IOutgoingWebhookRepository.GetOutgoingWebhookByID(ctx, runner, webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorOutgoingWebhookNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOutgoingWebhook
+	}
+	if webhook.HallID != hallID {
+		return nil, utils.ErrorOutgoingWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (s *outgoingWebhookService) validateURL(raw string) (string, error) {
+	url := strings.TrimSpace(raw)
+	if !strings.HasPrefix(url, "https://") && !(s.allowInsecure && strings.HasPrefix(url, "http://")) {
+		return "", utils.ErrorInvalidOutgoingWebhookURL
+	}
+	return url, nil
+}
+
+// normalizeEvents drops duplicates and rejects anything unknown
+func normalizeEvents(events []string) ([]string, error) {
+	seen := make(map[string]bool, len(events))
+	out := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !models.IsOutgoingEventType(event) {
+			return nil, utils.ErrorInvalidOutgoingWebhookEvent
+		}
+		if seen[event] {
+			continue
+		}
+		seen[event] = true
+		out = append(out, event)
+	}
+	return out, nil
+}
+
+// newOutgoingSecret returns the secret shown to the hall once and its sealed
+// form for the database
+func newOutgoingSecret() (string, []byte, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	secret := "whsec_" + hex.EncodeToString(buf)
+
+	sealed, err := auth.Seal([]byte(secret))
+	if err != nil {
+		return "", nil, err
+	}
+	return secret, sealed, nil
+}
+
+// outgoingBackoff is the wait after the given (1-based) failed attempt
+func outgoingBackoff(attempt int) time.Duration {
+	wait := outgoingBaseBackoff
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= outgoingMaxBackoff {
+			return outgoingMaxBackoff
+		}
+	}
+	return wait
+}
+
+// ── MANAGE ────────────────────────────────────────────────────────────────────
+
+func (s *outgoingWebhookService) CreateOutgoingWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, req *dto.CreateOutgoingWebhookReq) (*dto.OutgoingWebhookSecretRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	url, err := s.validateURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.requireManageHall(ctx, runner, userInfo.ID, hallID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.IOutgoingWebhookRepository.CountHallOutgoingWebhooks(ctx, runner, hallID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOutgoingWebhook
+	}
+	if count >= MAX_OUTGOING_WEBHOOKS_PER_HALL {
+		return nil, utils.ErrorTooManyOutgoingWebhooks
+	}
+
+	secret, sealed, err := newOutgoingSecret()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	webhookID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	webhook, err := s.IOutgoingWebhookRepository.CreateOutgoingWebhook(ctx, runner, &models.OutgoingWebhook{
+		ID:        webhookID,
+		HallID:    hallID,
+		URL:       url,
+		SecretEnc: sealed,
+		Events:    events,
+		CreatedBy: &userInfo.ID,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorCreatingOutgoingWebhook
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.OutgoingWebhookSecretRes{
+		Webhook: dto.ToOutgoingWebhookRes(webhook),
+		Secret:  secret,
+	}, nil
+}
+
+func (s *outgoingWebhookService) ListOutgoingWebhooks(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID) (*dto.OutgoingWebhooksRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.requireManageHall(ctx, runner, userInfo.ID, hallID); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.IOutgoingWebhookRepository.ListHallOutgoingWebhooks(ctx, runner, hallID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOutgoingWebhook
+	}
+
+	res := &dto.OutgoingWebhooksRes{Webhooks: make([]dto.OutgoingWebhookRes, 0, len(webhooks))}
+	for _, webhook := range webhooks {
+		res.Webhooks = append(res.Webhooks, dto.ToOutgoingWebhookRes(webhook))
+	}
+
+	return res, nil
+}
+
+func (s *outgoingWebhookService) UpdateOutgoingWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID, req *dto.UpdateOutgoingWebhookReq) (*dto.OutgoingWebhookRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	fields := map[string]any{}
+	if req.URL != nil {
+		url, err := s.validateURL(*req.URL)
+		if err != nil {
+			return nil, err
+		}
+		fields["url"] = url
+	}
+	if req.Events != nil {
+		events, err := normalizeEvents(*req.Events)
+		if err != nil {
+			return nil, err
+		}
+		fields["events"] = events
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.requireManageHall(ctx, runner, userInfo.ID, hallID); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.getHallOutgoingWebhook(ctx, runner, hallID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil && *req.Enabled && !webhook.Enabled {
+		fields["enabled"] = true
+		fields["failure_count"] = 0
+		fields["disabled_at"] = nil
+	}
+
+	if len(fields) > 0 {
+		webhook, err = s.IOutgoingWebhookRepository.UpdateOutgoingWebhook(ctx, runner, webhookID, fields)
+		if err != nil {
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorUpdatingOutgoingWebhook
+		}
+	}
+
+	if req.Enabled != nil && !*req.Enabled && webhook.Enabled {
+		webhook, err = s.IOutgoingWebhookRepository.DisableOutgoingWebhook(ctx, runner, webhookID)
+		if err != nil {
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorUpdatingOutgoingWebhook
+		}
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	res := dto.ToOutgoingWebhookRes(webhook)
+	return &res, nil
+}
+
+func (s *outgoingWebhookService) RotateOutgoingWebhookSecret(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID) (*dto.OutgoingWebhookSecretRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.requireManageHall(ctx, runner, userInfo.ID, hallID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getHallOutgoingWebhook(ctx, runner, hallID, webhookID); err != nil {
+		return nil, err
+	}
+
+	secret, sealed, err := newOutgoingSecret()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	// pending retries get signed with the new secret
+	webhook, err := s.IOutgoingWebhookRepository.UpdateOutgoingWebhook(ctx, runner, webhookID, map[string]any{
+		"secret_enc": sealed,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorUpdatingOutgoingWebhook
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.OutgoingWebhookSecretRes{
+		Webhook: dto.ToOutgoingWebhookRes(webhook),
+		Secret:  secret,
+	}, nil
+}
+
+func (s *outgoingWebhookService) DeleteOutgoingWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.requireManageHall(ctx, runner, userInfo.ID, hallID); err != nil {
+		return err
+	}
+
+	if _, err := s.getHallOutgoingWebhook(ctx, runner, hallID, webhookID); err != nil {
+		return err
+	}
+
+	if err := s.IOutgoingWebhookRepository.DeleteOutgoingWebhook(ctx, runner, webhookID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorOutgoingWebhookNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	return runner.Commit(ctx)
+}
+
+func (s *outgoingWebhookService) ListOutgoingDeliveries(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, webhookID uuid.UUID) (*dto.OutgoingDeliveriesRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.requireManageHall(ctx, runner, userInfo.ID, hallID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getHallOutgoingWebhook(ctx, runner, hallID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.IOutgoingWebhookRepository.ListOutgoingDeliveries(ctx, runner, webhookID, outgoingDeliveryLogLimit)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingOutgoingDeliveries
+	}
+
+	res := &dto.OutgoingDeliveriesRes{Deliveries: make([]dto.OutgoingDeliveryRes, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		res.Deliveries = append(res.Deliveries, dto.ToOutgoingDeliveryRes(delivery))
+	}
+
+	return res, nil
+}
+
+// ── DELIVERY WORKER ───────────────────────────────────────────────────────────
+
+// PublishHubEvent never blocks the request that produced the event.
+func (s *outgoingWebhookService) PublishHubEvent(event realtime.HubEvent) {
+	if _, _, ok := toOutgoingEvent(event); !ok {
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		log.Printf("outgoing webhook queue full, dropping event: %s", event.Type)
+	}
+}
+
+// toOutgoingEvent maps hub events to what receivers subscribe to. Kicks count
+// as leaving.
+func toOutgoingEvent(event realtime.HubEvent) (models.OutgoingEventType, any, bool) {
+	if event.HallID == uuid.Nil {
+		return "", nil, false
+	}
+
+	member := func() *dto.OutgoingMemberData {
+		data := &dto.OutgoingMemberData{UserID: event.UserID}
+		if event.MemberID != uuid.Nil {
+			data.MemberID = &event.MemberID
+		}
+		return data
+	}
+
+	// managing the hall doesn't open private rooms, so their messages never
+	// go to an endpoint a manager registered
+	message := event.Payload != nil && !event.IsPrivate
+
+	switch event.Type {
+	case realtime.HubEventMessageCreated:
+		return models.OutgoingEventMessageCreated, event.Payload, message
+	case realtime.HubEventMessageUpdated:
+		return models.OutgoingEventMessageUpdated, event.Payload, message
+	case realtime.HubEventMessageDeleted:
+		return models.OutgoingEventMessageDeleted, event.Payload, message
+
+	case realtime.HubEventUserJoinedHall:
+		return models.OutgoingEventMemberJoined, member(), true
+	case realtime.HubEventUserLeftHall:
+		return models.OutgoingEventMemberLeft, member(), true
+	case realtime.HubEventUserKickedFromHall:
+		data := member()
+		data.Kicked = true
+		return models.OutgoingEventMemberLeft, data, true
+	case realtime.HubEventUserBannedFromHall:
+		return models.OutgoingEventMemberBanned, member(), true
+
+	// role changes reach the hub as access resyncs, the ones naming a role
+	// are a member's new role or a role's new permissions
+	case realtime.HubEventUserAccessResync:
+		return models.OutgoingEventRoleChanged, &dto.OutgoingRoleData{RoleID: event.RoleID, UserID: &event.UserID}, event.RoleID != uuid.Nil
+	case realtime.HubEventHallAccessResync:
+		return models.OutgoingEventRoleChanged, &dto.OutgoingRoleData{RoleID: event.RoleID}, event.RoleID != uuid.Nil
+
+	case realtime.HubEventRoomCreated:
+		data := &dto.OutgoingRoomData{RoomID: event.RoomID, IsPrivate: event.IsPrivate}
+		if event.UserID != uuid.Nil {
+			data.CreatedBy = &event.UserID
+		}
+		return models.OutgoingEventRoomCreated, data, true
+	}
+
+	return "", nil, false
+}
+
+func (s *outgoingWebhookService) Run() {
+	go s.runDeliveries()
+
+	pruneTicker := time.NewTicker(outgoingPruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case event, ok := <-s.events:
+			if !ok {
+				return
+			}
+			s.record(event)
+
+		case <-pruneTicker.C:
+			s.pruneDeliveries()
+		}
+	}
+}
+
+// record writes one pending delivery per subscribed webhook, the row is what
+// survives a restart
+func (s *outgoingWebhookService) record(event realtime.HubEvent) {
+	eventType, data, ok := toOutgoingEvent(event)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		log.Printf("outgoing: acquire conn: %v", err)
+		return
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	webhooks, err := s.IOutgoingWebhookRepository.ListSubscribedOutgoingWebhooks(ctx, runner, event.HallID, string(eventType))
+	if err != nil {
+		log.Printf("outgoing: list webhooks for hall %s: %v", event.HallID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	eventID, err := uuid.NewV7()
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(&dto.OutgoingEvent{
+		ID:         eventID,
+		Type:       string(eventType),
+		HallID:     event.HallID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		log.Printf("outgoing: build payload for %s: %v", eventType, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		deliveryID, err := uuid.NewV7()
+		if err != nil {
+			continue
+		}
+		err = s.IOutgoingWebhookRepository.CreateOutgoingDelivery(ctx, runner, &models.OutgoingWebhookDelivery{
+			ID:        deliveryID,
+			WebhookID: webhook.ID,
+			EventType: string(eventType),
+			Payload:   payload,
+		})
+		if err != nil {
+			log.Printf("outgoing: record delivery for %s: %v", webhook.ID, err)
+		}
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *outgoingWebhookService) runDeliveries() {
+	ticker := time.NewTicker(outgoingDeliverInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.wake:
+		case <-ticker.C:
+		}
+		s.deliverDue()
+	}
+}
+
+// deliverDue sends claimed batches concurrently until nothing is due
+func (s *outgoingWebhookService) deliverDue() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		conn, err := s.pool.Acquire(ctx)
+		if err != nil {
+			cancel()
+			log.Printf("outgoing: acquire conn: %v", err)
+			return
+		}
+		deliveries, err := s.IOutgoingWebhookRepository.ClaimDueOutgoingDeliveries(ctx, database.NewConnWrapper(conn), outgoingDeliverBatch, outgoingDeliverLease)
+		conn.Release()
+		cancel()
+		if err != nil {
+			log.Printf("outgoing: claim deliveries: %v", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery *models.OutgoingWebhookDelivery) {
+				defer wg.Done()
+				s.attempt(delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < outgoingDeliverBatch {
+			return
+		}
+	}
+}
+
+func (s *outgoingWebhookService) attempt(delivery *models.OutgoingWebhookDelivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		log.Printf("outgoing: acquire conn: %v", err)
+		return
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	webhook, err := s.IOutgoingWebhookRepository.GetOutgoingWebhookByID(ctx, runner, delivery.WebhookID)
+	if err != nil {
+		log.Printf("outgoing: load webhook %s: %v", delivery.WebhookID, err)
+		return
+	}
+
+	secret, err := auth.Open(webhook.SecretEnc)
+	if err != nil {
+		log.Printf("outgoing: open secret of %s: %v", webhook.ID, err)
+		_ = s.IOutgoingWebhookRepository.MarkOutgoingDeliveryFailed(ctx, runner, delivery.ID, nil, "secret unreadable")
+		return
+	}
+
+	sendCtx, sendCancel := context.WithTimeout(context.Background(), outgoingSendTimeout)
+	status, sendErr := s.sender.Send(sendCtx, &outgoing.Request{
+		URL:        webhook.URL,
+		Secret:     secret,
+		EventType:  delivery.EventType,
+		DeliveryID: delivery.ID.String(),
+		Body:       delivery.Payload,
+	})
+	sendCancel()
+
+	// the send may have used up the first context
+	ctx, cancel = context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	if sendErr == nil && status >= 200 && status < 300 {
+		if err := s.IOutgoingWebhookRepository.MarkOutgoingDeliverySucceeded(ctx, runner, delivery.ID, status); err != nil {
+			log.Printf("outgoing: mark delivered %s: %v", delivery.ID, err)
+		}
+		if err := s.IOutgoingWebhookRepository.RecordOutgoingWebhookSuccess(ctx, runner, webhook.ID); err != nil {
+			log.Printf("outgoing: record success %s: %v", webhook.ID, err)
+		}
+		return
+	}
+
+	var statusCode *int
+	lastError := ""
+	if sendErr != nil {
+		lastError = sendErr.Error()
+	} else {
+		statusCode = &status
+		lastError = fmt.Sprintf("receiver answered %d", status)
+	}
+
+	if delivery.Attempts >= maxOutgoingAttempts {
+		err = s.IOutgoingWebhookRepository.MarkOutgoingDeliveryFailed(ctx, runner, delivery.ID, statusCode, lastError)
+	} else {
+		err = s.IOutgoingWebhookRepository.MarkOutgoingDeliveryRetry(ctx, runner, delivery.ID, statusCode, lastError, time.Now().Add(outgoingBackoff(delivery.Attempts)))
+	}
+	if err != nil {
+		log.Printf("outgoing: record attempt %s: %v", delivery.ID, err)
+	}
+
+	failures, err := s.IOutgoingWebhookRepository.RecordOutgoingWebhookFailure(ctx, runner, webhook.ID)
+	if err != nil {
+		log.Printf("outgoing: record failure %s: %v", webhook.ID, err)
+		return
+	}
+	if failures >= maxOutgoingFailures && webhook.Enabled {
+		if _, err := s.IOutgoingWebhookRepository.DisableOutgoingWebhook(ctx, runner, webhook.ID); err != nil {
+			log.Printf("outgoing: disable %s: %v", webhook.ID, err)
+			return
+		}
+		log.Printf("outgoing: disabled webhook %s after %d consecutive failures", webhook.ID, failures)
+	}
+}
+
+func (s *outgoingWebhookService) pruneDeliveries() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		log.Printf("outgoing: acquire conn for prune: %v", err)
+		return
+	}
+	defer conn.Release()
+
+	pruned, err := s.IOutgoingWebhookRepository.PruneOutgoingDeliveries(ctx, database.NewConnWrapper(conn), time.Now().Add(-outgoingDeliveryRetained))
+	if err != nil {
+		log.Printf("outgoing: prune deliveries: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("outgoing: pruned %d old deliveries", pruned)
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/realtime"
+)
+
+func newTestOutgoingWebhookService() *outgoingWebhookService {
+	return NewOutgoingWebhookService(nil, nil, nil, false, nil).(*outgoingWebhookService)
+}
+
+func TestPublishHubEventSkipsPrivateRoomMessages(t *testing.T) {
+	s := newTestOutgoingWebhookService()
+	hallID, roomID := uuid.New(), uuid.New()
+
+	for _, eventType := range []realtime.HubEventType{
+		realtime.HubEventMessageCreated,
+		realtime.HubEventMessageUpdated,
+		realtime.HubEventMessageDeleted,
+	} {
+		s.PublishHubEvent(realtime.HubEvent{
+			Type:      eventType,
+			HallID:    hallID,
+			RoomID:    roomID,
+			UserID:    uuid.New(),
+			IsPrivate: true,
+			Payload:   &dto.OutboundMessage{ID: uuid.New(), RoomID: roomID, HallID: hallID},
+		})
+	}
+
+	if len(s.events) != 0 {
+		t.Fatalf("queued %d private room message events, want none", len(s.events))
+	}
+}
+
+func TestPublishHubEventQueuesPublicRoomMessages(t *testing.T) {
+	s := newTestOutgoingWebhookService()
+	hallID, roomID := uuid.New(), uuid.New()
+
+	s.PublishHubEvent(realtime.HubEvent{
+		Type:    realtime.HubEventMessageCreated,
+		HallID:  hallID,
+		RoomID:  roomID,
+		UserID:  uuid.New(),
+		Payload: &dto.OutboundMessage{ID: uuid.New(), RoomID: roomID, HallID: hallID},
+	})
+
+	if len(s.events) != 1 {
+		t.Fatalf("queued %d public room message events, want 1", len(s.events))
+	}
+}
@@ -25,6 +25,9 @@ type IPermissionCheckerService interface {
 	CanManageInvites(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error)
 	CanManageRequests(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error)
 	CanManageServers(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error)
+	CanManageChannels(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error)
+	CanManageMessages(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error)
+	CanSendMessages(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error)
 
 	checkPermission(ctx context.Context, runner database.DBRunner, userID uuid.UUID, hallID uuid.UUID, permColumn string) (bool, error)
 }
@@ -34,18 +37,20 @@ type permissionCheckerService struct {
 	repositories.IUserRepository
 	repositories.IHallRepository
 	repositories.IBanRepsitory
+	repositories.ITwoFactorRepository
 
 	pool    *pgxpool.Pool
 	timeout time.Duration
 	mu      sync.RWMutex
 }
 
-func NewPermissionCheckerService(roleRepo repositories.IRoleRepository, userRepo repositories.IUserRepository, hallRepo repositories.IHallRepository, banRepo repositories.IBanRepsitory, pool *pgxpool.Pool) IPermissionCheckerService {
+func NewPermissionCheckerService(roleRepo repositories.IRoleRepository, userRepo repositories.IUserRepository, hallRepo repositories.IHallRepository, banRepo repositories.IBanRepsitory, twoFactorRepo repositories.ITwoFactorRepository, pool *pgxpool.Pool) IPermissionCheckerService {
 	return &permissionCheckerService{
 		roleRepo,
 		userRepo,
 		hallRepo,
 		banRepo,
+		twoFactorRepo,
 		pool,
 		time.Duration(2) * time.Second,
 		sync.RWMutex{},
@@ -87,15 +92,27 @@ func (s *permissionCheckerService) checkPermission(ctx context.Context, runner d
 		return false, utils.ErrorFetchingRole
 	}
 
-	if userRole.IsAdmin {
-		return true, nil
-	}
-
 	// Validating column
 	if _, ok := constants.ValidPermissionColumns[permColumn]; !ok {
 		return false, utils.ErrorPermissionsNotFound
 	}
 
+	// halls can demand 2FA before moderation permissions take effect,
+	// admins included, only the owner is exempt
+	if _, ok := constants.Mod2FAPermissions[permColumn]; ok {
+		satisfied, err := s.meetsMod2FARequirement(ctx, runner, userID, hallID)
+		if err != nil {
+			return false, err
+		}
+		if !satisfied {
+			return false, nil
+		}
+	}
+
+	if userRole.IsAdmin {
+		return true, nil
+	}
+
 	// for any other role, check
 	allowded, err := s.IRoleRepository.CheckUserPermission(ctx, runner, hallID, userID, permColumn)
 	if err != nil {
@@ -111,6 +128,28 @@ func (s *permissionCheckerService) checkPermission(ctx context.Context, runner d
 	return allowded, nil
 }
 
+func (s *permissionCheckerService) meetsMod2FARequirement(ctx context.Context, runner database.DBRunner, userID uuid.UUID, hallID uuid.UUID) (bool, error) {
+	required, err := s.IHallRepository.GetHallRequiresMod2FA(ctx, runner, hallID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return false, utils.ErrorRequestTimeout
+		}
+		return false, utils.ErrorFetchingHall
+	}
+	if !required {
+		return true, nil
+	}
+
+	enabled, err := s.ITwoFactorRepository.IsTwoFactorEnabled(ctx, runner, userID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return false, utils.ErrorRequestTimeout
+		}
+		return false, utils.ErrorInternal
+	}
+	return enabled, nil
+}
+
 // CanManageRoles - Return bool representing if the current user has appropriate permission to Manage other Roles from the corresponding hall
 func (s *permissionCheckerService) CanManageRoles(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error) {
 
@@ -149,3 +188,15 @@ func (s *permissionCheckerService) CanManageRequests(ctx context.Context, runner
 func (s *permissionCheckerService) CanManageServers(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error) {
 	return s.checkPermission(ctx, runner, userID, hallID, constants.PermManageServers)
 }
+
+func (s *permissionCheckerService) CanManageChannels(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error) {
+	return s.checkPermission(ctx, runner, userID, hallID, constants.PermManageChannels)
+}
+
+func (s *permissionCheckerService) CanManageMessages(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error) {
+	return s.checkPermission(ctx, runner, userID, hallID, constants.PermTextManageMessages)
+}
+
+func (s *permissionCheckerService) CanSendMessages(ctx context.Context, runner database.DBRunner, userID, hallID uuid.UUID) (bool, error) {
+	return s.checkPermission(ctx, runner, userID, hallID, constants.PermTextSendMessages)
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// MAX_PERSONAL_TOKENS_PER_USER is plenty for scripts, a leak is one revoke away
+const MAX_PERSONAL_TOKENS_PER_USER = 25
+
+type IPersonalTokenService interface {
+	CreatePersonalToken(c context.Context, userInfo *auth.UserInfo, req *dto.CreatePersonalTokenReq) (*dto.PersonalTokenCreatedRes, error)
+	ListPersonalTokens(c context.Context, userInfo *auth.UserInfo) (*dto.PersonalTokensRes, error)
+	RevokePersonalToken(c context.Context, userInfo *auth.UserInfo, tokenID uuid.UUID) error
+
+	// VerifyPersonalToken is the auth.PersonalTokenVerifier
+	VerifyPersonalToken(c context.Context, token string) (*auth.UserInfo, error)
+}
+
+type personalTokenService struct {
+	repositories.IPersonalTokenRepository
+	repositories.IUserRepository
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewPersonalTokenService(
+	personalTokenRepo repositories.IPersonalTokenRepository,
+	userRepo repositories.IUserRepository,
+	pool *pgxpool.Pool,
+) IPersonalTokenService {
+	return &personalTokenService{
+		personalTokenRepo,
+		userRepo,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+func (s *personalTokenService) CreatePersonalToken(c context.Context, userInfo *auth.UserInfo, req *dto.CreatePersonalTokenReq) (*dto.PersonalTokenCreatedRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, utils.ErrorInvalidInput
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !models.IsValidTokenScope(scope) {
+			return nil, utils.ErrorInvalidTokenScope
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		at := time.Now().UTC().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &at
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	count, err := s.IPersonalTokenRepository.CountUserPersonalTokens(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingPersonalTokens
+	}
+	if count >= MAX_PERSONAL_TOKENS_PER_USER {
+		return nil, utils.ErrorTooManyPersonalTokens
+	}
+
+	raw, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	secret := models.PersonalAccessTokenPrefix + raw
+
+	tokenID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	token, err := s.IPersonalTokenRepository.CreatePersonalToken(ctx, runner, &models.PersonalAccessToken{
+		ID:        tokenID,
+		UserID:    userInfo.ID,
+		Name:      name,
+		TokenHash: auth.HashOpaqueToken(secret),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorCreatingPersonalToken
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.PersonalTokenCreatedRes{
+		PersonalTokenRes: dto.ToPersonalTokenRes(*token),
+		Token:            secret,
+	}, nil
+}
+
+func (s *personalTokenService) ListPersonalTokens(c context.Context, userInfo *auth.UserInfo) (*dto.PersonalTokensRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	tokens, err := s.IPersonalTokenRepository.ListUserPersonalTokens(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingPersonalTokens
+	}
+
+	res := &dto.PersonalTokensRes{Tokens: make([]dto.PersonalTokenRes, 0, len(tokens))}
+	for _, token := range tokens {
+		res.Tokens = append(res.Tokens, dto.ToPersonalTokenRes(*token))
+	}
+	return res, nil
+}
+
+func (s *personalTokenService) RevokePersonalToken(c context.Context, userInfo *auth.UserInfo, tokenID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.IPersonalTokenRepository.DeleteUserPersonalToken(ctx, runner, userInfo.ID, tokenID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorPersonalTokenNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+func (s *personalTokenService) VerifyPersonalToken(c context.Context, token string) (*auth.UserInfo, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	pat, err := s.IPersonalTokenRepository.GetActivePersonalToken(ctx, runner, auth.HashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidPersonalToken
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, pat.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidPersonalToken
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	// bookkeeping only, a failed touch doesn't fail the request
+	_ = s.IPersonalTokenRepository.TouchPersonalToken(ctx, runner, pat.ID)
+
+	scopes := pat.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &auth.UserInfo{
+		ID:       user.ID,
+		Username: user.Username,
+		Scopes:   scopes,
+	}, nil
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	notificationDto "github.com/suck-seed/yapp/internal/dto/notification"
+	dto "github.com/suck-seed/yapp/internal/dto/push"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/push"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	pushQueueSize      = 1024
+	pushSendTimeout    = 10 * time.Second
+	pushPruneInterval  = time.Hour
+	maxPushFailures    = 5
+	maxPushDeviceCount = 10
+)
+
+// Only these inbox items are worth waking a phone up for.
+var pushableNotificationTypes = map[models.NotificationType]bool{
+	models.NotificationMention:         true,
+	models.NotificationMentionEveryone: true,
+	models.NotificationReminder:        true,
+}
+
+type IPushService interface {
+	// -------------- SUBSCRIPTIONS
+	GetVAPIDPublicKey(c context.Context) (*dto.VAPIDPublicKeyRes, error)
+	RegisterSubscription(c context.Context, userInfo *auth.UserInfo, req *dto.RegisterPushSubscriptionReq, userAgent string) (*dto.PushSubscriptionRes, error)
+	ListSubscriptions(c context.Context, userInfo *auth.UserInfo) ([]*dto.PushSubscriptionRes, error)
+	DeleteSubscription(c context.Context, userInfo *auth.UserInfo, subscriptionID uuid.UUID) error
+
+	// -------------- DELIVERY WORKER
+	EnqueueNotification(notification *notificationDto.NotificationRes)
+	Run()
+}
+
+type pushService struct {
+	repositories.IPushSubscriptionRepository
+	IPresenceService
+
+	sender         push.Sender
+	vapidPublicKey string
+	allowInsecure  bool
+	jobs           chan *notificationDto.NotificationRes
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewPushService(
+	pushRepo repositories.IPushSubscriptionRepository,
+	presenceService IPresenceService,
+	sender push.Sender,
+	vapidPublicKey string,
+	allowInsecureEndpoints bool,
+	pool *pgxpool.Pool,
+) IPushService {
+	return &pushService{
+		pushRepo,
+		presenceService,
+		sender,
+		vapidPublicKey,
+		allowInsecureEndpoints,
+		make(chan *notificationDto.NotificationRes, pushQueueSize),
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func pushSubscriptionToRes(sub *models.PushSubscription) *dto.PushSubscriptionRes {
+	return &dto.PushSubscriptionRes{
+		ID:            sub.ID,
+		Endpoint:      sub.Endpoint,
+		DeviceName:    sub.DeviceName,
+		UserAgent:     sub.UserAgent,
+		ExpiresAt:     sub.ExpiresAt,
+		LastSuccessAt: sub.LastSuccessAt,
+		CreatedAt:     sub.CreatedAt,
+	}
+}
+
+func buildPushPayload(n *notificationDto.NotificationRes) ([]byte, error) {
+	actor := "Someone"
+	if n.Actor != nil {
+		actor = n.Actor.DisplayName
+		if actor == "" {
+			actor = n.Actor.Username
+		}
+	}
+
+	title := actor + " sent you a notification"
+	switch n.Type {
+	case models.NotificationMention:
+		title = actor + " mentioned you"
+	case models.NotificationMentionEveryone:
+		title = actor + " mentioned @everyone"
+	case models.NotificationReminder:
+		title = "Reminder"
+	}
+
+	body := ""
+	if n.Preview != nil {
+		body = *n.Preview
+	}
+
+	return json.Marshal(&dto.PushPayload{
+		Type:           string(n.Type),
+		Title:          title,
+		Body:           body,
+		NotificationID: n.ID,
+		HallID:         n.HallID,
+		RoomID:         n.RoomID,
+		MessageID:      n.MessageID,
+	})
+}
+
+// ── SUBSCRIPTIONS ─────────────────────────────────────────────────────────────
+
+func (s *pushService) GetVAPIDPublicKey(c context.Context) (*dto.VAPIDPublicKeyRes, error) {
+	if s.vapidPublicKey == "" {
+		return nil, utils.ErrorPushNotConfigured
+	}
+
+	return &dto.VAPIDPublicKeyRes{PublicKey: s.vapidPublicKey}, nil
+}
+
+func (s *pushService) RegisterSubscription(c context.Context, userInfo *auth.UserInfo, req *dto.RegisterPushSubscriptionReq, userAgent string) (*dto.PushSubscriptionRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	// the server POSTs to whatever endpoint is stored, so only accept real
+	// push services (https) unless the local fake endpoint is allowed
+	endpoint := strings.TrimSpace(req.Endpoint)
+	if !strings.HasPrefix(endpoint, "https://") && !s.allowInsecure {
+		return nil, utils.ErrorInvalidPushEndpoint
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	existing, err := s.IPushSubscriptionRepository.ListUserPushSubscriptions(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingPushSubscriptions
+	}
+
+	alreadyKnown := false
+	for _, sub := range existing {
+		if sub.Endpoint == endpoint {
+			alreadyKnown = true
+			break
+		}
+	}
+	if !alreadyKnown && len(existing) >= maxPushDeviceCount {
+		return nil, utils.ErrorTooManyPushSubscriptions
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	sub := &models.PushSubscription{
+		ID:         id,
+		UserID:     userInfo.ID,
+		Endpoint:   endpoint,
+		P256dh:     req.Keys.P256dh,
+		Auth:       req.Keys.Auth,
+		DeviceName: req.DeviceName,
+	}
+	if userAgent != "" {
+		sub.UserAgent = &userAgent
+	}
+	if req.ExpirationTime != nil {
+		expiresAt := time.UnixMilli(*req.ExpirationTime).UTC()
+		if expiresAt.Before(time.Now()) {
+			return nil, utils.ErrorInvalidInput
+		}
+		sub.ExpiresAt = &expiresAt
+	}
+
+	saved, err := s.IPushSubscriptionRepository.UpsertPushSubscription(ctx, runner, sub)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorSavingPushSubscription
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return pushSubscriptionToRes(saved), nil
+}
+
+func (s *pushService) ListSubscriptions(c context.Context, userInfo *auth.UserInfo) ([]*dto.PushSubscriptionRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	subs, err := s.IPushSubscriptionRepository.ListUserPushSubscriptions(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingPushSubscriptions
+	}
+
+	out := make([]*dto.PushSubscriptionRes, 0, len(subs))
+	for _, sub := range subs {
+		out = append(out, pushSubscriptionToRes(sub))
+	}
+
+	return out, nil
+}
+
+func (s *pushService) DeleteSubscription(c context.Context, userInfo *auth.UserInfo, subscriptionID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.IPushSubscriptionRepository.DeletePushSubscription(ctx, runner, userInfo.ID, subscriptionID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorPushSubscriptionNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorDeletingPushSubscription
+	}
+
+	return nil
+}
+
+// ── DELIVERY WORKER ───────────────────────────────────────────────────────────
+
+// EnqueueNotification never blocks the request that produced the notification.
+func (s *pushService) EnqueueNotification(notification *notificationDto.NotificationRes) {
+	if notification == nil || !pushableNotificationTypes[notification.Type] {
+		return
+	}
+
+	select {
+	case s.jobs <- notification:
+	default:
+		log.Printf("push queue full, dropping notification %s", notification.ID)
+	}
+}
+
+func (s *pushService) Run() {
+	pruneTicker := time.NewTicker(pushPruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case notification, ok := <-s.jobs:
+			if !ok {
+				return
+			}
+			s.deliver(notification)
+
+		case <-pruneTicker.C:
+			s.pruneStale()
+		}
+	}
+}
+
+func (s *pushService) deliver(notification *notificationDto.NotificationRes) {
+	// someone with a live websocket already got it through the hub
+	if s.IPresenceService != nil {
+		presence, err := s.IPresenceService.GetUserPresence(context.Background(), notification.UserID)
+		if err == nil && presence.Status != models.PresenceStatusOffline {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		log.Printf("push: acquire conn: %v", err)
+		return
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	subs, err := s.IPushSubscriptionRepository.ListUserPushSubscriptions(ctx, runner, notification.UserID)
+	if err != nil {
+		log.Printf("push: list subscriptions for %s: %v", notification.UserID, err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	payload, err := buildPushPayload(notification)
+	if err != nil {
+		log.Printf("push: build payload for %s: %v", notification.ID, err)
+		return
+	}
+
+	for _, sub := range subs {
+		s.sendToSubscription(runner, sub, payload)
+	}
+}
+
+func (s *pushService) sendToSubscription(runner database.DBRunner, sub *models.PushSubscription, payload []byte) {
+	sendCtx, cancel := context.WithTimeout(context.Background(), pushSendTimeout)
+	status, err := s.sender.Send(sendCtx, sub, payload)
+	cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		if err := s.IPushSubscriptionRepository.MarkPushDelivered(ctx, runner, sub.ID); err != nil {
+			log.Printf("push: mark delivered %s: %v", sub.ID, err)
+		}
+
+	case err == nil && (status == http.StatusNotFound || status == http.StatusGone):
+		// the browser unsubscribed or the subscription expired on the push service side
+		if err := s.IPushSubscriptionRepository.DeletePushSubscriptionByID(ctx, runner, sub.ID); err != nil {
+			log.Printf("push: prune expired %s: %v", sub.ID, err)
+		}
+
+	default:
+		if err != nil {
+			log.Printf("push: send to %s: %v", sub.ID, err)
+		} else {
+			log.Printf("push: send to %s: push service answered %d", sub.ID, status)
+		}
+
+		failures, err := s.IPushSubscriptionRepository.IncrementPushFailure(ctx, runner, sub.ID)
+		if err != nil {
+			log.Printf("push: record failure %s: %v", sub.ID, err)
+			return
+		}
+		if failures >= maxPushFailures {
+			_ = s.IPushSubscriptionRepository.DeletePushSubscriptionByID(ctx, runner, sub.ID)
+		}
+	}
+}
+
+func (s *pushService) pruneStale() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		log.Printf("push: acquire conn for prune: %v", err)
+		return
+	}
+	defer conn.Release()
+
+	pruned, err := s.IPushSubscriptionRepository.PruneStalePushSubscriptions(ctx, database.NewConnWrapper(conn), maxPushFailures)
+	if err != nil {
+		log.Printf("push: prune stale subscriptions: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("push: pruned %d stale subscriptions", pruned)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/config"
+	"github.com/suck-seed/yapp/internal/database"
+	notificationDto "github.com/suck-seed/yapp/internal/dto/notification"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/push"
+	"github.com/suck-seed/yapp/internal/repositories"
+)
+
+// fakePushRepo records the delivery bookkeeping, the worker never needs more
+type fakePushRepo struct {
+	repositories.IPushSubscriptionRepository
+
+	mu        sync.Mutex
+	delivered []uuid.UUID
+	deleted   []uuid.UUID
+	failures  map[uuid.UUID]int
+}
+
+func newFakePushRepo() *fakePushRepo {
+	return &fakePushRepo{failures: make(map[uuid.UUID]int)}
+}
+
+func (r *fakePushRepo) MarkPushDelivered(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delivered = append(r.delivered, subscriptionID)
+	return nil
+}
+
+func (r *fakePushRepo) IncrementPushFailure(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures[subscriptionID]++
+	return r.failures[subscriptionID], nil
+}
+
+func (r *fakePushRepo) DeletePushSubscriptionByID(ctx context.Context, db database.DBRunner, subscriptionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, subscriptionID)
+	return nil
+}
+
+func newTestPushService(t *testing.T, repo repositories.IPushSubscriptionRepository) *pushService {
+	t.Helper()
+
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("generate VAPID keys: %v", err)
+	}
+
+	sender := push.NewWebPushSender(config.VAPIDConfig{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		Subject:    "mailto:test@yapp.local",
+	})
+
+	return NewPushService(repo, nil, sender, publicKey, true, nil).(*pushService)
+}
+
+// newBrowserSubscription makes the keys a browser would hand out, the sender
+// has to encrypt against a real P-256 point
+func newBrowserSubscription(t *testing.T, endpoint string) *models.PushSubscription {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate subscription key: %v", err)
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatalf("generate auth secret: %v", err)
+	}
+
+	return &models.PushSubscription{
+		ID:       uuid.New(),
+		UserID:   uuid.New(),
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(secret),
+	}
+}
+
+func newFakePushServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	push.NewFakeEndpoint().Register(r)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fakeDeliveries(t *testing.T, server *httptest.Server, token string) []push.FakeDelivery {
+	t.Helper()
+
+	res, err := http.Get(server.URL + "/dev/push/" + token)
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		Data []push.FakeDelivery `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("decode deliveries: %v", err)
+	}
+	return body.Data
+}
+
+func TestSendToSubscriptionDelivers(t *testing.T) {
+	server := newFakePushServer(t)
+	repo := newFakePushRepo()
+	s := newTestPushService(t, repo)
+
+	sub := newBrowserSubscription(t, server.URL+"/dev/push/phone")
+	s.sendToSubscription(nil, sub, []byte(`{"title":"hi"}`))
+
+	deliveries := fakeDeliveries(t, server, "phone")
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	got := deliveries[0]
+	if !got.HasVAPID {
+		t.Error("push was not signed with VAPID")
+	}
+	if got.ContentEncoding != "aes128gcm" {
+		t.Errorf("content encoding %q, want aes128gcm", got.ContentEncoding)
+	}
+	if got.Urgency != "high" {
+		t.Errorf("urgency %q, want high", got.Urgency)
+	}
+
+	if len(repo.delivered) != 1 || repo.delivered[0] != sub.ID {
+		t.Errorf("delivered %v, want [%s]", repo.delivered, sub.ID)
+	}
+	if len(repo.deleted) != 0 {
+		t.Errorf("deleted %v, want none", repo.deleted)
+	}
+}
+
+func TestSendToSubscriptionPrunesGoneEndpoint(t *testing.T) {
+	server := newFakePushServer(t)
+	repo := newFakePushRepo()
+	s := newTestPushService(t, repo)
+
+	sub := newBrowserSubscription(t, server.URL+"/dev/push/gone-laptop")
+	s.sendToSubscription(nil, sub, []byte(`{"title":"hi"}`))
+
+	if len(repo.deleted) != 1 || repo.deleted[0] != sub.ID {
+		t.Errorf("deleted %v, want [%s]", repo.deleted, sub.ID)
+	}
+	if len(repo.delivered) != 0 {
+		t.Errorf("delivered %v, want none", repo.delivered)
+	}
+	if repo.failures[sub.ID] != 0 {
+		t.Errorf("a 410 counted as %d failures, want 0", repo.failures[sub.ID])
+	}
+}
+
+func TestSendToSubscriptionPrunesAfterRepeatedFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	repo := newFakePushRepo()
+	s := newTestPushService(t, repo)
+	sub := newBrowserSubscription(t, server.URL+"/push/flaky")
+
+	for i := 1; i < maxPushFailures; i++ {
+		s.sendToSubscription(nil, sub, []byte(`{"title":"hi"}`))
+	}
+	if len(repo.deleted) != 0 {
+		t.Fatalf("pruned after %d failures, want to keep it until %d", maxPushFailures-1, maxPushFailures)
+	}
+
+	s.sendToSubscription(nil, sub, []byte(`{"title":"hi"}`))
+	if len(repo.deleted) != 1 || repo.deleted[0] != sub.ID {
+		t.Errorf("deleted %v after %d failures, want [%s]", repo.deleted, maxPushFailures, sub.ID)
+	}
+}
+
+func TestEnqueueNotificationSkipsUnpushableTypes(t *testing.T) {
+	s := newTestPushService(t, newFakePushRepo())
+
+	s.EnqueueNotification(&notificationDto.NotificationRes{ID: uuid.New(), Type: models.NotificationMention})
+	s.EnqueueNotification(&notificationDto.NotificationRes{ID: uuid.New(), Type: models.NotificationFriendRequest})
+	s.EnqueueNotification(nil)
+
+	if len(s.jobs) != 1 {
+		t.Fatalf("queued %d jobs, want only the mention", len(s.jobs))
+	}
+	if job := <-s.jobs; job.Type != models.NotificationMention {
+		t.Errorf("queued %q, want %q", job.Type, models.NotificationMention)
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+)
+
+// defaultRateLimits are the policies the router and the WebSocket hub ask
+// for. "<group>" is counted per user once signed in and per address before,
+// "<group>.ip" always per address, "<group>.write" only for writes.
+// "ws.<type>" is per connection and frame type, "ws" covers types without
+// their own. Bots get the same names under "bot.", kept apart so
+// integrations can be tuned without touching people.
+var defaultRateLimits = map[string]models.RateLimit{
+	"auth":       {Requests: 60, Per: time.Minute, Burst: 30},
+	"auth.write": {Requests: 20, Per: time.Minute, Burst: 10},
+
+	"api":       {Requests: 600, Per: time.Minute, Burst: 120},
+	"api.ip":    {Requests: 1200, Per: time.Minute, Burst: 240},
+	"api.write": {Requests: 120, Per: time.Minute, Burst: 30},
+
+	"halls.write": {Requests: 20, Per: time.Minute, Burst: 10},
+
+	"webhooks": {Requests: 60, Per: time.Minute, Burst: 20},
+
+	"ws":                    {Requests: 20, Per: time.Second, Burst: 40},
+	"ws.text":               {Requests: 5, Per: time.Second, Burst: 10},
+	"ws.typing":             {Requests: 2, Per: time.Second, Burst: 4},
+	"ws.stop_typing":        {Requests: 2, Per: time.Second, Burst: 4},
+	"ws.read":               {Requests: 10, Per: time.Second, Burst: 20},
+	"ws.react":              {Requests: 5, Per: time.Second, Burst: 10},
+	"ws.edit":               {Requests: 2, Per: time.Second, Burst: 5},
+	"ws.delete":             {Requests: 2, Per: time.Second, Burst: 5},
+	"ws.sync_subscriptions": {Requests: 1, Per: 5 * time.Second, Burst: 3},
+
+	"bot.api":         {Requests: 300, Per: time.Minute, Burst: 60},
+	"bot.api.write":   {Requests: 60, Per: time.Minute, Burst: 20},
+	"bot.halls.write": {Requests: 10, Per: time.Minute, Burst: 5},
+
+	"bot.ws":      {Requests: 10, Per: time.Second, Burst: 20},
+	"bot.ws.text": {Requests: 2, Per: time.Second, Burst: 5},
+}
+
+type IRateLimitService interface {
+	// Allow takes a token from subject's bucket for the policy. Unknown or
+	// disabled policies always allow, and so does an unreachable Redis.
+	Allow(c context.Context, policy string, subject string) *models.RateLimitResult
+	HasRateLimit(policy string) bool
+}
+
+type rateLimitService struct {
+	repositories.IRateLimitRepository
+
+	limits  map[string]models.RateLimit
+	timeout time.Duration
+}
+
+// NewRateLimitService starts from the defaults, overrides are
+// models.ParseRateLimit specs or "off"
+func NewRateLimitService(rateLimitRepo repositories.IRateLimitRepository, enabled bool, overrides map[string]string) IRateLimitService {
+	limits := make(map[string]models.RateLimit)
+	if enabled {
+		for name, limit := range defaultRateLimits {
+			limits[name] = limit
+		}
+
+		for name, spec := range overrides {
+			if spec == "off" {
+				delete(limits, name)
+				continue
+			}
+
+			limit, err := models.ParseRateLimit(spec)
+			if err != nil {
+				log.Printf("rate limit %s: %v, keeping the default", name, err)
+				continue
+			}
+			if limit.Per < time.Millisecond {
+				log.Printf("rate limit %s: period under 1ms, keeping the default", name)
+				continue
+			}
+			limits[name] = limit
+		}
+	}
+
+	return &rateLimitService{
+		IRateLimitRepository: rateLimitRepo,
+		limits:               limits,
+		timeout:              time.Second,
+	}
+}
+
+func (s *rateLimitService) HasRateLimit(policy string) bool {
+	_, ok := s.limits[policy]
+	return ok
+}
+
+func (s *rateLimitService) Allow(c context.Context, policy string, subject string) *models.RateLimitResult {
+	limit, ok := s.limits[policy]
+	if !ok {
+		return &models.RateLimitResult{Allowed: true}
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	result, err := s.IRateLimitRepository.TakeRateLimitToken(ctx, policy+":"+subject, limit)
+	if err != nil {
+		log.Printf("rate limit %s: %v", policy, err)
+		return &models.RateLimitResult{Allowed: true, Remaining: limit.Capacity()}
+	}
+
+	return result
+}
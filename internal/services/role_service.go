@@ -923,6 +923,7 @@ func (s *roleService) UpdateRolePermissions(ctx context.Context, userInfo *auth.
 	publishHubEvent(s.EventPublisher, realtime.HubEvent{
 		Type:   realtime.HubEventHallAccessResync,
 		HallID: hallID,
+		RoleID: role.ID,
 	})
 
 	// building response
@@ -87,6 +87,7 @@ func roomToRes(r *models.Room) dto.RoomRes {
 		Position:             r.Position,
 		IsPrivate:            r.IsPrivate,
 		SyncWithFloorMembers: r.SyncWithFloorMembers,
+		SlowmodeSeconds:      r.SlowmodeSeconds,
 		CreatedAt:            r.CreatedAt,
 		UpdatedAt:            r.UpdatedAt,
 	}
@@ -440,7 +441,7 @@ func (s *roomService) UpdateRoom(c context.Context, userInfo *auth.UserInfo, hal
 	ctx, cancel := context.WithTimeout(c, s.timeout)
 	defer cancel()
 
-	if req.Name == nil && req.IsPrivate == nil {
+	if req.Name == nil && req.IsPrivate == nil && req.SlowmodeSeconds == nil {
 		return nil, utils.ErrorNoFieldsToUpdate
 	}
 
@@ -458,6 +459,10 @@ func (s *roomService) UpdateRoom(c context.Context, userInfo *auth.UserInfo, hal
 		fields["is_private"] = *req.IsPrivate
 	}
 
+	if req.SlowmodeSeconds != nil {
+		fields["slowmode_seconds"] = *req.SlowmodeSeconds
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return nil, utils.ErrorInternal
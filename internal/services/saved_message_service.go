@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	MAX_SAVED_MESSAGES_PER_USER = 1000
+
+	minSavedReminderDelay   = time.Minute
+	maxSavedReminderDelay   = 365 * 24 * time.Hour
+	savedReminderTick       = 15 * time.Second
+	savedReminderClaimBatch = 100
+)
+
+type ISavedMessageService interface {
+	SaveMessage(c context.Context, userInfo *auth.UserInfo, req *dto.SaveMessageReq) (*dto.SavedMessageRes, error)
+	ListSavedMessages(c context.Context, userInfo *auth.UserInfo, query *dto.ListSavedMessagesQuery) (*dto.SavedMessageListRes, error)
+	UpdateSavedMessage(c context.Context, userInfo *auth.UserInfo, savedID uuid.UUID, req *dto.UpdateSavedMessageReq) (*dto.SavedMessageRes, error)
+	DeleteSavedMessage(c context.Context, userInfo *auth.UserInfo, savedID uuid.UUID) error
+
+	// -------------- REMINDER WORKER
+	Run()
+}
+
+type savedMessageService struct {
+	repositories.ISavedMessageRepository
+	repositories.IMessageRepository
+
+	IRoomService
+	INotificationService
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewSavedMessageService(
+	savedMessageRepo repositories.ISavedMessageRepository,
+	messageRepo repositories.IMessageRepository,
+	roomService IRoomService,
+	notificationService INotificationService,
+	pool *pgxpool.Pool,
+) ISavedMessageService {
+	return &savedMessageService{
+		savedMessageRepo,
+		messageRepo,
+		roomService,
+		notificationService,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func validReminderTime(remindAt time.Time) bool {
+	delay := time.Until(remindAt)
+	return delay >= minSavedReminderDelay && delay <= maxSavedReminderDelay
+}
+
+func cleanSavedNote(note *string) *string {
+	if note == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*note)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// visibleMessage loads the message behind a saved item. Items are hidden,
+// not deleted, while the user can't reach the room, e.g. after leaving the
+// hall, so they come back if access does.
+func (s *savedMessageService) visibleMessage(ctx context.Context, runner database.DBRunner, accessible map[uuid.UUID]uuid.UUID, messageID uuid.UUID) (*dto.MessageDetailed, uuid.UUID, error) {
+	message, err := s.IMessageRepository.GetMessageDetailed(ctx, runner, messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, uuid.Nil, utils.ErrorMessageNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, uuid.Nil, utils.ErrorRequestTimeout
+		}
+		return nil, uuid.Nil, utils.ErrorFetchingMessages
+	}
+
+	hallID, ok := accessible[message.RoomID]
+	if !ok {
+		return nil, uuid.Nil, utils.ErrorMessageNotFound
+	}
+
+	return message, hallID, nil
+}
+
+func toSavedMessageRes(saved *models.SavedMessage, message *dto.MessageDetailed, hallID uuid.UUID) *dto.SavedMessageRes {
+	return &dto.SavedMessageRes{
+		ID:         saved.ID,
+		HallID:     hallID,
+		RoomID:     message.RoomID,
+		Note:       saved.Note,
+		RemindAt:   saved.RemindAt,
+		RemindedAt: saved.RemindedAt,
+		CreatedAt:  saved.CreatedAt,
+		UpdatedAt:  saved.UpdatedAt,
+		Message:    message,
+	}
+}
+
+// ── SAVED MESSAGES ────────────────────────────────────────────────────────────
+
+func (s *savedMessageService) SaveMessage(c context.Context, userInfo *auth.UserInfo, req *dto.SaveMessageReq) (*dto.SavedMessageRes, error) {
+	if req.RemindAt != nil && !validReminderTime(*req.RemindAt) {
+		return nil, utils.ErrorInvalidReminderTime
+	}
+
+	accessible, err := s.IRoomService.GetAccessibleRoomsForUser(c, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	message, hallID, err := s.visibleMessage(ctx, runner, accessible, req.MessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.ISavedMessageRepository.CountSavedMessages(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingSavedMessages
+	}
+	if count >= MAX_SAVED_MESSAGES_PER_USER {
+		return nil, utils.ErrorTooManySavedMessages
+	}
+
+	savedID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	saved, err := s.ISavedMessageRepository.CreateSavedMessage(ctx, runner, &models.SavedMessage{
+		ID:        savedID,
+		UserID:    userInfo.ID,
+		MessageID: message.ID,
+		Note:      cleanSavedNote(req.Note),
+		RemindAt:  req.RemindAt,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorMessageAlreadySaved
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return toSavedMessageRes(saved, message, hallID), nil
+}
+
+func (s *savedMessageService) ListSavedMessages(c context.Context, userInfo *auth.UserInfo, query *dto.ListSavedMessagesQuery) (*dto.SavedMessageListRes, error) {
+	if query.Limit <= 0 {
+		return nil, utils.ErrorInvalidCursorLimit
+	}
+
+	accessible, err := s.IRoomService.GetAccessibleRoomsForUser(c, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dto.SavedMessageListRes{SavedMessages: []*dto.SavedMessageRes{}}
+	if len(accessible) == 0 {
+		return out, nil
+	}
+
+	roomIDs := make([]uuid.UUID, 0, len(accessible))
+	for roomID := range accessible {
+		roomIDs = append(roomIDs, roomID)
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	// fetch one extra row to know if there is another page
+	saved, err := s.ISavedMessageRepository.ListSavedMessages(ctx, runner, userInfo.ID, roomIDs, query.Before, query.Limit+1)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingSavedMessages
+	}
+
+	out.HasMore = len(saved) > query.Limit
+	if out.HasMore {
+		saved = saved[:query.Limit]
+	}
+
+	messageIDs := make([]uuid.UUID, 0, len(saved))
+	for _, item := range saved {
+		messageIDs = append(messageIDs, item.MessageID)
+	}
+
+	messages, err := s.IMessageRepository.GetMessagesDetailedByIDs(ctx, runner, messageIDs)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingMessages
+	}
+
+	byID := make(map[uuid.UUID]*dto.MessageDetailed, len(messages))
+	for _, message := range messages {
+		byID[message.ID] = message
+	}
+
+	for _, item := range saved {
+		message, ok := byID[item.MessageID]
+		if !ok {
+			continue
+		}
+		out.SavedMessages = append(out.SavedMessages, toSavedMessageRes(item, message, accessible[message.RoomID]))
+	}
+
+	return out, nil
+}
+
+func (s *savedMessageService) UpdateSavedMessage(c context.Context, userInfo *auth.UserInfo, savedID uuid.UUID, req *dto.UpdateSavedMessageReq) (*dto.SavedMessageRes, error) {
+	if req.RemindAt != nil && !validReminderTime(*req.RemindAt) {
+		return nil, utils.ErrorInvalidReminderTime
+	}
+
+	accessible, err := s.IRoomService.GetAccessibleRoomsForUser(c, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	saved, err := s.ISavedMessageRepository.GetSavedMessage(ctx, runner, userInfo.ID, savedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorSavedMessageNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingSavedMessages
+	}
+
+	message, hallID, err := s.visibleMessage(ctx, runner, accessible, saved.MessageID)
+	if err != nil {
+		if errors.Is(err, utils.ErrorMessageNotFound) {
+			return nil, utils.ErrorSavedMessageNotFound
+		}
+		return nil, err
+	}
+
+	if req.Note != nil {
+		saved.Note = cleanSavedNote(req.Note)
+	}
+	switch {
+	case req.ClearReminder:
+		saved.RemindAt = nil
+		saved.RemindedAt = nil
+	case req.RemindAt != nil:
+		// a new time is a new reminder, even if the old one went out already
+		saved.RemindAt = req.RemindAt
+		saved.RemindedAt = nil
+	}
+
+	updated, err := s.ISavedMessageRepository.UpdateSavedMessage(ctx, runner, saved)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return toSavedMessageRes(updated, message, hallID), nil
+}
+
+// DeleteSavedMessage works on hidden items too, nobody should be stuck with
+// a bookmark they can't see
+func (s *savedMessageService) DeleteSavedMessage(c context.Context, userInfo *auth.UserInfo, savedID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	defer conn.Release()
+
+	deleted, err := s.ISavedMessageRepository.DeleteSavedMessage(ctx, database.NewConnWrapper(conn), userInfo.ID, savedID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+	if !deleted {
+		return utils.ErrorSavedMessageNotFound
+	}
+
+	return nil
+}
+
+// ── REMINDER WORKER ───────────────────────────────────────────────────────────
+
+func (s *savedMessageService) Run() {
+	ticker := time.NewTicker(savedReminderTick)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.fireDueReminders(); err != nil {
+			log.Printf("saved messages: fire reminders: %v", err)
+		}
+	}
+}
+
+// fireDueReminders claims and delivers in one transaction, a failed batch
+// is retried on the next tick. Reminders for items the user can no longer
+// see are claimed and dropped.
+func (s *savedMessageService) fireDueReminders() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	due, err := s.ISavedMessageRepository.ClaimDueSavedReminders(ctx, runner, time.Now(), savedReminderClaimBatch)
+	if err != nil {
+		return fmt.Errorf("claim: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	accessByUser := make(map[uuid.UUID]map[uuid.UUID]uuid.UUID)
+	drafts := make([]*models.Notification, 0, len(due))
+
+	// one user's failed lookup must not hold back everybody else's reminders,
+	// theirs go back to the queue and the rest of the batch goes out
+	failedUsers := make(map[uuid.UUID]bool)
+	released := []uuid.UUID{}
+
+	for _, saved := range due {
+		if failedUsers[saved.UserID] {
+			released = append(released, saved.ID)
+			continue
+		}
+
+		accessible, ok := accessByUser[saved.UserID]
+		if !ok {
+			accessible, err = s.IRoomService.GetAccessibleRoomsForUser(ctx, &auth.UserInfo{ID: saved.UserID})
+			if err != nil {
+				log.Printf("saved messages: access for %s: %v", saved.UserID, err)
+				failedUsers[saved.UserID] = true
+				released = append(released, saved.ID)
+				continue
+			}
+			accessByUser[saved.UserID] = accessible
+		}
+
+		message, err := s.IMessageRepository.GetMessageByID(ctx, runner, saved.MessageID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return fmt.Errorf("message %s: %w", saved.MessageID, err)
+		}
+		if message.DeletedAt != nil {
+			continue
+		}
+
+		hallID, ok := accessible[message.RoomID]
+		if !ok {
+			continue
+		}
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+
+		preview := saved.Note
+		if preview == nil {
+			preview = message.Content
+		}
+
+		drafts = append(drafts, &models.Notification{
+			ID:          id,
+			UserID:      saved.UserID,
+			Type:        models.NotificationReminder,
+			HallID:      &hallID,
+			RoomID:      &message.RoomID,
+			MessageID:   &message.ID,
+			ReferenceID: &saved.ID,
+			Preview:     notificationPreview(preview),
+		})
+	}
+
+	if len(released) > 0 {
+		if err := s.ISavedMessageRepository.ReleaseSavedReminders(ctx, runner, released); err != nil {
+			return fmt.Errorf("release: %w", err)
+		}
+	}
+
+	notifications, err := s.INotificationService.CreateNotifications(ctx, runner, drafts)
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	s.INotificationService.PublishNotifications(notifications)
+	return nil
+}
@@ -0,0 +1,495 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	MAX_SCHEDULED_MESSAGES_PER_USER = 100
+
+	minScheduleDelay        = time.Minute
+	maxScheduleDelay        = 365 * 24 * time.Hour
+	scheduledDispatchTick   = 5 * time.Second
+	scheduledDispatchBatch  = 50
+	scheduledSendingTimeout = 5 * time.Minute
+)
+
+// failure kept on messages whose posting never finished, CreateMessage may
+// or may not have gone through so they aren't retried
+const scheduledInterruptedFailure = "Posting was interrupted, check the room before scheduling it again"
+
+type IScheduledMessageService interface {
+	ScheduleMessage(c context.Context, userInfo *auth.UserInfo, req *dto.ScheduleMessageReq) (*dto.ScheduledMessageRes, error)
+	ListScheduledMessages(c context.Context, userInfo *auth.UserInfo, query *dto.ListScheduledMessagesQuery) (*dto.ScheduledMessageListRes, error)
+	UpdateScheduledMessage(c context.Context, userInfo *auth.UserInfo, scheduledID uuid.UUID, req *dto.UpdateScheduledMessageReq) (*dto.ScheduledMessageRes, error)
+	CancelScheduledMessage(c context.Context, userInfo *auth.UserInfo, scheduledID uuid.UUID) error
+
+	// -------------- DISPATCHER
+	Run()
+}
+
+type scheduledMessageService struct {
+	repositories.IScheduledMessageRepository
+
+	IRoomService
+	IPermissionCheckerService
+	IMessageService
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewScheduledMessageService(
+	scheduledMessageRepo repositories.IScheduledMessageRepository,
+	roomService IRoomService,
+	permissionChecker IPermissionCheckerService,
+	messageService IMessageService,
+	pool *pgxpool.Pool,
+) IScheduledMessageService {
+	return &scheduledMessageService{
+		scheduledMessageRepo,
+		roomService,
+		permissionChecker,
+		messageService,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+func validScheduleTime(sendAt time.Time) bool {
+	delay := time.Until(sendAt)
+	return delay >= minScheduleDelay && delay <= maxScheduleDelay
+}
+
+func scheduledContent(content *string) *string {
+	if content == nil {
+		return nil
+	}
+	if strings.TrimSpace(*content) == "" {
+		return nil
+	}
+	return utils.SanitizeMessageContent(content)
+}
+
+// scheduledAttachments runs the checks CreateMessage does, so a bad file is
+// refused now and not an hour later when nobody is looking
+func scheduledAttachments(reqs *[]dto.AttachmentReq) ([]models.ScheduledAttachment, error) {
+	attachments := []models.ScheduledAttachment{}
+	if reqs == nil {
+		return attachments, nil
+	}
+
+	for _, a := range *reqs {
+		if _, err := utils.ValidateFileName(a.FileName); err != nil {
+			return nil, err
+		}
+		if _, err := utils.ValidateFileType(a.FileType, a.URL); err != nil {
+			return nil, err
+		}
+		if a.FileSize != nil && *a.FileSize >= utils.FileSize {
+			return nil, utils.ErrorLargeFileSize
+		}
+
+		attachments = append(attachments, models.ScheduledAttachment{
+			FileName: a.FileName,
+			URL:      a.URL,
+			FileType: a.FileType,
+			FileSize: a.FileSize,
+		})
+	}
+	return attachments, nil
+}
+
+func scheduledMentions(mentions *[]uuid.UUID) []uuid.UUID {
+	if mentions == nil {
+		return []uuid.UUID{}
+	}
+	return *mentions
+}
+
+// checkCanPost is what the author needs in the room, both when scheduling and
+// again when the message goes out
+func (s *scheduledMessageService) checkCanPost(ctx context.Context, runner database.DBRunner, accessible map[uuid.UUID]uuid.UUID, authorID uuid.UUID, roomID uuid.UUID) error {
+	hallID, ok := accessible[roomID]
+	if !ok {
+		return utils.ErrorUserDoesntBelongRoom
+	}
+
+	allowed, err := s.IPermissionCheckerService.CanSendMessages(ctx, runner, authorID, hallID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return utils.ErrorCannotSendMessages
+	}
+	return nil
+}
+
+// ── SCHEDULED MESSAGES ────────────────────────────────────────────────────────
+
+func (s *scheduledMessageService) ScheduleMessage(c context.Context, userInfo *auth.UserInfo, req *dto.ScheduleMessageReq) (*dto.ScheduledMessageRes, error) {
+	if !validScheduleTime(req.SendAt) {
+		return nil, utils.ErrorInvalidScheduleTime
+	}
+
+	content := scheduledContent(req.Content)
+	attachments, err := scheduledAttachments(req.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil && len(attachments) == 0 {
+		return nil, utils.ErrorEmptyScheduledMessage
+	}
+
+	accessible, err := s.IRoomService.GetAccessibleRoomsForUser(c, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.checkCanPost(ctx, runner, accessible, userInfo.ID, req.RoomID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.IScheduledMessageRepository.CountScheduledMessages(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingScheduledMessages
+	}
+	if count >= MAX_SCHEDULED_MESSAGES_PER_USER {
+		return nil, utils.ErrorTooManyScheduledMessages
+	}
+
+	scheduledID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	scheduled, err := s.IScheduledMessageRepository.CreateScheduledMessage(ctx, runner, &models.ScheduledMessage{
+		ID:              scheduledID,
+		AuthorID:        userInfo.ID,
+		RoomID:          req.RoomID,
+		Content:         content,
+		Attachments:     attachments,
+		MentionEveryone: req.MentionEveryone != nil && *req.MentionEveryone,
+		Mentions:        scheduledMentions(req.Mentions),
+		SendAt:          req.SendAt,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return dto.ToScheduledMessageRes(scheduled), nil
+}
+
+func (s *scheduledMessageService) ListScheduledMessages(c context.Context, userInfo *auth.UserInfo, query *dto.ListScheduledMessagesQuery) (*dto.ScheduledMessageListRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+
+	list, err := s.IScheduledMessageRepository.ListScheduledMessages(ctx, database.NewConnWrapper(conn), userInfo.ID, query.RoomID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingScheduledMessages
+	}
+
+	out := &dto.ScheduledMessageListRes{ScheduledMessages: make([]*dto.ScheduledMessageRes, 0, len(list))}
+	for _, scheduled := range list {
+		out.ScheduledMessages = append(out.ScheduledMessages, dto.ToScheduledMessageRes(scheduled))
+	}
+
+	return out, nil
+}
+
+func (s *scheduledMessageService) UpdateScheduledMessage(c context.Context, userInfo *auth.UserInfo, scheduledID uuid.UUID, req *dto.UpdateScheduledMessageReq) (*dto.ScheduledMessageRes, error) {
+	if req.SendAt != nil && !validScheduleTime(*req.SendAt) {
+		return nil, utils.ErrorInvalidScheduleTime
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	scheduled, err := s.IScheduledMessageRepository.GetScheduledMessageForUpdate(ctx, runner, userInfo.ID, scheduledID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorScheduledMessageNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingScheduledMessages
+	}
+	if scheduled.Status == models.ScheduledMessageSending {
+		return nil, utils.ErrorScheduledMessageSending
+	}
+
+	if req.Content != nil {
+		scheduled.Content = scheduledContent(req.Content)
+	}
+	if req.Attachments != nil {
+		scheduled.Attachments, err = scheduledAttachments(req.Attachments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if scheduled.Content == nil && len(scheduled.Attachments) == 0 {
+		return nil, utils.ErrorEmptyScheduledMessage
+	}
+
+	if req.MentionEveryone != nil {
+		scheduled.MentionEveryone = *req.MentionEveryone
+	}
+	if req.Mentions != nil {
+		scheduled.Mentions = scheduledMentions(req.Mentions)
+	}
+
+	if req.SendAt != nil {
+		scheduled.SendAt = *req.SendAt
+		scheduled.Status = models.ScheduledMessagePending
+		scheduled.Failure = nil
+	}
+
+	updated, err := s.IScheduledMessageRepository.UpdateScheduledMessage(ctx, runner, scheduled)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return dto.ToScheduledMessageRes(updated), nil
+}
+
+func (s *scheduledMessageService) CancelScheduledMessage(c context.Context, userInfo *auth.UserInfo, scheduledID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	scheduled, err := s.IScheduledMessageRepository.GetScheduledMessageForUpdate(ctx, runner, userInfo.ID, scheduledID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorScheduledMessageNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorFetchingScheduledMessages
+	}
+	if scheduled.Status == models.ScheduledMessageSending {
+		return utils.ErrorScheduledMessageSending
+	}
+
+	if _, err := s.IScheduledMessageRepository.DeleteScheduledMessage(ctx, runner, userInfo.ID, scheduledID); err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+// ── DISPATCHER ────────────────────────────────────────────────────────────────
+
+func (s *scheduledMessageService) Run() {
+	ticker := time.NewTicker(scheduledDispatchTick)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.dispatchDue(); err != nil {
+			log.Printf("scheduled messages: dispatch: %v", err)
+		}
+	}
+}
+
+// dispatchDue claims a batch and posts each message on its own. A claimed
+// message is never put back as is: posting twice is worse than not at all.
+func (s *scheduledMessageService) dispatchDue() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	runner := database.NewConnWrapper(conn)
+
+	stale, err := s.IScheduledMessageRepository.FailStaleScheduledMessages(ctx, runner, time.Now().Add(-scheduledSendingTimeout), scheduledInterruptedFailure)
+	if err != nil {
+		conn.Release()
+		return err
+	}
+	if stale > 0 {
+		log.Printf("scheduled messages: gave up on %d interrupted", stale)
+	}
+
+	due, err := s.IScheduledMessageRepository.ClaimDueScheduledMessages(ctx, runner, time.Now(), scheduledDispatchBatch)
+	conn.Release()
+	if err != nil {
+		return err
+	}
+
+	accessByAuthor := make(map[uuid.UUID]map[uuid.UUID]uuid.UUID)
+	for _, scheduled := range due {
+		s.dispatch(scheduled, accessByAuthor)
+	}
+
+	return nil
+}
+
+func (s *scheduledMessageService) dispatch(scheduled *models.ScheduledMessage, accessByAuthor map[uuid.UUID]map[uuid.UUID]uuid.UUID) {
+	err := s.post(scheduled, accessByAuthor)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, connErr := s.pool.Acquire(ctx)
+	if connErr != nil {
+		log.Printf("scheduled messages: settle %s: %v", scheduled.ID, connErr)
+		return
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	var cooldown *utils.CooldownError
+	switch {
+	case err == nil:
+		_, err = s.IScheduledMessageRepository.DeleteScheduledMessage(ctx, runner, scheduled.AuthorID, scheduled.ID)
+
+	case errors.As(err, &cooldown):
+		// slow mode is only a wait, not a reason to give up
+		err = s.IScheduledMessageRepository.RequeueScheduledMessage(ctx, runner, scheduled.ID, time.Now().Add(cooldown.Remaining))
+
+	default:
+		failure := utils.ErrorInternal.Message
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			failure = appErr.Message
+		}
+		err = s.IScheduledMessageRepository.FailScheduledMessage(ctx, runner, scheduled.ID, failure)
+	}
+
+	if err != nil {
+		log.Printf("scheduled messages: settle %s: %v", scheduled.ID, err)
+	}
+}
+
+// post checks the author may still write in the room, access and permission
+// can have changed since it was scheduled, then sends it the way the socket
+// does so the room gets the usual broadcast
+func (s *scheduledMessageService) post(scheduled *models.ScheduledMessage, accessByAuthor map[uuid.UUID]map[uuid.UUID]uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*s.timeout)
+	defer cancel()
+
+	accessible, ok := accessByAuthor[scheduled.AuthorID]
+	if !ok {
+		var err error
+		accessible, err = s.IRoomService.GetAccessibleRoomsForUser(ctx, &auth.UserInfo{ID: scheduled.AuthorID})
+		if err != nil {
+			return err
+		}
+		accessByAuthor[scheduled.AuthorID] = accessible
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	err = s.checkCanPost(ctx, database.NewConnWrapper(conn), accessible, scheduled.AuthorID, scheduled.RoomID)
+	conn.Release()
+	if err != nil {
+		return err
+	}
+
+	// CreateMessage wants content even when there are only attachments
+	content := ""
+	if scheduled.Content != nil {
+		content = *scheduled.Content
+	}
+
+	attachments := make([]dto.AttachmentReq, 0, len(scheduled.Attachments))
+	for _, a := range scheduled.Attachments {
+		attachments = append(attachments, dto.AttachmentReq{
+			FileName: a.FileName,
+			URL:      a.URL,
+			FileType: a.FileType,
+			FileSize: a.FileSize,
+		})
+	}
+
+	_, err = s.IMessageService.CreateMessage(ctx, &dto.CreateMessageReq{
+		RoomID:          scheduled.RoomID,
+		AuthorID:        scheduled.AuthorID,
+		Content:         &content,
+		SentAt:          time.Now().UTC(),
+		Attachments:     &attachments,
+		MentionEveryone: &scheduled.MentionEveryone,
+		Mentions:        &scheduled.Mentions,
+	})
+	return err
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	notificationDto "github.com/suck-seed/yapp/internal/dto/notification"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/mail"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const (
+	signinFailureWindow = 15 * time.Minute
+
+	// failures an account gets for free, after that every attempt has to
+	// wait signinDelayBase doubled per extra failure, up to signinDelayMax
+	signinFreeFailures = 3
+	signinDelayBase    = time.Second
+	signinDelayMax     = 30 * time.Second
+
+	// failures inside the window before sign-in is refused outright
+	signinAccountLockAfter = 10
+	signinIPLockAfter      = 50
+	signinLockout          = 15 * time.Minute
+
+	signinHistoryLimit = 50
+)
+
+type ISigninSecurityService interface {
+	// -------------- THROTTLE
+	// CheckSignin refuses while the address or account is locked out, or the
+	// account is still waiting out its last failure. userID is nil when the
+	// e-mail matched no account, the e-mail is throttled instead.
+	CheckSignin(c context.Context, client *dto.ClientInfo, userID *uuid.UUID, email string) error
+	RecordSigninFailure(c context.Context, client *dto.ClientInfo, userID *uuid.UUID, email string, method models.SigninMethod, reason string)
+
+	// RecordSigninSuccess clears the account's failures and remembers the
+	// device, telling the user when it has never been seen before.
+	RecordSigninSuccess(c context.Context, client *dto.ClientInfo, user *models.User, method models.SigninMethod)
+
+	// -------------- HISTORY
+	ListMySignins(c context.Context, userInfo *auth.UserInfo) (*dto.SigninAttemptsRes, error)
+}
+
+type signinSecurityService struct {
+	repositories.ISigninActivityRepository
+	repositories.ISigninThrottleRepository
+
+	INotificationService
+
+	mailer     mail.Mailer
+	appBaseURL string
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewSigninSecurityService(
+	signinActivityRepo repositories.ISigninActivityRepository,
+	signinThrottleRepo repositories.ISigninThrottleRepository,
+	notificationService INotificationService,
+	mailer mail.Mailer,
+	appBaseURL string,
+	pool *pgxpool.Pool,
+) ISigninSecurityService {
+	return &signinSecurityService{
+		signinActivityRepo,
+		signinThrottleRepo,
+		notificationService,
+		mailer,
+		strings.TrimRight(appBaseURL, "/"),
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+// signinAccountKey throttles known accounts by id, so the e-mail casing or a
+// later address change doesn't reset the count
+func signinAccountKey(userID *uuid.UUID, email string) string {
+	if userID != nil {
+		return "user:" + userID.String()
+	}
+	return "email:" + hex.EncodeToString(auth.HashOpaqueToken(strings.ToLower(strings.TrimSpace(email))))
+}
+
+func signinIPKey(client *dto.ClientInfo) string {
+	return "ip:" + client.IP
+}
+
+// signinDelay is how long an account has to wait after its latest failure
+func signinDelay(failures int) time.Duration {
+	if failures < signinFreeFailures {
+		return 0
+	}
+
+	delay := signinDelayBase << (failures - signinFreeFailures)
+	if delay <= 0 || delay > signinDelayMax {
+		return signinDelayMax
+	}
+	return delay
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// ── THROTTLE ──────────────────────────────────────────────────────────────────
+
+// Redis being down must not lock everyone out, so throttle lookups fail open.
+
+func (s *signinSecurityService) CheckSignin(c context.Context, client *dto.ClientInfo, userID *uuid.UUID, email string) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	accountKey := signinAccountKey(userID, email)
+
+	for _, key := range []string{signinIPKey(client), accountKey} {
+		locked, err := s.ISigninThrottleRepository.GetSigninLock(ctx, key)
+		if err != nil {
+			log.Printf("signin throttle: lock %s: %v", key, err)
+			return nil
+		}
+		if locked > 0 {
+			return utils.ErrorSigninLocked
+		}
+	}
+
+	failures, last, err := s.ISigninThrottleRepository.GetSigninFailures(ctx, accountKey, time.Now(), signinFailureWindow)
+	if err != nil {
+		log.Printf("signin throttle: failures %s: %v", accountKey, err)
+		return nil
+	}
+	if time.Since(last) < signinDelay(failures) {
+		return utils.ErrorSigninThrottled
+	}
+
+	return nil
+}
+
+func (s *signinSecurityService) RecordSigninFailure(c context.Context, client *dto.ClientInfo, userID *uuid.UUID, email string, method models.SigninMethod, reason string) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	now := time.Now()
+	limits := map[string]int{
+		signinAccountKey(userID, email): signinAccountLockAfter,
+		signinIPKey(client):             signinIPLockAfter,
+	}
+
+	for key, lockAfter := range limits {
+		failures, err := s.ISigninThrottleRepository.RecordSigninFailure(ctx, key, now, signinFailureWindow)
+		if err != nil {
+			log.Printf("signin throttle: record %s: %v", key, err)
+			continue
+		}
+		if failures < lockAfter {
+			continue
+		}
+
+		// start over once the lock runs out instead of locking again on the next try
+		if err := s.ISigninThrottleRepository.LockSignin(ctx, key, signinLockout); err != nil {
+			log.Printf("signin throttle: lock %s: %v", key, err)
+		}
+		if err := s.ISigninThrottleRepository.ClearSigninFailures(ctx, key); err != nil {
+			log.Printf("signin throttle: clear %s: %v", key, err)
+		}
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		log.Printf("signin history: %v", err)
+		return
+	}
+	defer conn.Release()
+
+	err = s.ISigninActivityRepository.CreateSigninAttempt(ctx, database.NewConnWrapper(conn), &models.SigninAttempt{
+		ID:            id,
+		UserID:        userID,
+		Method:        method,
+		Succeeded:     false,
+		FailureReason: &reason,
+		IP:            optionalString(client.IP),
+		UserAgent:     optionalString(client.UserAgent),
+	})
+	if err != nil {
+		log.Printf("signin history: record failure: %v", err)
+	}
+}
+
+func (s *signinSecurityService) RecordSigninSuccess(c context.Context, client *dto.ClientInfo, user *models.User, method models.SigninMethod) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	if err := s.ISigninThrottleRepository.ClearSigninFailures(ctx, signinAccountKey(&user.ID, user.Email)); err != nil {
+		log.Printf("signin throttle: clear %s: %v", user.ID, err)
+	}
+
+	deviceID, err := uuid.NewV7()
+	if err != nil {
+		return
+	}
+	attemptID, err := uuid.NewV7()
+	if err != nil {
+		return
+	}
+	deviceName := utils.DescribeUserAgent(client.UserAgent)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("signin history: %v", err)
+		return
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	knownDevices, err := s.ISigninActivityRepository.CountUserDevices(ctx, runner, user.ID)
+	if err != nil {
+		log.Printf("signin history: count devices: %v", err)
+		return
+	}
+
+	device, created, err := s.ISigninActivityRepository.TouchUserDevice(ctx, runner, &models.UserDevice{
+		ID:         deviceID,
+		UserID:     user.ID,
+		DeviceKey:  hex.EncodeToString(auth.HashOpaqueToken(client.DeviceID)),
+		DeviceName: &deviceName,
+		LastIP:     optionalString(client.IP),
+	})
+	if err != nil {
+		log.Printf("signin history: touch device: %v", err)
+		return
+	}
+
+	err = s.ISigninActivityRepository.CreateSigninAttempt(ctx, runner, &models.SigninAttempt{
+		ID:        attemptID,
+		UserID:    &user.ID,
+		DeviceID:  &device.ID,
+		Method:    method,
+		Succeeded: true,
+		IP:        optionalString(client.IP),
+		UserAgent: optionalString(client.UserAgent),
+	})
+	if err != nil {
+		log.Printf("signin history: record success: %v", err)
+		return
+	}
+
+	// the very first device is the one the account was created on
+	alert := created && knownDevices > 0
+
+	var notifications []*notificationDto.NotificationRes
+	if alert {
+		preview := deviceName
+		if client.IP != "" {
+			preview += " · " + client.IP
+		}
+
+		notifications, err = s.INotificationService.CreateNotifications(ctx, runner, []*models.Notification{{
+			UserID:      user.ID,
+			Type:        models.NotificationNewSignin,
+			ReferenceID: &attemptID,
+			Preview:     &preview,
+		}})
+		if err != nil {
+			log.Printf("signin history: notify: %v", err)
+			return
+		}
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		log.Printf("signin history: commit: %v", err)
+		return
+	}
+
+	if alert {
+		s.INotificationService.PublishNotifications(notifications)
+		deliverMail(s.mailer, mail.NewSigninMessage(
+			user.Email,
+			user.DisplayName,
+			deviceName,
+			client.IP,
+			time.Now(),
+			s.appBaseURL+"/forgot-password",
+		))
+	}
+}
+
+// ── HISTORY ───────────────────────────────────────────────────────────────────
+
+func (s *signinSecurityService) ListMySignins(c context.Context, userInfo *auth.UserInfo) (*dto.SigninAttemptsRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+
+	attempts, err := s.ISigninActivityRepository.ListSigninAttempts(ctx, database.NewConnWrapper(conn), userInfo.ID, signinHistoryLimit)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	out := make([]dto.SigninAttemptRes, 0, len(attempts))
+	for _, a := range attempts {
+		out = append(out, dto.SigninAttemptRes{
+			ID:            a.ID,
+			Method:        a.Method,
+			Succeeded:     a.Succeeded,
+			FailureReason: a.FailureReason,
+			IP:            a.IP,
+			DeviceName:    a.DeviceName,
+			CreatedAt:     a.CreatedAt,
+		})
+	}
+
+	return &dto.SigninAttemptsRes{Attempts: out}, nil
+}
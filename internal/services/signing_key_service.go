@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// a retired key keeps verifying until the last token it signed has expired,
+// plus some room for clock skew between instances
+const signingKeyVerifyGrace = auth.AccessTokenTTL + 5*time.Minute
+
+type ISigningKeyService interface {
+	// LoadSigningKeys is the auth.KeyLoader behind the server's key ring
+	LoadSigningKeys(c context.Context) ([]*auth.SigningKey, error)
+
+	// -------------- ADMIN
+	ListSigningKeys(c context.Context, includeExpired bool) ([]*models.JWTSigningKey, error)
+	GenerateSigningKey(c context.Context, alg string, activatesAt time.Time) (*models.JWTSigningKey, error)
+
+	// RotateSigningKeys publishes a new key right away and lets it take over
+	// signing after publishFor, the keys it replaces retire at that moment.
+	RotateSigningKeys(c context.Context, alg string, publishFor time.Duration) (*models.JWTSigningKey, []*models.JWTSigningKey, error)
+
+	// RetireSigningKey stops a key from signing now. With revoke the tokens
+	// it already signed stop working too.
+	RetireSigningKey(c context.Context, kid string, revoke bool) (*models.JWTSigningKey, error)
+	PruneSigningKeys(c context.Context) (int64, error)
+}
+
+type signingKeyService struct {
+	repositories.ISigningKeyRepository
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewSigningKeyService(signingKeyRepo repositories.ISigningKeyRepository, pool *pgxpool.Pool) ISigningKeyService {
+	return &signingKeyService{
+		signingKeyRepo,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+func (s *signingKeyService) LoadSigningKeys(c context.Context) ([]*auth.SigningKey, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	stored, err := s.ISigningKeyRepository.ListSigningKeys(ctx, database.NewConnWrapper(conn), false)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*auth.SigningKey, 0, len(stored))
+	for _, k := range stored {
+		// one unreadable key shouldn't take the others down with it
+		pkcs8, err := auth.Open(k.PrivateKeyEnc)
+		if err != nil {
+			log.Printf("signing keys: open %s: %v", k.ID, err)
+			continue
+		}
+		key, err := auth.ParseSigningKey(k.ID, k.Algorithm, pkcs8)
+		if err != nil {
+			log.Printf("signing keys: parse %s: %v", k.ID, err)
+			continue
+		}
+
+		key.NotBefore = k.ActivatesAt
+		key.RetiresAt = k.RetiresAt
+		key.ExpiresAt = k.ExpiresAt
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (s *signingKeyService) ListSigningKeys(c context.Context, includeExpired bool) ([]*models.JWTSigningKey, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+
+	keys, err := s.ISigningKeyRepository.ListSigningKeys(ctx, database.NewConnWrapper(conn), includeExpired)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return keys, nil
+}
+
+// newStoredSigningKey generates and seals a key, RSA generation alone can
+// take longer than the query timeout so it happens before the clock starts.
+func newStoredSigningKey(alg string, activatesAt time.Time) (*models.JWTSigningKey, error) {
+	if !auth.IsSupportedSigningAlgorithm(alg) {
+		return nil, utils.ErrorUnsupportedSigningAlgorithm
+	}
+
+	pkcs8, err := auth.GenerateSigningKey(alg)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	sealed, err := auth.Seal(pkcs8)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	kid, err := auth.NewSigningKeyID(time.Now())
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &models.JWTSigningKey{
+		ID:            kid,
+		Algorithm:     alg,
+		PrivateKeyEnc: sealed,
+		ActivatesAt:   activatesAt,
+	}, nil
+}
+
+func (s *signingKeyService) GenerateSigningKey(c context.Context, alg string, activatesAt time.Time) (*models.JWTSigningKey, error) {
+	key, err := newStoredSigningKey(alg, activatesAt)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+
+	saved, err := s.ISigningKeyRepository.CreateSigningKey(ctx, database.NewConnWrapper(conn), key)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return saved, nil
+}
+
+func (s *signingKeyService) RotateSigningKeys(c context.Context, alg string, publishFor time.Duration) (*models.JWTSigningKey, []*models.JWTSigningKey, error) {
+	switchAt := time.Now().Add(publishFor)
+
+	key, err := newStoredSigningKey(alg, switchAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	saved, err := s.ISigningKeyRepository.CreateSigningKey(ctx, runner, key)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, nil, utils.ErrorRequestTimeout
+		}
+		return nil, nil, utils.ErrorInternal
+	}
+
+	retired, err := s.ISigningKeyRepository.RetireOtherSigningKeys(ctx, runner, saved.ID, switchAt, switchAt.Add(signingKeyVerifyGrace))
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, nil, utils.ErrorRequestTimeout
+		}
+		return nil, nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, nil, utils.ErrorInternal
+	}
+
+	return saved, retired, nil
+}
+
+func (s *signingKeyService) RetireSigningKey(c context.Context, kid string, revoke bool) (*models.JWTSigningKey, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	now := time.Now()
+	expiresAt := now.Add(signingKeyVerifyGrace)
+	if revoke {
+		expiresAt = now
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+
+	key, err := s.ISigningKeyRepository.RetireSigningKey(ctx, database.NewConnWrapper(conn), kid, now, expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorSigningKeyNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return key, nil
+}
+
+func (s *signingKeyService) PruneSigningKeys(c context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return 0, utils.ErrorInternal
+	}
+	defer conn.Release()
+
+	deleted, err := s.ISigningKeyRepository.DeleteExpiredSigningKeys(ctx, database.NewConnWrapper(conn))
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return 0, utils.ErrorRequestTimeout
+		}
+		return 0, utils.ErrorInternal
+	}
+
+	return deleted, nil
+}
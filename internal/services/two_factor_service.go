@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const recoveryCodeCount = 10
+
+type ITwoFactorService interface {
+	// -------------- ENROLLMENT
+	GetTwoFactorStatus(c context.Context, userInfo *auth.UserInfo) (*dto.TwoFactorStatusRes, error)
+	BeginTOTPEnrollment(c context.Context, userInfo *auth.UserInfo) (*dto.TOTPEnrollmentRes, error)
+	ConfirmTOTPEnrollment(c context.Context, userInfo *auth.UserInfo, req *dto.ConfirmTOTPReq) (*dto.RecoveryCodesRes, error)
+	DisableTwoFactor(c context.Context, userInfo *auth.UserInfo, req *dto.TwoFactorCodeReq) error
+	RegenerateRecoveryCodes(c context.Context, userInfo *auth.UserInfo, req *dto.TwoFactorCodeReq) (*dto.RecoveryCodesRes, error)
+
+	// -------------- SIGN IN
+	// CompleteSignin trades the challenge from Signin plus a second factor for the JWT
+	CompleteSignin(c context.Context, req *dto.SigninSecondFactorReq, client *dto.ClientInfo) (*dto.SigninUserRes, error)
+}
+
+type twoFactorService struct {
+	repositories.ITwoFactorRepository
+	repositories.IUserRepository
+
+	IUserService
+	ISigninSecurityService
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewTwoFactorService(
+	twoFactorRepo repositories.ITwoFactorRepository,
+	userRepo repositories.IUserRepository,
+	userService IUserService,
+	signinSecurityService ISigninSecurityService,
+	pool *pgxpool.Pool,
+) ITwoFactorService {
+	return &twoFactorService{
+		twoFactorRepo,
+		userRepo,
+		userService,
+		signinSecurityService,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// normalizeRecoveryCode lets users type codes with or without the dash and in any case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// newRecoveryCodes returns the plain codes for the user and the rows to store.
+func newRecoveryCodes(userID uuid.UUID) ([]string, []*models.RecoveryCode, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	rows := make([]*models.RecoveryCode, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		plain = append(plain, raw[:5]+"-"+raw[5:])
+		rows = append(rows, &models.RecoveryCode{
+			ID:       id,
+			UserID:   userID,
+			CodeHash: auth.HashOpaqueToken(raw),
+		})
+	}
+
+	return plain, rows, nil
+}
+
+// verifySecondFactor accepts a current TOTP code or burns one recovery code.
+func (s *twoFactorService) verifySecondFactor(ctx context.Context, runner database.DBRunner, userID uuid.UUID, req *dto.TwoFactorCodeReq) error {
+	switch {
+	case req.Code != "":
+		totp, err := s.ITwoFactorRepository.GetUserTOTP(ctx, runner, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return utils.ErrorTwoFactorNotEnabled
+			}
+			if utils.IsDeadline(err) {
+				return utils.ErrorRequestTimeout
+			}
+			return utils.ErrorInternal
+		}
+		if !totp.IsEnabled() {
+			return utils.ErrorTwoFactorNotEnabled
+		}
+
+		step, ok := auth.ValidateTOTP(totp.Secret, req.Code, time.Now())
+		if !ok {
+			return utils.ErrorInvalidTwoFactorCode
+		}
+
+		// already used within this window
+		if err := s.ITwoFactorRepository.UseTOTPStep(ctx, runner, userID, step); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return utils.ErrorInvalidTwoFactorCode
+			}
+			if utils.IsDeadline(err) {
+				return utils.ErrorRequestTimeout
+			}
+			return utils.ErrorInternal
+		}
+		return nil
+
+	case req.RecoveryCode != "":
+		hash := auth.HashOpaqueToken(normalizeRecoveryCode(req.RecoveryCode))
+		if err := s.ITwoFactorRepository.ConsumeRecoveryCode(ctx, runner, userID, hash); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return utils.ErrorInvalidTwoFactorCode
+			}
+			if utils.IsDeadline(err) {
+				return utils.ErrorRequestTimeout
+			}
+			return utils.ErrorInternal
+		}
+		return nil
+	}
+
+	return utils.ErrorInvalidInput
+}
+
+// ── ENROLLMENT ────────────────────────────────────────────────────────────────
+
+func (s *twoFactorService) GetTwoFactorStatus(c context.Context, userInfo *auth.UserInfo) (*dto.TwoFactorStatusRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	res := &dto.TwoFactorStatusRes{}
+
+	totp, err := s.ITwoFactorRepository.GetUserTOTP(ctx, runner, userInfo.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return res, nil
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	res.Enabled = totp.IsEnabled()
+	res.PendingEnrollment = !res.Enabled
+
+	if res.Enabled {
+		remaining, err := s.ITwoFactorRepository.CountUnusedRecoveryCodes(ctx, runner, userInfo.ID)
+		if err != nil {
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorInternal
+		}
+		res.RecoveryCodesRemaining = remaining
+	}
+
+	return res, nil
+}
+
+func (s *twoFactorService) BeginTOTPEnrollment(c context.Context, userInfo *auth.UserInfo) (*dto.TOTPEnrollmentRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	enabled, err := s.ITwoFactorRepository.IsTwoFactorEnabled(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+	if enabled {
+		return nil, utils.ErrorTwoFactorAlreadyEnabled
+	}
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorUserNotFound
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	if _, err := s.ITwoFactorRepository.UpsertPendingTOTP(ctx, runner, userInfo.ID, secret); err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorUpdatingTwoFactor
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.TOTPEnrollmentRes{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(user.Email, secret),
+	}, nil
+}
+
+func (s *twoFactorService) ConfirmTOTPEnrollment(c context.Context, userInfo *auth.UserInfo, req *dto.ConfirmTOTPReq) (*dto.RecoveryCodesRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	totp, err := s.ITwoFactorRepository.GetUserTOTP(ctx, runner, userInfo.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorTwoFactorNotEnrolled
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+	if totp.IsEnabled() {
+		return nil, utils.ErrorTwoFactorAlreadyEnabled
+	}
+
+	step, ok := auth.ValidateTOTP(totp.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, utils.ErrorInvalidTwoFactorCode
+	}
+
+	if err := s.ITwoFactorRepository.UseTOTPStep(ctx, runner, userInfo.ID, step); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorInvalidTwoFactorCode
+		}
+		return nil, utils.ErrorUpdatingTwoFactor
+	}
+
+	if err := s.ITwoFactorRepository.EnableTOTP(ctx, runner, userInfo.ID); err != nil {
+		return nil, utils.ErrorUpdatingTwoFactor
+	}
+
+	plain, rows, err := newRecoveryCodes(userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	if err := s.ITwoFactorRepository.ReplaceRecoveryCodes(ctx, runner, userInfo.ID, rows); err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorUpdatingTwoFactor
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.RecoveryCodesRes{RecoveryCodes: plain}, nil
+}
+
+func (s *twoFactorService) DisableTwoFactor(c context.Context, userInfo *auth.UserInfo, req *dto.TwoFactorCodeReq) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	enabled, err := s.ITwoFactorRepository.IsTwoFactorEnabled(ctx, runner, userInfo.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+	if !enabled {
+		return utils.ErrorTwoFactorNotEnabled
+	}
+
+	if err := s.verifySecondFactor(ctx, runner, userInfo.ID, req); err != nil {
+		return err
+	}
+
+	if err := s.ITwoFactorRepository.DeleteTOTP(ctx, runner, userInfo.ID); err != nil {
+		return utils.ErrorUpdatingTwoFactor
+	}
+	if err := s.ITwoFactorRepository.DeleteRecoveryCodes(ctx, runner, userInfo.ID); err != nil {
+		return utils.ErrorUpdatingTwoFactor
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(c context.Context, userInfo *auth.UserInfo, req *dto.TwoFactorCodeReq) (*dto.RecoveryCodesRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.verifySecondFactor(ctx, runner, userInfo.ID, req); err != nil {
+		return nil, err
+	}
+
+	plain, rows, err := newRecoveryCodes(userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	if err := s.ITwoFactorRepository.ReplaceRecoveryCodes(ctx, runner, userInfo.ID, rows); err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorUpdatingTwoFactor
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.RecoveryCodesRes{RecoveryCodes: plain}, nil
+}
+
+// ── SIGN IN ───────────────────────────────────────────────────────────────────
+
+func (s *twoFactorService) CompleteSignin(c context.Context, req *dto.SigninSecondFactorReq, client *dto.ClientInfo) (*dto.SigninUserRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	subject, err := auth.ParseMFAChallengeToken(req.MFAToken)
+	if err != nil {
+		return nil, utils.ErrorInvalidMFAToken
+	}
+	userID, err := uuid.Parse(subject)
+	if err != nil {
+		return nil, utils.ErrorInvalidMFAToken
+	}
+
+	// guessing codes counts against the same budget as guessing passwords
+	if err := s.ISigninSecurityService.CheckSignin(c, client, &userID, ""); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.verifySecondFactor(ctx, runner, userID, &req.TwoFactorCodeReq); err != nil {
+		if errors.Is(err, utils.ErrorInvalidTwoFactorCode) {
+			s.ISigninSecurityService.RecordSigninFailure(c, client, &userID, "", models.SigninMethodTwoFactor, models.SigninFailureWrongCode)
+		}
+		return nil, err
+	}
+
+	user, err := s.IUserRepository.GetUserById(ctx, runner, userID)
+	if err != nil {
+		return nil, utils.ErrorUserNotFound
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	signedToken, err := auth.GetSignedToken(user)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	s.ISigninSecurityService.RecordSigninSuccess(c, client, user, models.SigninMethodTwoFactor)
+
+	userMe, err := s.IUserService.GetUserMe(c, &auth.UserInfo{ID: user.ID, Username: user.Username})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SigninUserRes{
+		AccessToken: signedToken,
+		Success:     true,
+		UserMe:      *userMe,
+	}, nil
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,10 +21,11 @@ import (
 
 type IUserService interface {
 	Signup(c context.Context, req *dto.SignupUserReq) (*dto.SignupUserRes, error)
-	Signin(c context.Context, req *dto.SigninUserReq) (*dto.SigninUserRes, error)
+	Signin(c context.Context, req *dto.SigninUserReq, client *dto.ClientInfo) (*dto.SigninUserRes, error)
 
 	GetUserMe(c context.Context, userInfo *auth.UserInfo) (*dto.UserMe, error)
 	GetUserById(c context.Context, userID uuid.UUID) (*models.User, error)
+	GetUsersByIds(c context.Context, userIDs []uuid.UUID) ([]*models.User, error)
 	GetUserPublic(c context.Context, currentUserID uuid.UUID, targetUserID uuid.UUID) (*dto.UserPublic, error)
 	GetMutualFriends(c context.Context, currentUserID uuid.UUID, targetUserID uuid.UUID) (*dto.MutualFriendRes, error)
 
@@ -36,20 +39,27 @@ type IUserService interface {
 	Unfriend(c context.Context, userInfo *auth.UserInfo, targetUserID uuid.UUID) error
 	GetMyFriends(c context.Context, userInfo *auth.UserInfo) (*dto.FriendListRes, error)
 
-	UpsertMyAppLink(c context.Context, userInfo *auth.UserInfo, req *dto.UpsertAppLinkReq) (*dto.UpsertAppLinkRes, error)
 	DeleteMyAppLink(c context.Context, userInfo *auth.UserInfo, provider models.AppProvider) error
 }
 
 type userService struct {
 	repositories.IUserRepository
+	repositories.ITwoFactorRepository
+	INotificationService
+	IAccountService
+	ISigninSecurityService
 	pool    *pgxpool.Pool
 	timeout time.Duration
 	mu      sync.RWMutex
 }
 
-func NewUserService(repository repositories.IUserRepository, pool *pgxpool.Pool) IUserService {
+func NewUserService(repository repositories.IUserRepository, twoFactorRepo repositories.ITwoFactorRepository, notificationService INotificationService, accountService IAccountService, signinSecurityService ISigninSecurityService, pool *pgxpool.Pool) IUserService {
 	return &userService{
 		repository,
+		twoFactorRepo,
+		notificationService,
+		accountService,
+		signinSecurityService,
 		pool,
 		time.Duration(2) * time.Second,
 		sync.RWMutex{},
@@ -71,8 +81,11 @@ func (s *userService) Signup(c context.Context, req *dto.SignupUserReq) (*dto.Si
 	if err != nil {
 		return nil, utils.ErrorInvalidUserName
 	}
-	canonPassword, err := utils.SanitizePasswordPolicy(req.Password)
+	canonPassword, err := utils.SanitizeNewPassword(req.Password)
 	if err != nil {
+		if errors.Is(err, utils.ErrorBreachedPassword) {
+			return nil, err
+		}
 		return nil, utils.ErrorInvalidPassword
 	}
 	canonEmail, err := utils.SanitizeEmail(req.Email)
@@ -121,6 +134,11 @@ func (s *userService) Signup(c context.Context, req *dto.SignupUserReq) (*dto.Si
 		return nil, utils.ErrorInternal
 	}
 
+	// the account exists either way, a failed e-mail can be resent from /me
+	if err := s.IAccountService.SendVerificationEmail(c, userCRES); err != nil {
+		log.Printf("signup: verification email for %s: %v", userCRES.ID, err)
+	}
+
 	return &dto.SignupUserRes{
 		ID:       userCRES.ID,
 		Username: userCRES.Username,
@@ -128,7 +146,7 @@ func (s *userService) Signup(c context.Context, req *dto.SignupUserReq) (*dto.Si
 	}, nil
 }
 
-func (s *userService) Signin(c context.Context, req *dto.SigninUserReq) (*dto.SigninUserRes, error) {
+func (s *userService) Signin(c context.Context, req *dto.SigninUserReq, client *dto.ClientInfo) (*dto.SigninUserRes, error) {
 	ctx, cancel := context.WithTimeout(c, s.timeout)
 	defer cancel()
 
@@ -151,19 +169,65 @@ func (s *userService) Signin(c context.Context, req *dto.SigninUserReq) (*dto.Si
 
 	user, err := s.IUserRepository.GetUserWithPasswordHashByEmail(ctx, runner, canonEmail)
 	if err != nil {
+		user = nil
+	}
+
+	var userID *uuid.UUID
+	if user != nil {
+		userID = &user.ID
+	}
+	if err := s.ISigninSecurityService.CheckSignin(c, client, userID, canonEmail); err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		s.ISigninSecurityService.RecordSigninFailure(c, client, nil, canonEmail, models.SigninMethodPassword, models.SigninFailureUnknownAccount)
 		return nil, utils.ErrorUserNotFound
 	}
 
 	err = utils.VerifyPassword(user.PasswordHash, canonPassword)
 	if err != nil {
+		s.ISigninSecurityService.RecordSigninFailure(c, client, userID, canonEmail, models.SigninMethodPassword, models.SigninFailureWrongPassword)
 		return nil, utils.ErrorWrongPassword
 	}
 
+	// older hashes are upgraded while the plain password is at hand, failing
+	// that is no reason to refuse the sign-in
+	if utils.PasswordNeedsRehash(user.PasswordHash) {
+		if rehashed, err := utils.HashPassword(canonPassword); err == nil {
+			if err := s.IUserRepository.UpdatePasswordHash(ctx, runner, user.ID, rehashed); err != nil {
+				log.Printf("signin: rehash password for %s: %v", user.ID, err)
+			}
+		}
+	}
+
+	// the password alone isn't enough, hand back a short lived challenge instead
+	twoFactorEnabled, err := s.ITwoFactorRepository.IsTwoFactorEnabled(ctx, runner, user.ID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+	if twoFactorEnabled {
+		mfaToken, err := auth.GetMFAChallengeToken(user)
+		if err != nil {
+			return nil, utils.ErrorInternal
+		}
+		return &dto.SigninUserRes{
+			Success:     true,
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
 	signedToken, err := auth.GetSignedToken(user)
 	if err != nil {
 		return nil, utils.ErrorCreatingUser
 	}
 
+	s.ISigninSecurityService.RecordSigninSuccess(c, client, user, models.SigninMethodPassword)
+
 	userMe, err := s.buildUserMe(ctx, runner, user)
 	if err != nil {
 		return nil, err
@@ -214,6 +278,28 @@ func (s *userService) GetUserById(c context.Context, userID uuid.UUID) (*models.
 	return user, nil
 }
 
+func (s *userService) GetUsersByIds(c context.Context, userIDs []uuid.UUID) ([]*models.User, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	users, err := s.IUserRepository.GetUsersByIds(ctx, runner, userIDs)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingUser
+	}
+
+	return users, nil
+}
+
 func (s *userService) GetUserPublic(c context.Context, currentUserID uuid.UUID, targetUserID uuid.UUID) (*dto.UserPublic, error) {
 	ctx, cancel := context.WithTimeout(c, s.timeout)
 	defer cancel()
@@ -425,6 +511,16 @@ func (s *userService) UpdateEmail(c context.Context, userInfo *auth.UserInfo, re
 		return nil, utils.ErrorEmailExists
 	}
 
+	current, err := s.IUserRepository.GetUserById(ctx, runner, userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorUserNotFound
+	}
+
+	// same address (maybe different casing), keep the verified state as is
+	if strings.EqualFold(current.Email, canonEmail) {
+		return s.buildUserMe(ctx, runner, current)
+	}
+
 	updated, err := s.IUserRepository.UpdateEmail(ctx, runner, userInfo.ID, canonEmail)
 	if err != nil {
 		return nil, utils.ErrorUserNotFound
@@ -434,6 +530,10 @@ func (s *userService) UpdateEmail(c context.Context, userInfo *auth.UserInfo, re
 		return nil, utils.ErrorInternal
 	}
 
+	if err := s.IAccountService.SendVerificationEmail(c, updated); err != nil {
+		log.Printf("update email: verification email for %s: %v", updated.ID, err)
+	}
+
 	conn, err := s.pool.Acquire(ctx)
 	if err != nil {
 		return nil, utils.ErrorInternal
@@ -532,10 +632,22 @@ func (s *userService) SendFriendRequest(c context.Context, userInfo *auth.UserIn
 		return nil, utils.ErrorUserNotFound
 	}
 
+	notifications, err := s.INotificationService.CreateNotifications(ctx, runner, []*models.Notification{{
+		UserID:      receiver.ID,
+		Type:        models.NotificationFriendRequest,
+		ActorID:     &sender.ID,
+		ReferenceID: &saved.ID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
 	if err := runner.Commit(ctx); err != nil {
 		return nil, utils.ErrorInternal
 	}
 
+	s.INotificationService.PublishNotifications(notifications)
+
 	return &dto.FriendRequestRes{
 		ID:        saved.ID,
 		Sender:    dto.ToUserPublic(*sender),
@@ -589,10 +701,22 @@ func (s *userService) RespondFriendRequest(c context.Context, userInfo *auth.Use
 		return utils.ErrorInternal
 	}
 
+	notifications, err := s.INotificationService.CreateNotifications(ctx, runner, []*models.Notification{{
+		UserID:      friendRequest.SenderID,
+		Type:        models.NotificationFriendRequestAccepted,
+		ActorID:     &friendRequest.ReceiverID,
+		ReferenceID: &friendRequest.ID,
+	}})
+	if err != nil {
+		return err
+	}
+
 	if err := runner.Commit(ctx); err != nil {
 		return utils.ErrorInternal
 	}
 
+	s.INotificationService.PublishNotifications(notifications)
+
 	return nil
 }
 
@@ -670,48 +794,6 @@ func (s *userService) GetMyFriends(c context.Context, userInfo *auth.UserInfo) (
 	}, nil
 }
 
-func (s *userService) UpsertMyAppLink(c context.Context, userInfo *auth.UserInfo, req *dto.UpsertAppLinkReq) (*dto.UpsertAppLinkRes, error) {
-	ctx, cancel := context.WithTimeout(c, s.timeout)
-	defer cancel()
-
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return nil, utils.ErrorInternal
-	}
-	runner := database.NewTxWrapper(tx)
-	defer runner.Rollback(ctx)
-
-	id, err := uuid.NewV7()
-	if err != nil {
-		return nil, utils.ErrorInternal
-	}
-
-	saved, err := s.IUserRepository.UpsertAppLink(ctx, runner, &models.UserAppLink{
-		ID:            id,
-		UserID:        userInfo.ID,
-		Provider:      req.Provider,
-		URL:           req.URL,
-		ShowOnProfile: req.Show,
-	})
-	if err != nil {
-		return nil, utils.ErrorInternal
-	}
-
-	if err := runner.Commit(ctx); err != nil {
-		return nil, utils.ErrorInternal
-	}
-
-	return &dto.UpsertAppLinkRes{
-		ID:            saved.ID,
-		UserID:        saved.UserID,
-		Provider:      saved.Provider,
-		URL:           saved.URL,
-		ShowOnProfile: saved.ShowOnProfile,
-		CreatedAt:     saved.CreatedAt,
-		UpdatedAt:     saved.UpdatedAt,
-	}, nil
-}
-
 func (s *userService) DeleteMyAppLink(c context.Context, userInfo *auth.UserInfo, provider models.AppProvider) error {
 	ctx, cancel := context.WithTimeout(c, s.timeout)
 	defer cancel()
@@ -757,6 +839,8 @@ func mapAppLinks(links []*models.UserAppLink) []dto.AppLink {
 			Provider: link.Provider,
 			URL:      link.URL,
 			Show:     link.ShowOnProfile,
+			Handle:   link.ExternalHandle,
+			Verified: link.IsVerified(),
 		})
 	}
 	return out
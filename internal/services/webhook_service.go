@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/suck-seed/yapp/internal/auth"
+	"github.com/suck-seed/yapp/internal/database"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+const MAX_WEBHOOKS_PER_ROOM = 15
+
+type IWebhookService interface {
+	CreateWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID, req *dto.CreateWebhookReq) (*dto.WebhookURLRes, error)
+	ListRoomWebhooks(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID) (*dto.WebhooksRes, error)
+	RotateWebhookToken(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID, webhookID uuid.UUID) (*dto.WebhookURLRes, error)
+	DeleteWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID, webhookID uuid.UUID) error
+
+	// ExecuteWebhook posts the payload into the webhook's room, the token from
+	// the URL is the only credential
+	ExecuteWebhook(c context.Context, webhookID uuid.UUID, token string, req *dto.ExecuteWebhookReq) (*dto.CreateMessageRes, error)
+}
+
+type webhookService struct {
+	repositories.IWebhookRepository
+	repositories.IBotRepository
+	repositories.IUserRepository
+	repositories.IRoomRepository
+
+	IPermissionCheckerService
+	IMessageService
+
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	mu      sync.RWMutex
+}
+
+func NewWebhookService(
+	webhookRepo repositories.IWebhookRepository,
+	botRepo repositories.IBotRepository,
+	userRepo repositories.IUserRepository,
+	roomRepo repositories.IRoomRepository,
+	permissionChecker IPermissionCheckerService,
+	messageService IMessageService,
+	pool *pgxpool.Pool,
+) IWebhookService {
+	return &webhookService{
+		webhookRepo,
+		botRepo,
+		userRepo,
+		roomRepo,
+		permissionChecker,
+		messageService,
+		pool,
+		time.Duration(2) * time.Second,
+		sync.RWMutex{},
+	}
+}
+
+// requireManageChannels checks the room is in the hall and the user may
+// manage its channels
+func (s *webhookService) requireManageChannels(ctx context.Context, runner database.DBRunner, userID, hallID, roomID uuid.UUID) error {
+	room, err := s.IRoomRepository.GetRoomByID(ctx, runner, roomID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorRoomNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorFetchingRoom
+	}
+	if room.HallID != hallID {
+		return utils.ErrorRoomNotFound
+	}
+
+	ok, err := s.IPermissionCheckerService.CanManageChannels(ctx, runner, userID, hallID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return utils.ErrorUserCannotManageChannels
+	}
+	return nil
+}
+
+// getRoomWebhook is ErrorWebhookNotFound for webhooks of other rooms too
+func (s *webhookService) getRoomWebhook(ctx context.Context, runner database.DBRunner, roomID, webhookID uuid.UUID) (*models.Webhook, error) {
+	webhook, err := s.IWebhookRepository.GetWebhookByID(ctx, runner, webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorWebhookNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingWebhook
+	}
+	if webhook.RoomID != roomID {
+		return nil, utils.ErrorWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func webhookURL(webhookID uuid.UUID, token string) string {
+	return "/api/v1/webhooks/" + webhookID.String() + "/" + token
+}
+
+// ── MANAGE ─────────────────────────────────────────────────────────────────
+
+func (s *webhookService) CreateWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID, req *dto.CreateWebhookReq) (*dto.WebhookURLRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	name, err := utils.SanitizeDisplayName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.requireManageChannels(ctx, runner, userInfo.ID, hallID, roomID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.IWebhookRepository.CountRoomWebhooks(ctx, runner, roomID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingWebhook
+	}
+	if count >= MAX_WEBHOOKS_PER_ROOM {
+		return nil, utils.ErrorTooManyWebhooks
+	}
+
+	userID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	// the author of everything the webhook posts. No owner, so it never shows
+	// up among anyone's bots and can't be given a bot token.
+	_, err = s.IBotRepository.CreateBot(ctx, runner, &models.User{
+		ID:          userID,
+		Username:    "webhook-" + strings.ReplaceAll(userID.String(), "-", "")[20:],
+		DisplayName: name,
+		Email:       userID.String() + "@webhooks.yapp.invalid",
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorCreatingWebhook
+	}
+
+	if req.AvatarURL != nil {
+		if _, err := s.IUserRepository.UpdateUserById(ctx, runner, userID, map[string]any{"avatar_url": *req.AvatarURL}); err != nil {
+			if utils.IsDeadline(err) {
+				return nil, utils.ErrorRequestTimeout
+			}
+			return nil, utils.ErrorCreatingWebhook
+		}
+	}
+
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	webhookID, err := uuid.NewV7()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	webhook, err := s.IWebhookRepository.CreateWebhook(ctx, runner, &models.Webhook{
+		ID:        webhookID,
+		RoomID:    roomID,
+		UserID:    userID,
+		Name:      name,
+		AvatarURL: req.AvatarURL,
+		TokenHash: tokenHash,
+		CreatedBy: &userInfo.ID,
+	})
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorCreatingWebhook
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.WebhookURLRes{
+		Webhook: dto.ToWebhookRes(webhook),
+		Token:   token,
+		URL:     webhookURL(webhook.ID, token),
+	}, nil
+}
+
+func (s *webhookService) ListRoomWebhooks(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID) (*dto.WebhooksRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	if err := s.requireManageChannels(ctx, runner, userInfo.ID, hallID, roomID); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.IWebhookRepository.ListRoomWebhooks(ctx, runner, roomID)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingWebhook
+	}
+
+	res := &dto.WebhooksRes{Webhooks: make([]dto.WebhookRes, 0, len(webhooks))}
+	for _, webhook := range webhooks {
+		res.Webhooks = append(res.Webhooks, dto.ToWebhookRes(webhook))
+	}
+	return res, nil
+}
+
+func (s *webhookService) RotateWebhookToken(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID, webhookID uuid.UUID) (*dto.WebhookURLRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.requireManageChannels(ctx, runner, userInfo.ID, hallID, roomID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getRoomWebhook(ctx, runner, roomID, webhookID); err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	// the old URL stops working as soon as this commits
+	webhook, err := s.IWebhookRepository.UpdateWebhookToken(ctx, runner, webhookID, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorWebhookNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.WebhookURLRes{
+		Webhook: dto.ToWebhookRes(webhook),
+		Token:   token,
+		URL:     webhookURL(webhook.ID, token),
+	}, nil
+}
+
+func (s *webhookService) DeleteWebhook(c context.Context, userInfo *auth.UserInfo, hallID uuid.UUID, roomID uuid.UUID, webhookID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return utils.ErrorInternal
+	}
+	runner := database.NewTxWrapper(tx)
+	defer runner.Rollback(ctx)
+
+	if err := s.requireManageChannels(ctx, runner, userInfo.ID, hallID, roomID); err != nil {
+		return err
+	}
+
+	if _, err := s.getRoomWebhook(ctx, runner, roomID, webhookID); err != nil {
+		return err
+	}
+
+	if err := s.IWebhookRepository.DeleteWebhook(ctx, runner, webhookID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return utils.ErrorWebhookNotFound
+		}
+		if utils.IsDeadline(err) {
+			return utils.ErrorRequestTimeout
+		}
+		return utils.ErrorInternal
+	}
+
+	if err := runner.Commit(ctx); err != nil {
+		return utils.ErrorInternal
+	}
+
+	return nil
+}
+
+// ── EXECUTE ────────────────────────────────────────────────────────────────
+
+func (s *webhookService) ExecuteWebhook(c context.Context, webhookID uuid.UUID, token string, req *dto.ExecuteWebhookReq) (*dto.CreateMessageRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	if req.Content == nil && (req.Attachments == nil || len(*req.Attachments) == 0) {
+		return nil, utils.ErrorInvalidInput
+	}
+
+	var name *string
+	if req.Username != nil {
+		canon, err := utils.SanitizeDisplayName(*req.Username)
+		if err != nil {
+			return nil, err
+		}
+		name = &canon
+	}
+
+	webhook, err := s.authenticateWebhook(ctx, webhookID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.IMessageService.CreateMessage(ctx, &dto.CreateMessageReq{
+		RoomID:      webhook.RoomID,
+		AuthorID:    webhook.UserID,
+		Content:     req.Content,
+		SentAt:      time.Now().UTC(),
+		Attachments: req.Attachments,
+		Webhook: &dto.WebhookAuthor{
+			WebhookID: webhook.ID,
+			Name:      name,
+			AvatarURL: req.AvatarURL,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (s *webhookService) authenticateWebhook(ctx context.Context, webhookID uuid.UUID, token string) (*models.Webhook, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+	defer conn.Release()
+	runner := database.NewConnWrapper(conn)
+
+	webhook, err := s.IWebhookRepository.GetWebhookByID(ctx, runner, webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.ErrorWebhookNotFound
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorFetchingWebhook
+	}
+
+	if !hmac.Equal(webhook.TokenHash, auth.HashOpaqueToken(token)) {
+		return nil, utils.ErrorInvalidWebhookToken
+	}
+
+	// bookkeeping only, a failed touch doesn't fail the post
+	_ = s.IWebhookRepository.TouchWebhook(ctx, runner, webhook.ID)
+
+	return webhook, nil
+}
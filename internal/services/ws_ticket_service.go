@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/user"
+	"github.com/suck-seed/yapp/internal/models"
+	"github.com/suck-seed/yapp/internal/repositories"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// long enough to open the socket right after asking, short enough that a
+// leaked ticket is useless
+const wsTicketTTL = 30 * time.Second
+
+type IWSTicketService interface {
+	IssueWSTicket(ctx context.Context, userInfo *auth.UserInfo, origin string) (*dto.WSTicketRes, error)
+
+	// RedeemWSTicket burns the ticket, it has to be presented from the same
+	// origin it was issued to
+	RedeemWSTicket(ctx context.Context, ticket string, origin string) (*auth.UserInfo, error)
+}
+
+type wsTicketService struct {
+	repositories.IWSTicketRepository
+
+	timeout time.Duration
+}
+
+func NewWSTicketService(wsTicketRepo repositories.IWSTicketRepository) IWSTicketService {
+	return &wsTicketService{
+		IWSTicketRepository: wsTicketRepo,
+		timeout:             2 * time.Second,
+	}
+}
+
+func (s *wsTicketService) IssueWSTicket(c context.Context, userInfo *auth.UserInfo, origin string) (*dto.WSTicketRes, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	raw, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	expiresAt := time.Now().Add(wsTicketTTL)
+	err = s.IWSTicketRepository.CreateWSTicket(ctx, hash, &models.WSTicket{
+		UserID:    userInfo.ID,
+		Username:  userInfo.Username,
+		IsBot:     userInfo.IsBot,
+		Origin:    origin,
+		ExpiresAt: expiresAt,
+	}, wsTicketTTL)
+	if err != nil {
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	return &dto.WSTicketRes{
+		Ticket:    raw,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *wsTicketService) RedeemWSTicket(c context.Context, raw string, origin string) (*auth.UserInfo, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	ticket, err := s.IWSTicketRepository.ConsumeWSTicket(ctx, auth.HashOpaqueToken(raw))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, utils.ErrorInvalidWSTicket
+		}
+		if utils.IsDeadline(err) {
+			return nil, utils.ErrorRequestTimeout
+		}
+		return nil, utils.ErrorInternal
+	}
+
+	// the key TTL already covers this, unless redis was restored from a snapshot
+	if time.Now().After(ticket.ExpiresAt) || ticket.Origin != origin {
+		return nil, utils.ErrorInvalidWSTicket
+	}
+
+	return &auth.UserInfo{
+		ID:       ticket.UserID,
+		Username: ticket.Username,
+		IsBot:    ticket.IsBot,
+	}, nil
+}
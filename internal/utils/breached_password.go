@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// The breached password list is a local copy of the Pwned Passwords ranges,
+// one file per 5 character SHA-1 prefix (the layout the range API and its
+// downloader use), each line a 35 character suffix and a count:
+//
+//	<dir>/21BD1.txt
+//	0018A45C4D1DEF81644B54AB7F969B88D65:10
+//
+// A lookup only ever reads the one file for its prefix, so the whole list
+// never has to be loaded and the password never leaves the process.
+
+var breachedPasswordDir atomic.Pointer[string]
+
+// UseBreachedPasswordList points the check at a ranges directory, an empty
+// dir turns it off
+func UseBreachedPasswordList(dir string) {
+	breachedPasswordDir.Store(&dir)
+}
+
+// IsPasswordBreached reports whether the password is on the list. Always false
+// while no list is configured.
+func IsPasswordBreached(password string) (bool, error) {
+	dir := breachedPasswordDir.Load()
+	if dir == nil || *dir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	f, err := os.Open(filepath.Join(*dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		f, err = os.Open(filepath.Join(*dir, prefix))
+	}
+	if err != nil {
+		// a missing range file means nothing in it was ever breached
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		hash, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(hash, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
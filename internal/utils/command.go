@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var commandNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+var commandDurationRegex = regexp.MustCompile(`^(?:(\d+)d)?(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?$`)
+
+// IsValidCommandName : lowercase, digits, - and _, at most 32 long
+func IsValidCommandName(name string) bool {
+	return commandNameRegex.MatchString(name)
+}
+
+// ParseCommandLine splits "/name rest of line" into the lowercased name and
+// the raw rest, ok is false when content isn't a slash command at all
+func ParseCommandLine(content string) (string, string, bool) {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "/") {
+		return "", "", false
+	}
+
+	name, rest, _ := strings.Cut(content[1:], " ")
+	name = strings.ToLower(name)
+	if !IsValidCommandName(name) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(rest), true
+}
+
+// SplitCommandArgs splits on whitespace, keeping "double quoted" runs together
+func SplitCommandArgs(rest string) []string {
+	args := make([]string, 0)
+
+	var current strings.Builder
+	inQuotes, hasToken := false, false
+	for _, r := range rest {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasToken {
+				args = append(args, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+	if hasToken {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// ParseCommandDuration reads durations people type, like "90s", "10m",
+// "1h30m" or "2d"
+func ParseCommandDuration(raw string) (time.Duration, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return 0, false
+	}
+
+	match := commandDurationRegex.FindStringSubmatch(raw)
+	if match == nil {
+		return 0, false
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(match[i+1], 10, 32)
+		if err != nil {
+			return 0, false
+		}
+		total += time.Duration(n) * unit
+	}
+
+	return total, total > 0
+}
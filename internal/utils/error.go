@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,6 +18,11 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+// Extensions puts the status code next to the message in GraphQL errors
+func (e *AppError) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
 var (
 	ErrorTest1 = &AppError{Code: http.StatusForbidden, Message: "Forbidden 1"}
 	ErrorTest2 = &AppError{Code: http.StatusForbidden, Message: "Forbidden 2"}
@@ -43,6 +49,12 @@ var (
 	ErrorInvalidToken  = &AppError{Code: http.StatusUnauthorized, Message: "Invalid Authorization Token"}
 	ErrorTokenExpired  = &AppError{Code: http.StatusUnauthorized, Message: "Authorization Token expired"}
 
+	ErrorMissingCSRFToken = &AppError{Code: http.StatusForbidden, Message: "Missing CSRF token"}
+	ErrorInvalidCSRFToken = &AppError{Code: http.StatusForbidden, Message: "Invalid CSRF token"}
+
+	ErrorSigninThrottled = &AppError{Code: http.StatusTooManyRequests, Message: "Too many sign-in attempts, wait a moment and try again"}
+	ErrorSigninLocked    = &AppError{Code: http.StatusTooManyRequests, Message: "Sign-in is temporarily locked after too many failed attempts, try again later"}
+
 	// =========================
 	// CONFLICT / ALREADY EXISTS
 	// =========================
@@ -71,6 +83,7 @@ var (
 	ErrorInvalidDisplayName                     = &AppError{Code: http.StatusBadRequest, Message: "Invalid Display Name"}
 	ErrorInvalidPassword                        = &AppError{Code: http.StatusBadRequest, Message: "Invalid Password Format"}
 	ErrorPasswordWhiteSpace                     = &AppError{Code: http.StatusBadRequest, Message: "Password has whitespace"}
+	ErrorBreachedPassword                       = &AppError{Code: http.StatusBadRequest, Message: "Password has appeared in a data breach, choose another one"}
 	ErrorInvalidIDFormart                       = &AppError{Code: http.StatusBadRequest, Message: "Error, Invalid ID format"}
 	ErrorInvalidRoomType                        = &AppError{Code: http.StatusBadRequest, Message: "Invalid Room Type"}
 	ErrorInvalidBannerColor                     = &AppError{Code: http.StatusBadRequest, Message: "Invalid banner color"}
@@ -131,6 +144,7 @@ var (
 	ErrorHallDefaultRoleNotFound = &AppError{Code: http.StatusNotFound, Message: "Hall Member not found"}
 	ErrorMessageNotFound         = &AppError{Code: http.StatusNotFound, Message: "Message not found"}
 	ErrorReactionNotFound        = &AppError{Code: http.StatusNotFound, Message: "Reaction not found"}
+	ErrorMessageNotPinned        = &AppError{Code: http.StatusNotFound, Message: "Message is not pinned"}
 
 	// =========================
 	// JOIN REQUEST ERRORS
@@ -170,6 +184,7 @@ var (
 	ErrorUserCannotManageInvites           = &AppError{Code: http.StatusUnauthorized, Message: "User does not have privilege to manage invites"}
 	ErrorUserCannotManageServer            = &AppError{Code: http.StatusUnauthorized, Message: "User does not have privilege to manage hall"}
 	ErrorUserCannotManageRequests          = &AppError{Code: http.StatusUnauthorized, Message: "User does not have privilege to manage requests"}
+	ErrorUserCannotManageChannels          = &AppError{Code: http.StatusUnauthorized, Message: "User does not have privilege to manage channels"}
 	ErrorUnauthorizedToUpdateHall          = &AppError{Code: http.StatusUnauthorized, Message: "Not Authorized to update hall"}
 	ErrorCannotUpdateDefaultRolePermission = &AppError{Code: http.StatusUnauthorized, Message: "Default Role's Permissions cannot be updated"}
 	ErrorCannotUpdateAdminRolePermission   = &AppError{Code: http.StatusUnauthorized, Message: "Admin Role's Permissions cannot be updated"}
@@ -210,6 +225,7 @@ var (
 	// =========================
 	ErrorFailedUpgrade       = &AppError{Code: http.StatusBadRequest, Message: "Failed to upgrade connection"}
 	ErrorInvalidRoomIDFormat = &AppError{Code: http.StatusBadRequest, Message: "Invalid Room Id"}
+	ErrorInvalidWSTicket     = &AppError{Code: http.StatusUnauthorized, Message: "WebSocket ticket is invalid, expired or already used"}
 
 	// =========================
 	// MESSAGE / FILE ERRORS
@@ -271,8 +287,240 @@ var (
 		Code:    http.StatusInternalServerError,
 		Message: "Error occured while Moving Hall",
 	}
+
+	// =========================
+	// NOTIFICATION ERRORS
+	// =========================
+	ErrorNotificationNotFound  = &AppError{Code: http.StatusNotFound, Message: "Notification not found"}
+	ErrorFetchingNotifications = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while fetching Notifications"}
+	ErrorCreatingNotification  = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while creating Notification"}
+	ErrorUpdatingNotification  = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while updating Notification"}
+	ErrorDeletingNotification  = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while deleting Notification"}
+
+	// =========================
+	// WEB PUSH ERRORS
+	// =========================
+	ErrorPushNotConfigured         = &AppError{Code: http.StatusServiceUnavailable, Message: "Web push is not configured on this server"}
+	ErrorInvalidPushEndpoint       = &AppError{Code: http.StatusBadRequest, Message: "Push endpoint must be an https URL"}
+	ErrorTooManyPushSubscriptions  = &AppError{Code: http.StatusBadRequest, Message: "Too many devices registered for push, remove one first"}
+	ErrorPushSubscriptionNotFound  = &AppError{Code: http.StatusNotFound, Message: "Push subscription not found"}
+	ErrorFetchingPushSubscriptions = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while fetching Push Subscriptions"}
+	ErrorSavingPushSubscription    = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while saving Push Subscription"}
+	ErrorDeletingPushSubscription  = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while deleting Push Subscription"}
+
+	// =========================
+	// NOTIFICATION SETTINGS ERRORS
+	// =========================
+	ErrorInvalidNotificationScope     = &AppError{Code: http.StatusBadRequest, Message: "Notification scope must be one of hall, floor or room"}
+	ErrorNotificationSettingNotFound  = &AppError{Code: http.StatusNotFound, Message: "Notification setting not found"}
+	ErrorFetchingNotificationSettings = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while fetching Notification Settings"}
+	ErrorSavingNotificationSetting    = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while saving Notification Setting"}
+	ErrorDeletingNotificationSetting  = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while deleting Notification Setting"}
+
+	// =========================
+	// EMAIL VERIFICATION / PASSWORD RESET ERRORS
+	// =========================
+	ErrorEmailNotVerified     = &AppError{Code: http.StatusForbidden, Message: "Verify your email address to do this"}
+	ErrorEmailAlreadyVerified = &AppError{Code: http.StatusBadRequest, Message: "Email address is already verified"}
+	ErrorInvalidEmailToken    = &AppError{Code: http.StatusBadRequest, Message: "Link is invalid or has expired"}
+	ErrorEmailTokenCooldown   = &AppError{Code: http.StatusTooManyRequests, Message: "Please wait a minute before requesting another email"}
+	ErrorIssuingEmailToken    = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while preparing the email"}
+
+	// =========================
+	// TWO FACTOR ERRORS
+	// =========================
+	ErrorTwoFactorAlreadyEnabled = &AppError{Code: http.StatusBadRequest, Message: "Two-factor authentication is already enabled"}
+	ErrorTwoFactorNotEnabled     = &AppError{Code: http.StatusBadRequest, Message: "Two-factor authentication is not enabled"}
+	ErrorTwoFactorNotEnrolled    = &AppError{Code: http.StatusBadRequest, Message: "Start two-factor enrollment first"}
+	ErrorTwoFactorRequired       = &AppError{Code: http.StatusForbidden, Message: "Enable two-factor authentication on your account first"}
+	ErrorInvalidTwoFactorCode    = &AppError{Code: http.StatusUnauthorized, Message: "Invalid two-factor code"}
+	ErrorInvalidMFAToken         = &AppError{Code: http.StatusUnauthorized, Message: "Sign-in attempt expired, sign in again"}
+	ErrorUpdatingTwoFactor       = &AppError{Code: http.StatusInternalServerError, Message: "Error occured while updating two-factor settings"}
+
+	// =========================
+	// EXTERNAL LOGIN ERRORS
+	// =========================
+	ErrorOIDCProviderNotFound  = &AppError{Code: http.StatusNotFound, Message: "Login provider not found"}
+	ErrorOIDCProviderFailed    = &AppError{Code: http.StatusBadGateway, Message: "Login provider could not be reached"}
+	ErrorInvalidOIDCState      = &AppError{Code: http.StatusBadRequest, Message: "Login attempt expired or was already used, try again"}
+	ErrorInvalidOIDCLogin      = &AppError{Code: http.StatusUnauthorized, Message: "Login provider rejected the sign in"}
+	ErrorInvalidSignupToken    = &AppError{Code: http.StatusUnauthorized, Message: "Sign-up attempt expired, sign in with the provider again"}
+	ErrorOIDCEmailInUse        = &AppError{Code: http.StatusConflict, Message: "An account with this email already exists, sign in and link the provider from your settings"}
+	ErrorIdentityAlreadyLinked = &AppError{Code: http.StatusConflict, Message: "This login is already linked to an account"}
+	ErrorProviderAlreadyLinked = &AppError{Code: http.StatusConflict, Message: "A login from this provider is already linked to your account"}
+	ErrorIdentityNotFound      = &AppError{Code: http.StatusNotFound, Message: "Linked login not found"}
+	ErrorCannotRemoveLastLogin = &AppError{Code: http.StatusConflict, Message: "Set a password or link another login before removing this one"}
+
+	// =========================
+	// APP LINK ERRORS
+	// =========================
+	ErrorAppLinkProviderNotFound    = &AppError{Code: http.StatusNotFound, Message: "App provider not found"}
+	ErrorAppLinkProviderFailed      = &AppError{Code: http.StatusBadGateway, Message: "App provider could not be reached"}
+	ErrorInvalidAppLinkState        = &AppError{Code: http.StatusBadRequest, Message: "Connection attempt expired or was already used, try again"}
+	ErrorAppLinkAuthorizationFailed = &AppError{Code: http.StatusUnauthorized, Message: "App provider did not authorize the connection"}
+	ErrorAppAccountAlreadyLinked    = &AppError{Code: http.StatusConflict, Message: "This account is already connected to another profile"}
+
+	// =========================
+	// SIGNING KEY ERRORS
+	// =========================
+	ErrorSigningKeyNotFound          = &AppError{Code: http.StatusNotFound, Message: "Signing key not found"}
+	ErrorUnsupportedSigningAlgorithm = &AppError{Code: http.StatusBadRequest, Message: "Signing algorithm must be EdDSA or RS256"}
+
+	// =========================
+	// RATE LIMIT ERRORS
+	// =========================
+	ErrorRateLimited = &AppError{Code: http.StatusTooManyRequests, Message: "Too many requests, slow down"}
+	ErrorSlowmode    = &AppError{Code: http.StatusTooManyRequests, Message: "Slow mode is on, wait before sending another message"}
+
+	// =========================
+	// PIN ERRORS
+	// =========================
+	ErrorTooManyPins  = &AppError{Code: http.StatusBadRequest, Message: "This room has reached the maximum number of pinned messages, unpin one first"}
+	ErrorFetchingPins = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching pinned messages"}
+
+	// =========================
+	// SAVED MESSAGE ERRORS
+	// =========================
+	ErrorSavedMessageNotFound  = &AppError{Code: http.StatusNotFound, Message: "Saved message not found"}
+	ErrorMessageAlreadySaved   = &AppError{Code: http.StatusConflict, Message: "You have saved this message already"}
+	ErrorTooManySavedMessages  = &AppError{Code: http.StatusBadRequest, Message: "You have reached the maximum number of saved messages"}
+	ErrorInvalidReminderTime   = &AppError{Code: http.StatusBadRequest, Message: "Reminders must be set between a minute and a year from now"}
+	ErrorFetchingSavedMessages = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching saved messages"}
+
+	// =========================
+	// SCHEDULED MESSAGE ERRORS
+	// =========================
+	ErrorScheduledMessageNotFound  = &AppError{Code: http.StatusNotFound, Message: "Scheduled message not found"}
+	ErrorScheduledMessageSending   = &AppError{Code: http.StatusConflict, Message: "This message is being posted right now"}
+	ErrorTooManyScheduledMessages  = &AppError{Code: http.StatusBadRequest, Message: "You have reached the maximum number of scheduled messages"}
+	ErrorInvalidScheduleTime       = &AppError{Code: http.StatusBadRequest, Message: "Messages can be scheduled between a minute and a year from now"}
+	ErrorEmptyScheduledMessage     = &AppError{Code: http.StatusBadRequest, Message: "A scheduled message needs content or attachments"}
+	ErrorCannotSendMessages        = &AppError{Code: http.StatusForbidden, Message: "You don't have permission to send messages in this room"}
+	ErrorFetchingScheduledMessages = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching scheduled messages"}
+
+	// =========================
+	// BOT ERRORS
+	// =========================
+	ErrorBotNotFound     = &AppError{Code: http.StatusNotFound, Message: "Bot not found"}
+	ErrorInvalidBotToken = &AppError{Code: http.StatusUnauthorized, Message: "Invalid bot token"}
+	ErrorBotsNotAllowed  = &AppError{Code: http.StatusForbidden, Message: "Bots can't do this"}
+	ErrorTooManyBots     = &AppError{Code: http.StatusBadRequest, Message: "You have reached the maximum number of bots"}
+	ErrorBotsOnly        = &AppError{Code: http.StatusForbidden, Message: "Only bots can do this"}
+
+	// =========================
+	// WEBHOOK ERRORS
+	// =========================
+	ErrorWebhookNotFound     = &AppError{Code: http.StatusNotFound, Message: "Webhook not found"}
+	ErrorInvalidWebhookToken = &AppError{Code: http.StatusUnauthorized, Message: "Invalid webhook token"}
+	ErrorTooManyWebhooks     = &AppError{Code: http.StatusBadRequest, Message: "This room has reached the maximum number of webhooks"}
+	ErrorCreatingWebhook     = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while creating webhook"}
+	ErrorFetchingWebhook     = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching webhook"}
+
+	// =========================
+	// OUTGOING WEBHOOK ERRORS
+	// =========================
+	ErrorOutgoingWebhookNotFound     = &AppError{Code: http.StatusNotFound, Message: "Outgoing webhook not found"}
+	ErrorInvalidOutgoingWebhookURL   = &AppError{Code: http.StatusBadRequest, Message: "Outgoing webhook URL must be https"}
+	ErrorInvalidOutgoingWebhookEvent = &AppError{Code: http.StatusBadRequest, Message: "Unknown outgoing webhook event type"}
+	ErrorTooManyOutgoingWebhooks     = &AppError{Code: http.StatusBadRequest, Message: "This hall has reached the maximum number of outgoing webhooks"}
+	ErrorCreatingOutgoingWebhook     = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while creating outgoing webhook"}
+	ErrorUpdatingOutgoingWebhook     = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while updating outgoing webhook"}
+	ErrorFetchingOutgoingWebhook     = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching outgoing webhook"}
+	ErrorFetchingOutgoingDeliveries  = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching outgoing webhook deliveries"}
+
+	// =========================
+	// COMMAND ERRORS
+	// =========================
+	ErrorUnknownCommand           = &AppError{Code: http.StatusNotFound, Message: "Unknown command"}
+	ErrorCommandNameTaken         = &AppError{Code: http.StatusConflict, Message: "A command with this name already exists in this hall"}
+	ErrorInvalidCommandDefinition = &AppError{Code: http.StatusBadRequest, Message: "Invalid command definition"}
+	ErrorTooManyCommands          = &AppError{Code: http.StatusBadRequest, Message: "A bot can register at most 50 commands per hall"}
+	ErrorInteractionNotFound      = &AppError{Code: http.StatusNotFound, Message: "Command interaction not found or expired"}
+	ErrorTooManyReminders         = &AppError{Code: http.StatusBadRequest, Message: "You have too many pending reminders"}
+	ErrorFetchingCommands         = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching commands"}
+	ErrorSavingCommands           = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while saving commands"}
+
+	// =========================
+	// PERSONAL ACCESS TOKEN ERRORS
+	// =========================
+	ErrorInvalidPersonalToken     = &AppError{Code: http.StatusUnauthorized, Message: "Invalid or expired access token"}
+	ErrorInsufficientScope        = &AppError{Code: http.StatusForbidden, Message: "This token doesn't have the scope needed for this request"}
+	ErrorPersonalTokensNotAllowed = &AppError{Code: http.StatusForbidden, Message: "Access tokens can't be used here, sign in instead"}
+	ErrorPersonalTokenNotFound    = &AppError{Code: http.StatusNotFound, Message: "Personal access token not found"}
+	ErrorInvalidTokenScope        = &AppError{Code: http.StatusBadRequest, Message: "Unknown token scope"}
+	ErrorTooManyPersonalTokens    = &AppError{Code: http.StatusBadRequest, Message: "You have reached the maximum number of personal access tokens"}
+	ErrorFetchingPersonalTokens   = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching personal access tokens"}
+	ErrorCreatingPersonalToken    = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while creating personal access token"}
+
+	// =========================
+	// OAUTH ERRORS
+	// =========================
+	ErrorOAuthAppNotFound           = &AppError{Code: http.StatusNotFound, Message: "OAuth app not found"}
+	ErrorTooManyOAuthApps           = &AppError{Code: http.StatusBadRequest, Message: "You have reached the maximum number of OAuth apps"}
+	ErrorInvalidRedirectURI         = &AppError{Code: http.StatusBadRequest, Message: "Redirect URIs must be https, http on localhost or a reverse-domain app scheme, without a fragment"}
+	ErrorRedirectURIMismatch        = &AppError{Code: http.StatusBadRequest, Message: "redirect_uri is not registered for this app"}
+	ErrorUnsupportedResponseType    = &AppError{Code: http.StatusBadRequest, Message: "Only response_type=code is supported"}
+	ErrorPKCERequired               = &AppError{Code: http.StatusBadRequest, Message: "This app must use PKCE with code_challenge_method=S256"}
+	ErrorOAuthAppIsPublic           = &AppError{Code: http.StatusBadRequest, Message: "Public apps don't have a client secret"}
+	ErrorOAuthAuthorizationNotFound = &AppError{Code: http.StatusNotFound, Message: "You haven't authorized this app"}
+	ErrorFetchingOAuthApps          = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while fetching OAuth apps"}
+	ErrorSavingOAuthApp             = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while saving OAuth app"}
+	ErrorIssuingOAuthCode           = &AppError{Code: http.StatusInternalServerError, Message: "Error occurred while authorizing the app"}
 )
 
+// CooldownError : an AppError that goes away on its own after Remaining
+type CooldownError struct {
+	*AppError
+	Remaining time.Duration
+}
+
+func (e *CooldownError) Unwrap() error {
+	return e.AppError
+}
+
+// OAuthError : what the token, revocation and introspection endpoints
+// answer with, shaped by RFC 6749 instead of the usual envelope
+type OAuthError struct {
+	Status      int
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+var (
+	ErrorOAuthInvalidRequest       = &OAuthError{Status: http.StatusBadRequest, Code: "invalid_request", Description: "The request is missing a parameter or has an invalid one"}
+	ErrorOAuthInvalidClient        = &OAuthError{Status: http.StatusUnauthorized, Code: "invalid_client", Description: "Client authentication failed"}
+	ErrorOAuthInvalidGrant         = &OAuthError{Status: http.StatusBadRequest, Code: "invalid_grant", Description: "The code or refresh token is invalid, expired, already used or was issued to another client"}
+	ErrorOAuthInvalidScope         = &OAuthError{Status: http.StatusBadRequest, Code: "invalid_scope", Description: "The requested scope is unknown or exceeds what was granted"}
+	ErrorOAuthUnsupportedGrantType = &OAuthError{Status: http.StatusBadRequest, Code: "unsupported_grant_type", Description: "Only authorization_code and refresh_token are supported"}
+	ErrorOAuthServerError          = &OAuthError{Status: http.StatusInternalServerError, Code: "server_error", Description: "Something went wrong, try again"}
+)
+
+// WriteOAuthError : anything that isn't an OAuthError becomes server_error
+func WriteOAuthError(c *gin.Context, err error) {
+	var oauthError *OAuthError
+	if !errors.As(err, &oauthError) {
+		oauthError = ErrorOAuthServerError
+	}
+
+	if oauthError.Status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="yapp"`)
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(oauthError.Status, gin.H{
+		"error":             oauthError.Code,
+		"error_description": oauthError.Description,
+	})
+}
+
+// CommandUsageError : a bad slash command invocation, answered with how to call it
+func CommandUsageError(usage string) *AppError {
+	return &AppError{Code: http.StatusBadRequest, Message: "Usage: " + usage}
+}
+
 // Writing Errors from handlers to client
 
 func WriteError(c *gin.Context, err error) {
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RFC 7636, 43 to 128 unreserved characters
+var pkceVerifierRegex = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// reverse-domain private schemes for native apps, RFC 8252 section 7.1
+var appSchemeRegex = regexp.MustCompile(`^[a-z][a-z0-9+\-]*(\.[a-z0-9+\-]+)+$`)
+
+// ParseOAuthScopes splits a space delimited scope parameter, dropping repeats
+func ParseOAuthScopes(raw string) []string {
+	scopes := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, scope := range strings.Fields(raw) {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// ValidateRedirectURI : https anywhere, http only on a loopback host unless
+// allowInsecure, or a reverse-domain scheme like com.example.app:/callback.
+// Fragments are never allowed.
+func ValidateRedirectURI(raw string, allowInsecure bool) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Fragment != "" || strings.Contains(raw, "#") || u.Scheme == "" {
+		return "", false
+	}
+
+	switch u.Scheme {
+	case "https":
+		return raw, u.Host != ""
+	case "http":
+		if u.Host == "" {
+			return "", false
+		}
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return raw, true
+		}
+		return raw, allowInsecure
+	default:
+		return raw, appSchemeRegex.MatchString(u.Scheme)
+	}
+}
+
+// VerifyPKCE checks an S256 code_verifier against the code_challenge
+func VerifyPKCE(verifier string, challenge string) bool {
+	if !pkceVerifierRegex.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// AppendQuery adds params to a redirect URI, keeping the query it already has
+func AppendQuery(rawURL string, params map[string]string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
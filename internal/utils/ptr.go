@@ -3,3 +3,7 @@ package utils
 func StringToPointer(s string) *string {
 	return &s
 }
+
+func Int64ToPointer(n int64) *int64 {
+	return &n
+}
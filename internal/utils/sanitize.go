@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"log"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -162,6 +163,27 @@ func SanitizePasswordPolicy(raw string) (string, error) {
 	return raw, nil
 }
 
+// SanitizeNewPassword : SanitizePasswordPolicy plus the breached password list,
+// for passwords being set. Sign-in leaves the list out, a password that turns
+// up in a breach later still has to let its owner in to change it.
+func SanitizeNewPassword(raw string) (string, error) {
+	canon, err := SanitizePasswordPolicy(raw)
+	if err != nil {
+		return "", err
+	}
+
+	breached, err := IsPasswordBreached(canon)
+	if err != nil {
+		// an unreadable list shouldn't stop anyone from signing up
+		log.Printf("breached passwords: %v", err)
+		return canon, nil
+	}
+	if breached {
+		return "", ErrorBreachedPassword
+	}
+	return canon, nil
+}
+
 // COLOR SECTION
 func SanitizeColorFormat(colorHex *string) (*string, error) {
 
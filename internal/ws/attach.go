@@ -0,0 +1,67 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/utils"
+)
+
+// Attach registers a client without a socket of its own, e.g. a GraphQL
+// subscription, and returns the frames the hub sends it. It gets the same
+// room subscriptions and access resyncs as a /ws connection and is dropped
+// when ctx ends. A slow reader is disconnected like any other client, the
+// channel is closed then.
+func (h *Hub) Attach(ctx context.Context, userInfo *auth.UserInfo) (<-chan *dto.OutboundMessage, error) {
+	if h.AccessResolver == nil {
+		return nil, utils.ErrorInternal
+	}
+
+	subscribedRooms, err := h.AccessResolver(ctx, userInfo.ID)
+	if err != nil {
+		return nil, utils.ErrorConnectingWebsocket
+	}
+
+	clientID, err := uuid.NewUUID()
+	if err != nil {
+		return nil, utils.ErrorInternal
+	}
+
+	const sendBuf = 256
+	client := &Client{
+		ID:              clientID,
+		Send:            make(chan *dto.OutboundMessage, sendBuf),
+		UserID:          userInfo.ID,
+		IsBot:           userInfo.IsBot,
+		SubscribedRooms: subscribedRooms,
+		ConnectedAt:     time.Now(),
+		LastPing:        time.Now(),
+	}
+
+	h.Register <- client
+
+	// no pongs come in to keep presence alive, refresh on the ping schedule
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				h.Unregister <- client
+				return
+
+			case <-ticker.C:
+				client.LastPing = time.Now()
+				if h.PresenceService != nil {
+					_ = h.PresenceService.RefreshConnection(context.Background(), client.UserID, client.ID)
+				}
+			}
+		}
+	}()
+
+	return client.Send, nil
+}
@@ -25,6 +25,9 @@ type Client struct {
 
 	UserID uuid.UUID
 
+	// IsBot connections are rate limited under the "bot.ws" policies
+	IsBot bool
+
 	// Map RoomID -> HallID
 	// The gateway subscribes this one client connection
 	// to every room the user can access
@@ -130,6 +133,11 @@ func (c *Client) readPump(hub *Hub) {
 		// Server-owned identity. Never trust these from frontend.
 		inboundMessage.UserID = c.UserID
 		inboundMessage.ClientID = c.ID
+		inboundMessage.IsBot = c.IsBot
+
+		if !hub.allowInbound(c, inboundMessage) {
+			continue
+		}
 
 		// sync_subscriptions is a connection-level command.
 		// It does NOT need room_id.
@@ -0,0 +1,36 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/suck-seed/yapp/internal/auth"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	"github.com/suck-seed/yapp/internal/services"
+)
+
+type CommandFunction func(ctx context.Context, in *dto.InboundMessage) (*dto.OutboundMessage, error)
+
+// MakeCommandFunction : Runs a slash command, the returned frame is meant for the invoker only
+func MakeCommandFunction(commandService services.ICommandService) CommandFunction {
+	return func(ctx context.Context, in *dto.InboundMessage) (*dto.OutboundMessage, error) {
+		userInfo := &auth.UserInfo{
+			ID:    in.UserID,
+			IsBot: in.IsBot,
+		}
+
+		result, err := commandService.ExecuteCommand(ctx, userInfo, in.ClientID, in.RoomID, *in.Content)
+		if err != nil || result == nil {
+			return nil, err
+		}
+
+		return &dto.OutboundMessage{
+			Type:     dto.MessageTypeCommandResponse,
+			RoomID:   in.RoomID,
+			AuthorID: in.UserID,
+			Content:  &result.Content,
+			Command:  &result.Command,
+			SentAt:   time.Now().UTC(),
+		}, nil
+	}
+}
@@ -2,6 +2,8 @@ package ws
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 
 	"sync"
@@ -35,10 +37,14 @@ type Hub struct {
 	// Persistence callback
 	PersistFunc     PersistFunction
 	ReadReceiptFunc ReadReceiptFunction
+	CommandFunc     CommandFunction
 
 	// Presence Service
 	PresenceService services.IPresenceService
 
+	// Inbound frames are limited per connection and message type
+	RateLimitService services.IRateLimitService
+
 	// Event Mapping
 	EventBus       *realtime.EventBus
 	AccessResolver AccessResolver
@@ -51,23 +57,27 @@ type Hub struct {
 func NewHub(
 	p PersistFunction,
 	readFunc ReadReceiptFunction,
+	commandFunc CommandFunction,
 	presenceService services.IPresenceService,
+	rateLimitService services.IRateLimitService,
 	eventBus *realtime.EventBus,
 	accessResolver AccessResolver,
 ) Hub {
 	return Hub{
-		Rooms:           make(map[uuid.UUID]*Room),   // room_id -> room subscription bucket
-		Clients:         make(map[uuid.UUID]*Client), // client_id -> client
-		UserClients:     make(map[uuid.UUID]map[uuid.UUID]*Client),
-		Register:        make(chan *Client, 1024),
-		Unregister:      make(chan *Client, 1024),
-		Inbound:         make(chan *dto.InboundMessage, 1024),
-		Outbound:        make(chan *dto.OutboundMessage, 1024),
-		PersistFunc:     p,
-		ReadReceiptFunc: readFunc,
-		PresenceService: presenceService,
-		EventBus:        eventBus,
-		AccessResolver:  accessResolver,
+		Rooms:            make(map[uuid.UUID]*Room),   // room_id -> room subscription bucket
+		Clients:          make(map[uuid.UUID]*Client), // client_id -> client
+		UserClients:      make(map[uuid.UUID]map[uuid.UUID]*Client),
+		Register:         make(chan *Client, 1024),
+		Unregister:       make(chan *Client, 1024),
+		Inbound:          make(chan *dto.InboundMessage, 1024),
+		Outbound:         make(chan *dto.OutboundMessage, 1024),
+		PersistFunc:      p,
+		ReadReceiptFunc:  readFunc,
+		CommandFunc:      commandFunc,
+		PresenceService:  presenceService,
+		RateLimitService: rateLimitService,
+		EventBus:         eventBus,
+		AccessResolver:   accessResolver,
 	}
 }
 
@@ -257,8 +267,21 @@ func (h *Hub) processTextMessage(msg *dto.InboundMessage) {
 		return
 	}
 
+	// "/name ..." runs a command instead of being posted, bots post as is
+	if !msg.IsBot && h.CommandFunc != nil && msg.Content != nil {
+		if _, _, ok := utils.ParseCommandLine(*msg.Content); ok {
+			h.processCommand(msg)
+			return
+		}
+	}
+
 	outboundingMsg, err := h.PersistFunc(context.Background(), msg)
 	if err != nil {
+		var cooldown *utils.CooldownError
+		if errors.As(err, &cooldown) {
+			h.sendSlowmodeToClient(msg, cooldown)
+			return
+		}
 		h.sendErrorToClient(msg.ClientID, msg.RoomID, msg.UserID, err.Error())
 		return
 	}
@@ -271,6 +294,24 @@ func (h *Hub) processTextMessage(msg *dto.InboundMessage) {
 
 }
 
+func (h *Hub) processCommand(msg *dto.InboundMessage) {
+	out, err := h.CommandFunc(context.Background(), msg)
+	if err != nil {
+		var cooldown *utils.CooldownError
+		if errors.As(err, &cooldown) {
+			h.sendSlowmodeToClient(msg, cooldown)
+			return
+		}
+		h.sendErrorToClient(msg.ClientID, msg.RoomID, msg.UserID, err.Error())
+		return
+	}
+
+	// only the invoker sees built-in replies
+	if out != nil {
+		h.sendToClientID(msg.ClientID, out)
+	}
+}
+
 func (h *Hub) processTypingIndicator(msg *dto.InboundMessage) {
 
 	if !h.isClientSubscribedToRoom(msg.ClientID, msg.RoomID) {
@@ -397,6 +438,61 @@ func (h *Hub) sendErrorToClient(clientID uuid.UUID, roomID uuid.UUID, userID uui
 	h.sendToClientID(clientID, errMsg)
 }
 
+// sendRateLimitedToClient tells the client which frame was dropped and when
+// it can send that type again
+func (h *Hub) sendRateLimitedToClient(msg *dto.InboundMessage, retryAfter time.Duration) {
+	errMsg := &dto.OutboundMessage{
+		Type:         dto.MessageTypeError,
+		RoomID:       msg.RoomID,
+		AuthorID:     msg.UserID,
+		Error:        utils.StringToPointer(fmt.Sprintf("too many %s messages, slow down", msg.Type)),
+		ErrorCode:    utils.StringToPointer("rate_limited"),
+		RetryAfterMs: utils.Int64ToPointer(retryAfter.Milliseconds()),
+		SentAt:       time.Now(),
+	}
+	h.sendToClientID(msg.ClientID, errMsg)
+}
+
+// sendSlowmodeToClient passes on how long the room's slow mode still holds
+// the author back
+func (h *Hub) sendSlowmodeToClient(msg *dto.InboundMessage, cooldown *utils.CooldownError) {
+	errMsg := &dto.OutboundMessage{
+		Type:         dto.MessageTypeError,
+		RoomID:       msg.RoomID,
+		AuthorID:     msg.UserID,
+		Error:        utils.StringToPointer(cooldown.Error()),
+		ErrorCode:    utils.StringToPointer("slowmode"),
+		RetryAfterMs: utils.Int64ToPointer(cooldown.Remaining.Milliseconds()),
+		SentAt:       time.Now(),
+	}
+	h.sendToClientID(msg.ClientID, errMsg)
+}
+
+// allowInbound takes a token for the frame's type, types without a policy of
+// their own share the "ws" one. Bot connections use the "bot.ws" set.
+func (h *Hub) allowInbound(client *Client, msg *dto.InboundMessage) bool {
+	if h.RateLimitService == nil {
+		return true
+	}
+
+	class := "ws"
+	if client.IsBot {
+		class = "bot.ws"
+	}
+
+	policy := class + "." + string(msg.Type)
+	if !h.RateLimitService.HasRateLimit(policy) {
+		policy = class
+	}
+
+	result := h.RateLimitService.Allow(context.Background(), policy, "client:"+msg.ClientID.String())
+	if !result.Allowed {
+		h.sendRateLimitedToClient(msg, result.RetryAfter)
+		return false
+	}
+	return true
+}
+
 func (h *Hub) sendToClientID(clientID uuid.UUID, msg *dto.OutboundMessage) {
 	var disconnected *Client
 
@@ -459,7 +555,9 @@ func (h *Hub) Close() error {
 
 	for _, client := range h.Clients {
 		client.SafeClose()
-		_ = client.Conn.Close()
+		if client.Conn != nil {
+			_ = client.Conn.Close()
+		}
 	}
 
 	close(h.Inbound)
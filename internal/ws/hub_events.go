@@ -3,8 +3,11 @@ package ws
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
+	dto "github.com/suck-seed/yapp/internal/dto/message"
+	notificationDto "github.com/suck-seed/yapp/internal/dto/notification"
 	"github.com/suck-seed/yapp/internal/realtime"
 )
 
@@ -85,11 +88,78 @@ func (h *Hub) handleHubEvent(event realtime.HubEvent) {
 	case realtime.HubEventUserAccessResync:
 		h.resyncUserAccess(context.Background(), event.UserID)
 
+	case realtime.HubEventNotificationCreated:
+		h.deliverNotification(event)
+
+	case realtime.HubEventMessageCreated,
+		realtime.HubEventMessagePinned,
+		realtime.HubEventMessageUnpinned:
+		h.deliverMessage(event)
+
+	case realtime.HubEventCommandInvoked,
+		realtime.HubEventCommandResponse:
+		h.deliverCommand(event)
+
 	default:
 		log.Printf("unknown hub event type: %+v", event)
 	}
 }
 
+// deliverNotification pushes a freshly created inbox item to every open tab of its owner.
+func (h *Hub) deliverNotification(event realtime.HubEvent) {
+	notification, ok := event.Payload.(*notificationDto.NotificationRes)
+	if !ok || notification == nil || event.UserID == uuid.Nil {
+		return
+	}
+
+	msg := &dto.OutboundMessage{
+		Type:         dto.MessageTypeNotification,
+		AuthorID:     event.UserID,
+		SentAt:       time.Now(),
+		Notification: notification,
+	}
+	if notification.HallID != nil {
+		msg.HallID = *notification.HallID
+	}
+	if notification.RoomID != nil {
+		msg.RoomID = *notification.RoomID
+	}
+
+	h.sendToUser(event.UserID, msg)
+}
+
+// deliverMessage broadcasts a message frame built outside the hub, e.g. a
+// webhook post or a pin, to its room.
+func (h *Hub) deliverMessage(event realtime.HubEvent) {
+	msg, ok := event.Payload.(*dto.OutboundMessage)
+	if !ok || msg == nil || msg.RoomID == uuid.Nil {
+		return
+	}
+
+	select {
+	case h.Outbound <- msg:
+	default:
+		log.Printf("outbound channel full, dropping message %s", msg.ID)
+	}
+}
+
+// deliverCommand sends a command frame to one connection when the event names
+// it, otherwise to every connection of the user.
+func (h *Hub) deliverCommand(event realtime.HubEvent) {
+	msg, ok := event.Payload.(*dto.OutboundMessage)
+	if !ok || msg == nil {
+		return
+	}
+
+	if event.ClientID != uuid.Nil {
+		h.sendToClientID(event.ClientID, msg)
+		return
+	}
+	if event.UserID != uuid.Nil {
+		h.sendToUser(event.UserID, msg)
+	}
+}
+
 // Subscription Mutation Helpers
 func (h *Hub) subscribeHallClientsToRoom(hallID uuid.UUID, roomID uuid.UUID) {
 	h.mu.Lock()
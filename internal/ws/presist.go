@@ -4,9 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	dto "github.com/suck-seed/yapp/internal/dto/message"
 	"github.com/suck-seed/yapp/internal/services"
-	"github.com/suck-seed/yapp/internal/utils"
 )
 
 type PersistFunction func(ctx context.Context, in *dto.InboundMessage) (*dto.OutboundMessage, error)
@@ -29,32 +29,14 @@ func MakePresistFunction(messageService services.IMessageService, userService se
 			Attachments:     in.Attachments,
 			MentionEveryone: in.MentionEveryone,
 			Mentions:        in.Mentions,
+			FromSocket:      true,
 		})
 
 		if err != nil {
 			return nil, err
 		}
 
-		return &dto.OutboundMessage{
-			Type: dto.MessageTypeText,
-
-			ID:       saved.ID,
-			RoomID:   saved.RoomID,
-			AuthorID: saved.AuthorID,
-			Content:  saved.Content,
-			SentAt:   saved.SentAt,
-
-			CreatedAt: saved.CreatedAt,
-			EditedAt:  saved.EditedAt,
-			DeletedAt: saved.DeletedAt,
-			UpdatedAt: saved.UpdatedAt,
-
-			MentionsEveryone: saved.MentionsEveryone,
-			Mentions:         saved.Mentions,
-			Attachments:      saved.Attachments,
-
-			Error: utils.StringToPointer(""),
-		}, nil
-
+		// deliverToRoom fills in the hall
+		return saved.ToOutbound(uuid.Nil), nil
 	}
 }
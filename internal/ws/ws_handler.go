@@ -15,16 +15,18 @@ import (
 )
 
 type WebsocketHandler struct {
-	hub *Hub
+	hub      *Hub
+	upgrader websocket.Upgrader
 	services.IMessageService
 	services.IHallService
 	services.IRoomService
 	services.IUserService
 }
 
-func NewWebsocketHandler(h *Hub, messageService services.IMessageService, hallService services.IHallService, roomService services.IRoomService, userService services.IUserService) *WebsocketHandler {
+func NewWebsocketHandler(h *Hub, allowedOrigins []string, messageService services.IMessageService, hallService services.IHallService, roomService services.IRoomService, userService services.IUserService) *WebsocketHandler {
 	return &WebsocketHandler{
 		h,
+		NewUpgrader(allowedOrigins),
 		messageService,
 		hallService,
 		roomService,
@@ -32,21 +34,32 @@ func NewWebsocketHandler(h *Hub, messageService services.IMessageService, hallSe
 	}
 }
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		origin := r.Header.Get("Origin")
-
-		log.Printf("Origin: \n %v\n", origin)
+// NewUpgrader only lets browsers on our own frontends open a socket. The jwt
+// cookie rides along on cross-site upgrades too, so without this any page
+// could talk to the API as the visitor. Clients that send no Origin at all
+// aren't browsers, nothing ambient gets sent on their behalf.
+func NewUpgrader(allowedOrigins []string) websocket.Upgrader {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[origin] = struct{}{}
+	}
 
-		// return origin == "https://yapp-frontend-gamma.vercel.app" ||
-		// 	origin == "http://localhost:3000" ||
-		// 	origin == "http://127.0.0.1:3000" ||
-		// 	origin == "https://yappserver.onrender.com"
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
 
-		return true
-	},
+			_, ok := allowed[origin]
+			if !ok {
+				log.Printf("ws: rejected upgrade from origin %q", origin)
+			}
+			return ok
+		},
+	}
 }
 
 // Connect godoc
@@ -73,6 +86,7 @@ var upgrader = websocket.Upgrader{
 // @Produce      json
 // @Security     CookieAuth
 // @Param        room_id  path  string  true  "Room ID (UUID)"
+// @Param        ticket   query string  false "One-time ticket from POST /ws/tickets, the jwt cookie works too"
 // @Success      101      "Switching Protocols — WebSocket handshake successful"
 // @Failure      400      {object}  map[string]interface{}  "Bad room / hall ID"
 // @Failure      401      {object}  map[string]interface{}  "Not authenticated"
@@ -104,7 +118,7 @@ func (h *WebsocketHandler) Connect(c *gin.Context) {
 	}
 
 	// Upgrade HTTP to websocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		utils.WriteError(c, utils.ErrorFailedUpgrade)
 		return
@@ -126,6 +140,7 @@ func (h *WebsocketHandler) Connect(c *gin.Context) {
 		Conn:   conn,
 		Send:   make(chan *dto.OutboundMessage, sendBuf),
 		UserID: user.ID,
+		IsBot:  userInfo.IsBot,
 
 		// INCLUDE THISSS ASAP
 		SubscribedRooms: subscribedRooms,
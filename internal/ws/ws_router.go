@@ -5,9 +5,9 @@ import (
 	"github.com/suck-seed/yapp/internal/services"
 )
 
-func RegisterWebSocketRoutes(r *gin.RouterGroup, hub *Hub, messageService services.IMessageService, hallService services.IHallService, roomService services.IRoomService, userService services.IUserService) {
+func RegisterWebSocketRoutes(r *gin.RouterGroup, hub *Hub, allowedOrigins []string, messageService services.IMessageService, hallService services.IHallService, roomService services.IRoomService, userService services.IUserService) {
 	// inject dependency to wsHandler
-	wsHandler := NewWebsocketHandler(hub, messageService, hallService, roomService, userService)
+	wsHandler := NewWebsocketHandler(hub, allowedOrigins, messageService, hallService, roomService, userService)
 
 	// Single gateway socket per browser/app/device.
 	r.GET("/", wsHandler.Connect)